@@ -19,14 +19,19 @@ type Config struct {
 
 // QueueConfig holds queue configuration
 type QueueConfig struct {
-	Type         string        `mapstructure:"type"`
-	Host         string        `mapstructure:"host"`
-	Port         int           `mapstructure:"port"`
-	Password     string        `mapstructure:"password"`
-	Database     int           `mapstructure:"database"`
-	QueueName    string        `mapstructure:"queue_name"`
-	BatchSize    int           `mapstructure:"batch_size"`
-	PollInterval time.Duration `mapstructure:"poll_interval"`
+	Type              string        `mapstructure:"type"`
+	Host              string        `mapstructure:"host"`
+	Port              int           `mapstructure:"port"`
+	Password          string        `mapstructure:"password"`
+	Database          int           `mapstructure:"database"`
+	QueueName         string        `mapstructure:"queue_name"`
+	BatchSize         int           `mapstructure:"batch_size"`
+	PollInterval      time.Duration `mapstructure:"poll_interval"`
+	VisibilityTimeout time.Duration `mapstructure:"visibility_timeout"`
+
+	// Kafka
+	Brokers []string `mapstructure:"brokers"`
+	GroupID string   `mapstructure:"group_id"`
 }
 
 // DatabaseConfig holds database configuration
@@ -68,6 +73,78 @@ type WorkerConfig struct {
 	RetryDelay      time.Duration `mapstructure:"retry_delay"`
 	ProcessTimeout  time.Duration `mapstructure:"process_timeout"`
 	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+	Lanes           LaneConfig    `mapstructure:"lanes"`
+	Autoscale       AutoscaleConfig `mapstructure:"autoscale"`
+	Leader          LeaderConfig  `mapstructure:"leader"`
+	Heartbeat       HeartbeatConfig `mapstructure:"heartbeat"`
+	Retry           RetryConfig   `mapstructure:"retry"`
+	MaxInFlight     int           `mapstructure:"max_in_flight"`
+	// IdempotencyWindow is how long a repeated idempotency key returns the original job
+	IdempotencyWindow time.Duration `mapstructure:"idempotency_window"`
+	// ShutdownGracePeriod is how long a shutdown waits for jobs in flight
+	ShutdownGracePeriod time.Duration `mapstructure:"shutdown_grace_period"`
+}
+
+// LeaderConfig holds leader election configuration
+type LeaderConfig struct {
+	LeaseTTL      time.Duration `mapstructure:"lease_ttl"`
+	RenewInterval time.Duration `mapstructure:"renew_interval"`
+}
+
+// HeartbeatConfig holds worker heartbeat configuration
+type HeartbeatConfig struct {
+	Interval     time.Duration `mapstructure:"interval"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	ReapInterval time.Duration `mapstructure:"reap_interval"`
+}
+
+// RetryConfig holds retry policy configuration. The default policy backs off
+// from retry_delay, templates and priorities override it field by field.
+type RetryConfig struct {
+	MaxDelay      time.Duration                `mapstructure:"max_delay"`
+	Jitter        float64                      `mapstructure:"jitter"`
+	ThrottleDelay time.Duration                `mapstructure:"throttle_delay"`
+	Templates     map[string]RetryPolicyConfig `mapstructure:"templates"`
+	Priorities    map[string]RetryPolicyConfig `mapstructure:"priorities"`
+	Budget        RetryBudgetConfig            `mapstructure:"budget"`
+}
+
+// RetryPolicyConfig holds the retry policy of a template or priority
+type RetryPolicyConfig struct {
+	MaxRetries    int           `mapstructure:"max_retries"`
+	BaseDelay     time.Duration `mapstructure:"base_delay"`
+	MaxDelay      time.Duration `mapstructure:"max_delay"`
+	Jitter        float64       `mapstructure:"jitter"`
+	ThrottleDelay time.Duration `mapstructure:"throttle_delay"`
+}
+
+// RetryBudgetConfig holds the retry budget configuration
+type RetryBudgetConfig struct {
+	Ratio      float64       `mapstructure:"ratio"`
+	MinRetries int           `mapstructure:"min_retries"`
+	Window     time.Duration `mapstructure:"window"`
+}
+
+// LaneConfig holds priority lane configuration
+type LaneConfig struct {
+	UrgentWeight    int           `mapstructure:"urgent_weight"`
+	HighWeight      int           `mapstructure:"high_weight"`
+	NormalWeight    int           `mapstructure:"normal_weight"`
+	LowWeight       int           `mapstructure:"low_weight"`
+	ReservedWorkers int           `mapstructure:"reserved_workers"`
+	AgingInterval   time.Duration `mapstructure:"aging_interval"`
+}
+
+// AutoscaleConfig holds worker pool autoscaling configuration
+type AutoscaleConfig struct {
+	MinWorkers         int           `mapstructure:"min_workers"`
+	MaxWorkers         int           `mapstructure:"max_workers"`
+	Interval           time.Duration `mapstructure:"interval"`
+	TargetBacklog      int           `mapstructure:"target_backlog"`
+	MaxJobAge          time.Duration `mapstructure:"max_job_age"`
+	MaxProviderLatency time.Duration `mapstructure:"max_provider_latency"`
+	ScaleUpCooldown    time.Duration `mapstructure:"scale_up_cooldown"`
+	ScaleDownCooldown  time.Duration `mapstructure:"scale_down_cooldown"`
 }
 
 // ServerConfig holds server configuration
@@ -80,12 +157,73 @@ type ServerConfig struct {
 type EmailConfig struct {
 	DefaultProvider string                    `mapstructure:"default_provider"`
 	Providers       map[string]ProviderConfig `mapstructure:"providers"`
+	// Chain lists the providers to send through, empty sends through
+	// DefaultProvider only
+	Chain          []string      `mapstructure:"chain"`
+	Routing        string        `mapstructure:"routing"`
+	HealthInterval time.Duration `mapstructure:"health_interval"`
+	// Rules route matching emails to other providers than the chain, they
+	// are reloaded when the config file changes
+	Rules          []RoutingRuleConfig  `mapstructure:"rules"`
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	RateLimits     []RateLimitConfig    `mapstructure:"rate_limits"`
+	Capture        CaptureConfig        `mapstructure:"capture"`
+}
+
+// CaptureConfig holds the capture provider settings of staging environments.
+// Store is postgres or file, empty disables capture. Emails are delivered
+// through the Deliver provider, if set, to the recipients matching the
+// allowlist and to the catch-all instead of the others.
+type CaptureConfig struct {
+	Store     string   `mapstructure:"store"`
+	Dir       string   `mapstructure:"dir"`
+	Allowlist []string `mapstructure:"allowlist"`
+	CatchAll  string   `mapstructure:"catch_all"`
+	Deliver   string   `mapstructure:"deliver"`
+}
+
+// RateLimitConfig holds the send rate limit of a provider or of a recipient
+// domain. Rate is in sends per second, burst defaults to the rate.
+type RateLimitConfig struct {
+	Provider string  `mapstructure:"provider"`
+	Domain   string  `mapstructure:"domain"`
+	Rate     float64 `mapstructure:"rate"`
+	Burst    int     `mapstructure:"burst"`
+}
+
+// CircuitBreakerConfig holds the configuration of the circuit breaker of each
+// provider. A failure threshold of zero disables circuit breakers.
+type CircuitBreakerConfig struct {
+	FailureThreshold int           `mapstructure:"failure_threshold"`
+	OpenTimeout      time.Duration `mapstructure:"open_timeout"`
+}
+
+// RoutingRuleConfig holds a provider routing rule. A rule matches the emails
+// that match every criterion it sets, and a criterion matches when any of its
+// values does.
+type RoutingRuleConfig struct {
+	Name       string   `mapstructure:"name"`
+	Domains    []string `mapstructure:"domains"`
+	Templates  []string `mapstructure:"templates"`
+	Priorities []string `mapstructure:"priorities"`
+	Tenants    []string `mapstructure:"tenants"`
+	Providers  []string `mapstructure:"providers"`
 }
 
 // ProviderConfig holds email provider configuration
 type ProviderConfig struct {
-	// SendGrid
+	// Type defaults to the name the provider is configured under
+	Type   string `mapstructure:"type"`
+	Weight int    `mapstructure:"weight"`
+	// Endpoint overrides the API endpoint of SendGrid and SES
+	Endpoint string `mapstructure:"endpoint"`
+
+	// SendGrid, Mailgun and Postmark, whose API key is its server token
 	APIKey string `mapstructure:"api_key"`
+	// Mailgun sending domain
+	Domain string `mapstructure:"domain"`
+	// Postmark message stream, outbound by default
+	MessageStream string `mapstructure:"message_stream"`
 	
 	// AWS SES
 	Region      string `mapstructure:"region"`
@@ -100,6 +238,13 @@ type ProviderConfig struct {
 	Username string `mapstructure:"username"`
 	Password string `mapstructure:"password"`
 	UseTLS   bool   `mapstructure:"use_tls"`
+	// TLSMode is auto, starttls, implicit or none, auto uses implicit TLS
+	// with use_tls or on port 465 and STARTTLS when offered otherwise
+	TLSMode string `mapstructure:"tls_mode"`
+	// Connections are pooled, idle ones are kept up to MaxIdleConns
+	MaxIdleConns int           `mapstructure:"max_idle_conns"`
+	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	MaxLifetime  time.Duration `mapstructure:"max_lifetime"`
 }
 
 // LoggingConfig holds logging configuration
@@ -246,6 +391,9 @@ func bindEnvVars() {
 	
 	// Email settings
 	viper.BindEnv("email.default_provider", "EMAIL_PROVIDER")
+	viper.BindEnv("email.chain", "EMAIL_PROVIDER_CHAIN")
+	viper.BindEnv("email.routing", "EMAIL_ROUTING")
+	viper.BindEnv("email.circuit_breaker.failure_threshold", "EMAIL_CIRCUIT_BREAKER_THRESHOLD")
 	viper.BindEnv("email.providers.sendgrid.api_key", "EMAIL_API_KEY")
 	viper.BindEnv("email.providers.sendgrid.from_email", "EMAIL_FROM")
 	
@@ -253,6 +401,16 @@ func bindEnvVars() {
 	viper.BindEnv("worker.worker_count", "WORKER_COUNT")
 	viper.BindEnv("worker.batch_size", "BATCH_SIZE")
 	viper.BindEnv("worker.max_retries", "MAX_RETRIES")
+	viper.BindEnv("worker.lanes.reserved_workers", "WORKER_RESERVED_WORKERS")
+	viper.BindEnv("worker.lanes.aging_interval", "WORKER_AGING_INTERVAL")
+	viper.BindEnv("worker.autoscale.min_workers", "WORKER_MIN_WORKERS")
+	viper.BindEnv("worker.autoscale.max_workers", "WORKER_MAX_WORKERS")
+	viper.BindEnv("worker.max_in_flight", "WORKER_MAX_IN_FLIGHT")
+	viper.BindEnv("worker.idempotency_window", "IDEMPOTENCY_WINDOW")
+	viper.BindEnv("worker.shutdown_grace_period", "WORKER_SHUTDOWN_GRACE_PERIOD")
+	viper.BindEnv("worker.leader.lease_ttl", "WORKER_LEADER_LEASE_TTL")
+	viper.BindEnv("worker.heartbeat.timeout", "WORKER_HEARTBEAT_TIMEOUT")
+	viper.BindEnv("worker.retry.budget.ratio", "WORKER_RETRY_BUDGET_RATIO")
 }
 
 // validateConfig validates the configuration
@@ -26,12 +26,7 @@ func NewConnection(cfg config.DatabaseConfig) (*DB, error) {
 	// Connect to master database
 	if cfg.MasterHost != "" {
 		// Master-slave configuration
-		masterDSN := fmt.Sprintf(
-			"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-			cfg.MasterHost, cfg.MasterPort, cfg.MasterUser, cfg.MasterPassword, cfg.MasterName, cfg.SSLMode,
-		)
-
-		masterDB, err = sqlx.Connect("postgres", masterDSN)
+		masterDB, err = sqlx.Connect("postgres", MasterDSN(cfg))
 		if err != nil {
 			return nil, fmt.Errorf("failed to connect to master database: %w", err)
 		}
@@ -69,12 +64,7 @@ func NewConnection(cfg config.DatabaseConfig) (*DB, error) {
 		}
 	} else if cfg.Host != "" {
 		// Legacy single database configuration
-		dsn := fmt.Sprintf(
-			"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode,
-		)
-
-		masterDB, err = sqlx.Connect("postgres", dsn)
+		masterDB, err = sqlx.Connect("postgres", MasterDSN(cfg))
 		if err != nil {
 			return nil, fmt.Errorf("failed to connect to database: %w", err)
 		}
@@ -96,6 +86,21 @@ func NewConnection(cfg config.DatabaseConfig) (*DB, error) {
 	}, nil
 }
 
+// MasterDSN returns the DSN of the database used for write operations,
+// falling back to the legacy single database configuration
+func MasterDSN(cfg config.DatabaseConfig) string {
+	if cfg.MasterHost != "" {
+		return fmt.Sprintf(
+			"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+			cfg.MasterHost, cfg.MasterPort, cfg.MasterUser, cfg.MasterPassword, cfg.MasterName, cfg.SSLMode,
+		)
+	}
+	return fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode,
+	)
+}
+
 // configureConnectionPool configures the connection pool settings
 func configureConnectionPool(db *sqlx.DB, cfg config.DatabaseConfig) {
 	maxOpenConns := cfg.MaxOpenConns
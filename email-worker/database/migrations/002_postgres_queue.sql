@@ -0,0 +1,33 @@
+-- Migration: 002_postgres_queue.sql
+-- Description: Lease column, claim index and pending notifications for the postgres queue
+-- Created: 2024-02-01
+
+-- Lease deadline of a claimed job, NULL when the job is not in flight
+ALTER TABLE email_jobs ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
+
+-- Claim order used by the postgres queue: priority, then FIFO
+CREATE INDEX IF NOT EXISTS idx_email_jobs_pending_claim
+    ON email_jobs(priority, created_at)
+    WHERE status = 'pending';
+
+-- Expired leases are looked up by deadline
+CREATE INDEX IF NOT EXISTS idx_email_jobs_locked_until
+    ON email_jobs(locked_until)
+    WHERE status = 'processing';
+
+-- Notify listening workers whenever a job becomes pending
+CREATE OR REPLACE FUNCTION notify_email_job_pending()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status = 'pending' THEN
+        PERFORM pg_notify('email_jobs_pending', NEW.id::text);
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS notify_email_jobs_pending ON email_jobs;
+CREATE TRIGGER notify_email_jobs_pending
+    AFTER INSERT OR UPDATE OF status ON email_jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_email_job_pending();
@@ -0,0 +1,7 @@
+-- Migration: 003_queue_lease_token.sql
+-- Description: Lease token identifying the current delivery of a claimed job
+-- Created: 2024-02-08
+
+-- Receipts carry this token, so a worker whose lease expired cannot settle
+-- a job that has been delivered to another worker in the meantime
+ALTER TABLE email_jobs ADD COLUMN IF NOT EXISTS lease_token UUID;
@@ -0,0 +1,7 @@
+-- Migration: 004_job_next_attempt.sql
+-- Description: Time of the next delivery attempt of a job waiting for a retry
+-- Created: 2024-02-12
+
+-- Retries are scheduled through the queue instead of being held in worker
+-- memory, the column records when the pending retry will be attempted
+ALTER TABLE email_jobs ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP;
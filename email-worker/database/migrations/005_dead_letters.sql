@@ -0,0 +1,19 @@
+-- Migration: 005_dead_letters.sql
+-- Description: Attempt history of jobs and the dead-letter store
+-- Created: 2024-02-15
+
+-- Failed attempts of a job (number, error, failed_at) as a JSON array
+ALTER TABLE email_jobs ADD COLUMN IF NOT EXISTS attempts JSONB;
+
+-- Jobs that exhausted their retries. The original job, including its
+-- attempt history, is kept as payload so that it can be replayed.
+CREATE TABLE IF NOT EXISTS email_dead_letters (
+    job_id UUID PRIMARY KEY,
+    template_name VARCHAR(255) NOT NULL,
+    final_error TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    dead_lettered_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_dead_letters_dead_lettered_at ON email_dead_letters(dead_lettered_at DESC);
+CREATE INDEX IF NOT EXISTS idx_email_dead_letters_template_name ON email_dead_letters(template_name);
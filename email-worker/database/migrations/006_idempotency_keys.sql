@@ -0,0 +1,18 @@
+-- Migration: 006_idempotency_keys.sql
+-- Description: Idempotency keys of submitted jobs
+-- Created: 2024-02-20
+
+-- Key the job was submitted with, if any
+ALTER TABLE email_jobs ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+
+-- Keys seen within the deduplication window. A repeated key returns the job
+-- stored as payload instead of creating a new one.
+CREATE TABLE IF NOT EXISTS email_idempotency_keys (
+    idempotency_key VARCHAR(255) PRIMARY KEY,
+    job_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_idempotency_keys_expires_at ON email_idempotency_keys(expires_at);
@@ -0,0 +1,13 @@
+-- Migration: 007_leader_terms.sql
+-- Description: Fencing tokens of the elected leader replica
+-- Created: 2024-03-04
+
+-- Leadership itself is a session advisory lock. Every replica that takes the
+-- lock starts a new term here, and the term's token fences off the work of
+-- earlier leaders.
+CREATE TABLE IF NOT EXISTS email_leader_terms (
+    name VARCHAR(255) PRIMARY KEY,
+    holder VARCHAR(255) NOT NULL,
+    token BIGINT NOT NULL,
+    elected_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
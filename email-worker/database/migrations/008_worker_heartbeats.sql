@@ -0,0 +1,13 @@
+-- Migration: 008_worker_heartbeats.sql
+-- Description: Heartbeats of the workers of all replicas
+-- Created: 2024-03-08
+
+-- Last heartbeat of each worker with the jobs it was sending. A worker that
+-- stopped beating leaves its row for the reaper to recover its jobs.
+CREATE TABLE IF NOT EXISTS email_worker_heartbeats (
+    worker VARCHAR(255) PRIMARY KEY,
+    jobs JSONB NOT NULL,
+    beat_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_worker_heartbeats_beat_at ON email_worker_heartbeats(beat_at);
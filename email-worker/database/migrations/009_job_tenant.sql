@@ -0,0 +1,6 @@
+-- Migration: 009_job_tenant.sql
+-- Description: Tenant of email jobs, used to route them to a provider
+-- Created: 2024-03-12
+
+-- Tenant the job is sent for, if any
+ALTER TABLE email_jobs ADD COLUMN IF NOT EXISTS tenant VARCHAR(100);
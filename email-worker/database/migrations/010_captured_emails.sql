@@ -0,0 +1,16 @@
+-- Migration: 010_captured_emails.sql
+-- Description: Emails stored by the capture provider of staging environments
+-- Created: 2024-03-20
+
+-- Captured emails. The fields the inbox shows are kept as JSON, the rendered
+-- message in .eml format as raw, and the lowercased sender, recipients,
+-- subject and template as search_text.
+CREATE TABLE IF NOT EXISTS captured_emails (
+    id UUID PRIMARY KEY,
+    search_text TEXT NOT NULL,
+    email JSONB NOT NULL,
+    raw BYTEA NOT NULL,
+    captured_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_captured_emails_captured_at ON captured_emails(captured_at DESC);
@@ -0,0 +1,7 @@
+-- Migration: 011_job_recipients.sql
+-- Description: Recipients of batch email jobs
+-- Created: 2024-03-22
+
+-- Recipients of a batch job with the data of their own message, as JSON. A
+-- retried batch only keeps the recipients that are still to be sent to.
+ALTER TABLE email_jobs ADD COLUMN IF NOT EXISTS recipients JSONB;
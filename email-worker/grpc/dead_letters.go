@@ -0,0 +1,179 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/protos"
+	"booking-system/email-worker/queue"
+)
+
+// ListDeadLetters implements the ListDeadLetters gRPC method
+func (s *Server) ListDeadLetters(ctx context.Context, req *protos.ListDeadLettersRequest) (*protos.ListDeadLettersResponse, error) {
+	filter := deadLetterFilterFromProto(req.Filter)
+	filter.Limit = int(req.Limit)
+	filter.Offset = int(req.Offset)
+
+	entries, err := s.processor.ListDeadLetters(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to list dead letters", zap.Error(err))
+		return &protos.ListDeadLettersResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to list dead letters: %v", err),
+		}, nil
+	}
+
+	deadLetters := make([]*protos.DeadLetter, 0, len(entries))
+	for _, entry := range entries {
+		deadLetters = append(deadLetters, deadLetterToProto(entry))
+	}
+
+	return &protos.ListDeadLettersResponse{
+		Success:     true,
+		Message:     "Dead letters listed successfully",
+		DeadLetters: deadLetters,
+	}, nil
+}
+
+// GetDeadLetter implements the GetDeadLetter gRPC method
+func (s *Server) GetDeadLetter(ctx context.Context, req *protos.GetDeadLetterRequest) (*protos.GetDeadLetterResponse, error) {
+	entry, err := s.processor.GetDeadLetter(ctx, req.JobId)
+	if err != nil {
+		return &protos.GetDeadLetterResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to get dead letter: %v", err),
+		}, nil
+	}
+
+	return &protos.GetDeadLetterResponse{
+		Success:    true,
+		Message:    "Dead letter retrieved successfully",
+		DeadLetter: deadLetterToProto(entry),
+	}, nil
+}
+
+// ReplayDeadLetters implements the ReplayDeadLetters gRPC method
+func (s *Server) ReplayDeadLetters(ctx context.Context, req *protos.ReplayDeadLettersRequest) (*protos.ReplayDeadLettersResponse, error) {
+	replayed, err := s.processor.ReplayDeadLetters(ctx, req.JobIds, deadLetterFilterFromProto(req.Filter))
+	if err != nil {
+		s.logger.Error("Failed to replay dead letters", zap.Int("replayed", replayed), zap.Error(err))
+		return &protos.ReplayDeadLettersResponse{
+			Success:  false,
+			Message:  fmt.Sprintf("Failed to replay dead letters: %v", err),
+			Replayed: int32(replayed),
+		}, nil
+	}
+
+	return &protos.ReplayDeadLettersResponse{
+		Success:  true,
+		Message:  "Dead letters replayed successfully",
+		Replayed: int32(replayed),
+	}, nil
+}
+
+// PurgeDeadLetters implements the PurgeDeadLetters gRPC method
+func (s *Server) PurgeDeadLetters(ctx context.Context, req *protos.PurgeDeadLettersRequest) (*protos.PurgeDeadLettersResponse, error) {
+	purged, err := s.processor.PurgeDeadLetters(ctx, req.JobIds, deadLetterFilterFromProto(req.Filter))
+	if err != nil {
+		s.logger.Error("Failed to purge dead letters", zap.Error(err))
+		return &protos.PurgeDeadLettersResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to purge dead letters: %v", err),
+		}, nil
+	}
+
+	return &protos.PurgeDeadLettersResponse{
+		Success: true,
+		Message: "Dead letters purged successfully",
+		Purged:  int32(purged),
+	}, nil
+}
+
+// deadLetterFilterFromProto converts a protobuf dead-letter filter
+func deadLetterFilterFromProto(filter *protos.DeadLetterFilter) queue.DeadLetterFilter {
+	if filter == nil {
+		return queue.DeadLetterFilter{}
+	}
+
+	converted := queue.DeadLetterFilter{
+		TemplateName:  filter.TemplateName,
+		ErrorContains: filter.ErrorContains,
+	}
+	if filter.Since != nil {
+		converted.Since = filter.Since.AsTime()
+	}
+	if filter.Until != nil {
+		converted.Until = filter.Until.AsTime()
+	}
+	return converted
+}
+
+// deadLetterToProto converts a dead letter to its protobuf message
+func deadLetterToProto(entry *queue.DeadLetter) *protos.DeadLetter {
+	attempts := make([]*protos.JobAttempt, 0, len(entry.Job.Attempts))
+	for _, attempt := range entry.Job.Attempts {
+		attempts = append(attempts, &protos.JobAttempt{
+			Number:   int32(attempt.Number),
+			Error:    attempt.Error,
+			FailedAt: timestamppb.New(attempt.FailedAt),
+		})
+	}
+
+	// The job was unmarshalled from JSON by the store, so it marshals again
+	payload, _ := json.Marshal(entry.Job)
+
+	return &protos.DeadLetter{
+		Job:            emailJobToProto(entry.Job),
+		FinalError:     entry.FinalError,
+		Attempts:       attempts,
+		DeadLetteredAt: timestamppb.New(entry.DeadLetteredAt),
+		Payload:        string(payload),
+	}
+}
+
+// emailJobToProto converts an email job to its protobuf message
+func emailJobToProto(job *models.EmailJob) *protos.EmailJob {
+	variables := make(map[string]string, len(job.Variables))
+	for k, v := range job.Variables {
+		variables[k] = fmt.Sprint(v)
+	}
+
+	var priority protos.JobPriority
+	switch job.Priority {
+	case models.JobPriorityUrgent:
+		priority = protos.JobPriority_PRIORITY_URGENT
+	case models.JobPriorityHigh:
+		priority = protos.JobPriority_PRIORITY_HIGH
+	case models.JobPriorityLow:
+		priority = protos.JobPriority_PRIORITY_LOW
+	default:
+		priority = protos.JobPriority_PRIORITY_NORMAL
+	}
+
+	converted := &protos.EmailJob{
+		Id:               job.ID.String(),
+		To:               job.To,
+		Cc:               job.CC,
+		Bcc:              job.BCC,
+		TemplateName:     job.TemplateName,
+		Variables:        variables,
+		Status:           jobStatusToProto(job),
+		Priority:         priority,
+		RetryCount:       int32(job.RetryCount),
+		MaxRetries:       int32(job.MaxRetries),
+		ErrorMessage:     job.ErrorMessage,
+		IdempotencyKey:   job.IdempotencyKey,
+		Tenant:           job.Tenant,
+		CreatedTimestamp: timestamppb.New(job.CreatedAt),
+		UpdatedTimestamp: timestamppb.New(job.UpdatedAt),
+	}
+	if job.CompletedAt != nil {
+		converted.CompletedTimestamp = timestamppb.New(*job.CompletedAt)
+	}
+	return converted
+}
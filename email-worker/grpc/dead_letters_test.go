@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/protos"
+	"booking-system/email-worker/queue"
+)
+
+// addDeadLetter stores a job of template that failed with finalError and
+// dead-letters it
+func (s *testServer) addDeadLetter(t *testing.T, template, finalError string) *models.EmailJob {
+	t.Helper()
+	job := models.NewEmailJob([]string{"guest@example.com"}, nil, nil, template, nil, models.JobPriorityNormal)
+	job.RecordAttempt(finalError)
+	job.MarkAsFailed()
+	require.NoError(t, s.jobs.Create(context.Background(), job))
+	require.NoError(t, s.deadLetters.Add(context.Background(), queue.NewDeadLetter(job, finalError)))
+	return job
+}
+
+func TestServer_DeadLetters(t *testing.T) {
+	s := newTestServer(t, newMemoryQueue(), nil)
+	ctx := context.Background()
+	welcome := s.addDeadLetter(t, "welcome", "provider outage")
+	verification := s.addDeadLetter(t, "email_verification", "mailbox full")
+	s.addDeadLetter(t, "password_reset", "provider outage")
+
+	listed, err := s.ListDeadLetters(ctx, &protos.ListDeadLettersRequest{})
+	require.NoError(t, err)
+	require.True(t, listed.Success, listed.Message)
+	assert.Len(t, listed.DeadLetters, 3)
+
+	listed, err = s.ListDeadLetters(ctx, &protos.ListDeadLettersRequest{Filter: &protos.DeadLetterFilter{TemplateName: "welcome"}})
+	require.NoError(t, err)
+	require.Len(t, listed.DeadLetters, 1)
+	assert.Equal(t, welcome.ID.String(), listed.DeadLetters[0].Job.Id)
+	assert.Equal(t, "provider outage", listed.DeadLetters[0].FinalError)
+	assert.Len(t, listed.DeadLetters[0].Attempts, 1)
+
+	got, err := s.GetDeadLetter(ctx, &protos.GetDeadLetterRequest{JobId: verification.ID.String()})
+	require.NoError(t, err)
+	require.True(t, got.Success, got.Message)
+	assert.Equal(t, "mailbox full", got.DeadLetter.FinalError)
+
+	// Replay by ID puts the job back on the queue
+	replayed, err := s.ReplayDeadLetters(ctx, &protos.ReplayDeadLettersRequest{JobIds: []string{welcome.ID.String()}})
+	require.NoError(t, err)
+	require.True(t, replayed.Success, replayed.Message)
+	assert.Equal(t, int32(1), replayed.Replayed)
+
+	job, err := s.queue.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, welcome.ID, job.ID)
+	assert.Equal(t, models.JobStatusPending, job.Status)
+	assert.Equal(t, models.JobStatusPending, s.storedJob(t, welcome).Status)
+
+	// Purge by filter
+	purged, err := s.PurgeDeadLetters(ctx, &protos.PurgeDeadLettersRequest{Filter: &protos.DeadLetterFilter{ErrorContains: "outage"}})
+	require.NoError(t, err)
+	require.True(t, purged.Success, purged.Message)
+	assert.Equal(t, int32(1), purged.Purged)
+
+	listed, err = s.ListDeadLetters(ctx, &protos.ListDeadLettersRequest{})
+	require.NoError(t, err)
+	require.Len(t, listed.DeadLetters, 1)
+	assert.Equal(t, verification.ID.String(), listed.DeadLetters[0].Job.Id)
+}
+
+func TestServer_DeadLettersNotFound(t *testing.T) {
+	s := newTestServer(t, newMemoryQueue(), nil)
+	ctx := context.Background()
+	s.addDeadLetter(t, "welcome", "provider outage")
+
+	for _, id := range []string{uuid.NewString(), "not-a-uuid"} {
+		got, err := s.GetDeadLetter(ctx, &protos.GetDeadLetterRequest{JobId: id})
+		require.NoError(t, err)
+		assert.False(t, got.Success, id)
+		assert.Nil(t, got.DeadLetter)
+
+		replayed, err := s.ReplayDeadLetters(ctx, &protos.ReplayDeadLettersRequest{JobIds: []string{id}})
+		require.NoError(t, err)
+		assert.False(t, replayed.Success, id)
+		assert.Zero(t, replayed.Replayed)
+
+		purged, err := s.PurgeDeadLetters(ctx, &protos.PurgeDeadLettersRequest{JobIds: []string{id}})
+		require.NoError(t, err)
+		assert.True(t, purged.Success, id)
+		assert.Zero(t, purged.Purged)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"booking-system/email-worker/config"
 	"booking-system/email-worker/models"
@@ -76,8 +77,8 @@ func (s *Server) CreateEmailJob(ctx context.Context, req *protos.CreateEmailJobR
 	// Create email job
 	job := s.createEmailJobFromRequest(req)
 
-	// Publish to queue
-	err := s.processor.PublishJob(ctx, job)
+	// Publish to queue, unless the idempotency key was already used
+	job, duplicate, err := s.processor.SubmitJob(ctx, job)
 	if err != nil {
 		s.logger.Error("Failed to publish email job", zap.Error(err))
 		return &protos.CreateEmailJobResponse{
@@ -86,7 +87,18 @@ func (s *Server) CreateEmailJob(ctx context.Context, req *protos.CreateEmailJobR
 		}, nil
 	}
 
+	if duplicate {
+		return &protos.CreateEmailJobResponse{
+			JobId:     job.ID.String(),
+			Job:       emailJobToProto(job),
+			Success:   true,
+			Message:   "Email job already exists for idempotency key",
+			Duplicate: true,
+		}, nil
+	}
+
 	return &protos.CreateEmailJobResponse{
+		JobId: job.ID.String(),
 		Job: &protos.EmailJob{
 			Id: job.ID.String(),
 		},
@@ -109,6 +121,38 @@ func (s *Server) GetEmailJob(ctx context.Context, req *protos.GetEmailJobRequest
 	}, nil
 }
 
+// GetJobStatus implements the GetJobStatus gRPC method
+func (s *Server) GetJobStatus(ctx context.Context, req *protos.GetJobStatusRequest) (*protos.GetJobStatusResponse, error) {
+	job, err := s.processor.GetJob(ctx, req.JobId)
+	if err != nil {
+		s.logger.Error("Failed to get email job", zap.String("job_id", req.JobId), zap.Error(err))
+		return &protos.GetJobStatusResponse{
+			JobId:   req.JobId,
+			Success: false,
+			Message: fmt.Sprintf("Failed to get job status: %v", err),
+		}, nil
+	}
+
+	response := &protos.GetJobStatusResponse{
+		JobId:        job.ID.String(),
+		Status:       jobStatusToProto(job),
+		RetryCount:   int32(job.RetryCount),
+		ErrorMessage: job.ErrorMessage,
+		CreatedAt:    timestamppb.New(job.CreatedAt),
+		UpdatedAt:    timestamppb.New(job.UpdatedAt),
+		Success:      true,
+		Message:      "Job status retrieved successfully",
+	}
+	if job.SentAt != nil {
+		response.CompletedAt = timestamppb.New(*job.SentAt)
+	}
+	if job.IsRetrying() {
+		response.NextAttemptAt = timestamppb.New(*job.NextAttemptAt)
+	}
+
+	return response, nil
+}
+
 // UpdateEmailJobStatus implements the UpdateEmailJobStatus gRPC method
 func (s *Server) UpdateEmailJobStatus(ctx context.Context, req *protos.UpdateEmailJobStatusRequest) (*protos.UpdateEmailJobStatusResponse, error) {
 	// This would need to be implemented to update job status
@@ -118,6 +162,45 @@ func (s *Server) UpdateEmailJobStatus(ctx context.Context, req *protos.UpdateEma
 	}, nil
 }
 
+// CancelEmailJob implements the CancelEmailJob gRPC method
+func (s *Server) CancelEmailJob(ctx context.Context, req *protos.CancelEmailJobRequest) (*protos.CancelEmailJobResponse, error) {
+	if err := s.processor.CancelJob(ctx, req.JobId); err != nil {
+		s.logger.Error("Failed to cancel email job", zap.String("job_id", req.JobId), zap.Error(err))
+		return &protos.CancelEmailJobResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to cancel email job: %v", err),
+		}, nil
+	}
+
+	return &protos.CancelEmailJobResponse{
+		Success: true,
+		Message: "Email job cancelled successfully",
+	}, nil
+}
+
+// RescheduleEmailJob implements the RescheduleEmailJob gRPC method
+func (s *Server) RescheduleEmailJob(ctx context.Context, req *protos.RescheduleEmailJobRequest) (*protos.RescheduleEmailJobResponse, error) {
+	if req.ScheduledAt == nil {
+		return &protos.RescheduleEmailJobResponse{
+			Success: false,
+			Message: "scheduled_at is required",
+		}, nil
+	}
+
+	if err := s.processor.RescheduleJob(ctx, req.JobId, req.ScheduledAt.AsTime()); err != nil {
+		s.logger.Error("Failed to reschedule email job", zap.String("job_id", req.JobId), zap.Error(err))
+		return &protos.RescheduleEmailJobResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to reschedule email job: %v", err),
+		}, nil
+	}
+
+	return &protos.RescheduleEmailJobResponse{
+		Success: true,
+		Message: "Email job rescheduled successfully",
+	}, nil
+}
+
 // ListEmailJobs implements the ListEmailJobs gRPC method
 func (s *Server) ListEmailJobs(ctx context.Context, req *protos.ListEmailJobsRequest) (*protos.ListEmailJobsResponse, error) {
 	// This would need to be implemented to list jobs
@@ -218,6 +301,8 @@ func (s *Server) createEmailJobFromRequest(req *protos.CreateEmailJobRequest) *m
 	// Convert protobuf priority to model priority
 	var priority models.JobPriority
 	switch req.Priority {
+	case protos.JobPriority_PRIORITY_URGENT:
+		priority = models.JobPriorityUrgent
 	case protos.JobPriority_PRIORITY_HIGH:
 		priority = models.JobPriorityHigh
 	case protos.JobPriority_PRIORITY_LOW:
@@ -246,6 +331,39 @@ func (s *Server) createEmailJobFromRequest(req *protos.CreateEmailJobRequest) *m
 	if req.MaxRetries > 0 {
 		job.MaxRetries = int(req.MaxRetries)
 	}
+	job.IdempotencyKey = req.IdempotencyKey
+	job.Tenant = req.Tenant
+	for _, recipient := range req.Recipients {
+		job.Recipients = append(job.Recipients, models.BatchRecipient{
+			To:   recipient.To,
+			CC:   recipient.Cc,
+			BCC:  recipient.Bcc,
+			Data: recipient.Data,
+		})
+	}
 
 	return job
-} 
\ No newline at end of file
+} 
+
+// jobStatusToProto converts a job status to its protobuf value. A pending job
+// that already failed and waits for its next attempt is reported as retrying.
+func jobStatusToProto(job *models.EmailJob) protos.JobStatus {
+	if job.IsRetrying() {
+		return protos.JobStatus_STATUS_RETRYING
+	}
+
+	switch job.Status {
+	case models.JobStatusPending:
+		return protos.JobStatus_STATUS_PENDING
+	case models.JobStatusProcessing:
+		return protos.JobStatus_STATUS_PROCESSING
+	case models.JobStatusCompleted:
+		return protos.JobStatus_STATUS_COMPLETED
+	case models.JobStatusFailed:
+		return protos.JobStatus_STATUS_FAILED
+	case models.JobStatusCancelled:
+		return protos.JobStatus_STATUS_CANCELLED
+	default:
+		return protos.JobStatus_STATUS_UNKNOWN
+	}
+}
\ No newline at end of file
@@ -0,0 +1,230 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/processor"
+	"booking-system/email-worker/protos"
+	"booking-system/email-worker/providers"
+	"booking-system/email-worker/queue"
+	"booking-system/email-worker/repositories"
+	"booking-system/email-worker/services"
+	"booking-system/email-worker/templates"
+)
+
+// failingProvider is a provider whose sends all fail with err
+type failingProvider struct {
+	err error
+}
+
+func (p *failingProvider) Name() string                     { return "failing" }
+func (p *failingProvider) Validate() error                  { return nil }
+func (p *failingProvider) Health(ctx context.Context) error { return nil }
+func (p *failingProvider) Close() error                     { return nil }
+
+func (p *failingProvider) Send(ctx context.Context, req *providers.EmailRequest) (*providers.EmailResponse, error) {
+	return nil, p.err
+}
+
+func (p *failingProvider) SendBatch(ctx context.Context, batch *providers.BatchRequest) ([]providers.BatchResult, error) {
+	return providers.SendEach(ctx, p, batch)
+}
+
+// testServer is a server over q with an in-memory dead-letter store and job
+// repository
+type testServer struct {
+	*Server
+	queue       queue.Queue
+	deadLetters *queue.MemoryDeadLetterStore
+	jobs        *repositories.MemoryEmailJobRepository
+}
+
+// newTestServer creates a server over q whose emails are sent through
+// provider, which may be nil
+func newTestServer(t *testing.T, q queue.Queue, provider providers.Provider) *testServer {
+	t.Helper()
+
+	logger := zap.NewNop()
+	deadLetters := queue.NewMemoryDeadLetterStore()
+	jobs := repositories.NewMemoryEmailJobRepository()
+	config := &processor.ProcessorConfig{
+		WorkerCount:     1,
+		BatchSize:       10,
+		PollInterval:    50 * time.Millisecond,
+		MaxRetries:      3,
+		RetryDelay:      time.Hour,
+		ProcessTimeout:  5 * time.Second,
+		CleanupInterval: time.Minute,
+	}
+	proc := processor.NewProcessor(q, deadLetters, queue.NewMemoryIdempotencyStore(),
+		queue.NewMemoryLeaderElector(), queue.NewMemoryHeartbeatStore(),
+		services.NewEmailService(jobs, nil, provider, templates.NewEngine()), config, logger)
+
+	return &testServer{Server: NewServer(proc, nil, logger), queue: q, deadLetters: deadLetters, jobs: jobs}
+}
+
+// newMemoryQueue creates the in-memory queue most tests run on
+func newMemoryQueue() *queue.MemoryQueue {
+	return queue.NewMemoryQueue(time.Minute, zap.NewNop())
+}
+
+// uncancellableQueue is a memory queue that can neither cancel nor move
+// jobs, like the Kafka queue
+type uncancellableQueue struct {
+	*queue.MemoryQueue
+}
+
+func (q *uncancellableQueue) Cancel(ctx context.Context, jobID string) error {
+	return queue.ErrNotSupported
+}
+
+func (q *uncancellableQueue) Cancelled(ctx context.Context, jobID string) (bool, error) {
+	return false, queue.ErrNotSupported
+}
+
+func (q *uncancellableQueue) Reschedule(ctx context.Context, jobID string, scheduledAt time.Time) error {
+	return queue.ErrNotSupported
+}
+
+// addJob stores a pending job and publishes it
+func (s *testServer) addJob(t *testing.T) *models.EmailJob {
+	t.Helper()
+	job := models.NewEmailJob([]string{"guest@example.com"}, nil, nil, "welcome", nil, models.JobPriorityNormal)
+	require.NoError(t, s.jobs.Create(context.Background(), job))
+	require.NoError(t, s.queue.Publish(context.Background(), job))
+	return job
+}
+
+// storedJob returns the stored state of a job
+func (s *testServer) storedJob(t *testing.T, job *models.EmailJob) *models.EmailJob {
+	t.Helper()
+	stored, err := s.jobs.GetByID(context.Background(), job.ID)
+	require.NoError(t, err)
+	return stored
+}
+
+// assertQueueSize asserts how many jobs are ready on the queue
+func (s *testServer) assertQueueSize(t *testing.T, want int64) {
+	t.Helper()
+	size, err := s.queue.Size(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, want, size)
+}
+
+func TestServer_CancelEmailJob(t *testing.T) {
+	s := newTestServer(t, newMemoryQueue(), nil)
+	ctx := context.Background()
+	job := s.addJob(t)
+
+	cancelled, err := s.CancelEmailJob(ctx, &protos.CancelEmailJobRequest{JobId: job.ID.String()})
+	require.NoError(t, err)
+	require.True(t, cancelled.Success, cancelled.Message)
+	assert.Equal(t, models.JobStatusCancelled, s.storedJob(t, job).Status)
+	s.assertQueueSize(t, 0)
+
+	status, err := s.GetJobStatus(ctx, &protos.GetJobStatusRequest{JobId: job.ID.String()})
+	require.NoError(t, err)
+	assert.Equal(t, protos.JobStatus_STATUS_CANCELLED, status.Status)
+
+	for _, id := range []string{job.ID.String(), uuid.NewString()} {
+		cancelled, err = s.CancelEmailJob(ctx, &protos.CancelEmailJobRequest{JobId: id})
+		require.NoError(t, err)
+		assert.False(t, cancelled.Success, id)
+	}
+}
+
+func TestServer_RescheduleEmailJob(t *testing.T) {
+	s := newTestServer(t, newMemoryQueue(), nil)
+	ctx := context.Background()
+	job := s.addJob(t)
+
+	scheduledAt := time.Now().Add(time.Hour)
+	rescheduled, err := s.RescheduleEmailJob(ctx, &protos.RescheduleEmailJobRequest{
+		JobId:       job.ID.String(),
+		ScheduledAt: timestamppb.New(scheduledAt),
+	})
+	require.NoError(t, err)
+	require.True(t, rescheduled.Success, rescheduled.Message)
+	s.assertQueueSize(t, 0)
+
+	stored := s.storedJob(t, job)
+	assert.Equal(t, models.JobStatusPending, stored.Status)
+	require.NotNil(t, stored.ProcessedAt)
+	assert.WithinDuration(t, scheduledAt, *stored.ProcessedAt, time.Millisecond)
+
+	rescheduled, err = s.RescheduleEmailJob(ctx, &protos.RescheduleEmailJobRequest{JobId: job.ID.String()})
+	require.NoError(t, err)
+	assert.False(t, rescheduled.Success, "scheduled_at is required")
+
+	rescheduled, err = s.RescheduleEmailJob(ctx, &protos.RescheduleEmailJobRequest{
+		JobId:       uuid.NewString(),
+		ScheduledAt: timestamppb.New(scheduledAt),
+	})
+	require.NoError(t, err)
+	assert.False(t, rescheduled.Success)
+}
+
+func TestServer_CancelEmailJobOnQueueWithoutCancellation(t *testing.T) {
+	s := newTestServer(t, &uncancellableQueue{MemoryQueue: newMemoryQueue()}, nil)
+	ctx := context.Background()
+	job := s.addJob(t)
+
+	// The job stays on the queue, workers drop it by its stored status
+	cancelled, err := s.CancelEmailJob(ctx, &protos.CancelEmailJobRequest{JobId: job.ID.String()})
+	require.NoError(t, err)
+	require.True(t, cancelled.Success, cancelled.Message)
+	assert.Equal(t, models.JobStatusCancelled, s.storedJob(t, job).Status)
+	s.assertQueueSize(t, 1)
+
+	cancelled, err = s.CancelEmailJob(ctx, &protos.CancelEmailJobRequest{JobId: job.ID.String()})
+	require.NoError(t, err)
+	assert.False(t, cancelled.Success, "a cancelled job cannot be cancelled again")
+
+	rescheduled, err := s.RescheduleEmailJob(ctx, &protos.RescheduleEmailJobRequest{
+		JobId:       s.addJob(t).ID.String(),
+		ScheduledAt: timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+	assert.False(t, rescheduled.Success)
+}
+
+func TestServer_CreateEmailJobStoresRetries(t *testing.T) {
+	s := newTestServer(t, newMemoryQueue(), &failingProvider{err: errors.New("smtp unavailable")})
+	ctx := context.Background()
+
+	created, err := s.CreateEmailJob(ctx, &protos.CreateEmailJobRequest{
+		To:           []string{"guest@example.com"},
+		TemplateName: "email_verification",
+	})
+	require.NoError(t, err)
+	require.True(t, created.Success, created.Message)
+
+	status, err := s.GetJobStatus(ctx, &protos.GetJobStatusRequest{JobId: created.JobId})
+	require.NoError(t, err)
+	require.True(t, status.Success, status.Message)
+	assert.Equal(t, protos.JobStatus_STATUS_PENDING, status.Status)
+
+	// The failed send schedules a retry, which the stored job reports
+	require.NoError(t, s.processor.Start())
+	t.Cleanup(func() { require.NoError(t, s.processor.Stop()) })
+	require.Eventually(t, func() bool {
+		status, err = s.GetJobStatus(ctx, &protos.GetJobStatusRequest{JobId: created.JobId})
+		require.NoError(t, err)
+		return status.Status == protos.JobStatus_STATUS_RETRYING
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, int32(1), status.RetryCount)
+	assert.Contains(t, status.ErrorMessage, "smtp unavailable")
+	require.NotNil(t, status.NextAttemptAt)
+	assert.True(t, status.NextAttemptAt.AsTime().After(time.Now()))
+}
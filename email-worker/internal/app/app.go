@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -22,12 +23,19 @@ import (
 
 // App represents the main application
 type App struct {
-	logger          *zap.Logger
-	config          *config.Config
-	db              *database.DB
-	emailProcessor  *processor.Processor
-	emailService    *services.EmailService
-	queueInstance   queue.Queue
+	logger         *zap.Logger
+	config         *config.Config
+	db             *database.DB
+	emailProcessor *processor.Processor
+	emailService   *services.EmailService
+	emailProvider  providers.Provider
+	router         *providers.Router
+	queueInstance  queue.Queue
+	deadLetters    queue.DeadLetterStore
+	idempotency    queue.IdempotencyStore
+	leaders        queue.LeaderElector
+	heartbeats     queue.HeartbeatStore
+	rateLimiter    queue.RateLimiter
 }
 
 // NewApp creates a new application instance
@@ -65,52 +73,148 @@ func (a *App) Initialize() error {
 	// Initialize template engine
 	templateEngine := templates.NewEngine()
 
+	// Queue configuration, shared by the queue and the stores next to it
+	queueFactory := queue.NewQueueFactory(a.logger)
+	queueConfig := queue.QueueConfig{
+		Type:              a.config.Queue.Type,
+		Host:              a.config.Queue.Host,
+		Port:              a.config.Queue.Port,
+		Password:          a.config.Queue.Password,
+		Database:          a.config.Queue.Database,
+		QueueName:         a.config.Queue.QueueName,
+		BatchSize:         a.config.Queue.BatchSize,
+		PollInterval:      a.config.Queue.PollInterval.String(),
+		VisibilityTimeout: a.config.Queue.VisibilityTimeout.String(),
+		Brokers:           a.config.Queue.Brokers,
+		GroupID:           a.config.Queue.GroupID,
+		DB:                db.GetSQLDB(),
+		DSN:               database.MasterDSN(a.config.Database),
+	}
+
 	// Initialize email provider factory
 	providerConfig := make(map[string]any)
 	for name, config := range a.config.Email.Providers {
 		providerConfig[name] = map[string]any{
-			"api_key":     config.APIKey,
-			"region":      config.Region,
-			"access_key":  config.AccessKey,
-			"secret_key":  config.SecretKey,
-			"host":        config.Host,
-			"port":        config.Port,
-			"username":    config.Username,
-			"password":    config.Password,
-			"use_tls":     config.UseTLS,
+			"type":              config.Type,
+			"weight":            config.Weight,
+			"endpoint":          config.Endpoint,
+			"api_key":           config.APIKey,
+			"domain":            config.Domain,
+			"message_stream":    config.MessageStream,
+			"region":            config.Region,
+			"access_key_id":     config.AccessKey,
+			"secret_access_key": config.SecretKey,
+			"from":              config.FromEmail,
+			"from_name":         config.FromName,
+			"host":              config.Host,
+			"port":              config.Port,
+			"username":          config.Username,
+			"password":          config.Password,
+			"tls":               config.UseTLS,
+			"tls_mode":          config.TLSMode,
+			"max_idle_conns":    config.MaxIdleConns,
+			"idle_timeout":      config.IdleTimeout,
+			"max_lifetime":      config.MaxLifetime,
 		}
 	}
-	
+
+	// Refuse to start without a provider, rather than accept jobs and drop them
+	chain := a.config.Email.Chain
+	if len(chain) == 0 {
+		chain = []string{a.config.Email.DefaultProvider}
+	}
 	providerFactory := providers.NewProviderFactory(providerConfig)
-	emailProvider, err := providerFactory.CreateProvider(providers.ProviderType(a.config.Email.DefaultProvider))
+
+	// Capture emails in staging, through the "capture" provider
+	if capture := a.config.Email.Capture; capture.Store != "" {
+		captureStore, err := newCaptureStore(capture, db)
+		if err != nil {
+			return fmt.Errorf("failed to create capture store: %w", err)
+		}
+		providerFactory.SetCapture(captureStore, providers.CaptureConfig{
+			Allowlist: capture.Allowlist,
+			CatchAll:  capture.CatchAll,
+			Deliver:   capture.Deliver,
+		})
+		if _, ok := providerConfig["capture"]; !ok {
+			providerConfig["capture"] = map[string]any{"type": "capture"}
+		}
+		a.logger.Info("Email capture enabled",
+			zap.String("store", capture.Store),
+			zap.String("deliver", capture.Deliver))
+	}
+	providerFactory.SetCircuitBreaker(providers.BreakerConfig{
+		FailureThreshold: a.config.Email.CircuitBreaker.FailureThreshold,
+		OpenTimeout:      a.config.Email.CircuitBreaker.OpenTimeout,
+	})
+
+	// Limit the send rate of providers and recipient domains
+	rateLimiter, err := queueFactory.CreateRateLimiter(queueConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create rate limiter: %w", err)
+	}
+	a.rateLimiter = rateLimiter
+	if err := providerFactory.SetRateLimits(rateLimiter, rateLimits(a.config.Email.RateLimits)); err != nil {
+		return fmt.Errorf("failed to set rate limits: %w", err)
+	}
+	compositeConfig := providers.CompositeConfig{
+		Mode:           providers.RoutingMode(a.config.Email.Routing),
+		HealthInterval: a.config.Email.HealthInterval,
+	}
+	emailProvider, err := providerFactory.CreateChain(chain, compositeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create email provider: %w", err)
+	}
+	a.logger.Info("Email provider ready",
+		zap.String("provider", emailProvider.Name()),
+		zap.String("routing", a.config.Email.Routing))
+
+	// Route the emails matched by a rule to the providers of the rule
+	router, err := providers.NewRouter(providerFactory, emailProvider, compositeConfig, routingRules(a.config.Email.Rules))
 	if err != nil {
-		a.logger.Warn("Failed to create email provider, email sending will be disabled", zap.Error(err))
-		// Set emailProvider to nil - email service will handle this gracefully
-		emailProvider = nil
+		emailProvider.Close()
+		return fmt.Errorf("failed to load routing rules: %w", err)
 	}
+	a.emailProvider = router
+	a.router = router
+	a.logger.Info("Routing rules loaded", zap.Int("rules", len(a.config.Email.Rules)))
 
 	// Initialize email service
-	emailService := services.NewEmailService(jobRepo, templateRepo, emailProvider, templateEngine)
+	emailService := services.NewEmailService(jobRepo, templateRepo, router, templateEngine)
+	emailService.SetTrackingRepository(repositories.NewEmailTrackingRepository(db.GetSQLDB(), a.logger))
 	a.emailService = emailService
 
 	// Initialize queue
-	queueFactory := queue.NewQueueFactory(a.logger)
-	queueConfig := queue.QueueConfig{
-		Type:         a.config.Queue.Type,
-		Host:         a.config.Queue.Host,
-		Port:         a.config.Queue.Port,
-		Password:     a.config.Queue.Password,
-		Database:     a.config.Queue.Database,
-		QueueName:    a.config.Queue.QueueName,
-		BatchSize:    a.config.Queue.BatchSize,
-		PollInterval: a.config.Queue.PollInterval.String(),
-	}
 	queueInstance, err := queueFactory.CreateQueue(queueConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create queue: %w", err)
 	}
 	a.queueInstance = queueInstance
 
+	deadLetters, err := queueFactory.CreateDeadLetterStore(queueConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create dead-letter store: %w", err)
+	}
+	a.deadLetters = deadLetters
+
+	idempotency, err := queueFactory.CreateIdempotencyStore(queueConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create idempotency store: %w", err)
+	}
+	a.idempotency = idempotency
+
+	leaders, err := queueFactory.CreateLeaderElector(queueConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create leader elector: %w", err)
+	}
+	a.leaders = leaders
+
+	heartbeats, err := queueFactory.CreateHeartbeatStore(queueConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create heartbeat store: %w", err)
+	}
+	a.heartbeats = heartbeats
+
 	// Initialize processor
 	processorConfig := &processor.ProcessorConfig{
 		WorkerCount:     a.config.Worker.WorkerCount,
@@ -120,9 +224,53 @@ func (a *App) Initialize() error {
 		RetryDelay:      a.config.Worker.RetryDelay,
 		ProcessTimeout:  a.config.Worker.ProcessTimeout,
 		CleanupInterval: a.config.Worker.CleanupInterval,
+		Lanes: processor.LaneConfig{
+			UrgentWeight:    a.config.Worker.Lanes.UrgentWeight,
+			HighWeight:      a.config.Worker.Lanes.HighWeight,
+			NormalWeight:    a.config.Worker.Lanes.NormalWeight,
+			LowWeight:       a.config.Worker.Lanes.LowWeight,
+			ReservedWorkers: a.config.Worker.Lanes.ReservedWorkers,
+			AgingInterval:   a.config.Worker.Lanes.AgingInterval,
+		},
+		Autoscale: processor.AutoscaleConfig{
+			MinWorkers:         a.config.Worker.Autoscale.MinWorkers,
+			MaxWorkers:         a.config.Worker.Autoscale.MaxWorkers,
+			Interval:           a.config.Worker.Autoscale.Interval,
+			TargetBacklog:      a.config.Worker.Autoscale.TargetBacklog,
+			MaxJobAge:          a.config.Worker.Autoscale.MaxJobAge,
+			MaxProviderLatency: a.config.Worker.Autoscale.MaxProviderLatency,
+			ScaleUpCooldown:    a.config.Worker.Autoscale.ScaleUpCooldown,
+			ScaleDownCooldown:  a.config.Worker.Autoscale.ScaleDownCooldown,
+		},
+		Leader: processor.LeaderConfig{
+			LeaseTTL:      a.config.Worker.Leader.LeaseTTL,
+			RenewInterval: a.config.Worker.Leader.RenewInterval,
+		},
+		Heartbeat: processor.HeartbeatConfig{
+			Interval:     a.config.Worker.Heartbeat.Interval,
+			Timeout:      a.config.Worker.Heartbeat.Timeout,
+			ReapInterval: a.config.Worker.Heartbeat.ReapInterval,
+		},
+		Retry: processor.RetryConfig{
+			Default: processor.RetryPolicy{
+				MaxDelay:      a.config.Worker.Retry.MaxDelay,
+				Jitter:        a.config.Worker.Retry.Jitter,
+				ThrottleDelay: a.config.Worker.Retry.ThrottleDelay,
+			},
+			Templates:  retryPolicies(a.config.Worker.Retry.Templates),
+			Priorities: retryPolicies(a.config.Worker.Retry.Priorities),
+			Budget: processor.RetryBudget{
+				Ratio:      a.config.Worker.Retry.Budget.Ratio,
+				MinRetries: a.config.Worker.Retry.Budget.MinRetries,
+				Window:     a.config.Worker.Retry.Budget.Window,
+			},
+		},
+		MaxInFlight:         a.config.Worker.MaxInFlight,
+		IdempotencyWindow:   a.config.Worker.IdempotencyWindow,
+		ShutdownGracePeriod: a.config.Worker.ShutdownGracePeriod,
 	}
 
-	emailProcessor := processor.NewProcessor(queueInstance, emailService, processorConfig, a.logger)
+	emailProcessor := processor.NewProcessor(queueInstance, deadLetters, idempotency, leaders, heartbeats, emailService, processorConfig, a.logger)
 	a.emailProcessor = emailProcessor
 
 	// Start processor
@@ -146,9 +294,21 @@ func (a *App) Run() error {
 
 	a.logger.Info("Shutting down Email Worker Service")
 
-	// Stop processor
-	if err := a.emailProcessor.Stop(); err != nil {
+	// Drain processor, jobs not finished within the grace period go back to the queue
+	drainCtx := context.Background()
+	if a.config.Worker.ShutdownGracePeriod > 0 {
+		var cancel context.CancelFunc
+		drainCtx, cancel = context.WithTimeout(drainCtx, a.config.Worker.ShutdownGracePeriod)
+		defer cancel()
+	}
+	report, err := a.emailProcessor.Shutdown(drainCtx)
+	if err != nil {
 		a.logger.Error("Error stopping processor", zap.Error(err))
+	} else if report.HandedBack() > 0 {
+		a.logger.Warn("Jobs handed back to the queue on shutdown",
+			zap.Int("released", len(report.Released)),
+			zap.Int("interrupted", len(report.Interrupted)),
+			zap.Bool("timed_out", report.TimedOut))
 	}
 
 	// Close queue
@@ -156,6 +316,36 @@ func (a *App) Run() error {
 		a.logger.Error("Error closing queue", zap.Error(err))
 	}
 
+	// Close dead-letter store
+	if err := a.deadLetters.Close(); err != nil {
+		a.logger.Error("Error closing dead-letter store", zap.Error(err))
+	}
+
+	// Close idempotency store
+	if err := a.idempotency.Close(); err != nil {
+		a.logger.Error("Error closing idempotency store", zap.Error(err))
+	}
+
+	// Close leader elector
+	if err := a.leaders.Close(); err != nil {
+		a.logger.Error("Error closing leader elector", zap.Error(err))
+	}
+
+	// Close heartbeat store
+	if err := a.heartbeats.Close(); err != nil {
+		a.logger.Error("Error closing heartbeat store", zap.Error(err))
+	}
+
+	// Close email provider
+	if err := a.emailProvider.Close(); err != nil {
+		a.logger.Error("Error closing email provider", zap.Error(err))
+	}
+
+	// Close rate limiter
+	if err := a.rateLimiter.Close(); err != nil {
+		a.logger.Error("Error closing rate limiter", zap.Error(err))
+	}
+
 	// Close database
 	if err := a.db.Close(); err != nil {
 		a.logger.Error("Error closing database", zap.Error(err))
@@ -165,6 +355,82 @@ func (a *App) Run() error {
 	return nil
 }
 
+// retryPolicies converts configured retry policies to processor policies
+func retryPolicies(configs map[string]config.RetryPolicyConfig) map[string]processor.RetryPolicy {
+	policies := make(map[string]processor.RetryPolicy, len(configs))
+	for name, policy := range configs {
+		policies[name] = processor.RetryPolicy{
+			MaxRetries:    policy.MaxRetries,
+			BaseDelay:     policy.BaseDelay,
+			MaxDelay:      policy.MaxDelay,
+			Jitter:        policy.Jitter,
+			ThrottleDelay: policy.ThrottleDelay,
+		}
+	}
+	return policies
+}
+
+// rateLimits converts the configured rate limits
+func rateLimits(configs []config.RateLimitConfig) []providers.RateLimit {
+	limits := make([]providers.RateLimit, len(configs))
+	for i, limit := range configs {
+		limits[i] = providers.RateLimit{
+			Provider: limit.Provider,
+			Domain:   limit.Domain,
+			Rate:     limit.Rate,
+			Burst:    limit.Burst,
+		}
+	}
+	return limits
+}
+
+// newCaptureStore creates the store of captured emails
+func newCaptureStore(capture config.CaptureConfig, db *database.DB) (providers.CaptureStore, error) {
+	switch capture.Store {
+	case "postgres":
+		return providers.NewPostgresCaptureStore(db.GetSQLDB()), nil
+	case "file":
+		return providers.NewFileCaptureStore(capture.Dir)
+	default:
+		return nil, fmt.Errorf("unsupported capture store: %s", capture.Store)
+	}
+}
+
+// routingRules converts the configured routing rules
+func routingRules(configs []config.RoutingRuleConfig) []providers.RoutingRule {
+	rules := make([]providers.RoutingRule, len(configs))
+	for i, rule := range configs {
+		rules[i] = providers.RoutingRule{
+			Name:       rule.Name,
+			Domains:    rule.Domains,
+			Templates:  rule.Templates,
+			Priorities: rule.Priorities,
+			Tenants:    rule.Tenants,
+			Providers:  rule.Providers,
+		}
+	}
+	return rules
+}
+
+// ReloadConfig applies a changed configuration. Only the routing rules are
+// reloaded, other changes take effect on restart.
+func (a *App) ReloadConfig(cfg *config.Config, err error) {
+	if err != nil {
+		a.logger.Error("Failed to reload configuration", zap.Error(err))
+		return
+	}
+	if err := a.router.Reload(routingRules(cfg.Email.Rules)); err != nil {
+		a.logger.Error("Failed to reload routing rules, keeping the loaded rules", zap.Error(err))
+		return
+	}
+	a.logger.Info("Routing rules reloaded", zap.Int("rules", len(cfg.Email.Rules)))
+}
+
+// GetRouter returns the provider router
+func (a *App) GetRouter() *providers.Router {
+	return a.router
+}
+
 // GetEmailProcessor returns the email processor instance
 func (a *App) GetEmailProcessor() *processor.Processor {
 	return a.emailProcessor
@@ -173,4 +439,4 @@ func (a *App) GetEmailProcessor() *processor.Processor {
 // GetLogger returns the logger instance
 func (a *App) GetLogger() *zap.Logger {
 	return a.logger
-} 
\ No newline at end of file
+}
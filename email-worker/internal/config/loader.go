@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 
@@ -43,6 +44,24 @@ func LoadConfig() (*config.Config, error) {
 	return &cfg, nil
 }
 
+// WatchConfig calls onChange with the configuration reloaded whenever the
+// config file changes. It does nothing when no config file was read.
+func WatchConfig(onChange func(*config.Config, error)) {
+	if viper.ConfigFileUsed() == "" {
+		return
+	}
+
+	viper.OnConfigChange(func(event fsnotify.Event) {
+		var cfg config.Config
+		if err := viper.Unmarshal(&cfg); err != nil {
+			onChange(nil, fmt.Errorf("failed to unmarshal config: %w", err))
+			return
+		}
+		onChange(&cfg, nil)
+	})
+	viper.WatchConfig()
+}
+
 // setDefaults sets default configuration values
 func setDefaults() {
 	// Queue defaults
@@ -53,6 +72,9 @@ func setDefaults() {
 	viper.SetDefault("queue.queue_name", "email-jobs")
 	viper.SetDefault("queue.batch_size", 10)
 	viper.SetDefault("queue.poll_interval", "1s")
+	viper.SetDefault("queue.visibility_timeout", "5m")
+	viper.SetDefault("queue.brokers", []string{"localhost:9092"})
+	viper.SetDefault("queue.group_id", "email-worker")
 
 	// Database defaults - Master-slave configuration
 	viper.SetDefault("database.master_host", "localhost")
@@ -85,6 +107,33 @@ func setDefaults() {
 	viper.SetDefault("worker.retry_delay", "5s")
 	viper.SetDefault("worker.process_timeout", "30s")
 	viper.SetDefault("worker.cleanup_interval", "1h")
+	viper.SetDefault("worker.lanes.urgent_weight", 8)
+	viper.SetDefault("worker.lanes.high_weight", 4)
+	viper.SetDefault("worker.lanes.normal_weight", 2)
+	viper.SetDefault("worker.lanes.low_weight", 1)
+	viper.SetDefault("worker.lanes.reserved_workers", 1)
+	viper.SetDefault("worker.lanes.aging_interval", "5m")
+	viper.SetDefault("worker.autoscale.min_workers", 2)
+	viper.SetDefault("worker.autoscale.max_workers", 20)
+	viper.SetDefault("worker.autoscale.interval", "15s")
+	viper.SetDefault("worker.autoscale.target_backlog", 100)
+	viper.SetDefault("worker.autoscale.max_job_age", "1m")
+	viper.SetDefault("worker.autoscale.max_provider_latency", "10s")
+	viper.SetDefault("worker.autoscale.scale_up_cooldown", "30s")
+	viper.SetDefault("worker.autoscale.scale_down_cooldown", "5m")
+	viper.SetDefault("worker.idempotency_window", "24h")
+	viper.SetDefault("worker.shutdown_grace_period", "25s")
+	viper.SetDefault("worker.leader.lease_ttl", "15s")
+	viper.SetDefault("worker.leader.renew_interval", "5s")
+	viper.SetDefault("worker.heartbeat.interval", "10s")
+	viper.SetDefault("worker.heartbeat.timeout", "1m")
+	viper.SetDefault("worker.heartbeat.reap_interval", "30s")
+	viper.SetDefault("worker.retry.max_delay", "1h")
+	viper.SetDefault("worker.retry.jitter", 0.2)
+	viper.SetDefault("worker.retry.throttle_delay", "1m")
+	viper.SetDefault("worker.retry.budget.ratio", 0.2)
+	viper.SetDefault("worker.retry.budget.min_retries", 10)
+	viper.SetDefault("worker.retry.budget.window", "1m")
 
 	// Server defaults
 	viper.SetDefault("server.port", 8080)
@@ -92,16 +141,25 @@ func setDefaults() {
 
 	// Email defaults
 	viper.SetDefault("email.default_provider", "sendgrid")
+	viper.SetDefault("email.routing", "failover")
+	viper.SetDefault("email.health_interval", "30s")
+	viper.SetDefault("email.circuit_breaker.failure_threshold", 5)
+	viper.SetDefault("email.circuit_breaker.open_timeout", "30s")
+	viper.SetDefault("email.capture.dir", "./captured-emails")
 }
 
 // bindEnvVars binds environment variables to configuration
 func bindEnvVars() {
 	// Queue
+	viper.BindEnv("queue.type", "QUEUE_TYPE")
 	viper.BindEnv("queue.host", "REDIS_HOST")
 	viper.BindEnv("queue.port", "REDIS_PORT")
 	viper.BindEnv("queue.password", "REDIS_PASSWORD")
 	viper.BindEnv("queue.database", "REDIS_DB")
 	viper.BindEnv("queue.queue_name", "QUEUE_NAME")
+	viper.BindEnv("queue.visibility_timeout", "QUEUE_VISIBILITY_TIMEOUT")
+	viper.BindEnv("queue.brokers", "KAFKA_BROKERS")
+	viper.BindEnv("queue.group_id", "KAFKA_GROUP_ID")
 
 	// Master database
 	viper.BindEnv("database.master_host", "DB_MASTER_HOST")
@@ -132,6 +190,16 @@ func bindEnvVars() {
 	viper.BindEnv("worker.worker_count", "WORKER_COUNT")
 	viper.BindEnv("worker.batch_size", "BATCH_SIZE")
 	viper.BindEnv("worker.max_retries", "MAX_RETRIES")
+	viper.BindEnv("worker.lanes.reserved_workers", "WORKER_RESERVED_WORKERS")
+	viper.BindEnv("worker.lanes.aging_interval", "WORKER_AGING_INTERVAL")
+	viper.BindEnv("worker.autoscale.min_workers", "WORKER_MIN_WORKERS")
+	viper.BindEnv("worker.autoscale.max_workers", "WORKER_MAX_WORKERS")
+	viper.BindEnv("worker.max_in_flight", "WORKER_MAX_IN_FLIGHT")
+	viper.BindEnv("worker.idempotency_window", "IDEMPOTENCY_WINDOW")
+	viper.BindEnv("worker.shutdown_grace_period", "WORKER_SHUTDOWN_GRACE_PERIOD")
+	viper.BindEnv("worker.leader.lease_ttl", "WORKER_LEADER_LEASE_TTL")
+	viper.BindEnv("worker.heartbeat.timeout", "WORKER_HEARTBEAT_TIMEOUT")
+	viper.BindEnv("worker.retry.budget.ratio", "WORKER_RETRY_BUDGET_RATIO")
 
 	// Server
 	viper.BindEnv("server.port", "PORT")
@@ -139,6 +207,9 @@ func bindEnvVars() {
 
 	// Email providers
 	viper.BindEnv("email.default_provider", "EMAIL_PROVIDER")
+	viper.BindEnv("email.chain", "EMAIL_PROVIDER_CHAIN")
+	viper.BindEnv("email.routing", "EMAIL_ROUTING")
+	viper.BindEnv("email.circuit_breaker.failure_threshold", "EMAIL_CIRCUIT_BREAKER_THRESHOLD")
 	viper.BindEnv("email.providers.sendgrid.api_key", "EMAIL_API_KEY")
 	viper.BindEnv("email.providers.sendgrid.from_email", "EMAIL_FROM")
 	viper.BindEnv("email.providers.ses.region", "AWS_SES_REGION")
@@ -148,4 +219,13 @@ func bindEnvVars() {
 	viper.BindEnv("email.providers.smtp.port", "SMTP_PORT")
 	viper.BindEnv("email.providers.smtp.username", "SMTP_USERNAME")
 	viper.BindEnv("email.providers.smtp.password", "SMTP_PASSWORD")
+	viper.BindEnv("email.providers.smtp.tls_mode", "SMTP_TLS_MODE")
+	viper.BindEnv("email.providers.mailgun.api_key", "MAILGUN_API_KEY")
+	viper.BindEnv("email.providers.mailgun.domain", "MAILGUN_DOMAIN")
+	viper.BindEnv("email.providers.postmark.api_key", "POSTMARK_SERVER_TOKEN")
+	viper.BindEnv("email.capture.store", "EMAIL_CAPTURE_STORE")
+	viper.BindEnv("email.capture.dir", "EMAIL_CAPTURE_DIR")
+	viper.BindEnv("email.capture.allowlist", "EMAIL_CAPTURE_ALLOWLIST")
+	viper.BindEnv("email.capture.catch_all", "EMAIL_CAPTURE_CATCH_ALL")
+	viper.BindEnv("email.capture.deliver", "EMAIL_CAPTURE_DELIVER")
 } 
\ No newline at end of file
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"booking-system/email-worker/processor"
+)
+
+// registerAdminRoutes sets up the operator routes. Pinning the worker pool
+// suspends autoscaling until the pin is removed, a pool that is not
+// autoscaled cannot be pinned.
+func (s *Server) registerAdminRoutes() {
+	admin := s.router.Group("/admin")
+	admin.GET("/workers", s.getWorkerPoolHandler)
+	admin.PUT("/workers/pin", s.pinWorkersHandler)
+	admin.DELETE("/workers/pin", s.unpinWorkersHandler)
+}
+
+// workerPin is the body of a pin request
+type workerPin struct {
+	Workers int `json:"workers" binding:"required"`
+}
+
+// getWorkerPoolHandler handles worker pool status requests
+func (s *Server) getWorkerPoolHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, s.emailProcessor.GetAutoscaleStatus())
+}
+
+// pinWorkersHandler handles requests to pin the worker pool size
+func (s *Server) pinWorkersHandler(c *gin.Context) {
+	var pin workerPin
+	if err := c.ShouldBindJSON(&pin); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	if err := s.emailProcessor.PinWorkers(pin.Workers); err != nil {
+		c.JSON(pinErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, s.emailProcessor.GetAutoscaleStatus())
+}
+
+// unpinWorkersHandler handles requests to hand the pool back to the autoscaler
+func (s *Server) unpinWorkersHandler(c *gin.Context) {
+	if err := s.emailProcessor.UnpinWorkers(); err != nil {
+		c.JSON(pinErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, s.emailProcessor.GetAutoscaleStatus())
+}
+
+// pinErrorStatus maps a worker pin error to an HTTP status code
+func pinErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, processor.ErrInvalidWorkerCount):
+		return http.StatusBadRequest
+	case errors.Is(err, processor.ErrAutoscaleDisabled):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/providers"
+)
+
+// inboxPageSize is how many captured emails an inbox page lists
+const inboxPageSize = 50
+
+// registerCaptureRoutes sets up the inbox of the emails stored by the capture
+// provider. Browsers get HTML pages, the messages routes return JSON and the
+// raw route downloads an email in .eml format.
+func (s *Server) registerCaptureRoutes() {
+	inbox := s.router.Group("/inbox")
+	inbox.GET("", s.inboxHandler)
+	inbox.GET("/messages", s.listCapturedEmailsHandler)
+	inbox.GET("/messages/:id", s.getCapturedEmailHandler)
+	inbox.GET("/:id", s.inboxEmailHandler)
+	inbox.GET("/:id/raw", s.rawCapturedEmailHandler)
+}
+
+// inboxTemplate renders the inbox pages
+var inboxTemplate = template.Must(template.New("inbox").Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.}} - Email Inbox</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: .4em .6em; border-bottom: 1px solid #ddd; }
+dt { font-weight: bold; } dd { margin: 0 0 .5em 0; }
+iframe { width: 100%; height: 32em; border: 1px solid #ddd; }
+pre { white-space: pre-wrap; background: #f6f6f6; padding: 1em; }
+</style></head><body>{{end}}
+
+{{define "list"}}{{template "head" "Captured emails"}}
+<h1>Captured emails</h1>
+<form method="get"><input type="search" name="q" value="{{.Search}}" placeholder="Recipient, subject or template"> <button>Search</button></form>
+<table>
+<tr><th>Captured</th><th>To</th><th>Subject</th><th>Template</th><th>Delivered to</th></tr>
+{{range .Emails}}<tr>
+<td>{{.CapturedAt.Format "2006-01-02 15:04:05"}}</td>
+<td>{{range $i, $to := .To}}{{if $i}}, {{end}}{{$to}}{{end}}</td>
+<td><a href="/inbox/{{.ID}}">{{.Subject}}</a></td>
+<td>{{.Template}}</td>
+<td>{{range $i, $to := .DeliveredTo}}{{if $i}}, {{end}}{{$to}}{{end}}</td>
+</tr>{{else}}<tr><td colspan="5">No captured emails</td></tr>{{end}}
+</table>
+{{if .Next}}<p><a href="/inbox?q={{.Search}}&amp;offset={{.Next}}">Older emails</a></p>{{end}}
+</body></html>{{end}}
+
+{{define "email"}}{{template "head" .Subject}}
+<p><a href="/inbox">&larr; Inbox</a> &middot; <a href="/inbox/{{.ID}}/raw">Download .eml</a></p>
+<h1>{{.Subject}}</h1>
+<dl>
+<dt>From</dt><dd>{{.From}}</dd>
+<dt>To</dt><dd>{{range $i, $to := .To}}{{if $i}}, {{end}}{{$to}}{{end}}</dd>
+{{if .CC}}<dt>CC</dt><dd>{{range $i, $to := .CC}}{{if $i}}, {{end}}{{$to}}{{end}}</dd>{{end}}
+{{if .BCC}}<dt>BCC</dt><dd>{{range $i, $to := .BCC}}{{if $i}}, {{end}}{{$to}}{{end}}</dd>{{end}}
+{{if .DeliveredTo}}<dt>Delivered to</dt><dd>{{range $i, $to := .DeliveredTo}}{{if $i}}, {{end}}{{$to}}{{end}} via {{.DeliveredBy}}</dd>{{end}}
+<dt>Captured</dt><dd>{{.CapturedAt.Format "2006-01-02 15:04:05 MST"}}</dd>
+{{if .Template}}<dt>Template</dt><dd>{{.Template}}</dd>{{end}}
+{{range $name, $value := .Headers}}<dt>{{$name}}</dt><dd>{{$value}}</dd>{{end}}
+{{if .Attachments}}<dt>Attachments</dt><dd>{{range .Attachments}}{{.Filename}} ({{.Size}} bytes) {{end}}</dd>{{end}}
+</dl>
+{{if .HTMLContent}}<iframe sandbox srcdoc="{{.HTMLContent}}"></iframe>{{end}}
+{{if .TextContent}}<pre>{{.TextContent}}</pre>{{end}}
+</body></html>{{end}}
+`))
+
+// inboxPage is the data of the inbox list page
+type inboxPage struct {
+	Search string
+	Emails []*providers.CapturedEmail
+	Next   int
+}
+
+// captureStore returns the store of captured emails, or responds with not
+// found when capture is disabled
+func (s *Server) captureStore(c *gin.Context) providers.CaptureStore {
+	store := s.providerRouter.CaptureStore()
+	if store == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "email capture is not enabled"})
+	}
+	return store
+}
+
+// inboxHandler handles requests for the inbox page
+func (s *Server) inboxHandler(c *gin.Context) {
+	store := s.captureStore(c)
+	if store == nil {
+		return
+	}
+	offset, err := parseIntQuery(c, "offset")
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := providers.CaptureQuery{Search: c.Query("q"), Limit: inboxPageSize + 1, Offset: offset}
+	emails, err := store.List(c.Request.Context(), query)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	page := inboxPage{Search: query.Search, Emails: emails}
+	if len(emails) > inboxPageSize {
+		page.Emails, page.Next = emails[:inboxPageSize], offset+inboxPageSize
+	}
+	s.renderInbox(c, "list", page)
+}
+
+// inboxEmailHandler handles requests for the page of a captured email
+func (s *Server) inboxEmailHandler(c *gin.Context) {
+	store := s.captureStore(c)
+	if store == nil {
+		return
+	}
+
+	email, err := store.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.String(captureErrorStatus(err), err.Error())
+		return
+	}
+	s.renderInbox(c, "email", email)
+}
+
+// renderInbox renders an inbox page
+func (s *Server) renderInbox(c *gin.Context, name string, data any) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := inboxTemplate.ExecuteTemplate(c.Writer, name, data); err != nil {
+		s.logger.Error("Failed to render inbox page", zap.String("page", name), zap.Error(err))
+	}
+}
+
+// listCapturedEmailsHandler handles captured email listing requests
+func (s *Server) listCapturedEmailsHandler(c *gin.Context) {
+	store := s.captureStore(c)
+	if store == nil {
+		return
+	}
+
+	query := providers.CaptureQuery{Search: c.Query("q")}
+	var err error
+	if query.Limit, err = parseIntQuery(c, "limit"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if query.Offset, err = parseIntQuery(c, "offset"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	emails, err := store.List(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"emails": emails,
+		"count":  len(emails),
+	})
+}
+
+// getCapturedEmailHandler handles captured email inspection requests
+func (s *Server) getCapturedEmailHandler(c *gin.Context) {
+	store := s.captureStore(c)
+	if store == nil {
+		return
+	}
+
+	email, err := store.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(captureErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, email)
+}
+
+// rawCapturedEmailHandler handles requests to download a captured email
+func (s *Server) rawCapturedEmailHandler(c *gin.Context) {
+	store := s.captureStore(c)
+	if store == nil {
+		return
+	}
+
+	raw, err := store.Raw(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(captureErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+c.Param("id")+`.eml"`)
+	c.Data(http.StatusOK, "message/rfc822", raw)
+}
+
+// captureErrorStatus maps a capture store error to an HTTP status code
+func captureErrorStatus(err error) int {
+	if errors.Is(err, providers.ErrCaptureNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
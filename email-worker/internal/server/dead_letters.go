@@ -0,0 +1,196 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"booking-system/email-worker/queue"
+)
+
+// registerDeadLetterRoutes sets up the dead-letter inspection and replay routes.
+// Bulk replay and purge select jobs by the same query filter as the listing,
+// or by the job IDs given in the request body.
+func (s *Server) registerDeadLetterRoutes() {
+	deadLetters := s.router.Group("/dead-letters")
+	deadLetters.GET("", s.listDeadLettersHandler)
+	deadLetters.GET("/:id", s.getDeadLetterHandler)
+	deadLetters.POST("/replay", s.replayDeadLettersHandler)
+	deadLetters.POST("/:id/replay", s.replayDeadLetterHandler)
+	deadLetters.DELETE("", s.purgeDeadLettersHandler)
+	deadLetters.DELETE("/:id", s.purgeDeadLetterHandler)
+}
+
+// deadLetterSelection is the optional body of bulk replay and purge requests
+type deadLetterSelection struct {
+	JobIDs []string `json:"job_ids"`
+}
+
+// listDeadLettersHandler handles dead-letter listing requests
+func (s *Server) listDeadLettersHandler(c *gin.Context) {
+	filter, err := parseDeadLetterFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := s.emailProcessor.ListDeadLetters(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dead_letters": entries,
+		"count":        len(entries),
+	})
+}
+
+// getDeadLetterHandler handles dead-letter inspection requests
+func (s *Server) getDeadLetterHandler(c *gin.Context) {
+	entry, err := s.emailProcessor.GetDeadLetter(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(deadLetterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// replayDeadLetterHandler handles replay requests for a single dead-lettered job
+func (s *Server) replayDeadLetterHandler(c *gin.Context) {
+	replayed, err := s.emailProcessor.ReplayDeadLetters(c.Request.Context(), []string{c.Param("id")}, queue.DeadLetterFilter{})
+	if err != nil {
+		c.JSON(deadLetterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"replayed": replayed})
+}
+
+// replayDeadLettersHandler handles bulk replay requests
+func (s *Server) replayDeadLettersHandler(c *gin.Context) {
+	jobIDs, filter, err := parseDeadLetterSelection(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	replayed, err := s.emailProcessor.ReplayDeadLetters(c.Request.Context(), jobIDs, filter)
+	if err != nil {
+		c.JSON(deadLetterErrorStatus(err), gin.H{"error": err.Error(), "replayed": replayed})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"replayed": replayed})
+}
+
+// purgeDeadLetterHandler handles purge requests for a single dead-lettered job
+func (s *Server) purgeDeadLetterHandler(c *gin.Context) {
+	purged, err := s.emailProcessor.PurgeDeadLetters(c.Request.Context(), []string{c.Param("id")}, queue.DeadLetterFilter{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if purged == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": queue.ErrDeadLetterNotFound.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"purged": purged})
+}
+
+// purgeDeadLettersHandler handles bulk purge requests
+func (s *Server) purgeDeadLettersHandler(c *gin.Context) {
+	jobIDs, filter, err := parseDeadLetterSelection(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	purged, err := s.emailProcessor.PurgeDeadLetters(c.Request.Context(), jobIDs, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"purged": purged})
+}
+
+// parseDeadLetterSelection reads the job IDs of a bulk request body, falling
+// back to the query filter when the body is empty
+func parseDeadLetterSelection(c *gin.Context) ([]string, queue.DeadLetterFilter, error) {
+	var selection deadLetterSelection
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&selection); err != nil {
+			return nil, queue.DeadLetterFilter{}, fmt.Errorf("invalid request body: %w", err)
+		}
+	}
+
+	filter, err := parseDeadLetterFilter(c)
+	return selection.JobIDs, filter, err
+}
+
+// parseDeadLetterFilter reads a dead-letter filter from the query string
+func parseDeadLetterFilter(c *gin.Context) (queue.DeadLetterFilter, error) {
+	filter := queue.DeadLetterFilter{
+		TemplateName:  c.Query("template"),
+		ErrorContains: c.Query("error"),
+	}
+
+	var err error
+	if filter.Since, err = parseTimeQuery(c, "since"); err != nil {
+		return filter, err
+	}
+	if filter.Until, err = parseTimeQuery(c, "until"); err != nil {
+		return filter, err
+	}
+	if filter.Limit, err = parseIntQuery(c, "limit"); err != nil {
+		return filter, err
+	}
+	if filter.Offset, err = parseIntQuery(c, "offset"); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// parseTimeQuery parses an optional RFC 3339 query parameter
+func parseTimeQuery(c *gin.Context, name string) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return parsed, nil
+}
+
+// parseIntQuery parses an optional non-negative integer query parameter
+func parseIntQuery(c *gin.Context, name string) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", name, value)
+	}
+	return parsed, nil
+}
+
+// deadLetterErrorStatus maps a dead-letter error to an HTTP status code
+func deadLetterErrorStatus(err error) int {
+	if errors.Is(err, queue.ErrDeadLetterNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
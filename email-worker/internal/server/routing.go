@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"booking-system/email-worker/providers"
+)
+
+// registerRoutingRoutes sets up the provider routing routes. A dry run tells
+// which rule and provider an email would be sent through, without sending it.
+func (s *Server) registerRoutingRoutes() {
+	routing := s.router.Group("/admin/routing")
+	routing.GET("", s.getRoutingRulesHandler)
+	routing.POST("/dry-run", s.routingDryRunHandler)
+}
+
+// routingDryRun is the body of a dry run request
+type routingDryRun struct {
+	To       []string `json:"to"`
+	CC       []string `json:"cc"`
+	BCC      []string `json:"bcc"`
+	Template string   `json:"template"`
+	Priority string   `json:"priority"`
+	Tenant   string   `json:"tenant"`
+}
+
+// getRoutingRulesHandler handles requests for the loaded routing rules
+func (s *Server) getRoutingRulesHandler(c *gin.Context) {
+	rules, loadedAt := s.providerRouter.Rules()
+	c.JSON(http.StatusOK, gin.H{
+		"rules":     rules,
+		"loaded_at": loadedAt.UTC(),
+	})
+}
+
+// routingDryRunHandler handles requests to route an email without sending it
+func (s *Server) routingDryRunHandler(c *gin.Context) {
+	var dryRun routingDryRun
+	if err := c.ShouldBindJSON(&dryRun); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	route := s.providerRouter.Route(&providers.EmailRequest{
+		To:       dryRun.To,
+		CC:       dryRun.CC,
+		BCC:      dryRun.BCC,
+		Template: dryRun.Template,
+		Priority: dryRun.Priority,
+		Tenant:   dryRun.Tenant,
+	})
+	c.JSON(http.StatusOK, route)
+}
@@ -11,6 +11,7 @@ import (
 	"go.uber.org/zap"
 
 	"booking-system/email-worker/processor"
+	"booking-system/email-worker/providers"
 )
 
 // Server represents the HTTP server
@@ -18,14 +19,16 @@ type Server struct {
 	router         *gin.Engine
 	logger         *zap.Logger
 	emailProcessor *processor.Processor
+	providerRouter *providers.Router
 	port           int
 }
 
 // NewServer creates a new HTTP server
-func NewServer(logger *zap.Logger, emailProcessor *processor.Processor, port int) *Server {
+func NewServer(logger *zap.Logger, emailProcessor *processor.Processor, providerRouter *providers.Router, port int) *Server {
 	return &Server{
 		logger:         logger,
 		emailProcessor: emailProcessor,
+		providerRouter: providerRouter,
 		port:           port,
 	}
 }
@@ -47,6 +50,18 @@ func (s *Server) Initialize() {
 
 	// Queue size endpoint
 	s.router.GET("/queue/size", s.queueSizeHandler)
+
+	// Dead-letter endpoints
+	s.registerDeadLetterRoutes()
+
+	// Admin endpoints
+	s.registerAdminRoutes()
+
+	// Provider routing endpoints
+	s.registerRoutingRoutes()
+
+	// Captured email inbox
+	s.registerCaptureRoutes()
 }
 
 // Start starts the HTTP server
@@ -65,19 +80,21 @@ func (s *Server) healthHandler(c *gin.Context) {
 	err := s.emailProcessor.Health(ctx)
 	if err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"status":    "unhealthy",
-			"error":     err.Error(),
-			"timestamp": time.Now().UTC(),
+			"status":           "unhealthy",
+			"error":            err.Error(),
+			"timestamp":        time.Now().UTC(),
+			"circuit_breakers": s.providerRouter.CircuitBreakers(),
 		})
 		return
 	}
 
 	stats := s.emailProcessor.GetStats()
 	c.JSON(http.StatusOK, gin.H{
-		"status":    "healthy",
-		"timestamp": time.Now().UTC(),
-		"version":   "1.0.0",
-		"stats":     stats,
+		"status":           "healthy",
+		"timestamp":        time.Now().UTC(),
+		"version":          "1.0.0",
+		"stats":            stats,
+		"circuit_breakers": s.providerRouter.CircuitBreakers(),
 	})
 }
 
@@ -89,6 +106,8 @@ func (s *Server) statsHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"processor_stats": stats,
 		"worker_stats":    workerStats,
+		"autoscaler":      s.emailProcessor.GetAutoscaleStatus(),
+		"leader":          s.emailProcessor.GetLeaderStatus(),
 	})
 }
 
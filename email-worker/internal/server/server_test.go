@@ -0,0 +1,267 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/processor"
+	"booking-system/email-worker/providers"
+	"booking-system/email-worker/queue"
+	"booking-system/email-worker/services"
+	"booking-system/email-worker/templates"
+)
+
+// testServer is a server over an in-memory queue and dead-letter store, whose
+// emails are captured to a directory
+type testServer struct {
+	*Server
+	queue       *queue.MemoryQueue
+	deadLetters *queue.MemoryDeadLetterStore
+}
+
+func newTestServer(t *testing.T, configure func(*processor.ProcessorConfig)) *testServer {
+	t.Helper()
+
+	store, err := providers.NewFileCaptureStore(t.TempDir())
+	require.NoError(t, err)
+	factory := providers.NewProviderFactory(map[string]any{
+		"capture": map[string]any{"from": "noreply@example.com"},
+	})
+	factory.SetCapture(store, providers.CaptureConfig{})
+	capture, err := factory.CreateNamedProvider("capture")
+	require.NoError(t, err)
+	router, err := providers.NewRouter(factory, capture, providers.CompositeConfig{}, nil)
+	require.NoError(t, err)
+
+	logger := zap.NewNop()
+	memoryQueue := queue.NewMemoryQueue(time.Minute, logger)
+	deadLetters := queue.NewMemoryDeadLetterStore()
+	emailService := services.NewEmailService(nil, nil, router, templates.NewEngine())
+	config := &processor.ProcessorConfig{
+		WorkerCount:     1,
+		BatchSize:       10,
+		PollInterval:    50 * time.Millisecond,
+		MaxRetries:      3,
+		RetryDelay:      100 * time.Millisecond,
+		ProcessTimeout:  5 * time.Second,
+		CleanupInterval: time.Minute,
+	}
+	if configure != nil {
+		configure(config)
+	}
+	proc := processor.NewProcessor(memoryQueue, deadLetters, queue.NewMemoryIdempotencyStore(),
+		queue.NewMemoryLeaderElector(), queue.NewMemoryHeartbeatStore(), emailService, config, logger)
+
+	server := NewServer(logger, proc, router, 0)
+	server.Initialize()
+	return &testServer{Server: server, queue: memoryQueue, deadLetters: deadLetters}
+}
+
+// serve sends a request to the server and returns its response
+func (s *testServer) serve(t *testing.T, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// decode unmarshals the JSON body of a response
+func decode(t *testing.T, recorder *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body), recorder.Body.String())
+	return body
+}
+
+// addDeadLetter dead-letters a job of template with finalError
+func (s *testServer) addDeadLetter(t *testing.T, template, finalError string) *models.EmailJob {
+	t.Helper()
+	job := models.NewEmailJob([]string{"guest@example.com"}, nil, nil, template, nil, models.JobPriorityNormal)
+	job.RecordAttempt(finalError)
+	require.NoError(t, s.deadLetters.Add(context.Background(), queue.NewDeadLetter(job, finalError)))
+	return job
+}
+
+func TestServer_DeadLetters(t *testing.T) {
+	s := newTestServer(t, nil)
+	welcome := s.addDeadLetter(t, "welcome", "provider outage")
+	verification := s.addDeadLetter(t, "email_verification", "mailbox full")
+	reset := s.addDeadLetter(t, "password_reset", "provider outage")
+
+	resp := s.serve(t, http.MethodGet, "/dead-letters", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, float64(3), decode(t, resp)["count"])
+
+	resp = s.serve(t, http.MethodGet, "/dead-letters?template=welcome", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, float64(1), decode(t, resp)["count"])
+
+	resp = s.serve(t, http.MethodGet, "/dead-letters?since=yesterday", "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = s.serve(t, http.MethodGet, "/dead-letters/"+welcome.ID.String(), "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "provider outage", decode(t, resp)["final_error"])
+
+	// Replay one job by ID, which puts it back on the queue
+	resp = s.serve(t, http.MethodPost, "/dead-letters/"+welcome.ID.String()+"/replay", "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, float64(1), decode(t, resp)["replayed"])
+
+	replayed, err := s.queue.Consume(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, welcome.ID, replayed.ID)
+	assert.Equal(t, models.JobStatusPending, replayed.Status)
+	assert.Equal(t, http.StatusNotFound, s.serve(t, http.MethodGet, "/dead-letters/"+welcome.ID.String(), "").Code)
+
+	// Replay in bulk by the job IDs of the body
+	resp = s.serve(t, http.MethodPost, "/dead-letters/replay", `{"job_ids": ["`+verification.ID.String()+`"]}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, float64(1), decode(t, resp)["replayed"])
+
+	resp = s.serve(t, http.MethodPost, "/dead-letters/replay", `{"job_ids": `)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// Purge in bulk by filter
+	resp = s.serve(t, http.MethodDelete, "/dead-letters?error=outage", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, float64(1), decode(t, resp)["purged"])
+	assert.Equal(t, http.StatusNotFound, s.serve(t, http.MethodDelete, "/dead-letters/"+reset.ID.String(), "").Code)
+
+	resp = s.serve(t, http.MethodGet, "/dead-letters", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, float64(0), decode(t, resp)["count"])
+}
+
+func TestServer_DeadLettersNotFound(t *testing.T) {
+	s := newTestServer(t, nil)
+	s.addDeadLetter(t, "welcome", "provider outage")
+
+	for _, id := range []string{uuid.NewString(), "not-a-uuid"} {
+		assert.Equal(t, http.StatusNotFound, s.serve(t, http.MethodGet, "/dead-letters/"+id, "").Code, id)
+		assert.Equal(t, http.StatusNotFound, s.serve(t, http.MethodPost, "/dead-letters/"+id+"/replay", "").Code, id)
+		assert.Equal(t, http.StatusNotFound, s.serve(t, http.MethodDelete, "/dead-letters/"+id, "").Code, id)
+	}
+
+	resp := s.serve(t, http.MethodPost, "/dead-letters/replay", `{"job_ids": ["`+uuid.NewString()+`"]}`)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, float64(0), decode(t, resp)["replayed"])
+}
+
+func TestServer_PinWorkers(t *testing.T) {
+	s := newTestServer(t, func(config *processor.ProcessorConfig) {
+		config.Autoscale = processor.AutoscaleConfig{MinWorkers: 1, MaxWorkers: 3, Interval: time.Hour}
+	})
+	require.NoError(t, s.emailProcessor.Start())
+	defer s.emailProcessor.Stop()
+
+	resp := s.serve(t, http.MethodPut, "/admin/workers/pin", `{"workers": 9}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = s.serve(t, http.MethodPut, "/admin/workers/pin", `{"workers": 2}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, float64(2), decode(t, resp)["pinned"])
+
+	resp = s.serve(t, http.MethodDelete, "/admin/workers/pin", "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, float64(0), decode(t, resp)["pinned"])
+}
+
+func TestServer_PinWorkersWithoutAutoscaling(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	resp := s.serve(t, http.MethodPut, "/admin/workers/pin", `{"workers": 1}`)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, processor.ErrAutoscaleDisabled.Error(), decode(t, resp)["error"])
+
+	resp = s.serve(t, http.MethodDelete, "/admin/workers/pin", "")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, http.StatusOK, s.serve(t, http.MethodGet, "/admin/workers", "").Code)
+}
+
+// captureEmails sends emails with the given subjects through the server's
+// capture provider, returning the listed emails newest first
+func (s *testServer) captureEmails(t *testing.T, subjects ...string) []*providers.CapturedEmail {
+	t.Helper()
+	ctx := context.Background()
+	for _, subject := range subjects {
+		_, err := s.providerRouter.Send(ctx, &providers.EmailRequest{
+			To:          []string{"guest@example.com"},
+			Subject:     subject,
+			HTMLContent: "<p>" + subject + "</p>",
+			TextContent: subject,
+			Template:    "booking_confirmation",
+		})
+		require.NoError(t, err)
+	}
+
+	emails, err := s.providerRouter.CaptureStore().List(ctx, providers.CaptureQuery{})
+	require.NoError(t, err)
+	require.Len(t, emails, len(subjects))
+	return emails
+}
+
+func TestServer_Inbox(t *testing.T) {
+	s := newTestServer(t, nil)
+	emails := s.captureEmails(t, "Booking confirmed", "Password reset")
+
+	resp := s.serve(t, http.MethodGet, "/inbox/messages", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, float64(2), decode(t, resp)["count"])
+
+	resp = s.serve(t, http.MethodGet, "/inbox/messages?q=PASSWORD", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	body := decode(t, resp)
+	require.Equal(t, float64(1), body["count"])
+	assert.Equal(t, "Password reset", body["emails"].([]any)[0].(map[string]any)["subject"])
+
+	resp = s.serve(t, http.MethodGet, "/inbox/messages?limit=-1", "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	id := emails[1].ID
+	resp = s.serve(t, http.MethodGet, "/inbox/messages/"+id, "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, emails[1].Subject, decode(t, resp)["subject"])
+
+	resp = s.serve(t, http.MethodGet, "/inbox/"+id+"/raw", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "message/rfc822", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="`+id+`.eml"`, resp.Header().Get("Content-Disposition"))
+	assert.Contains(t, resp.Body.String(), "Subject: "+emails[1].Subject)
+
+	// The HTML pages list and show the emails
+	resp = s.serve(t, http.MethodGet, "/inbox?q=booking+confirmed", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Booking confirmed")
+	assert.NotContains(t, resp.Body.String(), "Password reset")
+
+	resp = s.serve(t, http.MethodGet, "/inbox/"+id, "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "<h1>"+emails[1].Subject+"</h1>")
+}
+
+func TestServer_InboxNotFound(t *testing.T) {
+	s := newTestServer(t, nil)
+	s.captureEmails(t, "Booking confirmed")
+
+	for _, id := range []string{uuid.NewString(), "not-a-uuid"} {
+		assert.Equal(t, http.StatusNotFound, s.serve(t, http.MethodGet, "/inbox/messages/"+id, "").Code, id)
+		assert.Equal(t, http.StatusNotFound, s.serve(t, http.MethodGet, "/inbox/"+id, "").Code, id)
+		assert.Equal(t, http.StatusNotFound, s.serve(t, http.MethodGet, "/inbox/"+id+"/raw", "").Code, id)
+	}
+}
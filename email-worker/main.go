@@ -37,8 +37,11 @@ func main() {
 		loggerInstance.Fatal("Failed to initialize application", zap.Error(err))
 	}
 
+	// Reload the routing rules when the config file changes
+	config.WatchConfig(appInstance.ReloadConfig)
+
 	// Initialize HTTP server
-	httpServer := server.NewServer(loggerInstance, appInstance.GetEmailProcessor(), cfg.Server.Port)
+	httpServer := server.NewServer(loggerInstance, appInstance.GetEmailProcessor(), appInstance.GetRouter(), cfg.Server.Port)
 	httpServer.Initialize()
 
 	// Start HTTP server in background
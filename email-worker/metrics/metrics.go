@@ -21,9 +21,63 @@ var (
 		},
 		[]string{"job_type"},
 	)
+
+	StuckJobsRecovered = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "email_stuck_jobs_recovered_total",
+			Help: "Total number of jobs recovered from workers that stopped heartbeating",
+		},
+		[]string{"outcome"},
+	)
+
+	RetryDecisions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "email_retry_decisions_total",
+			Help: "Total number of failed email jobs by error class and retry outcome",
+		},
+		[]string{"class", "outcome"},
+	)
+
+	ProviderCircuitState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "email_provider_circuit_state",
+			Help: "State of the circuit breaker of each provider (0 closed, 1 half-open, 2 open)",
+		},
+		[]string{"provider"},
+	)
+
+	ProviderCircuitTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "email_provider_circuit_transitions_total",
+			Help: "Total number of circuit breaker state changes by provider and new state",
+		},
+		[]string{"provider", "state"},
+	)
+
+	RateLimitTokens = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "email_rate_limit_tokens",
+			Help: "Send tokens left in the rate limit bucket of a provider or recipient domain",
+		},
+		[]string{"bucket"},
+	)
+
+	RateLimited = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "email_rate_limited_total",
+			Help: "Total number of sends deferred by the rate limit of a provider or recipient domain",
+		},
+		[]string{"bucket"},
+	)
 )
 
 func Init() {
 	prometheus.MustRegister(EmailJobsProcessed)
 	prometheus.MustRegister(EmailJobProcessingDuration)
+	prometheus.MustRegister(StuckJobsRecovered)
+	prometheus.MustRegister(RetryDecisions)
+	prometheus.MustRegister(ProviderCircuitState)
+	prometheus.MustRegister(ProviderCircuitTransitions)
+	prometheus.MustRegister(RateLimitTokens)
+	prometheus.MustRegister(RateLimited)
 } 
\ No newline at end of file
@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +16,29 @@ type StringArray []string
 // VariablesMap represents a variables map for database storage
 type VariablesMap map[string]any
 
+// JobAttempt records a failed delivery attempt of a job
+type JobAttempt struct {
+	Number   int       `json:"number"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// JobAttempts represents the attempt history of a job for database storage
+type JobAttempts []JobAttempt
+
+// BatchRecipient is a recipient of a batch job. Each recipient gets a message
+// of their own, in which their data replace the template variables of the
+// same name.
+type BatchRecipient struct {
+	To   []string          `json:"to"`
+	CC   []string          `json:"cc,omitempty"`
+	BCC  []string          `json:"bcc,omitempty"`
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// BatchRecipients represents the recipients of a batch job for database storage
+type BatchRecipients []BatchRecipient
+
 // JobStatus represents the status of an email job
 type JobStatus string
 
@@ -30,13 +54,33 @@ const (
 // JobPriority represents the priority of an email job
 type JobPriority int
 
-// Job priority constants
+// Job priority constants, a lower value is served first
 const (
+	JobPriorityUrgent JobPriority = 0
 	JobPriorityHigh   JobPriority = 1
 	JobPriorityNormal JobPriority = 2
 	JobPriorityLow    JobPriority = 3
 )
 
+// JobPriorities lists every priority from the most to the least urgent
+var JobPriorities = []JobPriority{JobPriorityUrgent, JobPriorityHigh, JobPriorityNormal, JobPriorityLow}
+
+// String returns the name of the priority
+func (p JobPriority) String() string {
+	switch p {
+	case JobPriorityUrgent:
+		return "urgent"
+	case JobPriorityHigh:
+		return "high"
+	case JobPriorityNormal:
+		return "normal"
+	case JobPriorityLow:
+		return "low"
+	default:
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+}
+
 // EmailJob represents an email job in the system
 type EmailJob struct {
 	ID             uuid.UUID     `db:"id" json:"id"`
@@ -52,12 +96,25 @@ type EmailJob struct {
 	ErrorMessage   string        `db:"error_message" json:"error_message"`
 	ProcessedAt    *time.Time    `db:"processed_at" json:"processed_at"`
 	SentAt         *time.Time    `db:"sent_at" json:"sent_at"`
+	NextAttemptAt  *time.Time    `db:"next_attempt_at" json:"next_attempt_at"`
+	Attempts       JobAttempts   `db:"attempts" json:"attempts,omitempty"`
+	IdempotencyKey string        `db:"idempotency_key" json:"idempotency_key,omitempty"`
+	// Tenant the job is sent for, if any
+	Tenant         string        `db:"tenant" json:"tenant,omitempty"`
+	// Recipients make the job a batch sent to each of them, the recipients
+	// still to be sent to when it is retried
+	Recipients     BatchRecipients `db:"recipients" json:"recipients,omitempty"`
+	// Provider and MessageID name the provider that sent the email and its
+	// message ID there, once the job is sent
+	Provider       string        `json:"provider,omitempty"`
+	MessageID      string        `json:"message_id,omitempty"`
 	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
 	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
 
 	// Queue-specific fields
 	IsTracked      bool          `json:"is_tracked"`
 	QueueID        string        `json:"queue_id"`
+	Receipt        string        `json:"-"` // lease receipt of the current delivery, set on consume
 	ProcessingAt   *time.Time    `json:"processing_at"`
 	CompletedAt    *time.Time    `json:"completed_at"`
 }
@@ -108,6 +165,52 @@ func (m *VariablesMap) Scan(value any) error {
 	return json.Unmarshal(bytes, m)
 }
 
+// Value implements driver.Valuer for JobAttempts
+func (a JobAttempts) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
+// Scan implements sql.Scanner for JobAttempts
+func (a *JobAttempts) Scan(value any) error {
+	if value == nil {
+		*a = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(bytes, a)
+}
+
+// Value implements driver.Valuer for BatchRecipients
+func (r BatchRecipients) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+// Scan implements sql.Scanner for BatchRecipients
+func (r *BatchRecipients) Scan(value any) error {
+	if value == nil {
+		*r = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(bytes, r)
+}
+
 // NewEmailJob tạo một email job mới
 func NewEmailJob(to, cc, bcc []string, templateName string, variables map[string]any, priority JobPriority) *EmailJob {
 	return &EmailJob{
@@ -147,6 +250,11 @@ func (j *EmailJob) SetQueueID(queueID string) {
 	j.QueueID = queueID
 }
 
+// SetReceipt sets the lease receipt of the current delivery
+func (j *EmailJob) SetReceipt(receipt string) {
+	j.Receipt = receipt
+}
+
 // CanRetry checks if the job can be retried
 func (j *EmailJob) CanRetry() bool {
 	return j.RetryCount < j.MaxRetries
@@ -158,6 +266,28 @@ func (j *EmailJob) IncrementRetry() {
 	j.UpdatedAt = time.Now()
 }
 
+// RecordAttempt appends a failed attempt to the job's attempt history
+func (j *EmailJob) RecordAttempt(reason string) {
+	j.Attempts = append(j.Attempts, JobAttempt{
+		Number:   len(j.Attempts) + 1,
+		Error:    reason,
+		FailedAt: time.Now(),
+	})
+	j.ErrorMessage = reason
+}
+
+// ResetForReplay makes a dead-lettered job pending again with a fresh retry
+// budget. The attempt history is kept.
+func (j *EmailJob) ResetForReplay() {
+	j.Status = JobStatusPending
+	j.RetryCount = 0
+	j.ErrorMessage = ""
+	j.ProcessedAt = nil
+	j.NextAttemptAt = nil
+	j.CompletedAt = nil
+	j.UpdatedAt = time.Now()
+}
+
 // IsReadyToProcess checks if the job is ready to be processed
 func (j *EmailJob) IsReadyToProcess() bool {
 	if j.Status != JobStatusPending {
@@ -190,12 +320,28 @@ func (j *EmailJob) MarkAsFailed() {
 	j.UpdatedAt = now
 }
 
-// MarkAsRetrying marks the job as retrying
-func (j *EmailJob) MarkAsRetrying() {
-	j.Status = JobStatusProcessing // Use processing for retrying
+// MarkAsCancelled marks the job as cancelled
+func (j *EmailJob) MarkAsCancelled() {
+	now := time.Now()
+	j.Status = JobStatusCancelled
+	j.CompletedAt = &now
+	j.UpdatedAt = now
+}
+
+// MarkAsRetrying marks the job as waiting for its next attempt at nextAttemptAt.
+// A retry is a pending job scheduled for later.
+func (j *EmailJob) MarkAsRetrying(nextAttemptAt time.Time, reason string) {
+	j.Status = JobStatusPending
+	j.ErrorMessage = reason
+	j.NextAttemptAt = &nextAttemptAt
 	j.UpdatedAt = time.Now()
 }
 
+// IsRetrying checks if the job failed before and waits for its next attempt
+func (j *EmailJob) IsRetrying() bool {
+	return j.Status == JobStatusPending && j.RetryCount > 0 && j.NextAttemptAt != nil
+}
+
 // IsCompleted checks if the job is completed (success or failure)
 func (j *EmailJob) IsCompleted() bool {
 	return j.Status == JobStatusCompleted || j.Status == JobStatusFailed
@@ -0,0 +1,499 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/queue"
+)
+
+// AutoscaleConfig configures the adaptive worker pool. The pool grows towards
+// one worker per TargetBacklog ready jobs, and by at least one worker while the
+// oldest ready job has waited longer than MaxJobAge. It shrinks when the
+// backlog allows, by at most half of the pool per step. Leaving MaxWorkers at
+// zero disables autoscaling, the pool then stays at WorkerCount.
+type AutoscaleConfig struct {
+	MinWorkers int `mapstructure:"min_workers"`
+	MaxWorkers int `mapstructure:"max_workers"`
+	// Interval is how often the pool size is evaluated
+	Interval time.Duration `mapstructure:"interval"`
+	// TargetBacklog is how many ready jobs one worker is expected to keep up with
+	TargetBacklog int `mapstructure:"target_backlog"`
+	// MaxJobAge is how long the oldest ready job may wait before the pool grows
+	MaxJobAge time.Duration `mapstructure:"max_job_age"`
+	// MaxProviderLatency holds scale-ups while the average send takes longer,
+	// more workers would only add load to a struggling provider. Zero disables it.
+	MaxProviderLatency time.Duration `mapstructure:"max_provider_latency"`
+	// ScaleUpCooldown and ScaleDownCooldown are the minimum time since the
+	// last resize before the pool grows or shrinks again
+	ScaleUpCooldown   time.Duration `mapstructure:"scale_up_cooldown"`
+	ScaleDownCooldown time.Duration `mapstructure:"scale_down_cooldown"`
+}
+
+// Enabled reports whether the pool is autoscaled
+func (c AutoscaleConfig) Enabled() bool {
+	return c.MaxWorkers > 0
+}
+
+// Autoscaling defaults for values left unset
+const (
+	defaultAutoscaleInterval = 15 * time.Second
+	defaultTargetBacklog     = 100
+
+	// maxScalingDecisions bounds the decision history kept for statistics
+	maxScalingDecisions = 20
+)
+
+// Scaling actions
+const (
+	ScaleActionUp    = "scale_up"
+	ScaleActionDown  = "scale_down"
+	ScaleActionHold  = "hold"
+	ScaleActionPin   = "pin"
+	ScaleActionUnpin = "unpin"
+)
+
+// ErrInvalidWorkerCount is returned when a worker count is outside the pool bounds
+var ErrInvalidWorkerCount = errors.New("worker count outside the pool bounds")
+
+// ErrAutoscaleDisabled is returned when pinning a pool that is not autoscaled
+var ErrAutoscaleDisabled = errors.New("worker autoscaling is disabled")
+
+// ScalingDecision records one evaluation of the worker pool size
+type ScalingDecision struct {
+	At              time.Time     `json:"at"`
+	Action          string        `json:"action"`
+	From            int           `json:"from"`
+	To              int           `json:"to"`
+	Reason          string        `json:"reason"`
+	QueueSize       int64         `json:"queue_size"`
+	OldestJobAge    time.Duration `json:"oldest_job_age"`
+	ProviderLatency time.Duration `json:"provider_latency"`
+}
+
+// AutoscaleStatus describes the worker pool for statistics
+type AutoscaleStatus struct {
+	Enabled    bool `json:"enabled"`
+	MinWorkers int  `json:"min_workers"`
+	MaxWorkers int  `json:"max_workers"`
+	Workers    int  `json:"workers"`
+	// Pinned is the operator pinned worker count, zero when not pinned
+	Pinned       int               `json:"pinned"`
+	LastDecision *ScalingDecision  `json:"last_decision,omitempty"`
+	Decisions    []ScalingDecision `json:"decisions"`
+}
+
+// scalingSignals are the inputs of a scaling decision
+type scalingSignals struct {
+	queueSize       int64
+	oldestJobAge    time.Duration
+	providerLatency time.Duration
+}
+
+// autoscaler decides the worker pool size and keeps the decision history.
+// Resizing the pool itself is left to the processor.
+type autoscaler struct {
+	mu         sync.Mutex
+	config     AutoscaleConfig
+	enabled    bool
+	pinned     int
+	lastResize time.Time
+	last       *ScalingDecision
+	decisions  []ScalingDecision
+}
+
+// newAutoscaler creates an autoscaler, filling in defaults and keeping the
+// bounds consistent. Without autoscaling both bounds are workerCount.
+func newAutoscaler(config AutoscaleConfig, workerCount int) *autoscaler {
+	enabled := config.Enabled()
+	if !enabled {
+		config.MinWorkers, config.MaxWorkers = workerCount, workerCount
+	}
+	if config.MinWorkers < 1 {
+		config.MinWorkers = 1
+	}
+	if config.MaxWorkers < config.MinWorkers {
+		config.MaxWorkers = config.MinWorkers
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultAutoscaleInterval
+	}
+	if config.TargetBacklog <= 0 {
+		config.TargetBacklog = defaultTargetBacklog
+	}
+	return &autoscaler{config: config, enabled: enabled}
+}
+
+// initialWorkers returns the pool size to start with
+func (a *autoscaler) initialWorkers(workerCount int) int {
+	return a.clamp(workerCount)
+}
+
+// clamp keeps a worker count within the pool bounds
+func (a *autoscaler) clamp(workers int) int {
+	if workers < a.config.MinWorkers {
+		return a.config.MinWorkers
+	}
+	if workers > a.config.MaxWorkers {
+		return a.config.MaxWorkers
+	}
+	return workers
+}
+
+// decide evaluates the pool size for the current signals and records the
+// decision. It returns the worker count the pool should have.
+func (a *autoscaler) decide(current int, signals scalingSignals, now time.Time) ScalingDecision {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	decision := ScalingDecision{
+		At:              now,
+		Action:          ScaleActionHold,
+		From:            current,
+		To:              current,
+		QueueSize:       signals.queueSize,
+		OldestJobAge:    signals.oldestJobAge,
+		ProviderLatency: signals.providerLatency,
+	}
+
+	if a.pinned > 0 {
+		decision.To = a.pinned
+		decision.Reason = fmt.Sprintf("pinned at %d workers", a.pinned)
+		a.record(decision)
+		return decision
+	}
+
+	backlog := int((signals.queueSize + int64(a.config.TargetBacklog) - 1) / int64(a.config.TargetBacklog))
+	stale := a.config.MaxJobAge > 0 && signals.oldestJobAge > a.config.MaxJobAge
+	sinceResize := now.Sub(a.lastResize)
+
+	switch {
+	case backlog > current || stale:
+		target := backlog
+		if target <= current {
+			target = current + 1
+		}
+		target = a.clamp(target)
+
+		switch {
+		case target <= current:
+			decision.Reason = "at max workers"
+		case a.config.MaxProviderLatency > 0 && signals.providerLatency > a.config.MaxProviderLatency:
+			decision.Reason = fmt.Sprintf("provider latency %s above %s", signals.providerLatency.Round(time.Millisecond), a.config.MaxProviderLatency)
+		case sinceResize < a.config.ScaleUpCooldown:
+			decision.Reason = "scale-up cooldown"
+		default:
+			decision.Action = ScaleActionUp
+			decision.To = target
+			if stale && backlog <= current {
+				decision.Reason = fmt.Sprintf("oldest job waited %s", signals.oldestJobAge.Round(time.Second))
+			} else {
+				decision.Reason = fmt.Sprintf("%d ready jobs", signals.queueSize)
+			}
+		}
+
+	case backlog < current:
+		// Shrink gradually, the backlog is only a snapshot
+		target := backlog
+		if half := current / 2; target < half {
+			target = half
+		}
+		target = a.clamp(target)
+
+		switch {
+		case target >= current:
+			decision.Reason = "at min workers"
+		case sinceResize < a.config.ScaleDownCooldown:
+			decision.Reason = "scale-down cooldown"
+		default:
+			decision.Action = ScaleActionDown
+			decision.To = target
+			decision.Reason = fmt.Sprintf("%d ready jobs", signals.queueSize)
+		}
+
+	default:
+		decision.Reason = "backlog matches pool"
+	}
+
+	if decision.To != decision.From {
+		a.lastResize = now
+	}
+	a.record(decision)
+	return decision
+}
+
+// pin fixes the pool size until unpin is called
+func (a *autoscaler) pin(workers, current int, now time.Time) (ScalingDecision, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.enabled {
+		return ScalingDecision{}, ErrAutoscaleDisabled
+	}
+	if workers < a.config.MinWorkers || workers > a.config.MaxWorkers {
+		return ScalingDecision{}, fmt.Errorf("%w: %d not in [%d, %d]", ErrInvalidWorkerCount, workers, a.config.MinWorkers, a.config.MaxWorkers)
+	}
+
+	a.pinned = workers
+	a.lastResize = now
+	decision := ScalingDecision{
+		At:     now,
+		Action: ScaleActionPin,
+		From:   current,
+		To:     workers,
+		Reason: "pinned by operator",
+	}
+	a.record(decision)
+	return decision, nil
+}
+
+// unpin hands the pool size back to the autoscaler
+func (a *autoscaler) unpin(current int, now time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.enabled {
+		return ErrAutoscaleDisabled
+	}
+	if a.pinned == 0 {
+		return nil
+	}
+	a.pinned = 0
+	a.record(ScalingDecision{
+		At:     now,
+		Action: ScaleActionUnpin,
+		From:   current,
+		To:     current,
+		Reason: "unpinned by operator",
+	})
+	return nil
+}
+
+// record keeps the decision as the latest one. Holds are not added to the
+// history, it would otherwise only show the last few evaluations.
+func (a *autoscaler) record(decision ScalingDecision) {
+	a.last = &decision
+	if decision.Action == ScaleActionHold {
+		return
+	}
+	a.decisions = append(a.decisions, decision)
+	if len(a.decisions) > maxScalingDecisions {
+		a.decisions = a.decisions[len(a.decisions)-maxScalingDecisions:]
+	}
+}
+
+// status returns the autoscaler state for statistics
+func (a *autoscaler) status(workers int) AutoscaleStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	status := AutoscaleStatus{
+		Enabled:    a.enabled,
+		MinWorkers: a.config.MinWorkers,
+		MaxWorkers: a.config.MaxWorkers,
+		Workers:    workers,
+		Pinned:     a.pinned,
+		Decisions:  append([]ScalingDecision(nil), a.decisions...),
+	}
+	if a.last != nil {
+		last := *a.last
+		status.LastDecision = &last
+	}
+	return status
+}
+
+// latencyTracker keeps an exponentially weighted moving average of send latency
+type latencyTracker struct {
+	mu      sync.Mutex
+	average time.Duration
+}
+
+// latencySmoothing is the weight of the newest sample in the moving average
+const latencySmoothing = 0.2
+
+// observe adds a send duration to the average
+func (l *latencyTracker) observe(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.average == 0 {
+		l.average = latency
+		return
+	}
+	l.average += time.Duration(latencySmoothing * float64(latency-l.average))
+}
+
+// value returns the current average send latency
+func (l *latencyTracker) value() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.average
+}
+
+// autoscaleTask periodically resizes the worker pool from the queue backlog,
+// the age of the oldest ready job and the provider latency
+func (p *Processor) autoscaleTask() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.autoscaler.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopChan:
+			return
+		case <-ticker.C:
+			p.autoscale()
+		}
+	}
+}
+
+// autoscale runs one scaling evaluation
+func (p *Processor) autoscale() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	queueSize, err := p.queue.Size(ctx)
+	if err != nil {
+		p.logger.Error("Failed to get queue size for autoscaling", zap.Error(err))
+		return
+	}
+
+	// Without the age the pool scales on the backlog alone
+	oldestJobAge, err := p.queue.OldestAge(ctx)
+	if err != nil && !errors.Is(err, queue.ErrNotSupported) {
+		p.logger.Error("Failed to get oldest job age for autoscaling", zap.Error(err))
+	}
+
+	decision := p.autoscaler.decide(p.workerCount(), scalingSignals{
+		queueSize:       queueSize,
+		oldestJobAge:    oldestJobAge,
+		providerLatency: p.latency.value(),
+	}, time.Now())
+
+	if decision.To != decision.From {
+		p.logger.Info("Resizing worker pool",
+			zap.String("action", decision.Action),
+			zap.Int("from", decision.From),
+			zap.Int("to", decision.To),
+			zap.String("reason", decision.Reason),
+		)
+		p.resize(decision.To)
+	}
+}
+
+// PinWorkers fixes the worker pool at workers until UnpinWorkers is called.
+// The count must be within the autoscaling bounds.
+func (p *Processor) PinWorkers(workers int) error {
+	decision, err := p.autoscaler.pin(workers, p.workerCount(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	p.logger.Info("Worker pool pinned",
+		zap.Int("from", decision.From),
+		zap.Int("to", decision.To),
+	)
+	p.resize(workers)
+	return nil
+}
+
+// UnpinWorkers hands the worker pool size back to the autoscaler
+func (p *Processor) UnpinWorkers() error {
+	if err := p.autoscaler.unpin(p.workerCount(), time.Now()); err != nil {
+		return err
+	}
+	p.logger.Info("Worker pool unpinned")
+	return nil
+}
+
+// GetAutoscaleStatus returns the worker pool bounds and recent scaling decisions
+func (p *Processor) GetAutoscaleStatus() AutoscaleStatus {
+	return p.autoscaler.status(p.workerCount())
+}
+
+// resize starts or stops workers until the pool has target workers. Workers
+// are removed newest first, so the reserved workers are kept. The removed
+// workers are drained at once, after the pool is unlocked: they finish their
+// jobs in flight within the drain timeout and hand back the rest.
+func (p *Processor) resize(target int) {
+	p.resizeMutex.Lock()
+	select {
+	case <-p.stopChan:
+		p.resizeMutex.Unlock()
+		return
+	default:
+	}
+
+	p.workersMutex.Lock()
+	var stopping []*Worker
+	for len(p.workers) < target {
+		p.workers = append(p.workers, p.newWorker(len(p.workers)))
+	}
+	if len(p.workers) > target {
+		stopping = p.workers[target:]
+		p.workers = p.workers[:target:target]
+	}
+	p.workersMutex.Unlock()
+
+	// Shutdown waits for the drains, its own workers no longer include them
+	p.scaleDowns.Add(len(stopping))
+	p.resizeMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.drainTimeout())
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, worker := range stopping {
+		wg.Add(1)
+		go func(worker *Worker) {
+			defer p.scaleDowns.Done()
+			defer wg.Done()
+			worker.Drain(ctx)
+		}(worker)
+	}
+	wg.Wait()
+}
+
+// drainTimeout returns how long workers removed from the pool may finish
+// their jobs in flight: the shutdown grace period, or ProcessTimeout without
+// one
+func (p *Processor) drainTimeout() time.Duration {
+	if p.config.ShutdownGracePeriod > 0 {
+		return p.config.ShutdownGracePeriod
+	}
+	return p.config.ProcessTimeout
+}
+
+// newWorker creates and starts the worker at position index of the pool
+func (p *Processor) newWorker(index int) *Worker {
+	p.nextWorkerID++
+	worker := NewWorker(p.nextWorkerID, p.queue, p.deadLetters, p.emailService, p.workerConfig(), p.logger)
+	worker.lanes = p.generalLanes
+	if index < p.reserved {
+		worker.lanes = p.reservedLanes
+	}
+	worker.latency = p.latency
+	worker.limiter = p.limiter
+	worker.identity = fmt.Sprintf("%s/%d", p.leadership.identity, worker.id)
+	worker.heartbeats = p.heartbeats
+	worker.retries = p.retries
+	worker.Start()
+	return worker
+}
+
+// workerConfig returns the configuration of the processor's workers
+func (p *Processor) workerConfig() *WorkerConfig {
+	return &WorkerConfig{
+		BatchSize:         p.config.BatchSize,
+		PollInterval:      p.config.PollInterval,
+		MaxRetries:        p.config.MaxRetries,
+		RetryDelay:        p.config.RetryDelay,
+		ProcessTimeout:    p.config.ProcessTimeout,
+		HeartbeatInterval: p.config.Heartbeat.Interval,
+	}
+}
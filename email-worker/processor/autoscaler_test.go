@@ -0,0 +1,181 @@
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/queue"
+	"booking-system/email-worker/services"
+	"booking-system/email-worker/templates"
+)
+
+func newTestAutoscaler() *autoscaler {
+	return newAutoscaler(AutoscaleConfig{
+		MinWorkers:         2,
+		MaxWorkers:         10,
+		TargetBacklog:      100,
+		MaxJobAge:          time.Minute,
+		MaxProviderLatency: 5 * time.Second,
+		ScaleUpCooldown:    30 * time.Second,
+		ScaleDownCooldown:  5 * time.Minute,
+	}, 4)
+}
+
+func TestAutoscaler_ScalesWithBacklog(t *testing.T) {
+	a := newTestAutoscaler()
+	now := time.Now()
+
+	decision := a.decide(4, scalingSignals{queueSize: 750}, now)
+	assert.Equal(t, ScaleActionUp, decision.Action)
+	assert.Equal(t, 8, decision.To)
+
+	// Growth is capped at the maximum
+	decision = a.decide(8, scalingSignals{queueSize: 5000}, now.Add(time.Minute))
+	assert.Equal(t, 10, decision.To)
+
+	decision = a.decide(10, scalingSignals{queueSize: 5000}, now.Add(2*time.Minute))
+	assert.Equal(t, ScaleActionHold, decision.Action)
+	assert.Equal(t, "at max workers", decision.Reason)
+}
+
+func TestAutoscaler_ScalesUpOnOldestJobAge(t *testing.T) {
+	a := newTestAutoscaler()
+
+	// The backlog alone needs one worker, but jobs wait too long
+	decision := a.decide(4, scalingSignals{queueSize: 50, oldestJobAge: 2 * time.Minute}, time.Now())
+	assert.Equal(t, ScaleActionUp, decision.Action)
+	assert.Equal(t, 5, decision.To)
+}
+
+func TestAutoscaler_HoldsScaleUpOnProviderLatency(t *testing.T) {
+	a := newTestAutoscaler()
+
+	decision := a.decide(4, scalingSignals{queueSize: 750, providerLatency: 8 * time.Second}, time.Now())
+	assert.Equal(t, ScaleActionHold, decision.Action)
+	assert.Equal(t, 4, decision.To)
+	assert.Contains(t, decision.Reason, "provider latency")
+}
+
+func TestAutoscaler_Cooldowns(t *testing.T) {
+	a := newTestAutoscaler()
+	now := time.Now()
+
+	require.Equal(t, ScaleActionUp, a.decide(4, scalingSignals{queueSize: 600}, now).Action)
+
+	decision := a.decide(6, scalingSignals{queueSize: 900}, now.Add(10*time.Second))
+	assert.Equal(t, ScaleActionHold, decision.Action)
+	assert.Equal(t, "scale-up cooldown", decision.Reason)
+
+	decision = a.decide(6, scalingSignals{}, now.Add(time.Minute))
+	assert.Equal(t, "scale-down cooldown", decision.Reason)
+
+	// Shrinking halves the pool at most, down to the minimum
+	decision = a.decide(6, scalingSignals{}, now.Add(6*time.Minute))
+	assert.Equal(t, ScaleActionDown, decision.Action)
+	assert.Equal(t, 3, decision.To)
+
+	decision = a.decide(3, scalingSignals{}, now.Add(12*time.Minute))
+	assert.Equal(t, 2, decision.To)
+}
+
+func TestAutoscaler_PinAndHistory(t *testing.T) {
+	a := newTestAutoscaler()
+	now := time.Now()
+
+	_, err := a.pin(11, 4, now)
+	assert.ErrorIs(t, err, ErrInvalidWorkerCount)
+
+	_, err = a.pin(3, 4, now)
+	require.NoError(t, err)
+
+	// A pinned pool ignores the backlog
+	decision := a.decide(3, scalingSignals{queueSize: 5000}, now.Add(time.Hour))
+	assert.Equal(t, 3, decision.To)
+
+	require.NoError(t, a.unpin(3, now.Add(time.Hour)))
+	decision = a.decide(3, scalingSignals{queueSize: 5000}, now.Add(2*time.Hour))
+	assert.Equal(t, ScaleActionUp, decision.Action)
+
+	// Holds are only kept as the last decision
+	status := a.status(10)
+	require.Len(t, status.Decisions, 3)
+	assert.Equal(t, ScaleActionPin, status.Decisions[0].Action)
+	assert.Equal(t, ScaleActionUnpin, status.Decisions[1].Action)
+	assert.Equal(t, ScaleActionUp, status.Decisions[2].Action)
+	assert.Equal(t, 0, status.Pinned)
+	assert.True(t, status.Enabled)
+}
+
+func TestAutoscaler_DisabledKeepsWorkerCount(t *testing.T) {
+	a := newAutoscaler(AutoscaleConfig{}, 5)
+
+	assert.Equal(t, 5, a.initialWorkers(5))
+	assert.False(t, a.status(5).Enabled)
+	_, err := a.pin(6, 5, time.Now())
+	assert.ErrorIs(t, err, ErrAutoscaleDisabled)
+	assert.ErrorIs(t, a.unpin(5, time.Now()), ErrAutoscaleDisabled)
+	assert.Empty(t, a.status(5).Decisions)
+}
+
+func TestProcessor_ResizeDrainsRemovedWorkersAtOnce(t *testing.T) {
+	// Every worker sends a job of its own queue that hangs until the drain
+	// timeout interrupts it
+	provider := &delayProvider{delay: func(int) time.Duration { return time.Hour }}
+	emailService := services.NewEmailService(nil, nil, provider, templates.NewEngine())
+	p := NewProcessor(queue.NewMemoryQueue(time.Minute, zap.NewNop()), queue.NewMemoryDeadLetterStore(), queue.NewMemoryIdempotencyStore(), queue.NewMemoryLeaderElector(), queue.NewMemoryHeartbeatStore(), emailService, &ProcessorConfig{
+		WorkerCount:         4,
+		BatchSize:           1,
+		ProcessTimeout:      time.Hour,
+		ShutdownGracePeriod: 200 * time.Millisecond,
+	}, zap.NewNop())
+
+	var queues []*queue.MemoryQueue
+	for i := 0; i < 4; i++ {
+		workers, memoryQueue := newPipelineWorkers(t, provider, 1, 1, 1, time.Hour)
+		workers[0].Start()
+		p.workers = append(p.workers, workers[0])
+		queues = append(queues, memoryQueue)
+	}
+	require.Eventually(t, func() bool {
+		for _, worker := range p.workers {
+			if worker.inFlight.Load() != 1 {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 5*time.Millisecond)
+	kept := p.workers[0]
+
+	start := time.Now()
+	resized := make(chan time.Duration)
+	go func() {
+		p.resize(1)
+		resized <- time.Since(start)
+	}()
+
+	// The pool is not locked while the removed workers drain
+	require.Eventually(t, func() bool {
+		return p.workerCount() == 1
+	}, time.Second, time.Millisecond)
+	p.resize(1)
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+
+	// Draining three workers one after the other would take 600ms
+	elapsed := <-resized
+	assert.GreaterOrEqual(t, elapsed, 200*time.Millisecond)
+	assert.Less(t, elapsed, 400*time.Millisecond)
+	for _, memoryQueue := range queues[1:] {
+		size, err := memoryQueue.Size(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), size, "the interrupted job is handed back")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	kept.Drain(ctx)
+}
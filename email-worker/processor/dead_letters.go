@@ -0,0 +1,102 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/queue"
+)
+
+// ListDeadLetters returns the dead-lettered jobs selected by filter, newest first
+func (p *Processor) ListDeadLetters(ctx context.Context, filter queue.DeadLetterFilter) ([]*queue.DeadLetter, error) {
+	entries, err := p.deadLetters.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return entries, nil
+}
+
+// GetDeadLetter returns the dead letter of a job
+func (p *Processor) GetDeadLetter(ctx context.Context, jobID string) (*queue.DeadLetter, error) {
+	return p.deadLetters.Get(ctx, jobID)
+}
+
+// ReplayDeadLetters publishes dead-lettered jobs again with a fresh retry
+// budget, marks their jobs pending and removes them from the dead-letter
+// store. Jobs are selected by ID, or by filter when no IDs are given. It
+// returns how many jobs were replayed.
+func (p *Processor) ReplayDeadLetters(ctx context.Context, jobIDs []string, filter queue.DeadLetterFilter) (int, error) {
+	entries, err := p.selectDeadLetters(ctx, jobIDs, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, entry := range entries {
+		job := entry.Job
+		job.ResetForReplay()
+
+		// The job row is pending before a worker can lease the job again
+		if err := p.emailService.ReplayJob(ctx, job); err != nil {
+			p.logger.Error("Failed to update replayed job status",
+				zap.String("job_id", entry.JobID()),
+				zap.Error(err))
+		}
+		if err := p.queue.Publish(ctx, job); err != nil {
+			return replayed, fmt.Errorf("failed to replay job %s: %w", entry.JobID(), err)
+		}
+
+		// The job is back on the queue, a failed delete only leaves a stale dead letter
+		if _, err := p.deadLetters.Delete(ctx, entry.JobID()); err != nil {
+			p.logger.Error("Failed to delete replayed dead letter",
+				zap.String("job_id", entry.JobID()),
+				zap.Error(err))
+		}
+		replayed++
+	}
+
+	p.logger.Info("Replayed dead-lettered jobs", zap.Int("count", replayed))
+	return replayed, nil
+}
+
+// PurgeDeadLetters deletes dead-lettered jobs, selected by ID or by filter when
+// no IDs are given. It returns how many dead letters were deleted.
+func (p *Processor) PurgeDeadLetters(ctx context.Context, jobIDs []string, filter queue.DeadLetterFilter) (int, error) {
+	if len(jobIDs) == 0 {
+		entries, err := p.selectDeadLetters(ctx, nil, filter)
+		if err != nil {
+			return 0, err
+		}
+		for _, entry := range entries {
+			jobIDs = append(jobIDs, entry.JobID())
+		}
+	}
+
+	purged, err := p.deadLetters.Delete(ctx, jobIDs...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge dead letters: %w", err)
+	}
+
+	p.logger.Info("Purged dead-lettered jobs", zap.Int("count", purged))
+	return purged, nil
+}
+
+// selectDeadLetters returns the dead letters of the given jobs, or those
+// selected by filter when no IDs are given
+func (p *Processor) selectDeadLetters(ctx context.Context, jobIDs []string, filter queue.DeadLetterFilter) ([]*queue.DeadLetter, error) {
+	if len(jobIDs) == 0 {
+		return p.ListDeadLetters(ctx, filter)
+	}
+
+	entries := make([]*queue.DeadLetter, 0, len(jobIDs))
+	for _, jobID := range jobIDs {
+		entry, err := p.deadLetters.Get(ctx, jobID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dead letter %s: %w", jobID, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,126 @@
+package processor
+
+import (
+	"sync"
+	"time"
+
+	"booking-system/email-worker/models"
+)
+
+// LaneConfig configures per-priority consumption. Workers serve the priority
+// lanes in proportion to their weights, a lane with weight zero is only served
+// when the weighted lanes are empty. Setting every weight to zero disables
+// lanes, workers then consume in strict priority order.
+type LaneConfig struct {
+	UrgentWeight int `mapstructure:"urgent_weight"`
+	HighWeight   int `mapstructure:"high_weight"`
+	NormalWeight int `mapstructure:"normal_weight"`
+	LowWeight    int `mapstructure:"low_weight"`
+	// ReservedWorkers only ever serve urgent and high priority jobs, so a
+	// burst of bulk mail cannot occupy every worker
+	ReservedWorkers int `mapstructure:"reserved_workers"`
+	// AgingInterval is how long a job waits before it is promoted one
+	// priority, up to high. Zero disables aging.
+	AgingInterval time.Duration `mapstructure:"aging_interval"`
+}
+
+// Enabled reports whether any lane has a weight
+func (c LaneConfig) Enabled() bool {
+	return c.UrgentWeight > 0 || c.HighWeight > 0 || c.NormalWeight > 0 || c.LowWeight > 0
+}
+
+// weight returns the configured weight of a priority lane
+func (c LaneConfig) weight(priority models.JobPriority) int {
+	switch priority {
+	case models.JobPriorityUrgent:
+		return c.UrgentWeight
+	case models.JobPriorityHigh:
+		return c.HighWeight
+	case models.JobPriorityNormal:
+		return c.NormalWeight
+	default:
+		return c.LowWeight
+	}
+}
+
+// laneScheduler picks the lane a worker consumes from next using smooth
+// weighted round robin. It is shared by the workers of a group so the weights
+// hold across the group rather than per worker.
+type laneScheduler struct {
+	mu         sync.Mutex
+	priorities []models.JobPriority
+	weights    []int
+	current    []int
+	total      int
+	disabled   bool
+}
+
+// newLaneScheduler creates a scheduler over the given lanes, most urgent first
+func newLaneScheduler(config LaneConfig, priorities ...models.JobPriority) *laneScheduler {
+	s := &laneScheduler{
+		priorities: priorities,
+		weights:    make([]int, len(priorities)),
+		current:    make([]int, len(priorities)),
+	}
+	for i, priority := range priorities {
+		s.weights[i] = config.weight(priority)
+		s.total += s.weights[i]
+	}
+	return s
+}
+
+// next returns the lanes to try in order: the lane whose turn it is, then the
+// remaining lanes most urgent first, so a worker never idles while any of its
+// lanes has work. It returns nil once the scheduler is disabled.
+func (s *laneScheduler) next() []models.JobPriority {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.disabled {
+		return nil
+	}
+
+	chosen := -1
+	if s.total > 0 {
+		for i, weight := range s.weights {
+			s.current[i] += weight
+			if weight > 0 && (chosen < 0 || s.current[i] > s.current[chosen]) {
+				chosen = i
+			}
+		}
+		s.current[chosen] -= s.total
+	}
+
+	order := make([]models.JobPriority, 0, len(s.priorities))
+	if chosen >= 0 {
+		order = append(order, s.priorities[chosen])
+	}
+	for i, priority := range s.priorities {
+		if i != chosen {
+			order = append(order, priority)
+		}
+	}
+	return order
+}
+
+// disable makes every worker of the group fall back to plain batch consumption
+func (s *laneScheduler) disable() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disabled = true
+}
+
+// names returns the lane names for worker statistics
+func (s *laneScheduler) names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.disabled {
+		return nil
+	}
+	names := make([]string, len(s.priorities))
+	for i, priority := range s.priorities {
+		names[i] = priority.String()
+	}
+	return names
+}
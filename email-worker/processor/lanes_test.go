@@ -0,0 +1,45 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"booking-system/email-worker/models"
+)
+
+func TestLaneScheduler_Weights(t *testing.T) {
+	config := LaneConfig{UrgentWeight: 8, HighWeight: 4, NormalWeight: 2, LowWeight: 1}
+	scheduler := newLaneScheduler(config, models.JobPriorities...)
+
+	// Over one full round every lane is picked first as often as its weight
+	picks := map[models.JobPriority]int{}
+	for i := 0; i < 15; i++ {
+		order := scheduler.next()
+		assert.Len(t, order, 4)
+		picks[order[0]]++
+	}
+	assert.Equal(t, map[models.JobPriority]int{
+		models.JobPriorityUrgent: 8,
+		models.JobPriorityHigh:   4,
+		models.JobPriorityNormal: 2,
+		models.JobPriorityLow:    1,
+	}, picks)
+}
+
+func TestLaneScheduler_FallsThroughInPriorityOrder(t *testing.T) {
+	config := LaneConfig{UrgentWeight: 1, LowWeight: 1}
+	scheduler := newLaneScheduler(config, models.JobPriorities...)
+
+	// Lanes without weight are only tried after the chosen one
+	assert.Equal(t, []models.JobPriority{
+		models.JobPriorityUrgent, models.JobPriorityHigh, models.JobPriorityNormal, models.JobPriorityLow,
+	}, scheduler.next())
+	assert.Equal(t, []models.JobPriority{
+		models.JobPriorityLow, models.JobPriorityUrgent, models.JobPriorityHigh, models.JobPriorityNormal,
+	}, scheduler.next())
+
+	scheduler.disable()
+	assert.Nil(t, scheduler.next())
+	assert.Nil(t, scheduler.names())
+}
@@ -0,0 +1,246 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/queue"
+)
+
+// LeaderConfig holds leader election configuration. Only the elected replica
+// runs the background tasks that act on the whole queue or database.
+type LeaderConfig struct {
+	// LeaseTTL is how long a leader that stops renewing keeps the lease
+	LeaseTTL time.Duration `mapstructure:"lease_ttl"`
+	// RenewInterval is how often the lease is renewed, or how often a
+	// follower tries to take it over
+	RenewInterval time.Duration `mapstructure:"renew_interval"`
+}
+
+// Default leader election timings, used when none are configured
+const (
+	defaultLeaseTTL      = 15 * time.Second
+	defaultRenewInterval = 5 * time.Second
+)
+
+// LeaderStatus describes the leadership of this replica
+type LeaderStatus struct {
+	Identity string    `json:"identity"`
+	Leader   bool      `json:"leader"`
+	Token    int64     `json:"token,omitempty"`
+	Since    time.Time `json:"since,omitempty"`
+}
+
+// leadership keeps this replica's lease. Each term gets a context that is
+// cancelled as soon as the lease is lost, so singleton tasks stop with it.
+type leadership struct {
+	elector  queue.LeaderElector
+	identity string
+	config   LeaderConfig
+	logger   *zap.Logger
+
+	mu      sync.RWMutex
+	lease   *queue.Lease
+	since   time.Time
+	term    context.Context
+	endTerm context.CancelFunc
+}
+
+// newLeadership creates the leadership of a replica, which starts as a follower
+func newLeadership(elector queue.LeaderElector, config LeaderConfig, logger *zap.Logger) *leadership {
+	if config.LeaseTTL <= 0 {
+		config.LeaseTTL = defaultLeaseTTL
+	}
+	if config.RenewInterval <= 0 {
+		config.RenewInterval = defaultRenewInterval
+	}
+	if config.RenewInterval >= config.LeaseTTL {
+		config.RenewInterval = config.LeaseTTL / 3
+	}
+
+	return &leadership{
+		elector:  elector,
+		identity: replicaIdentity(),
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// replicaIdentity names this replica as a lease holder
+func replicaIdentity() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "email-worker"
+	}
+	return fmt.Sprintf("%s-%s", host, uuid.NewString()[:8])
+}
+
+// renew takes or renews the lease. A lease that cannot be renewed because the
+// backend is unreachable is kept until it expires, since no other replica can
+// take it over before then either.
+func (l *leadership) renew() {
+	ctx, cancel := context.WithTimeout(context.Background(), l.config.RenewInterval)
+	defer cancel()
+
+	lease, err := l.elector.Acquire(ctx, l.identity, l.config.LeaseTTL)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch {
+	case err == nil:
+		if l.lease == nil || l.lease.Token != lease.Token {
+			l.startTerm(lease)
+		}
+		l.lease = lease
+	case errors.Is(err, queue.ErrNotLeader):
+		l.stepDown("lease held by another replica")
+	default:
+		l.logger.Error("Failed to renew leader lease", zap.Error(err))
+		if l.lease != nil && !time.Now().Before(l.lease.ExpiresAt) {
+			l.stepDown("lease expired")
+		}
+	}
+}
+
+// startTerm makes this replica the leader under lease, l.mu must be held
+func (l *leadership) startTerm(lease *queue.Lease) {
+	if l.endTerm != nil {
+		l.endTerm()
+	}
+	l.term, l.endTerm = context.WithCancel(context.Background())
+	l.since = time.Now()
+
+	l.logger.Info("Elected leader",
+		zap.String("identity", l.identity),
+		zap.Int64("token", lease.Token))
+}
+
+// stepDown makes this replica a follower, l.mu must be held
+func (l *leadership) stepDown(reason string) {
+	if l.lease == nil {
+		return
+	}
+
+	l.logger.Warn("Lost leadership",
+		zap.String("identity", l.identity),
+		zap.Int64("token", l.lease.Token),
+		zap.String("reason", reason))
+	l.endTerm()
+	l.lease = nil
+	l.term, l.endTerm = nil, nil
+}
+
+// current returns the lease and term context while this replica leads, and
+// a nil lease otherwise
+func (l *leadership) current() (*queue.Lease, context.Context) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.lease == nil || !time.Now().Before(l.lease.ExpiresAt) {
+		return nil, nil
+	}
+	return l.lease, l.term
+}
+
+// fenced steps down if lease is still the current one, after its fencing
+// token was refused
+func (l *leadership) fenced(lease *queue.Lease) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.lease != nil && l.lease.Token == lease.Token {
+		l.stepDown("fencing token superseded")
+	}
+}
+
+// resign gives up the lease so another replica takes over without waiting
+// for it to expire
+func (l *leadership) resign() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.lease == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), settleTimeout)
+	defer cancel()
+	if err := l.elector.Release(ctx, l.lease); err != nil {
+		l.logger.Error("Failed to release leader lease", zap.Error(err))
+	}
+	l.stepDown("shutting down")
+}
+
+// status returns the leadership of this replica
+func (l *leadership) status() LeaderStatus {
+	lease, _ := l.current()
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	status := LeaderStatus{Identity: l.identity}
+	if lease != nil {
+		status.Leader = true
+		status.Token = lease.Token
+		status.Since = l.since
+	}
+	return status
+}
+
+// leaderTask keeps taking or renewing the lease until the processor stops,
+// then gives it up
+func (p *Processor) leaderTask() {
+	defer p.wg.Done()
+	defer p.leadership.resign()
+
+	ticker := time.NewTicker(p.leadership.config.RenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopChan:
+			return
+		case <-ticker.C:
+			p.leadership.renew()
+		}
+	}
+}
+
+// runAsLeader runs a singleton task if this replica leads. The task's context
+// ends with the term, and the fencing token is checked right before the task
+// starts, so a replica that lost its lease without noticing does not run it.
+func (p *Processor) runAsLeader(name string, timeout time.Duration, task func(ctx context.Context)) {
+	lease, term := p.leadership.current()
+	if lease == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(term, timeout)
+	defer cancel()
+
+	if err := p.leadership.elector.Check(ctx, lease); err != nil {
+		if errors.Is(err, queue.ErrNotLeader) {
+			p.leadership.fenced(lease)
+			return
+		}
+		p.logger.Error("Failed to check leader lease, skipping task",
+			zap.String("task", name),
+			zap.Error(err))
+		return
+	}
+
+	task(ctx)
+}
+
+// GetLeaderStatus returns the leadership of this replica
+func (p *Processor) GetLeaderStatus() LeaderStatus {
+	return p.leadership.status()
+}
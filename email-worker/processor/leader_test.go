@@ -0,0 +1,64 @@
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/queue"
+)
+
+func TestLeadership_FailsOverOnResign(t *testing.T) {
+	elector := queue.NewMemoryLeaderElector()
+	config := LeaderConfig{LeaseTTL: time.Minute, RenewInterval: time.Second}
+	a := newLeadership(elector, config, zap.NewNop())
+	b := newLeadership(elector, config, zap.NewNop())
+
+	a.renew()
+	b.renew()
+	lease, term := a.current()
+	require.NotNil(t, lease)
+	assert.True(t, a.status().Leader)
+	assert.False(t, b.status().Leader)
+
+	// Resigning ends the term at once and lets the follower take over
+	a.resign()
+	assert.Error(t, term.Err())
+	b.renew()
+	assert.False(t, a.status().Leader)
+	require.True(t, b.status().Leader)
+	assert.Greater(t, b.status().Token, lease.Token)
+}
+
+func TestProcessor_RunAsLeaderChecksFencingToken(t *testing.T) {
+	elector := queue.NewMemoryLeaderElector()
+	config := LeaderConfig{LeaseTTL: time.Minute, RenewInterval: time.Second}
+	a := newLeadership(elector, config, zap.NewNop())
+	b := newLeadership(elector, config, zap.NewNop())
+	p := &Processor{leadership: a, logger: zap.NewNop()}
+
+	ran := 0
+	task := func(ctx context.Context) { ran++ }
+
+	a.renew()
+	p.runAsLeader("test", time.Second, task)
+	assert.Equal(t, 1, ran)
+
+	// The lease moves on without a noticing, e.g. after a long pause
+	lease, _ := a.current()
+	require.NoError(t, elector.Release(context.Background(), lease))
+	b.renew()
+	require.True(t, b.status().Leader)
+
+	p.runAsLeader("test", time.Second, task)
+	assert.Equal(t, 1, ran)
+	assert.False(t, a.status().Leader)
+
+	// Followers do not run singleton tasks
+	p.runAsLeader("test", time.Second, task)
+	assert.Equal(t, 1, ran)
+}
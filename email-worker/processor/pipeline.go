@@ -0,0 +1,76 @@
+package processor
+
+import "sync"
+
+// inFlightLimiter bounds how many jobs are being sent at once. The processor
+// shares one limiter between all of its workers, so the load on the provider
+// does not grow with the number of workers.
+type inFlightLimiter struct {
+	mu    sync.Mutex
+	max   int
+	used  int
+	freed chan struct{}
+}
+
+// newInFlightLimiter creates a limiter with limit slots
+func newInFlightLimiter(limit int) *inFlightLimiter {
+	if limit < 1 {
+		limit = 1
+	}
+	return &inFlightLimiter{max: limit, freed: make(chan struct{})}
+}
+
+// acquire waits for a free slot and takes up to n of the free slots, so that
+// workers only lease as many jobs as they can start. It returns the number of
+// slots taken, or 0 if stop was closed first.
+func (l *inFlightLimiter) acquire(stop <-chan struct{}, n int) int {
+	for {
+		select {
+		case <-stop:
+			return 0
+		default:
+		}
+
+		l.mu.Lock()
+		if free := l.max - l.used; free > 0 {
+			taken := min(n, free)
+			l.used += taken
+			l.mu.Unlock()
+			return taken
+		}
+		freed := l.freed
+		l.mu.Unlock()
+
+		select {
+		case <-freed:
+		case <-stop:
+			return 0
+		}
+	}
+}
+
+// release frees n slots taken by acquire
+func (l *inFlightLimiter) release(n int) {
+	if n < 1 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.used -= n
+	close(l.freed)
+	l.freed = make(chan struct{})
+}
+
+// inUse returns how many slots are taken
+func (l *inFlightLimiter) inUse() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.used
+}
+
+// limit returns the number of slots
+func (l *inFlightLimiter) limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.max
+}
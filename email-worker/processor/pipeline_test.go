@@ -0,0 +1,389 @@
+package processor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/providers"
+	"booking-system/email-worker/queue"
+	"booking-system/email-worker/repositories"
+	"booking-system/email-worker/services"
+	"booking-system/email-worker/templates"
+)
+
+// delayProvider is a provider whose sends take delay(n) for the n-th send.
+// It records how many sends ran at once.
+type delayProvider struct {
+	delay func(n int) time.Duration
+
+	mu        sync.Mutex
+	started   int
+	sent      int
+	active    int
+	maxActive int
+}
+
+func (p *delayProvider) Name() string                     { return "delay" }
+func (p *delayProvider) Validate() error                  { return nil }
+func (p *delayProvider) Health(ctx context.Context) error { return nil }
+func (p *delayProvider) Close() error                     { return nil }
+
+func (p *delayProvider) SendBatch(ctx context.Context, batch *providers.BatchRequest) ([]providers.BatchResult, error) {
+	return providers.SendEach(ctx, p, batch)
+}
+
+func (p *delayProvider) Send(ctx context.Context, req *providers.EmailRequest) (*providers.EmailResponse, error) {
+	p.mu.Lock()
+	n := p.started
+	p.started++
+	p.active++
+	if p.active > p.maxActive {
+		p.maxActive = p.active
+	}
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.active--
+		p.mu.Unlock()
+	}()
+
+	select {
+	case <-time.After(p.delay(n)):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	p.mu.Lock()
+	p.sent++
+	p.mu.Unlock()
+	return &providers.EmailResponse{Status: "sent", SentAt: time.Now()}, nil
+}
+
+func (p *delayProvider) counts() (sent, maxActive int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sent, p.maxActive
+}
+
+// newPipelineWorkers creates count workers sharing an in-flight limit over a
+// memory queue holding jobs jobs
+func newPipelineWorkers(tb testing.TB, provider providers.Provider, count, limit, jobs int, processTimeout time.Duration) ([]*Worker, *queue.MemoryQueue) {
+	tb.Helper()
+
+	logger := zap.NewNop()
+	memoryQueue := queue.NewMemoryQueue(time.Minute, logger)
+	emailService := services.NewEmailService(nil, nil, provider, templates.NewEngine())
+	config := &WorkerConfig{
+		BatchSize:      10,
+		PollInterval:   time.Millisecond,
+		MaxRetries:     3,
+		RetryDelay:     time.Hour,
+		ProcessTimeout: processTimeout,
+	}
+
+	ctx := context.Background()
+	for i := 0; i < jobs; i++ {
+		job := models.NewEmailJob([]string{"test@example.com"}, nil, nil, "email_verification", nil, models.JobPriorityNormal)
+		require.NoError(tb, memoryQueue.Publish(ctx, job))
+	}
+
+	limiter := newInFlightLimiter(limit)
+	workers := make([]*Worker, count)
+	for i := range workers {
+		workers[i] = NewWorker(i+1, memoryQueue, queue.NewMemoryDeadLetterStore(), emailService, config, logger)
+		workers[i].limiter = limiter
+	}
+	return workers, memoryQueue
+}
+
+func TestWorker_InFlightLimitIsShared(t *testing.T) {
+	provider := &delayProvider{delay: func(int) time.Duration { return 5 * time.Millisecond }}
+	workers, _ := newPipelineWorkers(t, provider, 3, 4, 60, time.Second)
+
+	for _, worker := range workers {
+		worker.Start()
+	}
+	assert.Eventually(t, func() bool {
+		sent, _ := provider.counts()
+		return sent == 60
+	}, 5*time.Second, 5*time.Millisecond)
+	for _, worker := range workers {
+		worker.Stop()
+	}
+
+	// Three workers with batches of ten never send more than the shared limit
+	_, maxActive := provider.counts()
+	assert.LessOrEqual(t, maxActive, 4)
+	assert.Equal(t, 4, maxActive)
+}
+
+func TestWorker_LeasesNoJobsWhileLimitIsFull(t *testing.T) {
+	provider := &delayProvider{delay: func(int) time.Duration { return 0 }}
+	workers, memoryQueue := newPipelineWorkers(t, provider, 1, 1, 5, time.Second)
+	require.Equal(t, 1, workers[0].limiter.acquire(nil, 1))
+
+	workers[0].Start()
+	defer workers[0].Stop()
+	time.Sleep(30 * time.Millisecond)
+	size, err := memoryQueue.Size(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), size, "no job may be leased while no slot is free")
+
+	workers[0].limiter.release(1)
+	assert.Eventually(t, func() bool {
+		sent, _ := provider.counts()
+		return sent == 5
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestWorker_LeasesOnlyJobsWithFreeSlots(t *testing.T) {
+	provider := &delayProvider{delay: func(int) time.Duration { return time.Hour }}
+	workers, memoryQueue := newPipelineWorkers(t, provider, 1, 3, 10, time.Hour)
+	require.Equal(t, 1, workers[0].limiter.acquire(nil, 1))
+
+	// The batch is sized to the two free slots, not to BatchSize
+	workers[0].Start()
+	require.Eventually(t, func() bool {
+		return workers[0].inFlight.Load() == 2
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	size, err := memoryQueue.Size(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), size)
+	assert.Equal(t, 3, workers[0].limiter.inUse())
+
+	// A freed slot leases one more job
+	workers[0].limiter.release(1)
+	require.Eventually(t, func() bool {
+		return workers[0].inFlight.Load() == 3
+	}, time.Second, 5*time.Millisecond)
+	size, err = memoryQueue.Size(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), size)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	workers[0].Drain(ctx)
+	assert.Zero(t, workers[0].limiter.inUse())
+}
+
+func TestProcessor_InFlightLimitCoversLargestPool(t *testing.T) {
+	newProcessor := func(config *ProcessorConfig) *Processor {
+		emailService := services.NewEmailService(nil, nil, &delayProvider{}, templates.NewEngine())
+		return NewProcessor(queue.NewMemoryQueue(time.Minute, zap.NewNop()), queue.NewMemoryDeadLetterStore(), queue.NewMemoryIdempotencyStore(), queue.NewMemoryLeaderElector(), queue.NewMemoryHeartbeatStore(), emailService, config, zap.NewNop())
+	}
+
+	p := newProcessor(&ProcessorConfig{WorkerCount: 2, BatchSize: 5, Autoscale: AutoscaleConfig{MaxWorkers: 6}})
+	assert.Equal(t, 30, p.GetStats().MaxInFlight, "workers the autoscaler adds must get slots too")
+
+	p = newProcessor(&ProcessorConfig{WorkerCount: 2, BatchSize: 5})
+	assert.Equal(t, 10, p.GetStats().MaxInFlight)
+
+	p = newProcessor(&ProcessorConfig{WorkerCount: 2, BatchSize: 5, MaxInFlight: 7, Autoscale: AutoscaleConfig{MaxWorkers: 6}})
+	assert.Equal(t, 7, p.GetStats().MaxInFlight)
+}
+
+func TestWorker_SlowSendDoesNotTimeOutItsBatch(t *testing.T) {
+	// The first send hangs until its own deadline, the others are quick
+	provider := &delayProvider{delay: func(n int) time.Duration {
+		if n == 0 {
+			return time.Hour
+		}
+		return 20 * time.Millisecond
+	}}
+	workers, memoryQueue := newPipelineWorkers(t, provider, 1, 10, 10, 100*time.Millisecond)
+
+	workers[0].Start()
+	assert.Eventually(t, func() bool {
+		size, err := memoryQueue.Size(context.Background())
+		require.NoError(t, err)
+		sent, _ := provider.counts()
+		return sent == 9 && size == 0 && workers[0].inFlight.Load() == 0
+	}, 2*time.Second, 5*time.Millisecond)
+	workers[0].Stop()
+}
+
+func TestWorker_StopHandsBackUnstartedJobs(t *testing.T) {
+	provider := &delayProvider{delay: func(int) time.Duration { return 50 * time.Millisecond }}
+	workers, memoryQueue := newPipelineWorkers(t, provider, 1, 2, 30, time.Second)
+
+	workers[0].Start()
+	time.Sleep(20 * time.Millisecond)
+	workers[0].Stop()
+
+	// Jobs in flight were finished, the leased rest is available again
+	sent, _ := provider.counts()
+	size, err := memoryQueue.Size(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, int64(28), size)
+}
+
+func TestWorker_WakesOnPublishWithoutPolling(t *testing.T) {
+	provider := &delayProvider{delay: func(int) time.Duration { return 0 }}
+	workers, memoryQueue := newPipelineWorkers(t, provider, 1, 10, 0, time.Second)
+	workers[0].config.PollInterval = time.Hour
+
+	workers[0].Start()
+	defer workers[0].Stop()
+
+	// Give the first, empty fetch time to finish so only the signal can wake it
+	time.Sleep(20 * time.Millisecond)
+	job := models.NewEmailJob([]string{"test@example.com"}, nil, nil, "email_verification", nil, models.JobPriorityUrgent)
+	require.NoError(t, memoryQueue.Publish(context.Background(), job))
+
+	assert.Eventually(t, func() bool {
+		sent, _ := provider.counts()
+		return sent == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestWorker_DrainInterruptsSendsAfterGracePeriod(t *testing.T) {
+	// The first two sends hang, the drain hands them back with the rest
+	provider := &delayProvider{delay: func(int) time.Duration { return time.Hour }}
+	workers, memoryQueue := newPipelineWorkers(t, provider, 1, 2, 10, time.Hour)
+
+	workers[0].Start()
+	require.Eventually(t, func() bool {
+		return workers[0].inFlight.Load() == 2
+	}, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	handedBack := workers[0].Drain(ctx)
+
+	// Only the jobs with a slot were leased, the rest stayed on the queue
+	assert.Len(t, handedBack.Interrupted, 2)
+	assert.Empty(t, handedBack.Released)
+	size, err := memoryQueue.Size(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), size)
+
+	// Interrupted jobs are sent again without counting an attempt
+	jobs, err := memoryQueue.ConsumeBatch(context.Background(), 10)
+	require.NoError(t, err)
+	for _, job := range jobs {
+		assert.Equal(t, 0, job.RetryCount)
+	}
+}
+
+func TestProcessor_ShutdownReportsHandedBackJobs(t *testing.T) {
+	provider := &delayProvider{delay: func(n int) time.Duration {
+		if n == 0 {
+			return time.Hour
+		}
+		return time.Millisecond
+	}}
+	memoryQueue := queue.NewMemoryQueue(time.Minute, zap.NewNop())
+	jobs := repositories.NewMemoryEmailJobRepository()
+	emailService := services.NewEmailService(jobs, nil, provider, templates.NewEngine())
+	p := NewProcessor(memoryQueue, queue.NewMemoryDeadLetterStore(), queue.NewMemoryIdempotencyStore(), queue.NewMemoryLeaderElector(), queue.NewMemoryHeartbeatStore(), emailService, &ProcessorConfig{
+		WorkerCount:     2,
+		BatchSize:       5,
+		PollInterval:    time.Millisecond,
+		MaxRetries:      3,
+		RetryDelay:      time.Hour,
+		ProcessTimeout:  time.Hour,
+		CleanupInterval: time.Hour,
+	}, zap.NewNop())
+
+	job := models.NewEmailJob([]string{"test@example.com"}, nil, nil, "email_verification", nil, models.JobPriorityNormal)
+	require.NoError(t, p.PublishJob(context.Background(), job))
+	require.NoError(t, p.Start())
+	require.Eventually(t, func() bool {
+		return p.GetStats().InFlightJobs == 1
+	}, time.Second, 5*time.Millisecond)
+	stored, err := jobs.GetByID(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusProcessing, stored.Status)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	report, err := p.Shutdown(ctx)
+	require.NoError(t, err)
+
+	assert.True(t, report.TimedOut)
+	assert.Equal(t, []string{job.ID.String()}, report.Interrupted)
+	assert.Equal(t, 1, report.HandedBack())
+	assert.Less(t, report.Duration, time.Second)
+
+	// The interrupted job is stored as pending again, with no attempt counted
+	stored, err = jobs.GetByID(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusPending, stored.Status)
+	assert.Equal(t, 0, stored.RetryCount)
+
+	// Stopping again is a no-op
+	report, err = p.Shutdown(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, report.HandedBack())
+	assert.NoError(t, p.Stop())
+}
+
+// processBatches is the former worker loop, kept as the benchmark baseline:
+// every job of a batch is sent at once and the next batch is only leased
+// when the whole batch is done
+func processBatches(w *Worker, total int) {
+	for processed := 0; processed < total; {
+		ctx, cancel := context.WithTimeout(context.Background(), w.config.ProcessTimeout)
+		jobs, _ := w.queue.ConsumeBatch(ctx, w.config.BatchSize)
+
+		var wg sync.WaitGroup
+		for _, job := range jobs {
+			wg.Add(1)
+			go func(j *models.EmailJob) {
+				defer wg.Done()
+				w.processJob(ctx, j)
+			}(job)
+		}
+		wg.Wait()
+		cancel()
+		processed += len(jobs)
+	}
+}
+
+// BenchmarkWorker_Throughput compares the batch-at-a-time loop with the
+// pipeline when one send in ten is slow. Both send at most ten jobs at once.
+func BenchmarkWorker_Throughput(b *testing.B) {
+	latency := func(n int) time.Duration {
+		if n%10 == 0 {
+			return 20 * time.Millisecond
+		}
+		return time.Millisecond
+	}
+
+	b.Run("batch", func(b *testing.B) {
+		provider := &delayProvider{delay: latency}
+		workers, _ := newPipelineWorkers(b, provider, 1, 10, b.N, time.Minute)
+
+		b.ResetTimer()
+		processBatches(workers[0], b.N)
+		b.StopTimer()
+		b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "jobs/s")
+	})
+
+	b.Run("pipeline", func(b *testing.B) {
+		provider := &delayProvider{delay: latency}
+		workers, _ := newPipelineWorkers(b, provider, 1, 10, b.N, time.Minute)
+
+		b.ResetTimer()
+		workers[0].Start()
+		for {
+			if sent, _ := provider.counts(); sent >= b.N {
+				break
+			}
+			time.Sleep(100 * time.Microsecond)
+		}
+		b.StopTimer()
+		workers[0].Stop()
+		b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "jobs/s")
+	})
+}
@@ -2,6 +2,7 @@ package processor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -16,11 +17,29 @@ import (
 // Processor manages multiple workers for email processing
 type Processor struct {
 	workers       []*Worker
+	workersMutex  sync.RWMutex
+	// resizeMutex serializes changes to the worker pool
+	resizeMutex   sync.Mutex
+	// scaleDowns tracks the workers resize is still draining
+	scaleDowns    sync.WaitGroup
+	nextWorkerID  int
+	reserved      int
+	reservedLanes *laneScheduler
+	generalLanes  *laneScheduler
+	autoscaler    *autoscaler
+	latency       *latencyTracker
+	limiter       *inFlightLimiter
+	leadership    *leadership
+	heartbeats    queue.HeartbeatStore
+	retries       *retryEngine
 	queue         queue.Queue
+	deadLetters   queue.DeadLetterStore
+	idempotency   queue.IdempotencyStore
 	emailService  *services.EmailService
 	logger        *zap.Logger
 	config        *ProcessorConfig
 	stopChan      chan struct{}
+	stopOnce      sync.Once
 	wg            sync.WaitGroup
 	stats         *ProcessorStats
 	statsMutex    sync.RWMutex
@@ -35,8 +54,38 @@ type ProcessorConfig struct {
 	RetryDelay    time.Duration `mapstructure:"retry_delay"`
 	ProcessTimeout time.Duration `mapstructure:"process_timeout"`
 	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+	Lanes         LaneConfig    `mapstructure:"lanes"`
+	Autoscale     AutoscaleConfig `mapstructure:"autoscale"`
+	Leader        LeaderConfig  `mapstructure:"leader"`
+	Heartbeat     HeartbeatConfig `mapstructure:"heartbeat"`
+	Retry         RetryConfig   `mapstructure:"retry"`
+	// MaxInFlight bounds the jobs being sent at once across all workers,
+	// zero allows one batch per worker of the largest pool
+	MaxInFlight   int           `mapstructure:"max_in_flight"`
+	// IdempotencyWindow is how long an idempotency key returns the job it created
+	IdempotencyWindow time.Duration `mapstructure:"idempotency_window"`
+	// ShutdownGracePeriod is how long Stop waits for jobs in flight before
+	// handing them back to the queue, zero waits until they are finished
+	ShutdownGracePeriod time.Duration `mapstructure:"shutdown_grace_period"`
 }
 
+// DrainReport describes a shutdown of the processor
+type DrainReport struct {
+	Duration time.Duration `json:"duration"`
+	// TimedOut is set when the grace period ran out before all jobs in
+	// flight were finished
+	TimedOut bool `json:"timed_out"`
+	HandedBackJobs
+}
+
+// HandedBack returns how many jobs were returned to the queue
+func (r *DrainReport) HandedBack() int {
+	return len(r.Released) + len(r.Interrupted)
+}
+
+// defaultIdempotencyWindow is used when no idempotency window is configured
+const defaultIdempotencyWindow = 24 * time.Hour
+
 // ProcessorStats holds processor statistics
 type ProcessorStats struct {
 	TotalJobsProcessed   int64     `json:"total_jobs_processed"`
@@ -47,72 +96,153 @@ type ProcessorStats struct {
 	LastProcessedAt      time.Time `json:"last_processed_at"`
 	QueueSize            int64     `json:"queue_size"`
 	ActiveWorkers        int       `json:"active_workers"`
+	InFlightJobs         int       `json:"in_flight_jobs"`
+	MaxInFlight          int       `json:"max_in_flight"`
 }
 
 // NewProcessor creates a new processor instance
-func NewProcessor(queue queue.Queue, emailService *services.EmailService, config *ProcessorConfig, logger *zap.Logger) *Processor {
+func NewProcessor(queue queue.Queue, deadLetters queue.DeadLetterStore, idempotency queue.IdempotencyStore, leaders queue.LeaderElector, heartbeats queue.HeartbeatStore, emailService *services.EmailService, config *ProcessorConfig, logger *zap.Logger) *Processor {
+	config.Heartbeat = config.Heartbeat.withDefaults()
+	config.Retry = config.Retry.withDefaults(config.RetryDelay)
+	autoscaler := newAutoscaler(config.Autoscale, config.WorkerCount)
 	return &Processor{
 		queue:        queue,
+		deadLetters:  deadLetters,
+		idempotency:  idempotency,
 		emailService: emailService,
 		logger:       logger,
 		config:       config,
 		stopChan:     make(chan struct{}),
 		stats:        &ProcessorStats{},
+		autoscaler:   autoscaler,
+		latency:      &latencyTracker{},
+		limiter:      newInFlightLimiter(maxInFlight(config, autoscaler.config.MaxWorkers)),
+		leadership:   newLeadership(leaders, config.Leader, logger),
+		heartbeats:   heartbeats,
+		retries:      newRetryEngine(config.Retry),
 	}
 }
 
+// maxInFlight returns the global in-flight limit of a configuration whose
+// pool grows up to maxWorkers
+func maxInFlight(config *ProcessorConfig, maxWorkers int) int {
+	if config.MaxInFlight > 0 {
+		return config.MaxInFlight
+	}
+	return maxWorkers * config.BatchSize
+}
+
 // Start starts the processor and all workers
 func (p *Processor) Start() error {
 	p.logger.Info("Starting email processor",
 		zap.Int("worker_count", p.config.WorkerCount),
 		zap.Int("batch_size", p.config.BatchSize),
+		zap.Int("max_in_flight", p.limiter.limit()),
 		zap.Duration("poll_interval", p.config.PollInterval),
 	)
 
-	// Create and start workers
-	p.workers = make([]*Worker, p.config.WorkerCount)
-	for i := 0; i < p.config.WorkerCount; i++ {
-		workerConfig := &WorkerConfig{
-			BatchSize:      p.config.BatchSize,
-			PollInterval:   p.config.PollInterval,
-			MaxRetries:     p.config.MaxRetries,
-			RetryDelay:     p.config.RetryDelay,
-			ProcessTimeout: p.config.ProcessTimeout,
-		}
-
-		worker := NewWorker(i+1, p.queue, p.emailService, workerConfig, p.logger)
-		p.workers[i] = worker
-		worker.Start()
+	// Reserved workers serve urgent and high priority jobs only, the other
+	// workers share one scheduler over all lanes
+	if p.config.Lanes.Enabled() {
+		p.reserved = p.reservedWorkers()
+		p.reservedLanes = newLaneScheduler(p.config.Lanes, models.JobPriorityUrgent, models.JobPriorityHigh)
+		p.generalLanes = newLaneScheduler(p.config.Lanes, models.JobPriorities...)
 	}
 
-	// Start background tasks
-	p.wg.Add(3)
+	// Create and start workers
+	p.resize(p.autoscaler.initialWorkers(p.config.WorkerCount))
+
+	// Start background tasks. Tasks acting on the whole queue or database
+	// only run on the leader, so take or wait for the lease first.
+	p.leadership.renew()
+	p.wg.Add(5)
+	go p.leaderTask()
+	go p.reaperTask()
 	go p.scheduledJobsProcessor()
 	go p.cleanupTask()
 	go p.statsCollector()
 
+	if p.config.Autoscale.Enabled() {
+		p.wg.Add(1)
+		go p.autoscaleTask()
+	}
+
+	if p.config.Lanes.Enabled() && p.config.Lanes.AgingInterval > 0 {
+		p.wg.Add(1)
+		go p.agingTask()
+	}
+
 	p.logger.Info("Email processor started successfully")
 	return nil
 }
 
-// Stop stops the processor and all workers
+// Stop stops the processor and all workers, waiting up to the shutdown grace
+// period for jobs in flight
 func (p *Processor) Stop() error {
+	ctx := context.Background()
+	if p.config.ShutdownGracePeriod > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.ShutdownGracePeriod)
+		defer cancel()
+	}
+
+	_, err := p.Shutdown(ctx)
+	return err
+}
+
+// Shutdown drains the processor. Workers stop leasing jobs at once and hand
+// back the jobs they leased but did not start. Jobs in flight are finished
+// until ctx is done, then their sends are cancelled and they are handed back
+// as well, so they are sent again by the next worker to lease them. Calling it
+// again returns an empty report.
+func (p *Processor) Shutdown(ctx context.Context) (*DrainReport, error) {
 	p.logger.Info("Stopping email processor")
+	start := time.Now()
 
 	// Stop background tasks
-	close(p.stopChan)
+	p.stopOnce.Do(func() { close(p.stopChan) })
 	p.wg.Wait()
 
-	// Stop all workers
-	for _, worker := range p.workers {
-		worker.Stop()
+	// Drain all workers at once, so they share the grace period
+	p.resizeMutex.Lock()
+	defer p.resizeMutex.Unlock()
+	p.workersMutex.Lock()
+	workers := p.workers
+	p.workers = nil
+	p.workersMutex.Unlock()
+
+	handedBack := make([]HandedBackJobs, len(workers))
+	var wg sync.WaitGroup
+	for i, worker := range workers {
+		wg.Add(1)
+		go func(i int, worker *Worker) {
+			defer wg.Done()
+			handedBack[i] = worker.Drain(ctx)
+		}(i, worker)
 	}
+	wg.Wait()
+	p.scaleDowns.Wait()
 
-	p.logger.Info("Email processor stopped")
-	return nil
+	report := &DrainReport{
+		Duration: time.Since(start),
+		TimedOut: ctx.Err() != nil,
+	}
+	for _, jobs := range handedBack {
+		report.Released = append(report.Released, jobs.Released...)
+		report.Interrupted = append(report.Interrupted, jobs.Interrupted...)
+	}
+
+	p.logger.Info("Email processor stopped",
+		zap.Duration("duration", report.Duration),
+		zap.Bool("timed_out", report.TimedOut),
+		zap.Int("handed_back", report.HandedBack()),
+		zap.Strings("released_jobs", report.Released),
+		zap.Strings("interrupted_jobs", report.Interrupted),
+	)
+	return report, nil
 }
 
-// scheduledJobsProcessor processes scheduled jobs
+// scheduledJobsProcessor moves due scheduled jobs to the queue on the leader
 func (p *Processor) scheduledJobsProcessor() {
 	defer p.wg.Done()
 
@@ -124,18 +254,68 @@ func (p *Processor) scheduledJobsProcessor() {
 		case <-p.stopChan:
 			return
 		case <-ticker.C:
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			err := p.queue.ProcessScheduledJobs(ctx)
-			cancel()
+			p.runAsLeader("scheduled_jobs", 10*time.Second, func(ctx context.Context) {
+				if err := p.queue.ProcessScheduledJobs(ctx); err != nil {
+					p.logger.Error("Failed to process scheduled jobs", zap.Error(err))
+				}
+			})
+		}
+	}
+}
 
+// reservedWorkers returns how many workers to reserve for urgent and high
+// priority jobs, keeping at least one worker for the other lanes even when the
+// pool is at its smallest
+func (p *Processor) reservedWorkers() int {
+	reserved := p.config.Lanes.ReservedWorkers
+	minWorkers := p.autoscaler.config.MinWorkers
+	if reserved > 0 && reserved >= minWorkers {
+		p.logger.Warn("Reserved workers must leave a worker for the other lanes",
+			zap.Int("reserved_workers", reserved),
+			zap.Int("min_workers", minWorkers))
+		reserved = minWorkers - 1
+	}
+	if reserved < 0 {
+		reserved = 0
+	}
+	return reserved
+}
+
+// agingTask periodically promotes jobs that have waited too long, so low
+// priority jobs cannot starve behind a steady stream of higher priority ones.
+// Jobs are never aged into the urgent lane. Only the leader promotes jobs.
+func (p *Processor) agingTask() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.Lanes.AgingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopChan:
+			return
+		case <-ticker.C:
+			var err error
+			p.runAsLeader("aging", 10*time.Second, func(ctx context.Context) {
+				var promoted int
+				promoted, err = p.queue.PromoteAged(ctx, p.config.Lanes.AgingInterval, models.JobPriorityHigh)
+				if promoted > 0 {
+					p.logger.Info("Promoted aged jobs", zap.Int("count", promoted))
+				}
+			})
+
+			if errors.Is(err, queue.ErrNotSupported) {
+				p.logger.Warn("Queue does not support priority aging", zap.Error(err))
+				return
+			}
 			if err != nil {
-				p.logger.Error("Failed to process scheduled jobs", zap.Error(err))
+				p.logger.Error("Failed to promote aged jobs", zap.Error(err))
 			}
 		}
 	}
 }
 
-// cleanupTask performs periodic cleanup
+// cleanupTask performs periodic cleanup on the leader
 func (p *Processor) cleanupTask() {
 	defer p.wg.Done()
 
@@ -147,16 +327,13 @@ func (p *Processor) cleanupTask() {
 		case <-p.stopChan:
 			return
 		case <-ticker.C:
-			p.performCleanup()
+			p.runAsLeader("cleanup", 5*time.Minute, p.performCleanup)
 		}
 	}
 }
 
 // performCleanup performs cleanup tasks
-func (p *Processor) performCleanup() {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
-
+func (p *Processor) performCleanup(ctx context.Context) {
 	// Clean up old jobs (older than 30 days)
 	cutoffTime := time.Now().AddDate(0, 0, -30)
 	err := p.emailService.CleanupOldJobs(ctx, cutoffTime)
@@ -164,10 +341,19 @@ func (p *Processor) performCleanup() {
 		p.logger.Error("Failed to cleanup old jobs", zap.Error(err))
 	}
 
+	// Forget idempotency keys whose window has passed
+	expired, err := p.idempotency.DeleteExpired(ctx)
+	if err != nil {
+		p.logger.Error("Failed to delete expired idempotency keys", zap.Error(err))
+	} else if expired > 0 {
+		p.logger.Info("Deleted expired idempotency keys", zap.Int("count", expired))
+	}
+
 	p.logger.Info("Cleanup completed")
 }
 
-// statsCollector collects and updates statistics
+// statsCollector collects and updates statistics. It runs on every replica,
+// each serves its own stats.
 func (p *Processor) statsCollector() {
 	defer p.wg.Done()
 
@@ -209,7 +395,7 @@ func (p *Processor) updateStats() {
 		p.stats.SuccessfulJobs = int64(dbStats.CompletedJobs)
 		p.stats.FailedJobs = int64(dbStats.FailedJobs)
 	}
-	p.stats.ActiveWorkers = len(p.workers)
+	p.stats.ActiveWorkers = p.workerCount()
 	p.statsMutex.Unlock()
 }
 
@@ -220,6 +406,8 @@ func (p *Processor) GetStats() *ProcessorStats {
 
 	// Create a copy to avoid race conditions
 	stats := *p.stats
+	stats.InFlightJobs = p.limiter.inUse()
+	stats.MaxInFlight = p.limiter.limit()
 	return &stats
 }
 
@@ -239,7 +427,7 @@ func (p *Processor) Health(ctx context.Context) error {
 
 	// Check if workers are running
 	activeWorkers := 0
-	for _, worker := range p.workers {
+	for _, worker := range p.currentWorkers() {
 		stats := worker.GetStats()
 		if stats["status"] == "running" {
 			activeWorkers++
@@ -253,11 +441,17 @@ func (p *Processor) Health(ctx context.Context) error {
 	return nil
 }
 
-// PublishJob publishes a job to the queue
+// PublishJob stores a job and publishes it to the queue
 func (p *Processor) PublishJob(ctx context.Context, job *models.EmailJob) error {
+	stored, err := p.storeJob(ctx, job)
+	if err != nil {
+		return err
+	}
+
 	// Publish to queue
-	err := p.queue.Publish(ctx, job)
+	err = p.queue.Publish(ctx, job)
 	if err != nil {
+		p.abandonJob(job, stored, err)
 		return fmt.Errorf("failed to publish job to queue: %w", err)
 	}
 
@@ -270,12 +464,57 @@ func (p *Processor) PublishJob(ctx context.Context, job *models.EmailJob) error
 	return nil
 }
 
+// SubmitJob publishes a job unless its idempotency key was already used within
+// the idempotency window. A repeated key returns the original job and reports
+// it as a duplicate, nothing is published for it.
+func (p *Processor) SubmitJob(ctx context.Context, job *models.EmailJob) (*models.EmailJob, bool, error) {
+	if job.IdempotencyKey == "" {
+		return job, false, p.PublishJob(ctx, job)
+	}
+
+	window := p.config.IdempotencyWindow
+	if window <= 0 {
+		window = defaultIdempotencyWindow
+	}
+
+	original, err := p.idempotency.Reserve(ctx, job.IdempotencyKey, job, window)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if original != nil {
+		p.logger.Info("Duplicate job submission, returning original job",
+			zap.String("idempotency_key", job.IdempotencyKey),
+			zap.String("job_id", original.ID.String()),
+		)
+		return original, true, nil
+	}
+
+	if err := p.PublishJob(ctx, job); err != nil {
+		// Free the key so the caller's retry can publish the job
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if releaseErr := p.idempotency.Release(releaseCtx, job.IdempotencyKey, job.ID.String()); releaseErr != nil {
+			p.logger.Error("Failed to release idempotency key",
+				zap.String("idempotency_key", job.IdempotencyKey),
+				zap.Error(releaseErr))
+		}
+		return nil, false, err
+	}
+
+	return job, false, nil
+}
+
 // PublishScheduledJob publishes a job for scheduled delivery
 func (p *Processor) PublishScheduledJob(ctx context.Context, job *models.EmailJob, scheduledAt time.Time) error {
-	// For now, just publish to scheduled queue
-	// TODO: Implement tracking logic when needed
-	err := p.queue.PublishScheduled(ctx, job, scheduledAt)
+	job.SetScheduledAt(scheduledAt)
+	stored, err := p.storeJob(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	err = p.queue.PublishScheduled(ctx, job, scheduledAt)
 	if err != nil {
+		p.abandonJob(job, stored, err)
 		return fmt.Errorf("failed to publish scheduled job: %w", err)
 	}
 
@@ -288,11 +527,101 @@ func (p *Processor) PublishScheduledJob(ctx context.Context, job *models.EmailJo
 	return nil
 }
 
+// storeJob stores a new job before it is queued, so a worker never leases a
+// job without a stored row to record its status on. Queues keeping their
+// jobs in email_jobs store them when they are published. It reports whether
+// the job was stored.
+func (p *Processor) storeJob(ctx context.Context, job *models.EmailJob) (bool, error) {
+	if storing, ok := p.queue.(queue.JobStoringQueue); ok && storing.StoresJobs() {
+		return false, nil
+	}
+	if err := p.emailService.CreateJob(ctx, job); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// abandonJob records a stored job that could not be queued as failed
+func (p *Processor) abandonJob(job *models.EmailJob, stored bool, err error) {
+	if !stored {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	job.MarkAsFailed()
+	job.ErrorMessage = fmt.Sprintf("failed to queue job: %v", err)
+	if recordErr := p.emailService.RecordJobOutcome(ctx, job); recordErr != nil {
+		p.logger.Error("Failed to record job that could not be queued",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(recordErr))
+	}
+}
+
+// CancelJob removes a pending or scheduled job from the queue and marks it
+// cancelled. A job a worker has already leased is dropped by that worker.
+// Queues that cannot remove jobs, like Kafka, keep delivering the job and
+// workers drop it by its stored status instead.
+func (p *Processor) CancelJob(ctx context.Context, jobID string) error {
+	err := p.queue.Cancel(ctx, jobID)
+	if errors.Is(err, queue.ErrNotSupported) {
+		err = p.emailService.CheckCancellable(ctx, jobID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to cancel job %s: %w", jobID, err)
+	}
+
+	if err := p.emailService.UpdateJobStatus(ctx, jobID, string(models.JobStatusCancelled)); err != nil {
+		return fmt.Errorf("failed to mark job %s cancelled: %w", jobID, err)
+	}
+
+	p.logger.Info("Job cancelled", zap.String("job_id", jobID))
+	return nil
+}
+
+// RescheduleJob moves a pending or scheduled job to scheduledAt
+func (p *Processor) RescheduleJob(ctx context.Context, jobID string, scheduledAt time.Time) error {
+	if err := p.queue.Reschedule(ctx, jobID, scheduledAt); err != nil {
+		return fmt.Errorf("failed to reschedule job %s: %w", jobID, err)
+	}
+
+	if err := p.emailService.RescheduleJob(ctx, jobID, scheduledAt); err != nil {
+		return fmt.Errorf("failed to record schedule of job %s: %w", jobID, err)
+	}
+
+	p.logger.Info("Job rescheduled",
+		zap.String("job_id", jobID),
+		zap.Time("scheduled_at", scheduledAt),
+	)
+	return nil
+}
+
+// GetJob returns the stored state of an email job
+func (p *Processor) GetJob(ctx context.Context, jobID string) (*models.EmailJob, error) {
+	return p.emailService.GetJob(ctx, jobID)
+}
+
 // GetWorkerStats returns statistics for all workers
 func (p *Processor) GetWorkerStats() []map[string]any {
-	stats := make([]map[string]any, len(p.workers))
-	for i, worker := range p.workers {
+	workers := p.currentWorkers()
+	stats := make([]map[string]any, len(workers))
+	for i, worker := range workers {
 		stats[i] = worker.GetStats()
 	}
 	return stats
+}
+
+// currentWorkers returns a snapshot of the worker pool
+func (p *Processor) currentWorkers() []*Worker {
+	p.workersMutex.RLock()
+	defer p.workersMutex.RUnlock()
+	return append([]*Worker(nil), p.workers...)
+}
+
+// workerCount returns the current size of the worker pool
+func (p *Processor) workerCount() int {
+	p.workersMutex.RLock()
+	defer p.workersMutex.RUnlock()
+	return len(p.workers)
 } 
\ No newline at end of file
@@ -0,0 +1,390 @@
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/providers"
+	"booking-system/email-worker/queue"
+	"booking-system/email-worker/services"
+	"booking-system/email-worker/templates"
+)
+
+// acceptingProvider is a provider that accepts every email
+type acceptingProvider struct{}
+
+func (p *acceptingProvider) Name() string                     { return "accepting" }
+func (p *acceptingProvider) Validate() error                  { return nil }
+func (p *acceptingProvider) Health(ctx context.Context) error { return nil }
+func (p *acceptingProvider) Close() error                     { return nil }
+
+func (p *acceptingProvider) Send(ctx context.Context, req *providers.EmailRequest) (*providers.EmailResponse, error) {
+	return &providers.EmailResponse{MessageID: "accepted", Status: "sent", Provider: p.Name(), SentAt: time.Now()}, nil
+}
+
+func (p *acceptingProvider) SendBatch(ctx context.Context, batch *providers.BatchRequest) ([]providers.BatchResult, error) {
+	return providers.SendEach(ctx, p, batch)
+}
+
+// newTestProcessor creates a processor backed by an in-memory queue and dead-letter store
+func newTestProcessor(t *testing.T, workerCount int) (*Processor, *queue.MemoryQueue, *queue.MemoryDeadLetterStore) {
+	t.Helper()
+	return newLaneTestProcessor(t, workerCount, LaneConfig{})
+}
+
+// newLaneTestProcessor creates a test processor consuming through priority lanes
+func newLaneTestProcessor(t *testing.T, workerCount int, lanes LaneConfig) (*Processor, *queue.MemoryQueue, *queue.MemoryDeadLetterStore) {
+	t.Helper()
+	return newConfiguredTestProcessor(t, workerCount, func(config *ProcessorConfig) {
+		config.Lanes = lanes
+	})
+}
+
+// newConfiguredTestProcessor creates a test processor with configure applied
+// to the default test configuration
+func newConfiguredTestProcessor(t *testing.T, workerCount int, configure func(*ProcessorConfig)) (*Processor, *queue.MemoryQueue, *queue.MemoryDeadLetterStore) {
+	t.Helper()
+
+	logger := zap.NewNop()
+	memoryQueue := queue.NewMemoryQueue(time.Minute, logger)
+	deadLetters := queue.NewMemoryDeadLetterStore()
+	idempotency := queue.NewMemoryIdempotencyStore()
+	leaders := queue.NewMemoryLeaderElector()
+	heartbeats := queue.NewMemoryHeartbeatStore()
+	emailService := services.NewEmailService(nil, nil, &acceptingProvider{}, templates.NewEngine())
+
+	config := &ProcessorConfig{
+		WorkerCount:     workerCount,
+		BatchSize:       10,
+		PollInterval:    50 * time.Millisecond,
+		MaxRetries:      3,
+		RetryDelay:      100 * time.Millisecond,
+		ProcessTimeout:  5 * time.Second,
+		CleanupInterval: time.Minute,
+	}
+	configure(config)
+
+	return NewProcessor(memoryQueue, deadLetters, idempotency, leaders, heartbeats, emailService, config, logger), memoryQueue, deadLetters
+}
+
+func newTestJob(priority models.JobPriority) *models.EmailJob {
+	return models.NewEmailJob([]string{"test@example.com"}, nil, nil, "email_verification", map[string]any{"Name": "Test"}, priority)
+}
+
+func queueSize(t *testing.T, q queue.Queue) int64 {
+	size, err := q.Size(context.Background())
+	require.NoError(t, err)
+	return size
+}
+
+func TestProcessor_StartStop(t *testing.T) {
+	proc, _, _ := newTestProcessor(t, 2)
+
+	require.NoError(t, proc.Start())
+	assert.Len(t, proc.GetWorkerStats(), 2)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, proc.Stop())
+}
+
+func TestProcessor_ProcessJobs(t *testing.T) {
+	proc, memoryQueue, _ := newTestProcessor(t, 2)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, proc.PublishJob(ctx, newTestJob(models.JobPriorityNormal)))
+	}
+	assert.Equal(t, int64(5), queueSize(t, memoryQueue))
+
+	require.NoError(t, proc.Start())
+	defer proc.Stop()
+
+	assert.Eventually(t, func() bool {
+		return queueSize(t, memoryQueue) == 0
+	}, 2*time.Second, 20*time.Millisecond)
+
+	// Every job was acknowledged, so none is redelivered after its lease
+	require.NoError(t, memoryQueue.ProcessScheduledJobs(ctx))
+	assert.Equal(t, int64(0), queueSize(t, memoryQueue))
+}
+
+func TestProcessor_ProcessScheduledJob(t *testing.T) {
+	proc, memoryQueue, _ := newTestProcessor(t, 1)
+	ctx := context.Background()
+
+	job := newTestJob(models.JobPriorityHigh)
+	require.NoError(t, proc.PublishScheduledJob(ctx, job, time.Now().Add(200*time.Millisecond)))
+
+	require.NoError(t, proc.Start())
+	defer proc.Stop()
+
+	// Not due yet
+	time.Sleep(100 * time.Millisecond)
+	_, err := memoryQueue.Consume(ctx)
+	assert.Equal(t, queue.ErrQueueEmpty, err)
+
+	// Once due it is picked up by a worker
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, int64(0), queueSize(t, memoryQueue))
+	_, err = memoryQueue.Consume(ctx)
+	assert.Equal(t, queue.ErrQueueEmpty, err)
+}
+
+func TestProcessor_ProcessJobsWithQueueError(t *testing.T) {
+	proc, memoryQueue, _ := newTestProcessor(t, 1)
+
+	// A closed queue fails every consume, workers must keep running
+	require.NoError(t, memoryQueue.Close())
+	require.NoError(t, proc.Start())
+
+	time.Sleep(200 * time.Millisecond)
+	assert.NoError(t, proc.Stop())
+}
+
+func TestProcessor_GetWorkerStats(t *testing.T) {
+	proc, _, _ := newTestProcessor(t, 3)
+	ctx := context.Background()
+
+	require.NoError(t, proc.PublishScheduledJob(ctx, newTestJob(models.JobPriorityLow), time.Now().Add(time.Hour)))
+	require.NoError(t, proc.Start())
+	defer proc.Stop()
+
+	stats := proc.GetWorkerStats()
+	require.Len(t, stats, 3)
+	for i, workerStats := range stats {
+		assert.Equal(t, i+1, workerStats["worker_id"])
+		assert.Equal(t, "running", workerStats["status"])
+		assert.Equal(t, int64(0), workerStats["queue_size"])
+	}
+}
+
+func TestProcessor_ReplayAndPurgeDeadLetters(t *testing.T) {
+	proc, memoryQueue, deadLetters := newTestProcessor(t, 1)
+	ctx := context.Background()
+
+	var jobs []*models.EmailJob
+	for _, templateName := range []string{"welcome", "welcome", "email_verification"} {
+		job := newTestJob(models.JobPriorityNormal)
+		job.TemplateName = templateName
+		job.RetryCount = job.MaxRetries
+		job.RecordAttempt("provider outage")
+		job.MarkAsFailed()
+		require.NoError(t, deadLetters.Add(ctx, queue.NewDeadLetter(job, "provider outage")))
+		jobs = append(jobs, job)
+	}
+
+	// Replay a single job by ID
+	replayed, err := proc.ReplayDeadLetters(ctx, []string{jobs[0].ID.String()}, queue.DeadLetterFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+
+	consumed, err := memoryQueue.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, jobs[0].ID, consumed.ID)
+	assert.Equal(t, models.JobStatusPending, consumed.Status)
+	assert.Equal(t, 0, consumed.RetryCount)
+	assert.Len(t, consumed.Attempts, 1)
+
+	_, err = proc.GetDeadLetter(ctx, jobs[0].ID.String())
+	assert.ErrorIs(t, err, queue.ErrDeadLetterNotFound)
+
+	// Unknown IDs fail without replaying anything
+	_, err = proc.ReplayDeadLetters(ctx, []string{"missing"}, queue.DeadLetterFilter{})
+	assert.ErrorIs(t, err, queue.ErrDeadLetterNotFound)
+
+	// Replay in bulk by filter
+	replayed, err = proc.ReplayDeadLetters(ctx, nil, queue.DeadLetterFilter{TemplateName: "welcome"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	assert.Equal(t, int64(1), queueSize(t, memoryQueue))
+
+	// Purge what is left
+	purged, err := proc.PurgeDeadLetters(ctx, nil, queue.DeadLetterFilter{ErrorContains: "outage"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	remaining, err := proc.ListDeadLetters(ctx, queue.DeadLetterFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+}
+
+func TestProcessor_PriorityLanes(t *testing.T) {
+	proc, memoryQueue, _ := newLaneTestProcessor(t, 2, LaneConfig{
+		UrgentWeight:    8,
+		HighWeight:      4,
+		NormalWeight:    2,
+		LowWeight:       1,
+		ReservedWorkers: 1,
+		AgingInterval:   time.Minute,
+	})
+	ctx := context.Background()
+
+	for _, priority := range models.JobPriorities {
+		for i := 0; i < 3; i++ {
+			require.NoError(t, proc.PublishJob(ctx, newTestJob(priority)))
+		}
+	}
+
+	require.NoError(t, proc.Start())
+	defer proc.Stop()
+
+	// The reserved worker only serves urgent and high, the other one every lane
+	stats := proc.GetWorkerStats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, []string{"urgent", "high"}, stats[0]["lanes"])
+	assert.Equal(t, []string{"urgent", "high", "normal", "low"}, stats[1]["lanes"])
+
+	assert.Eventually(t, func() bool {
+		return queueSize(t, memoryQueue) == 0
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestProcessor_SubmitJobDeduplicatesIdempotencyKey(t *testing.T) {
+	proc, memoryQueue, _ := newTestProcessor(t, 1)
+	ctx := context.Background()
+
+	first := newTestJob(models.JobPriorityUrgent)
+	first.IdempotencyKey = "auth-service:verify:42"
+	submitted, duplicate, err := proc.SubmitJob(ctx, first)
+	require.NoError(t, err)
+	assert.False(t, duplicate)
+	assert.Equal(t, first.ID, submitted.ID)
+
+	// The caller retries after a timeout, no second email is queued
+	retry := newTestJob(models.JobPriorityUrgent)
+	retry.IdempotencyKey = first.IdempotencyKey
+	submitted, duplicate, err = proc.SubmitJob(ctx, retry)
+	require.NoError(t, err)
+	assert.True(t, duplicate)
+	assert.Equal(t, first.ID, submitted.ID)
+	assert.Equal(t, int64(1), queueSize(t, memoryQueue))
+
+	// Jobs without a key are never deduplicated
+	for i := 0; i < 2; i++ {
+		_, duplicate, err = proc.SubmitJob(ctx, newTestJob(models.JobPriorityNormal))
+		require.NoError(t, err)
+		assert.False(t, duplicate)
+	}
+	assert.Equal(t, int64(3), queueSize(t, memoryQueue))
+}
+
+func TestProcessor_SubmitJobReleasesKeyWhenPublishFails(t *testing.T) {
+	proc, memoryQueue, _ := newTestProcessor(t, 1)
+	ctx := context.Background()
+
+	require.NoError(t, memoryQueue.Close())
+	job := newTestJob(models.JobPriorityUrgent)
+	job.IdempotencyKey = "auth-service:verify:42"
+	_, _, err := proc.SubmitJob(ctx, job)
+	require.Error(t, err)
+
+	// The key was released, so the retry is not reported as a duplicate
+	retry := newTestJob(models.JobPriorityUrgent)
+	retry.IdempotencyKey = job.IdempotencyKey
+	_, duplicate, err := proc.SubmitJob(ctx, retry)
+	require.Error(t, err)
+	assert.False(t, duplicate)
+}
+
+func TestProcessor_CancelJob(t *testing.T) {
+	proc, memoryQueue, _ := newTestProcessor(t, 1)
+	ctx := context.Background()
+
+	reminder := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, proc.PublishScheduledJob(ctx, reminder, time.Now().Add(time.Hour)))
+	require.NoError(t, proc.CancelJob(ctx, reminder.ID.String()))
+	assert.ErrorIs(t, proc.CancelJob(ctx, reminder.ID.String()), queue.ErrJobNotFound)
+
+	// A job cancelled while leased is dropped by the worker that gets it
+	leased := newTestJob(models.JobPriorityHigh)
+	require.NoError(t, proc.PublishJob(ctx, leased))
+	consumed, err := memoryQueue.Consume(ctx)
+	require.NoError(t, err)
+	require.NoError(t, proc.CancelJob(ctx, leased.ID.String()))
+	require.NoError(t, memoryQueue.Nack(ctx, consumed.Receipt, 0))
+
+	require.NoError(t, proc.Start())
+	defer proc.Stop()
+
+	assert.Eventually(t, func() bool {
+		cancelled, err := memoryQueue.Cancelled(ctx, leased.ID.String())
+		return err == nil && !cancelled && queueSize(t, memoryQueue) == 0
+	}, 2*time.Second, 20*time.Millisecond)
+
+	// It was acked, not retried
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, memoryQueue.ProcessScheduledJobs(ctx))
+	assert.Equal(t, int64(0), queueSize(t, memoryQueue))
+}
+
+func TestProcessor_RescheduleJob(t *testing.T) {
+	proc, memoryQueue, _ := newTestProcessor(t, 1)
+	ctx := context.Background()
+
+	job := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, proc.PublishJob(ctx, job))
+	require.NoError(t, proc.RescheduleJob(ctx, job.ID.String(), time.Now().Add(time.Hour)))
+	assert.Equal(t, int64(0), queueSize(t, memoryQueue))
+
+	require.NoError(t, proc.RescheduleJob(ctx, job.ID.String(), time.Now()))
+	assert.Equal(t, int64(1), queueSize(t, memoryQueue))
+}
+
+func TestProcessor_AutoscaleShrinksIdlePool(t *testing.T) {
+	proc, _, _ := newConfiguredTestProcessor(t, 4, func(config *ProcessorConfig) {
+		config.Autoscale = AutoscaleConfig{
+			MinWorkers: 1,
+			MaxWorkers: 6,
+			Interval:   20 * time.Millisecond,
+		}
+	})
+
+	require.NoError(t, proc.Start())
+	defer proc.Stop()
+	assert.Len(t, proc.GetWorkerStats(), 4)
+
+	// An empty queue halves the pool on every evaluation down to the minimum
+	assert.Eventually(t, func() bool {
+		return len(proc.GetWorkerStats()) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	status := proc.GetAutoscaleStatus()
+	assert.True(t, status.Enabled)
+	assert.Equal(t, 1, status.Workers)
+	require.NotEmpty(t, status.Decisions)
+	assert.Equal(t, ScaleActionDown, status.Decisions[0].Action)
+	assert.Equal(t, 4, status.Decisions[0].From)
+}
+
+func TestProcessor_PinWorkers(t *testing.T) {
+	proc, _, _ := newConfiguredTestProcessor(t, 2, func(config *ProcessorConfig) {
+		config.Autoscale = AutoscaleConfig{
+			MinWorkers: 1,
+			MaxWorkers: 5,
+			Interval:   20 * time.Millisecond,
+		}
+	})
+
+	require.NoError(t, proc.Start())
+	defer proc.Stop()
+
+	assert.ErrorIs(t, proc.PinWorkers(6), ErrInvalidWorkerCount)
+	require.NoError(t, proc.PinWorkers(5))
+	assert.Len(t, proc.GetWorkerStats(), 5)
+
+	// The autoscaler leaves a pinned pool alone
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, proc.GetWorkerStats(), 5)
+	assert.Equal(t, 5, proc.GetAutoscaleStatus().Pinned)
+
+	require.NoError(t, proc.UnpinWorkers())
+	assert.Eventually(t, func() bool {
+		return len(proc.GetWorkerStats()) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+}
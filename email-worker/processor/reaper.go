@@ -0,0 +1,158 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/metrics"
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/queue"
+)
+
+// HeartbeatConfig holds worker heartbeat configuration. Workers record the
+// jobs they are sending every Interval. The leader recovers the jobs of
+// workers that have not done so for Timeout.
+type HeartbeatConfig struct {
+	Interval     time.Duration `mapstructure:"interval"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	ReapInterval time.Duration `mapstructure:"reap_interval"`
+}
+
+// Default heartbeat timings, used when none are configured
+const (
+	defaultHeartbeatInterval = 10 * time.Second
+	defaultHeartbeatTimeout  = time.Minute
+	defaultReapInterval      = 30 * time.Second
+)
+
+// Outcomes of a recovered job, the label of the recovery metric
+const (
+	recoveryRequeued    = "requeued"
+	recoveryFailed      = "failed"
+	recoveryCancelled   = "cancelled"
+	recoveryRedelivered = "redelivered"
+)
+
+// withDefaults fills in the heartbeat timings that are not configured. The
+// timeout is kept above two intervals, so one late heartbeat is not a death.
+func (c HeartbeatConfig) withDefaults() HeartbeatConfig {
+	if c.Interval <= 0 {
+		c.Interval = defaultHeartbeatInterval
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultHeartbeatTimeout
+	}
+	if c.Timeout < 2*c.Interval {
+		c.Timeout = 2 * c.Interval
+	}
+	if c.ReapInterval <= 0 {
+		c.ReapInterval = defaultReapInterval
+	}
+	return c
+}
+
+// reaperTask recovers the jobs of dead workers on the leader
+func (p *Processor) reaperTask() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.Heartbeat.ReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopChan:
+			return
+		case <-ticker.C:
+			p.runAsLeader("reaper", time.Minute, func(ctx context.Context) {
+				p.reapStuckJobs(ctx)
+			})
+		}
+	}
+}
+
+// reapStuckJobs recovers the jobs that workers of any replica were sending
+// when they stopped heartbeating. It returns how many jobs were recovered.
+func (p *Processor) reapStuckJobs(ctx context.Context) int {
+	heartbeats, err := p.heartbeats.Expired(ctx, time.Now().Add(-p.config.Heartbeat.Timeout))
+	if err != nil {
+		p.logger.Error("Failed to list expired heartbeats", zap.Error(err))
+		return 0
+	}
+
+	// A detached worker settles recovered jobs like a failed send
+	settler := NewWorker(0, p.queue, p.deadLetters, p.emailService, p.workerConfig(), p.logger.With(zap.String("component", "reaper")))
+	settler.retries = p.retries
+
+	recovered := 0
+	for _, heartbeat := range heartbeats {
+		complete := true
+		for _, entry := range heartbeat.Jobs {
+			if err := p.recoverJob(ctx, settler, heartbeat, entry); err != nil {
+				p.logger.Error("Failed to recover stuck job",
+					zap.String("worker", heartbeat.Worker),
+					zap.String("job_id", entry.Job.ID.String()),
+					zap.Error(err))
+				complete = false
+				continue
+			}
+			recovered++
+		}
+
+		// Keep the heartbeat until all of its jobs are recovered
+		if !complete {
+			continue
+		}
+		if err := p.heartbeats.Remove(ctx, heartbeat); err != nil {
+			p.logger.Error("Failed to remove expired heartbeat",
+				zap.String("worker", heartbeat.Worker),
+				zap.Error(err))
+		}
+	}
+	return recovered
+}
+
+// recoverJob settles a job a dead worker was sending. The attempt counts
+// against the job's retries: it is queued again while it has retries left,
+// and failed with the dead worker as the reason otherwise. A job whose lease
+// already went to another worker is left to that worker.
+func (p *Processor) recoverJob(ctx context.Context, settler *Worker, heartbeat *queue.Heartbeat, entry *queue.HeartbeatJob) error {
+	job := entry.Job
+	job.Receipt = entry.Receipt
+
+	// Holding on to the lease also makes sure it was not handed out again
+	err := p.queue.ExtendLease(ctx, entry.Receipt, p.config.ProcessTimeout)
+	if errors.Is(err, queue.ErrLeaseLost) || errors.Is(err, queue.ErrInvalidReceipt) {
+		p.logger.Info("Stuck job was already delivered again",
+			zap.String("worker", heartbeat.Worker),
+			zap.String("job_id", job.ID.String()))
+		metrics.StuckJobsRecovered.WithLabelValues(recoveryRedelivered).Inc()
+		return nil
+	}
+	if err != nil && !errors.Is(err, queue.ErrNotSupported) {
+		return fmt.Errorf("failed to extend lease: %w", err)
+	}
+
+	reason := fmt.Errorf("worker %s stopped heartbeating at %s", heartbeat.Worker, heartbeat.BeatAt.Format(time.RFC3339))
+	settler.handleJobFailure(ctx, job, entry.Receipt, reason)
+
+	outcome := recoveryRequeued
+	switch job.Status {
+	case models.JobStatusFailed:
+		outcome = recoveryFailed
+	case models.JobStatusCancelled:
+		outcome = recoveryCancelled
+	}
+
+	p.logger.Warn("Recovered stuck job",
+		zap.String("worker", heartbeat.Worker),
+		zap.String("job_id", job.ID.String()),
+		zap.String("template", job.TemplateName),
+		zap.String("outcome", outcome),
+		zap.Int("retry_count", job.RetryCount))
+	metrics.StuckJobsRecovered.WithLabelValues(outcome).Inc()
+	return nil
+}
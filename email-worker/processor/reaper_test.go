@@ -0,0 +1,171 @@
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/queue"
+	"booking-system/email-worker/repositories"
+	"booking-system/email-worker/services"
+	"booking-system/email-worker/templates"
+)
+
+// reaperTest holds a processor that is not started and the memory stores it
+// runs on
+type reaperTest struct {
+	processor   *Processor
+	queue       *queue.MemoryQueue
+	deadLetters *queue.MemoryDeadLetterStore
+	heartbeats  *queue.MemoryHeartbeatStore
+	jobs        *repositories.MemoryEmailJobRepository
+}
+
+// newReaperTest creates a processor that is not started, over memory stores
+// with a lease of visibilityTimeout
+func newReaperTest(t *testing.T, visibilityTimeout time.Duration) *reaperTest {
+	t.Helper()
+
+	memoryQueue := queue.NewMemoryQueue(visibilityTimeout, zap.NewNop())
+	deadLetters := queue.NewMemoryDeadLetterStore()
+	heartbeats := queue.NewMemoryHeartbeatStore()
+	jobs := repositories.NewMemoryEmailJobRepository()
+	provider := &delayProvider{delay: func(int) time.Duration { return 0 }}
+	emailService := services.NewEmailService(jobs, nil, provider, templates.NewEngine())
+	p := NewProcessor(memoryQueue, deadLetters, queue.NewMemoryIdempotencyStore(), queue.NewMemoryLeaderElector(), heartbeats, emailService, &ProcessorConfig{
+		WorkerCount:    1,
+		BatchSize:      10,
+		PollInterval:   time.Millisecond,
+		MaxRetries:     3,
+		RetryDelay:     time.Millisecond,
+		ProcessTimeout: time.Second,
+		Heartbeat:      HeartbeatConfig{Timeout: time.Minute},
+	}, zap.NewNop())
+	return &reaperTest{processor: p, queue: memoryQueue, deadLetters: deadLetters, heartbeats: heartbeats, jobs: jobs}
+}
+
+// leaseWithDeadWorker stores and publishes job, leases it and leaves the
+// heartbeat of a worker that died while sending it
+func (r *reaperTest) leaseWithDeadWorker(t *testing.T, job *models.EmailJob) {
+	t.Helper()
+	ctx := context.Background()
+
+	require.NoError(t, r.processor.PublishJob(ctx, job))
+	leased, err := r.queue.ConsumeBatch(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, leased, 1)
+
+	heartbeat := queue.NewHeartbeat("replica-a/1", leased)
+	heartbeat.BeatAt = heartbeat.BeatAt.Add(-2 * time.Minute)
+	require.NoError(t, r.heartbeats.Beat(ctx, heartbeat))
+}
+
+// storedJob returns the stored state of a job
+func (r *reaperTest) storedJob(t *testing.T, job *models.EmailJob) *models.EmailJob {
+	t.Helper()
+	stored, err := r.jobs.GetByID(context.Background(), job.ID)
+	require.NoError(t, err)
+	return stored
+}
+
+func TestWorker_HeartbeatsJobsBeingSent(t *testing.T) {
+	provider := &delayProvider{delay: func(int) time.Duration { return 200 * time.Millisecond }}
+	workers, _ := newPipelineWorkers(t, provider, 1, 10, 1, time.Second)
+	heartbeats := queue.NewMemoryHeartbeatStore()
+	workers[0].identity = "replica-a/1"
+	workers[0].heartbeats = heartbeats
+	workers[0].config.HeartbeatInterval = 10 * time.Millisecond
+
+	workers[0].Start()
+	assert.Eventually(t, func() bool {
+		beats, err := heartbeats.Expired(context.Background(), time.Now().Add(time.Second))
+		require.NoError(t, err)
+		return len(beats) == 1 && len(beats[0].Jobs) == 1 && beats[0].Jobs[0].Receipt != ""
+	}, time.Second, 5*time.Millisecond)
+
+	// A worker that stops cleanly leaves no heartbeat behind
+	workers[0].Stop()
+	beats, err := heartbeats.Expired(context.Background(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Empty(t, beats)
+}
+
+func TestProcessor_ReapRequeuesJobsOfDeadWorkers(t *testing.T) {
+	r := newReaperTest(t, time.Minute)
+	job := models.NewEmailJob([]string{"test@example.com"}, nil, nil, "email_verification", nil, models.JobPriorityNormal)
+	r.leaseWithDeadWorker(t, job)
+
+	assert.Equal(t, 1, r.processor.reapStuckJobs(context.Background()))
+
+	stored := r.storedJob(t, job)
+	assert.True(t, stored.IsRetrying())
+	assert.Equal(t, 1, stored.RetryCount)
+	assert.Contains(t, stored.ErrorMessage, "worker replica-a/1 stopped heartbeating")
+
+	// The job comes back with the attempt counted, the heartbeat is gone
+	var requeued []*models.EmailJob
+	require.Eventually(t, func() bool {
+		var err error
+		requeued, err = r.queue.ConsumeBatch(context.Background(), 10)
+		require.NoError(t, err)
+		return len(requeued) > 0
+	}, time.Second, 5*time.Millisecond)
+	require.Len(t, requeued, 1)
+	assert.Equal(t, job.ID, requeued[0].ID)
+	assert.Equal(t, 1, requeued[0].RetryCount)
+	assert.Contains(t, requeued[0].ErrorMessage, "worker replica-a/1 stopped heartbeating")
+
+	beats, err := r.heartbeats.Expired(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, beats)
+}
+
+func TestProcessor_ReapFailsJobsWithoutRetries(t *testing.T) {
+	r := newReaperTest(t, time.Minute)
+	job := models.NewEmailJob([]string{"test@example.com"}, nil, nil, "email_verification", nil, models.JobPriorityNormal)
+	job.SetMaxRetries(0)
+	r.leaseWithDeadWorker(t, job)
+
+	assert.Equal(t, 1, r.processor.reapStuckJobs(context.Background()))
+
+	// The stored job is failed with the dead worker as the reason
+	stored := r.storedJob(t, job)
+	assert.Equal(t, models.JobStatusFailed, stored.Status)
+	assert.Contains(t, stored.ErrorMessage, "worker replica-a/1 stopped heartbeating")
+	require.Len(t, stored.Attempts, 1)
+	assert.Equal(t, stored.ErrorMessage, stored.Attempts[0].Error)
+
+	entry, err := r.deadLetters.Get(context.Background(), job.ID.String())
+	require.NoError(t, err)
+	assert.Contains(t, entry.FinalError, "stopped heartbeating")
+	size, err := r.queue.Size(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, size)
+}
+
+func TestProcessor_ReapLeavesRedeliveredJobs(t *testing.T) {
+	r := newReaperTest(t, 20*time.Millisecond)
+	job := models.NewEmailJob([]string{"test@example.com"}, nil, nil, "email_verification", nil, models.JobPriorityNormal)
+	r.leaseWithDeadWorker(t, job)
+
+	// The lease expires and another worker takes the job
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, r.queue.ProcessScheduledJobs(context.Background()))
+	redelivered, err := r.queue.ConsumeBatch(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, redelivered, 1)
+
+	assert.Equal(t, 1, r.processor.reapStuckJobs(context.Background()))
+	require.NoError(t, r.queue.Ack(context.Background(), redelivered[0].Receipt))
+
+	// Nothing was queued a second time
+	time.Sleep(10 * time.Millisecond)
+	size, err := r.queue.Size(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, size)
+}
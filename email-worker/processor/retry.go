@@ -0,0 +1,307 @@
+package processor
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"booking-system/email-worker/metrics"
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/providers"
+)
+
+// RetryPolicy configures how failed jobs are retried. Policy fields that are
+// not set fall back to the default policy.
+type RetryPolicy struct {
+	// MaxRetries replaces the retry limit of the job when positive
+	MaxRetries int `mapstructure:"max_retries"`
+	// BaseDelay is doubled for every retry
+	BaseDelay time.Duration `mapstructure:"base_delay"`
+	// MaxDelay caps the backoff before jitter is applied
+	MaxDelay time.Duration `mapstructure:"max_delay"`
+	// Jitter moves every backoff randomly by up to this fraction, so jobs
+	// that failed together are not retried together
+	Jitter float64 `mapstructure:"jitter"`
+	// ThrottleDelay is the least a throttled job waits when the provider did
+	// not send a Retry-After
+	ThrottleDelay time.Duration `mapstructure:"throttle_delay"`
+}
+
+// RetryBudget limits the retries of a replica to a share of its sends within
+// a sliding window, so a provider outage does not turn into a retry storm
+// once the provider is back
+type RetryBudget struct {
+	// Ratio is the most retries per send, zero disables the budget
+	Ratio float64 `mapstructure:"ratio"`
+	// MinRetries are allowed per window whatever the ratio, so a quiet
+	// replica can still retry
+	MinRetries int           `mapstructure:"min_retries"`
+	Window     time.Duration `mapstructure:"window"`
+}
+
+// RetryConfig holds retry configuration. The policy of a job is the default
+// policy, overridden by the policy of its priority, overridden by the policy
+// of its template. Priorities are named urgent, high, normal and low.
+type RetryConfig struct {
+	Default    RetryPolicy            `mapstructure:"default"`
+	Templates  map[string]RetryPolicy `mapstructure:"templates"`
+	Priorities map[string]RetryPolicy `mapstructure:"priorities"`
+	Budget     RetryBudget            `mapstructure:"budget"`
+}
+
+// Default retry settings, used when none are configured
+const (
+	defaultMaxRetryDelay     = time.Hour
+	defaultRetryBudgetWindow = time.Minute
+)
+
+// Outcomes of a retry decision, the label of the retry metric
+const (
+	retryOutcomeRetried   = "retried"
+	retryOutcomePermanent = "permanent"
+	retryOutcomeExhausted = "exhausted"
+	retryOutcomeBudget    = "budget_exhausted"
+	retryOutcomeDeferred  = "deferred"
+)
+
+// withDefaults fills in the retry settings that are not configured. The
+// default backoff starts at retryDelay. Policy names are matched
+// case-insensitively, as configuration keys are lowercased when loaded.
+func (c RetryConfig) withDefaults(retryDelay time.Duration) RetryConfig {
+	if c.Default.BaseDelay <= 0 {
+		c.Default.BaseDelay = retryDelay
+	}
+	if c.Default.MaxDelay <= 0 {
+		c.Default.MaxDelay = defaultMaxRetryDelay
+	}
+	if c.Budget.Window <= 0 {
+		c.Budget.Window = defaultRetryBudgetWindow
+	}
+	c.Templates = lowerKeys(c.Templates)
+	c.Priorities = lowerKeys(c.Priorities)
+	return c
+}
+
+// lowerKeys returns policies keyed by their lowercased names
+func lowerKeys(policies map[string]RetryPolicy) map[string]RetryPolicy {
+	lowered := make(map[string]RetryPolicy, len(policies))
+	for name, policy := range policies {
+		lowered[strings.ToLower(name)] = policy
+	}
+	return lowered
+}
+
+// merge returns the policy with the fields set in override replacing its own
+func (p RetryPolicy) merge(override RetryPolicy) RetryPolicy {
+	if override.MaxRetries > 0 {
+		p.MaxRetries = override.MaxRetries
+	}
+	if override.BaseDelay > 0 {
+		p.BaseDelay = override.BaseDelay
+	}
+	if override.MaxDelay > 0 {
+		p.MaxDelay = override.MaxDelay
+	}
+	if override.Jitter > 0 {
+		p.Jitter = override.Jitter
+	}
+	if override.ThrottleDelay > 0 {
+		p.ThrottleDelay = override.ThrottleDelay
+	}
+	return p
+}
+
+// backoff returns the delay before retry number attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		spread := p.Jitter * (2*rand.Float64() - 1)
+		delay = time.Duration(float64(delay) * (1 + spread))
+	}
+	return delay
+}
+
+// retryDecision is what happens to a failed job
+type retryDecision struct {
+	Outcome string
+	Class   providers.ErrorClass
+	// Delay is how long a retried job waits
+	Delay time.Duration
+}
+
+// retry reports whether the job is retried
+func (d retryDecision) retry() bool {
+	return d.Outcome == retryOutcomeRetried
+}
+
+// deferred reports whether the job waits for a provider without spending a
+// retry
+func (d retryDecision) deferred() bool {
+	return d.Outcome == retryOutcomeDeferred
+}
+
+// reason describes why a job is not retried
+func (d retryDecision) reason() string {
+	switch d.Outcome {
+	case retryOutcomePermanent:
+		return "permanent error"
+	case retryOutcomeBudget:
+		return "retry budget exhausted"
+	default:
+		return "max retries reached"
+	}
+}
+
+// retryEngine decides how failed jobs are retried. It is shared by the
+// workers of a processor, so they spend one retry budget.
+type retryEngine struct {
+	config RetryConfig
+	budget *retryBudget
+}
+
+// newRetryEngine creates a retry engine, config must have its defaults
+func newRetryEngine(config RetryConfig) *retryEngine {
+	return &retryEngine{
+		config: config,
+		budget: newRetryBudget(config.Budget),
+	}
+}
+
+// policy returns the retry policy of a job
+func (e *retryEngine) policy(job *models.EmailJob) RetryPolicy {
+	policy := e.config.Default
+	if override, ok := e.config.Priorities[job.Priority.String()]; ok {
+		policy = policy.merge(override)
+	}
+	if override, ok := e.config.Templates[strings.ToLower(job.TemplateName)]; ok {
+		policy = policy.merge(override)
+	}
+	return policy
+}
+
+// recordSend counts a send towards the retry budget
+func (e *retryEngine) recordSend() {
+	e.budget.recordSend(time.Now())
+}
+
+// decide classifies the error a job failed with and decides whether and when
+// it is retried. Permanent errors are never retried, and throttled jobs wait
+// at least as long as the provider asked. Jobs that were not sent because the
+// circuit breakers of their providers are open, or they were over a rate
+// limit, are deferred until the providers accept sends again.
+func (e *retryEngine) decide(job *models.EmailJob, err error) retryDecision {
+	class, retryAfter := providers.Classify(err)
+	policy := e.policy(job)
+
+	maxRetries := job.MaxRetries
+	if policy.MaxRetries > 0 {
+		maxRetries = policy.MaxRetries
+	}
+
+	decision := retryDecision{Class: class}
+	switch {
+	case providers.IsDeferred(err):
+		decision.Outcome = retryOutcomeDeferred
+		decision.Delay = retryAfter
+		if decision.Delay <= 0 {
+			decision.Delay = policy.backoff(0)
+		}
+	case class == providers.ErrorClassPermanent:
+		decision.Outcome = retryOutcomePermanent
+	case job.RetryCount >= maxRetries:
+		decision.Outcome = retryOutcomeExhausted
+	case !e.budget.spend(time.Now()):
+		decision.Outcome = retryOutcomeBudget
+	default:
+		decision.Outcome = retryOutcomeRetried
+		decision.Delay = policy.backoff(job.RetryCount + 1)
+		if class == providers.ErrorClassThrottled {
+			wait := retryAfter
+			if wait <= 0 {
+				wait = policy.ThrottleDelay
+			}
+			if wait > decision.Delay {
+				decision.Delay = wait
+			}
+		}
+	}
+
+	metrics.RetryDecisions.WithLabelValues(string(class), decision.Outcome).Inc()
+	return decision
+}
+
+// retryBudget counts the sends and retries of a replica over a sliding
+// window, approximated from the counts of the current and previous window
+type retryBudget struct {
+	config RetryBudget
+
+	mu          sync.Mutex
+	windowStart time.Time
+	sends       int
+	retries     int
+	lastSends   int
+	lastRetries int
+}
+
+// newRetryBudget creates a retry budget
+func newRetryBudget(config RetryBudget) *retryBudget {
+	return &retryBudget{config: config}
+}
+
+// advance moves the window forward to now, b.mu must be held
+func (b *retryBudget) advance(now time.Time) {
+	elapsed := now.Sub(b.windowStart)
+	switch {
+	case elapsed >= 2*b.config.Window:
+		b.windowStart = now
+		b.lastSends, b.lastRetries = 0, 0
+		b.sends, b.retries = 0, 0
+	case elapsed >= b.config.Window:
+		b.windowStart = b.windowStart.Add(b.config.Window)
+		b.lastSends, b.lastRetries = b.sends, b.retries
+		b.sends, b.retries = 0, 0
+	}
+}
+
+// recordSend counts a send
+func (b *retryBudget) recordSend(now time.Time) {
+	if b.config.Ratio <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(now)
+	b.sends++
+}
+
+// spend takes a retry from the budget, it reports false once the budget is
+// spent
+func (b *retryBudget) spend(now time.Time) bool {
+	if b.config.Ratio <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(now)
+	weight := 1 - float64(now.Sub(b.windowStart))/float64(b.config.Window)
+	sends := float64(b.sends) + float64(b.lastSends)*weight
+	retries := float64(b.retries) + float64(b.lastRetries)*weight
+
+	if retries >= float64(b.config.MinRetries) && retries+1 > b.config.Ratio*sends {
+		return false
+	}
+	b.retries++
+	return true
+}
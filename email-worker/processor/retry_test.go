@@ -0,0 +1,326 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/providers"
+	"booking-system/email-worker/queue"
+	"booking-system/email-worker/repositories"
+	"booking-system/email-worker/services"
+	"booking-system/email-worker/templates"
+)
+
+// failingProvider is a provider whose sends all fail with err
+type failingProvider struct {
+	err error
+}
+
+func (p *failingProvider) Name() string                     { return "failing" }
+func (p *failingProvider) Validate() error                  { return nil }
+func (p *failingProvider) Health(ctx context.Context) error { return nil }
+func (p *failingProvider) Close() error                     { return nil }
+
+func (p *failingProvider) Send(ctx context.Context, req *providers.EmailRequest) (*providers.EmailResponse, error) {
+	return nil, p.err
+}
+
+func (p *failingProvider) SendBatch(ctx context.Context, batch *providers.BatchRequest) ([]providers.BatchResult, error) {
+	return providers.SendEach(ctx, p, batch)
+}
+
+// recipientProvider is a provider whose sends fail with the error of their
+// first recipient, if it has one, and which records who it sent to
+type recipientProvider struct {
+	errs map[string]error
+
+	mu   sync.Mutex
+	sent []string
+}
+
+func (p *recipientProvider) Name() string                     { return "recipient" }
+func (p *recipientProvider) Validate() error                  { return nil }
+func (p *recipientProvider) Health(ctx context.Context) error { return nil }
+func (p *recipientProvider) Close() error                     { return nil }
+
+func (p *recipientProvider) Send(ctx context.Context, req *providers.EmailRequest) (*providers.EmailResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, req.To[0])
+	if err := p.errs[req.To[0]]; err != nil {
+		return nil, err
+	}
+	return &providers.EmailResponse{MessageID: req.To[0], Status: "sent", Provider: p.Name()}, nil
+}
+
+func (p *recipientProvider) SendBatch(ctx context.Context, batch *providers.BatchRequest) ([]providers.BatchResult, error) {
+	return providers.SendEach(ctx, p, batch)
+}
+
+// sendOnce publishes a job, sends it through a worker whose provider fails
+// with err and returns the job and the dead letters of the worker
+func sendOnce(t *testing.T, err error) (*models.EmailJob, *queue.MemoryDeadLetterStore) {
+	t.Helper()
+	return sendOnceThrough(t, &failingProvider{err: err})
+}
+
+// sendOnceThrough publishes a job and sends it through a worker with provider
+func sendOnceThrough(t *testing.T, provider providers.Provider) (*models.EmailJob, *queue.MemoryDeadLetterStore) {
+	t.Helper()
+	job := models.NewEmailJob([]string{"test@example.com"}, nil, nil, "email_verification", nil, models.JobPriorityNormal)
+	return sendJobThrough(t, provider, job)
+}
+
+// sendJobThrough publishes job and sends it through a worker with provider
+func sendJobThrough(t *testing.T, provider providers.Provider, job *models.EmailJob) (*models.EmailJob, *queue.MemoryDeadLetterStore) {
+	t.Helper()
+	leased, deadLetters, _ := sendStoredJobThrough(t, provider, job)
+	return leased, deadLetters
+}
+
+// sendStoredJobThrough stores and publishes job and sends it through a worker
+// with provider. It returns the job repository along with the dead letters.
+func sendStoredJobThrough(t *testing.T, provider providers.Provider, job *models.EmailJob) (*models.EmailJob, *queue.MemoryDeadLetterStore, *repositories.MemoryEmailJobRepository) {
+	t.Helper()
+	ctx := context.Background()
+
+	memoryQueue := queue.NewMemoryQueue(time.Minute, zap.NewNop())
+	deadLetters := queue.NewMemoryDeadLetterStore()
+	jobs := repositories.NewMemoryEmailJobRepository()
+	require.NoError(t, jobs.Create(ctx, job))
+	emailService := services.NewEmailService(jobs, nil, provider, templates.NewEngine())
+	worker := NewWorker(1, memoryQueue, deadLetters, emailService, &WorkerConfig{
+		BatchSize:      1,
+		RetryDelay:     time.Second,
+		ProcessTimeout: time.Second,
+	}, zap.NewNop())
+
+	require.NoError(t, memoryQueue.Publish(ctx, job))
+	leased, consumeErr := memoryQueue.ConsumeBatch(ctx, 1)
+	require.NoError(t, consumeErr)
+	require.Len(t, leased, 1)
+
+	worker.processJob(ctx, leased[0])
+	return leased[0], deadLetters, jobs
+}
+
+func TestWorker_RecordsOutcomeOnStoredJob(t *testing.T) {
+	ctx := context.Background()
+
+	sent := models.NewEmailJob([]string{"test@example.com"}, nil, nil, "email_verification", nil, models.JobPriorityNormal)
+	_, _, jobs := sendStoredJobThrough(t, &recipientProvider{}, sent)
+	stored, err := jobs.GetByID(ctx, sent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusCompleted, stored.Status)
+	assert.NotNil(t, stored.SentAt)
+
+	// A job failing permanently is recorded failed with its reason
+	failed := models.NewEmailJob([]string{"test@example.com"}, nil, nil, "email_verification", nil, models.JobPriorityNormal)
+	_, deadLetters, jobs := sendStoredJobThrough(t, &failingProvider{err: providers.NewHTTPSendError(400, "", "invalid email address")}, failed)
+	stored, err = jobs.GetByID(ctx, failed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusFailed, stored.Status)
+	assert.Contains(t, stored.ErrorMessage, "invalid email address")
+	assert.Len(t, stored.Attempts, 1)
+	assert.Nil(t, stored.SentAt)
+
+	_, err = deadLetters.Get(ctx, failed.ID.String())
+	assert.NoError(t, err)
+}
+
+func TestRetryEngine_PolicyPrecedence(t *testing.T) {
+	engine := newRetryEngine(RetryConfig{
+		Default: RetryPolicy{Jitter: 0.1},
+		Priorities: map[string]RetryPolicy{
+			"urgent": {BaseDelay: time.Second, MaxRetries: 5},
+		},
+		Templates: map[string]RetryPolicy{
+			"Password_Reset": {MaxRetries: 8},
+		},
+	}.withDefaults(5 * time.Second))
+
+	job := models.NewEmailJob([]string{"test@example.com"}, nil, nil, "newsletter", nil, models.JobPriorityLow)
+	assert.Equal(t, RetryPolicy{BaseDelay: 5 * time.Second, MaxDelay: time.Hour, Jitter: 0.1}, engine.policy(job))
+
+	job = models.NewEmailJob([]string{"test@example.com"}, nil, nil, "password_reset", nil, models.JobPriorityUrgent)
+	assert.Equal(t, RetryPolicy{MaxRetries: 8, BaseDelay: time.Second, MaxDelay: time.Hour, Jitter: 0.1}, engine.policy(job))
+}
+
+func TestRetryPolicy_BackoffWithJitter(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}
+	assert.Equal(t, 2*time.Second, policy.backoff(1))
+	assert.Equal(t, 8*time.Second, policy.backoff(3))
+	assert.Equal(t, time.Minute, policy.backoff(40))
+
+	policy.Jitter = 0.5
+	spread := map[time.Duration]bool{}
+	for i := 0; i < 50; i++ {
+		delay := policy.backoff(3)
+		assert.GreaterOrEqual(t, delay, 4*time.Second)
+		assert.LessOrEqual(t, delay, 12*time.Second)
+		spread[delay] = true
+	}
+	assert.Greater(t, len(spread), 1, "jitter should spread retries")
+}
+
+func TestRetryEngine_ClassifiesErrors(t *testing.T) {
+	engine := newRetryEngine(RetryConfig{Default: RetryPolicy{ThrottleDelay: time.Minute}}.withDefaults(time.Second))
+	job := models.NewEmailJob([]string{"test@example.com"}, nil, nil, "email_verification", nil, models.JobPriorityNormal)
+
+	decision := engine.decide(job, providers.NewHTTPSendError(400, "", "invalid email"))
+	assert.False(t, decision.retry())
+	assert.Equal(t, retryOutcomePermanent, decision.Outcome)
+
+	decision = engine.decide(job, errors.New("connection reset"))
+	assert.True(t, decision.retry())
+	assert.Equal(t, providers.ErrorClassTransient, decision.Class)
+	assert.Equal(t, 2*time.Second, decision.Delay)
+
+	// Throttled jobs wait for Retry-After, or the throttle delay without one
+	decision = engine.decide(job, providers.NewHTTPSendError(429, "90", "slow down"))
+	assert.Equal(t, providers.ErrorClassThrottled, decision.Class)
+	assert.Equal(t, 90*time.Second, decision.Delay)
+	decision = engine.decide(job, providers.NewHTTPSendError(429, "", "slow down"))
+	assert.Equal(t, time.Minute, decision.Delay)
+
+	// Sends over a rate limit wait for a token without spending a retry
+	decision = engine.decide(job, &providers.ProviderError{
+		Message:    providers.ErrRateLimited.Message,
+		Class:      providers.ErrorClassThrottled,
+		RetryAfter: 200 * time.Millisecond,
+	})
+	assert.True(t, decision.deferred())
+	assert.Equal(t, 200*time.Millisecond, decision.Delay)
+
+	job.RetryCount = job.MaxRetries
+	decision = engine.decide(job, errors.New("connection reset"))
+	assert.Equal(t, retryOutcomeExhausted, decision.Outcome)
+}
+
+func TestRetryBudget_LimitsRetriesToShareOfSends(t *testing.T) {
+	budget := newRetryBudget(RetryBudget{Ratio: 0.5, MinRetries: 2, Window: time.Minute})
+	now := time.Now()
+
+	// The minimum is allowed without any sends
+	assert.True(t, budget.spend(now))
+	assert.True(t, budget.spend(now))
+	assert.False(t, budget.spend(now))
+
+	for i := 0; i < 4; i++ {
+		budget.recordSend(now)
+	}
+	assert.False(t, budget.spend(now), "2 retries for 4 sends is the whole budget")
+	for i := 0; i < 4; i++ {
+		budget.recordSend(now)
+	}
+	assert.True(t, budget.spend(now))
+	assert.True(t, budget.spend(now))
+	assert.False(t, budget.spend(now))
+
+	// Half a window later the previous window counts for half, 4 sends and
+	// 2 retries
+	later := now.Add(90 * time.Second)
+	assert.False(t, budget.spend(later))
+	budget.recordSend(later)
+	budget.recordSend(later)
+	assert.True(t, budget.spend(later))
+
+	// Two windows later the budget starts over
+	assert.True(t, budget.spend(now.Add(3*time.Minute)))
+}
+
+func TestWorker_DeadLettersPermanentErrors(t *testing.T) {
+	job, deadLetters := sendOnce(t, providers.NewHTTPSendError(400, "", "invalid email address"))
+
+	assert.Equal(t, models.JobStatusFailed, job.Status)
+	assert.Zero(t, job.RetryCount)
+	entry, err := deadLetters.Get(context.Background(), job.ID.String())
+	require.NoError(t, err)
+	assert.Contains(t, entry.FinalError, "permanent error")
+	assert.Contains(t, entry.FinalError, "invalid email address")
+}
+
+func TestWorker_DefersJobsWhileCircuitIsOpen(t *testing.T) {
+	before := time.Now()
+	circuitOpen := &providers.ProviderError{
+		Message:    providers.ErrCircuitOpen.Message,
+		Class:      providers.ErrorClassThrottled,
+		RetryAfter: 30 * time.Second,
+	}
+	job, deadLetters := sendOnce(t, circuitOpen)
+
+	assert.Zero(t, job.RetryCount, "a deferred job does not spend a retry")
+	assert.Empty(t, job.Attempts)
+	assert.Equal(t, models.JobStatusPending, job.Status)
+	require.NotNil(t, job.NextAttemptAt)
+	assert.WithinDuration(t, before.Add(30*time.Second), *job.NextAttemptAt, 5*time.Second)
+	_, err := deadLetters.Get(context.Background(), job.ID.String())
+	assert.Error(t, err)
+}
+
+func TestWorker_RetriesThrottledJobsAfterRetryAfter(t *testing.T) {
+	before := time.Now()
+	job, deadLetters := sendOnce(t, providers.NewHTTPSendError(429, "120", "too many requests"))
+
+	assert.Equal(t, 1, job.RetryCount)
+	require.NotNil(t, job.NextAttemptAt)
+	assert.WithinDuration(t, before.Add(2*time.Minute), *job.NextAttemptAt, 5*time.Second)
+	_, err := deadLetters.Get(context.Background(), job.ID.String())
+	assert.Error(t, err)
+}
+
+func TestWorker_FailsJobsWithoutProvider(t *testing.T) {
+	job, _ := sendOnceThrough(t, nil)
+
+	assert.NotEqual(t, models.JobStatusCompleted, job.Status, "a job that was not sent must not complete")
+	assert.Nil(t, job.SentAt)
+	assert.Equal(t, 1, job.RetryCount)
+	assert.Contains(t, job.ErrorMessage, services.ErrNoProvider.Error())
+}
+
+func TestWorker_SendsBatchJobs(t *testing.T) {
+	provider := &recipientProvider{}
+	job := models.NewEmailJob(nil, nil, nil, "newsletter", nil, models.JobPriorityLow)
+	job.Recipients = models.BatchRecipients{
+		{To: []string{"a@example.com"}, Data: map[string]string{"name": "Ann"}},
+		{To: []string{"b@example.com"}},
+	}
+
+	job, deadLetters := sendJobThrough(t, provider, job)
+
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, provider.sent)
+	assert.NotNil(t, job.SentAt)
+	assert.Equal(t, "recipient", job.Provider)
+	assert.Equal(t, 0, job.RetryCount)
+	_, err := deadLetters.Get(context.Background(), job.ID.String())
+	assert.Error(t, err)
+}
+
+func TestWorker_RetriesFailedBatchRecipients(t *testing.T) {
+	provider := &recipientProvider{errs: map[string]error{
+		"b@example.com": providers.NewHTTPSendError(503, "", "unavailable"),
+		"c@example.com": providers.NewHTTPSendError(400, "", "invalid email"),
+	}}
+	job := models.NewEmailJob(nil, nil, nil, "newsletter", nil, models.JobPriorityLow)
+	job.Recipients = models.BatchRecipients{
+		{To: []string{"a@example.com"}},
+		{To: []string{"b@example.com"}},
+		{To: []string{"c@example.com"}},
+	}
+
+	job, _ = sendJobThrough(t, provider, job)
+
+	// Only the recipient that can still be sent to is retried
+	assert.Nil(t, job.SentAt)
+	assert.Equal(t, 1, job.RetryCount)
+	assert.Equal(t, models.BatchRecipients{{To: []string{"b@example.com"}}}, job.Recipients)
+	assert.Contains(t, job.ErrorMessage, "2 of 3 recipients")
+}
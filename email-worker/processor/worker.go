@@ -2,7 +2,10 @@ package processor
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
@@ -12,15 +15,56 @@ import (
 	"booking-system/email-worker/services"
 )
 
+// settleTimeout bounds how long acking or nacking a job may take
+const settleTimeout = 5 * time.Second
+
+// subscribedPollInterval is how often a subscribed worker still polls, for
+// scheduled jobs and expired leases that become ready without a signal
+const subscribedPollInterval = 5 * time.Second
+
 // Worker processes email jobs from the queue
 type Worker struct {
 	id           int
 	queue        queue.Queue
+	deadLetters  queue.DeadLetterStore
 	emailService *services.EmailService
 	logger       *zap.Logger
 	stopChan     chan struct{}
 	wg           sync.WaitGroup
 	config       *WorkerConfig
+	// lanes is nil when the worker consumes without priority lanes
+	lanes        *laneScheduler
+	// latency collects send durations for autoscaling, it may be nil
+	latency      *latencyTracker
+	// limiter bounds concurrent sends, shared with the other workers of a processor
+	limiter      *inFlightLimiter
+	inFlight     atomic.Int64
+	// sendCtx is the parent of every send, it is cancelled when a drain
+	// runs out of time
+	sendCtx      context.Context
+	cancelSends  context.CancelFunc
+	stopOnce     sync.Once
+	handedBack   HandedBackJobs
+	handedMutex  sync.Mutex
+	// identity names the worker in heartbeats across replicas
+	identity     string
+	// heartbeats is nil when the worker does not heartbeat
+	heartbeats   queue.HeartbeatStore
+	sending      map[string]*models.EmailJob
+	sendingMutex sync.Mutex
+	beatStop     chan struct{}
+	beatWG       sync.WaitGroup
+	// retries decides how failed jobs are retried
+	retries      *retryEngine
+}
+
+// HandedBackJobs lists the IDs of leased jobs a worker returned to the queue
+// when it stopped
+type HandedBackJobs struct {
+	// Released jobs were leased but never started
+	Released    []string `json:"released"`
+	// Interrupted jobs were being sent when the grace period ran out
+	Interrupted []string `json:"interrupted"`
 }
 
 // WorkerConfig holds worker configuration
@@ -30,91 +74,368 @@ type WorkerConfig struct {
 	MaxRetries    int           `mapstructure:"max_retries"`
 	RetryDelay    time.Duration `mapstructure:"retry_delay"`
 	ProcessTimeout time.Duration `mapstructure:"process_timeout"`
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"`
 }
 
 // NewWorker creates a new worker instance
-func NewWorker(id int, queue queue.Queue, emailService *services.EmailService, config *WorkerConfig, logger *zap.Logger) *Worker {
+func NewWorker(id int, queue queue.Queue, deadLetters queue.DeadLetterStore, emailService *services.EmailService, config *WorkerConfig, logger *zap.Logger) *Worker {
+	sendCtx, cancelSends := context.WithCancel(context.Background())
 	return &Worker{
 		id:           id,
 		queue:        queue,
+		deadLetters:  deadLetters,
 		emailService: emailService,
 		logger:       logger.With(zap.Int("worker_id", id)),
 		stopChan:     make(chan struct{}),
 		config:       config,
+		limiter:      newInFlightLimiter(config.BatchSize),
+		sendCtx:      sendCtx,
+		cancelSends:  cancelSends,
+		sending:      make(map[string]*models.EmailJob),
+		beatStop:     make(chan struct{}),
+		retries:      newRetryEngine(RetryConfig{}.withDefaults(config.RetryDelay)),
 	}
 }
 
 // Start starts the worker
 func (w *Worker) Start() {
 	w.logger.Info("Starting email worker")
-	w.wg.Add(1)
-	go w.run()
+	w.wg.Add(2)
+	batches := make(chan []*models.EmailJob, 1)
+	go w.fetch(batches)
+	go w.run(batches)
+
+	if w.heartbeats != nil && w.config.HeartbeatInterval > 0 {
+		w.beatWG.Add(1)
+		go w.heartbeat()
+	}
 }
 
-// Stop stops the worker
+// Stop stops the worker once the jobs in flight are finished
 func (w *Worker) Stop() {
+	w.Drain(context.Background())
+}
+
+// Drain stops the worker from leasing jobs and waits for the jobs in flight
+// until ctx is done. Sends still running then are cancelled and their jobs are
+// handed back to the queue, like the leased jobs that were never started.
+func (w *Worker) Drain(ctx context.Context) HandedBackJobs {
 	w.logger.Info("Stopping email worker")
-	close(w.stopChan)
-	w.wg.Wait()
-	w.logger.Info("Email worker stopped")
+	w.stopOnce.Do(func() { close(w.stopChan) })
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		w.logger.Warn("Grace period expired, interrupting jobs in flight",
+			zap.Int64("in_flight", w.inFlight.Load()))
+		w.cancelSends()
+		<-done
+	}
+	w.cancelSends()
+	w.stopHeartbeat()
+
+	handedBack := w.handedBackJobs()
+	w.logger.Info("Email worker stopped",
+		zap.Int("released", len(handedBack.Released)),
+		zap.Int("interrupted", len(handedBack.Interrupted)))
+	return handedBack
 }
 
-// run is the main worker loop
-func (w *Worker) run() {
+// fetch leases batches of jobs and hands them to run. Every batch takes its
+// slots of the in-flight limit before it is leased and is only as large as
+// the free slots, so leased jobs never wait for a slot while their leases
+// run out. The next batch is leased while the current one is being sent, as
+// soon as a slot is free. Once the queue is empty the
+// worker waits for a signal of the queue subscription, or polls every
+// PollInterval if the queue does not support subscriptions. Batches still
+// buffered when the worker stops are handed back.
+func (w *Worker) fetch(batches chan<- []*models.EmailJob) {
 	defer w.wg.Done()
+	defer close(batches)
 
-	ticker := time.NewTicker(w.config.PollInterval)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals, pollInterval := w.subscribe(ctx)
+	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
 
 	for {
+		slots := w.limiter.acquire(w.stopChan, w.config.BatchSize)
+		if slots == 0 {
+			return
+		}
+		jobs := w.fetchBatch(slots)
+		w.limiter.release(slots - len(jobs))
+		if len(jobs) > 0 {
+			select {
+			case batches <- jobs:
+				// Prefetch the next batch right away
+				continue
+			case <-w.stopChan:
+				w.releaseJobs(jobs)
+				return
+			}
+		}
+
 		select {
 		case <-w.stopChan:
 			return
 		case <-ticker.C:
-			w.processJobs()
+		case _, ok := <-signals:
+			if !ok {
+				w.logger.Warn("Queue subscription ended, falling back to polling")
+				signals = nil
+				ticker.Reset(w.config.PollInterval)
+			}
 		}
 	}
 }
 
-// processJobs processes jobs from the queue
-func (w *Worker) processJobs() {
+// subscribe subscribes to the queue and returns the signal channel with the
+// poll interval to use next to it. Without a subscription the channel is nil
+// and the worker polls every PollInterval.
+func (w *Worker) subscribe(ctx context.Context) (<-chan struct{}, time.Duration) {
+	signals, err := w.queue.Subscribe(ctx)
+	if err != nil {
+		if !errors.Is(err, queue.ErrNotSupported) {
+			w.logger.Error("Failed to subscribe to queue, falling back to polling", zap.Error(err))
+		}
+		return nil, w.config.PollInterval
+	}
+
+	if w.config.PollInterval > subscribedPollInterval {
+		return signals, w.config.PollInterval
+	}
+	return signals, subscribedPollInterval
+}
+
+// fetchBatch leases up to limit jobs, logging consume errors
+func (w *Worker) fetchBatch(limit int) []*models.EmailJob {
 	ctx, cancel := context.WithTimeout(context.Background(), w.config.ProcessTimeout)
 	defer cancel()
 
-	// Try to consume a batch of jobs
-	jobs, err := w.queue.ConsumeBatch(ctx, w.config.BatchSize)
+	jobs, err := w.consume(ctx, limit)
 	if err != nil {
 		if err == queue.ErrQueueEmpty {
 			// No jobs available, this is normal
-			return
+			return nil
 		}
 		w.logger.Error("Failed to consume jobs from queue", zap.Error(err))
+		return nil
+	}
+	return jobs
+}
+
+// run sends the fetched jobs in the in-flight slots fetch took for them. Each
+// job gets its own ProcessTimeout, so a slow send neither holds back nor
+// times out the rest of its batch. Jobs in flight when the worker stops are
+// finished first, batches not started yet are handed back.
+func (w *Worker) run(batches <-chan []*models.EmailJob) {
+	defer w.wg.Done()
+
+	var inFlight sync.WaitGroup
+	defer inFlight.Wait()
+
+	for jobs := range batches {
+		w.logger.Info("Processing batch of jobs", zap.Int("count", len(jobs)))
+
+		select {
+		case <-w.stopChan:
+			w.releaseJobs(jobs)
+			continue
+		default:
+		}
+
+		for _, job := range jobs {
+			inFlight.Add(1)
+			w.inFlight.Add(1)
+			w.track(job)
+			go func(j *models.EmailJob) {
+				defer inFlight.Done()
+				defer w.inFlight.Add(-1)
+				defer w.limiter.release(1)
+				defer w.untrack(j)
+
+				ctx, cancel := context.WithTimeout(w.sendCtx, w.config.ProcessTimeout)
+				defer cancel()
+				w.processJob(ctx, j)
+			}(job)
+		}
+	}
+}
+
+// track records a job as being sent for the heartbeats. The heartbeat keeps
+// a copy, the job itself is changed while it is sent.
+func (w *Worker) track(job *models.EmailJob) {
+	snapshot := *job
+	w.sendingMutex.Lock()
+	w.sending[job.ID.String()] = &snapshot
+	w.sendingMutex.Unlock()
+}
+
+// untrack removes a job that was settled from the heartbeats
+func (w *Worker) untrack(job *models.EmailJob) {
+	w.sendingMutex.Lock()
+	delete(w.sending, job.ID.String())
+	w.sendingMutex.Unlock()
+}
+
+// heartbeat records the jobs being sent every HeartbeatInterval, until the
+// jobs in flight are finished when the worker stops
+func (w *Worker) heartbeat() {
+	defer w.beatWG.Done()
+
+	ticker := time.NewTicker(w.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		w.beat()
+		select {
+		case <-w.beatStop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// beat stores a heartbeat with the jobs being sent and returns it
+func (w *Worker) beat() *queue.Heartbeat {
+	w.sendingMutex.Lock()
+	jobs := make([]*models.EmailJob, 0, len(w.sending))
+	for _, job := range w.sending {
+		jobs = append(jobs, job)
+	}
+	w.sendingMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), settleTimeout)
+	defer cancel()
+
+	heartbeat := queue.NewHeartbeat(w.identity, jobs)
+	if err := w.heartbeats.Beat(ctx, heartbeat); err != nil {
+		w.logger.Error("Failed to store heartbeat", zap.Error(err))
+	}
+	return heartbeat
+}
+
+// stopHeartbeat stops beating and removes the worker's heartbeat, so a
+// worker that stopped cleanly is not taken for dead
+func (w *Worker) stopHeartbeat() {
+	if w.heartbeats == nil || w.config.HeartbeatInterval <= 0 {
 		return
 	}
 
-	if len(jobs) == 0 {
+	select {
+	case <-w.beatStop:
 		return
+	default:
+		close(w.beatStop)
 	}
+	w.beatWG.Wait()
 
-	w.logger.Info("Processing batch of jobs", zap.Int("count", len(jobs)))
+	ctx, cancel := context.WithTimeout(context.Background(), settleTimeout)
+	defer cancel()
+	if err := w.heartbeats.Remove(ctx, w.beat()); err != nil {
+		w.logger.Error("Failed to remove heartbeat", zap.Error(err))
+	}
+}
 
-	// Process jobs concurrently
-	var wg sync.WaitGroup
+// releaseJobs hands leased jobs that were never started back to the queue
+// and frees their in-flight slots
+func (w *Worker) releaseJobs(jobs []*models.EmailJob) {
+	defer w.limiter.release(len(jobs))
 	for _, job := range jobs {
-		wg.Add(1)
-		go func(j *models.EmailJob) {
-			defer wg.Done()
-			w.processJob(ctx, j)
-		}(job)
+		w.nackJob(job, job.Receipt, 0)
+		w.recordHandedBack(job, false)
 	}
+}
 
-	wg.Wait()
+// interruptJob hands a job whose send was cancelled by a drain back to the
+// queue. Its status is reset to pending and no attempt is counted, the next
+// worker sends it again.
+func (w *Worker) interruptJob(job *models.EmailJob, receipt string) {
+	ctx, cancel := context.WithTimeout(context.Background(), settleTimeout)
+	defer cancel()
+
+	job.Status = models.JobStatusPending
+	if err := w.emailService.UpdateJobStatus(ctx, job.ID.String(), string(job.Status)); err != nil {
+		w.logger.Error("Failed to update job status to pending",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err))
+	}
+
+	w.logger.Warn("Email job interrupted by shutdown, handing it back",
+		zap.String("job_id", job.ID.String()),
+		zap.String("template", job.TemplateName),
+	)
+
+	w.nackJob(job, receipt, 0)
+	w.recordHandedBack(job, true)
+}
+
+// recordHandedBack records a job returned to the queue on stop
+func (w *Worker) recordHandedBack(job *models.EmailJob, interrupted bool) {
+	w.handedMutex.Lock()
+	defer w.handedMutex.Unlock()
+	if interrupted {
+		w.handedBack.Interrupted = append(w.handedBack.Interrupted, job.ID.String())
+	} else {
+		w.handedBack.Released = append(w.handedBack.Released, job.ID.String())
+	}
+}
+
+// handedBackJobs returns the jobs handed back so far
+func (w *Worker) handedBackJobs() HandedBackJobs {
+	w.handedMutex.Lock()
+	defer w.handedMutex.Unlock()
+	return HandedBackJobs{
+		Released:    append([]string(nil), w.handedBack.Released...),
+		Interrupted: append([]string(nil), w.handedBack.Interrupted...),
+	}
+}
+
+// consume leases the next batch of up to limit jobs, from the lanes of the worker in the
+// order its scheduler picks, or from the whole queue without lanes
+func (w *Worker) consume(ctx context.Context, limit int) ([]*models.EmailJob, error) {
+	var lanes []models.JobPriority
+	if w.lanes != nil {
+		lanes = w.lanes.next()
+	}
+	if lanes == nil {
+		return w.queue.ConsumeBatch(ctx, limit)
+	}
+
+	for _, priority := range lanes {
+		jobs, err := w.queue.ConsumeLane(ctx, priority, limit)
+		if errors.Is(err, queue.ErrNotSupported) {
+			w.logger.Warn("Queue does not support priority lanes, consuming in priority order", zap.Error(err))
+			w.lanes.disable()
+			return w.queue.ConsumeBatch(ctx, limit)
+		}
+		if err != nil || len(jobs) > 0 {
+			return jobs, err
+		}
+	}
+	return nil, nil
 }
 
-// processJob processes a single email job
+// processJob processes a single email job and settles its lease. The job is
+// only acknowledged once it has been handled, so a worker dying mid-send
+// leaves it leased until the lease expires and it is delivered again.
 func (w *Worker) processJob(ctx context.Context, job *models.EmailJob) {
 	startTime := time.Now()
+	receipt := job.Receipt
 	
+	if w.dropIfCancelled(ctx, job, receipt) {
+		return
+	}
+
 	w.logger.Info("Processing email job",
 		zap.String("job_id", job.ID.String()),
 		zap.String("template", job.TemplateName),
@@ -131,10 +452,20 @@ func (w *Worker) processJob(ctx context.Context, job *models.EmailJob) {
 	}
 
 	// Process the email
+	sendStart := time.Now()
+	w.retries.recordSend()
 	err := w.emailService.ProcessEmailJob(ctx, job)
+	if w.latency != nil {
+		w.latency.observe(time.Since(sendStart))
+	}
 	
 	processingTime := time.Since(startTime)
 
+	if err != nil && w.sendCtx.Err() != nil {
+		w.interruptJob(job, receipt)
+		return
+	}
+
 	if err != nil {
 		w.logger.Error("Failed to process email job",
 			zap.String("job_id", job.ID.String()),
@@ -144,32 +475,128 @@ func (w *Worker) processJob(ctx context.Context, job *models.EmailJob) {
 		)
 
 		// Handle retry logic
-		w.handleJobFailure(ctx, job, err)
+		w.handleJobFailure(ctx, job, receipt, err)
 		return
 	}
 
 	// Mark job as completed
 	job.MarkAsCompleted()
-	completeErr := w.emailService.UpdateJobStatus(ctx, job.ID.String(), string(job.Status))
+	completeErr := w.emailService.RecordJobOutcome(ctx, job)
 	if completeErr != nil {
 		w.logger.Error("Failed to update job status to completed", 
 			zap.String("job_id", job.ID.String()),
 			zap.Error(completeErr))
 	}
 
+	if recordErr := w.emailService.RecordDelivery(ctx, job); recordErr != nil {
+		w.logger.Error("Failed to record delivery",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(recordErr))
+	}
+
+	w.ackJob(job, receipt)
+
 	w.logger.Info("Email job processed successfully",
 		zap.String("job_id", job.ID.String()),
 		zap.String("template", job.TemplateName),
+		zap.String("provider", job.Provider),
 		zap.Duration("processing_time", processingTime),
 	)
 }
 
+// ackJob acknowledges a handled job
+func (w *Worker) ackJob(job *models.EmailJob, receipt string) {
+	// Use a fresh context, the batch context may already have timed out
+	ctx, cancel := context.WithTimeout(context.Background(), settleTimeout)
+	defer cancel()
+
+	err := w.queue.Ack(ctx, receipt)
+	if errors.Is(err, queue.ErrLeaseLost) {
+		// The lease expired and the job was handed out again, the new
+		// delivery will be processed and acknowledged on its own
+		w.logger.Warn("Lease lost before ack, job may be processed twice",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err))
+		return
+	}
+	if err != nil {
+		w.logger.Error("Failed to ack job",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err))
+	}
+}
+
+// nackJob releases a job that could not be handled, it is delivered again after requeueAfter
+func (w *Worker) nackJob(job *models.EmailJob, receipt string, requeueAfter time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), settleTimeout)
+	defer cancel()
+
+	if err := w.queue.Nack(ctx, receipt, requeueAfter); err != nil {
+		w.logger.Error("Failed to nack job",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err))
+	}
+}
+
+// dropIfCancelled acknowledges a leased job that was cancelled after it was
+// consumed, so that it is neither sent nor retried. Queues that do not track
+// cancellations are checked against the stored job. It reports whether the
+// job was dropped.
+func (w *Worker) dropIfCancelled(ctx context.Context, job *models.EmailJob, receipt string) bool {
+	cancelled, err := w.queue.Cancelled(ctx, job.ID.String())
+	if errors.Is(err, queue.ErrNotSupported) {
+		cancelled, err = w.emailService.JobCancelled(ctx, job.ID.String())
+	}
+	if err != nil {
+		w.logger.Error("Failed to check job cancellation",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err))
+		return false
+	}
+	if !cancelled {
+		return false
+	}
+
+	job.MarkAsCancelled()
+	if updateErr := w.emailService.UpdateJobStatus(ctx, job.ID.String(), string(job.Status)); updateErr != nil {
+		w.logger.Error("Failed to update job status to cancelled",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(updateErr))
+	}
+
+	w.logger.Info("Skipping cancelled email job",
+		zap.String("job_id", job.ID.String()),
+		zap.String("template", job.TemplateName),
+	)
+
+	w.ackJob(job, receipt)
+	return true
+}
+
 // handleJobFailure handles job processing failures
-func (w *Worker) handleJobFailure(ctx context.Context, job *models.EmailJob, err error) {
-	if !job.CanRetry() {
-		// Max retries reached, mark as failed
+func (w *Worker) handleJobFailure(ctx context.Context, job *models.EmailJob, receipt string, err error) {
+	// The job may have been cancelled while it was being sent
+	if w.dropIfCancelled(ctx, job, receipt) {
+		return
+	}
+
+	decision := w.retries.decide(job, err)
+	if decision.deferred() {
+		nextAttemptAt := time.Now().Add(decision.Delay)
+		w.logger.Warn("Deferring email job, its providers do not accept sends",
+			zap.String("job_id", job.ID.String()),
+			zap.String("template", job.TemplateName),
+			zap.Time("next_attempt_at", nextAttemptAt),
+			zap.Error(err),
+		)
+		w.rescheduleJob(job, receipt, nextAttemptAt, err)
+		return
+	}
+
+	job.RecordAttempt(err.Error())
+	if !decision.retry() {
 		job.MarkAsFailed()
-		updateErr := w.emailService.UpdateJobStatus(ctx, job.ID.String(), string(job.Status))
+		updateErr := w.emailService.RecordJobOutcome(ctx, job)
 		if updateErr != nil {
 			w.logger.Error("Failed to update job status to failed", 
 				zap.String("job_id", job.ID.String()),
@@ -180,59 +607,83 @@ func (w *Worker) handleJobFailure(ctx context.Context, job *models.EmailJob, err
 			zap.String("job_id", job.ID.String()),
 			zap.String("template", job.TemplateName),
 			zap.Int("retry_count", job.RetryCount),
+			zap.String("error_class", string(decision.Class)),
+			zap.String("reason", decision.reason()),
 			zap.Error(err),
 		)
+
+		if decision.Outcome != retryOutcomeExhausted {
+			err = fmt.Errorf("%s: %w", decision.reason(), err)
+		}
+		w.deadLetterJob(job, receipt, err)
 		return
 	}
 
 	// Increment retry count
 	job.IncrementRetry()
-	
-	job.MarkAsRetrying()
-	updateErr := w.emailService.UpdateJobStatus(ctx, job.ID.String(), string(job.Status))
-	if updateErr != nil {
-		w.logger.Error("Failed to update job status to retrying", 
-			zap.String("job_id", job.ID.String()),
-			zap.Error(updateErr))
-	}
 
-	// Calculate retry delay with exponential backoff
-	retryDelay := w.calculateRetryDelay(job.RetryCount)
+	retryDelay := decision.Delay
+	nextAttemptAt := time.Now().Add(retryDelay)
 
 	w.logger.Info("Scheduling job retry",
 		zap.String("job_id", job.ID.String()),
 		zap.String("template", job.TemplateName),
 		zap.Int("retry_count", job.RetryCount),
+		zap.String("error_class", string(decision.Class)),
 		zap.Duration("retry_delay", retryDelay),
+		zap.Time("next_attempt_at", nextAttemptAt),
 		zap.Error(err),
 	)
+	w.rescheduleJob(job, receipt, nextAttemptAt, err)
+}
 
-	// Schedule retry
-	go func() {
-		time.Sleep(retryDelay)
-		
-		// Re-queue the job
-		requeueErr := w.queue.Publish(context.Background(), job)
-		if requeueErr != nil {
-			w.logger.Error("Failed to requeue job for retry",
-				zap.String("job_id", job.ID.String()),
-				zap.Error(requeueErr))
-		}
-	}()
+// rescheduleJob schedules the next attempt of a failed job at nextAttemptAt
+func (w *Worker) rescheduleJob(job *models.EmailJob, receipt string, nextAttemptAt time.Time, err error) {
+	// Use a fresh context, the batch context may already have timed out
+	retryCtx, cancel := context.WithTimeout(context.Background(), settleTimeout)
+	defer cancel()
+
+	job.MarkAsRetrying(nextAttemptAt, err.Error())
+	updateErr := w.emailService.ScheduleJobRetry(retryCtx, job)
+	if updateErr != nil {
+		w.logger.Error("Failed to update job status to retrying", 
+			zap.String("job_id", job.ID.String()),
+			zap.Error(updateErr))
+	}
+
+	// The retry goes through the queue's scheduled path, so it survives a
+	// restart of this worker. The original delivery is only acknowledged once
+	// the retry is on the queue.
+	if requeueErr := w.queue.PublishScheduled(retryCtx, job, nextAttemptAt); requeueErr != nil {
+		w.logger.Error("Failed to schedule job retry",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(requeueErr))
+
+		// Hand the original delivery back for the same backoff instead
+		w.nackJob(job, receipt, time.Until(nextAttemptAt))
+		return
+	}
+
+	w.ackJob(job, receipt)
 }
 
-// calculateRetryDelay calculates the delay for retry with exponential backoff
-func (w *Worker) calculateRetryDelay(retryCount int) time.Duration {
-	// Base delay with exponential backoff
-	delay := w.config.RetryDelay * time.Duration(1<<retryCount)
-	
-	// Cap the delay at 1 hour
-	maxDelay := time.Hour
-	if delay > maxDelay {
-		delay = maxDelay
+// deadLetterJob moves a permanently failed job to the dead-letter store. The
+// delivery is only acknowledged once the dead letter is stored, otherwise it
+// is handed back so the job is not lost.
+func (w *Worker) deadLetterJob(job *models.EmailJob, receipt string, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), settleTimeout)
+	defer cancel()
+
+	if addErr := w.deadLetters.Add(ctx, queue.NewDeadLetter(job, err.Error())); addErr != nil {
+		w.logger.Error("Failed to dead-letter job",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(addErr))
+
+		w.nackJob(job, receipt, w.config.RetryDelay)
+		return
 	}
-	
-	return delay
+
+	w.ackJob(job, receipt)
 }
 
 // GetStats returns worker statistics
@@ -243,9 +694,14 @@ func (w *Worker) GetStats() map[string]any {
 		queueSize = -1
 	}
 
-	return map[string]any{
+	stats := map[string]any{
 		"worker_id":  w.id,
 		"queue_size": queueSize,
 		"status":     "running",
+		"in_flight":  w.inFlight.Load(),
+	}
+	if w.lanes != nil {
+		stats["lanes"] = w.lanes.names()
 	}
+	return stats
 } 
\ No newline at end of file
@@ -156,8 +156,17 @@ type CreateEmailJobRequest struct {
 	MaxRetries     int32                  `protobuf:"varint,12,opt,name=max_retries,json=maxRetries,proto3" json:"max_retries,omitempty"`
 	ScheduledAt    *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=scheduled_at,json=scheduledAt,proto3" json:"scheduled_at,omitempty"`
 	IsTracked      bool                   `protobuf:"varint,14,opt,name=is_tracked,json=isTracked,proto3" json:"is_tracked,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// Optional key identifying the submission. Repeating a key within the
+	// deduplication window returns the original job instead of a new one.
+	IdempotencyKey string `protobuf:"bytes,15,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Optional tenant the job is sent for, used to route it to a provider
+	Tenant string `protobuf:"bytes,16,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	// Optional recipients that make the job a batch. Each of them gets a
+	// message of their own, in which their data replace the variables of the
+	// same name; to, cc and bcc are then ignored.
+	Recipients    []*BatchRecipient `protobuf:"bytes,17,rep,name=recipients,proto3" json:"recipients,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateEmailJobRequest) Reset() {
@@ -288,20 +297,112 @@ func (x *CreateEmailJobRequest) GetIsTracked() bool {
 	return false
 }
 
-type CreateEmailJobResponse struct {
+func (x *CreateEmailJobRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *CreateEmailJobRequest) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+func (x *CreateEmailJobRequest) GetRecipients() []*BatchRecipient {
+	if x != nil {
+		return x.Recipients
+	}
+	return nil
+}
+
+// A recipient of a batch job
+type BatchRecipient struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
-	IsTracked     bool                   `protobuf:"varint,4,opt,name=is_tracked,json=isTracked,proto3" json:"is_tracked,omitempty"`
-	Job           *EmailJob              `protobuf:"bytes,5,opt,name=job,proto3" json:"job,omitempty"`
+	To            []string               `protobuf:"bytes,1,rep,name=to,proto3" json:"to,omitempty"`
+	Cc            []string               `protobuf:"bytes,2,rep,name=cc,proto3" json:"cc,omitempty"`
+	Bcc           []string               `protobuf:"bytes,3,rep,name=bcc,proto3" json:"bcc,omitempty"`
+	Data          map[string]string      `protobuf:"bytes,4,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchRecipient) Reset() {
+	*x = BatchRecipient{}
+	mi := &file_protos_email_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchRecipient) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchRecipient) ProtoMessage() {}
+
+func (x *BatchRecipient) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchRecipient.ProtoReflect.Descriptor instead.
+func (*BatchRecipient) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BatchRecipient) GetTo() []string {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *BatchRecipient) GetCc() []string {
+	if x != nil {
+		return x.Cc
+	}
+	return nil
+}
+
+func (x *BatchRecipient) GetBcc() []string {
+	if x != nil {
+		return x.Bcc
+	}
+	return nil
+}
+
+func (x *BatchRecipient) GetData() map[string]string {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type CreateEmailJobResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	JobId     string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Success   bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Message   string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	IsTracked bool                   `protobuf:"varint,4,opt,name=is_tracked,json=isTracked,proto3" json:"is_tracked,omitempty"`
+	Job       *EmailJob              `protobuf:"bytes,5,opt,name=job,proto3" json:"job,omitempty"`
+	// Set when the idempotency key was already used and job is the original
+	Duplicate     bool `protobuf:"varint,6,opt,name=duplicate,proto3" json:"duplicate,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateEmailJobResponse) Reset() {
 	*x = CreateEmailJobResponse{}
-	mi := &file_protos_email_proto_msgTypes[1]
+	mi := &file_protos_email_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -313,7 +414,7 @@ func (x *CreateEmailJobResponse) String() string {
 func (*CreateEmailJobResponse) ProtoMessage() {}
 
 func (x *CreateEmailJobResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[1]
+	mi := &file_protos_email_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -326,7 +427,7 @@ func (x *CreateEmailJobResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateEmailJobResponse.ProtoReflect.Descriptor instead.
 func (*CreateEmailJobResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{1}
+	return file_protos_email_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *CreateEmailJobResponse) GetJobId() string {
@@ -364,6 +465,13 @@ func (x *CreateEmailJobResponse) GetJob() *EmailJob {
 	return nil
 }
 
+func (x *CreateEmailJobResponse) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
 type GetEmailJobRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	JobId         int64                  `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
@@ -373,7 +481,7 @@ type GetEmailJobRequest struct {
 
 func (x *GetEmailJobRequest) Reset() {
 	*x = GetEmailJobRequest{}
-	mi := &file_protos_email_proto_msgTypes[2]
+	mi := &file_protos_email_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -385,7 +493,7 @@ func (x *GetEmailJobRequest) String() string {
 func (*GetEmailJobRequest) ProtoMessage() {}
 
 func (x *GetEmailJobRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[2]
+	mi := &file_protos_email_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -398,7 +506,7 @@ func (x *GetEmailJobRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetEmailJobRequest.ProtoReflect.Descriptor instead.
 func (*GetEmailJobRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{2}
+	return file_protos_email_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *GetEmailJobRequest) GetJobId() int64 {
@@ -419,7 +527,7 @@ type GetEmailJobResponse struct {
 
 func (x *GetEmailJobResponse) Reset() {
 	*x = GetEmailJobResponse{}
-	mi := &file_protos_email_proto_msgTypes[3]
+	mi := &file_protos_email_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -431,7 +539,7 @@ func (x *GetEmailJobResponse) String() string {
 func (*GetEmailJobResponse) ProtoMessage() {}
 
 func (x *GetEmailJobResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[3]
+	mi := &file_protos_email_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -444,7 +552,7 @@ func (x *GetEmailJobResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetEmailJobResponse.ProtoReflect.Descriptor instead.
 func (*GetEmailJobResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{3}
+	return file_protos_email_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *GetEmailJobResponse) GetSuccess() bool {
@@ -477,7 +585,7 @@ type GetJobStatusRequest struct {
 
 func (x *GetJobStatusRequest) Reset() {
 	*x = GetJobStatusRequest{}
-	mi := &file_protos_email_proto_msgTypes[4]
+	mi := &file_protos_email_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -489,7 +597,7 @@ func (x *GetJobStatusRequest) String() string {
 func (*GetJobStatusRequest) ProtoMessage() {}
 
 func (x *GetJobStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[4]
+	mi := &file_protos_email_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -502,7 +610,7 @@ func (x *GetJobStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetJobStatusRequest.ProtoReflect.Descriptor instead.
 func (*GetJobStatusRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{4}
+	return file_protos_email_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GetJobStatusRequest) GetJobId() string {
@@ -523,13 +631,14 @@ type GetJobStatusResponse struct {
 	CompletedAt   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
 	Success       bool                   `protobuf:"varint,8,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,9,opt,name=message,proto3" json:"message,omitempty"`
+	NextAttemptAt *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=next_attempt_at,json=nextAttemptAt,proto3" json:"next_attempt_at,omitempty"` // set while a retry is pending
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetJobStatusResponse) Reset() {
 	*x = GetJobStatusResponse{}
-	mi := &file_protos_email_proto_msgTypes[5]
+	mi := &file_protos_email_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -541,7 +650,7 @@ func (x *GetJobStatusResponse) String() string {
 func (*GetJobStatusResponse) ProtoMessage() {}
 
 func (x *GetJobStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[5]
+	mi := &file_protos_email_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -554,7 +663,7 @@ func (x *GetJobStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetJobStatusResponse.ProtoReflect.Descriptor instead.
 func (*GetJobStatusResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{5}
+	return file_protos_email_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *GetJobStatusResponse) GetJobId() string {
@@ -620,6 +729,13 @@ func (x *GetJobStatusResponse) GetMessage() string {
 	return ""
 }
 
+func (x *GetJobStatusResponse) GetNextAttemptAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextAttemptAt
+	}
+	return nil
+}
+
 type UpdateEmailJobStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	JobId         int64                  `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
@@ -632,7 +748,7 @@ type UpdateEmailJobStatusRequest struct {
 
 func (x *UpdateEmailJobStatusRequest) Reset() {
 	*x = UpdateEmailJobStatusRequest{}
-	mi := &file_protos_email_proto_msgTypes[6]
+	mi := &file_protos_email_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -644,7 +760,7 @@ func (x *UpdateEmailJobStatusRequest) String() string {
 func (*UpdateEmailJobStatusRequest) ProtoMessage() {}
 
 func (x *UpdateEmailJobStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[6]
+	mi := &file_protos_email_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -657,7 +773,7 @@ func (x *UpdateEmailJobStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateEmailJobStatusRequest.ProtoReflect.Descriptor instead.
 func (*UpdateEmailJobStatusRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{6}
+	return file_protos_email_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *UpdateEmailJobStatusRequest) GetJobId() int64 {
@@ -699,7 +815,7 @@ type UpdateEmailJobStatusResponse struct {
 
 func (x *UpdateEmailJobStatusResponse) Reset() {
 	*x = UpdateEmailJobStatusResponse{}
-	mi := &file_protos_email_proto_msgTypes[7]
+	mi := &file_protos_email_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -711,7 +827,7 @@ func (x *UpdateEmailJobStatusResponse) String() string {
 func (*UpdateEmailJobStatusResponse) ProtoMessage() {}
 
 func (x *UpdateEmailJobStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[7]
+	mi := &file_protos_email_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -724,7 +840,7 @@ func (x *UpdateEmailJobStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateEmailJobStatusResponse.ProtoReflect.Descriptor instead.
 func (*UpdateEmailJobStatusResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{7}
+	return file_protos_email_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *UpdateEmailJobStatusResponse) GetSuccess() bool {
@@ -748,33 +864,28 @@ func (x *UpdateEmailJobStatusResponse) GetJob() *EmailJob {
 	return nil
 }
 
-type ListEmailJobsRequest struct {
+type CancelEmailJobRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	JobType       string                 `protobuf:"bytes,2,opt,name=job_type,json=jobType,proto3" json:"job_type,omitempty"`
-	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Email         string                 `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
-	Page          int32                  `protobuf:"varint,5,opt,name=page,proto3" json:"page,omitempty"`
-	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListEmailJobsRequest) Reset() {
-	*x = ListEmailJobsRequest{}
-	mi := &file_protos_email_proto_msgTypes[8]
+func (x *CancelEmailJobRequest) Reset() {
+	*x = CancelEmailJobRequest{}
+	mi := &file_protos_email_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListEmailJobsRequest) String() string {
+func (x *CancelEmailJobRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListEmailJobsRequest) ProtoMessage() {}
+func (*CancelEmailJobRequest) ProtoMessage() {}
 
-func (x *ListEmailJobsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[8]
+func (x *CancelEmailJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -785,80 +896,41 @@ func (x *ListEmailJobsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListEmailJobsRequest.ProtoReflect.Descriptor instead.
-func (*ListEmailJobsRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{8}
-}
-
-func (x *ListEmailJobsRequest) GetStatus() string {
-	if x != nil {
-		return x.Status
-	}
-	return ""
-}
-
-func (x *ListEmailJobsRequest) GetJobType() string {
-	if x != nil {
-		return x.JobType
-	}
-	return ""
-}
-
-func (x *ListEmailJobsRequest) GetUserId() string {
-	if x != nil {
-		return x.UserId
-	}
-	return ""
+// Deprecated: Use CancelEmailJobRequest.ProtoReflect.Descriptor instead.
+func (*CancelEmailJobRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *ListEmailJobsRequest) GetEmail() string {
+func (x *CancelEmailJobRequest) GetJobId() string {
 	if x != nil {
-		return x.Email
+		return x.JobId
 	}
 	return ""
 }
 
-func (x *ListEmailJobsRequest) GetPage() int32 {
-	if x != nil {
-		return x.Page
-	}
-	return 0
-}
-
-func (x *ListEmailJobsRequest) GetLimit() int32 {
-	if x != nil {
-		return x.Limit
-	}
-	return 0
-}
-
-type ListEmailJobsResponse struct {
+type CancelEmailJobResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Jobs          []*EmailJob            `protobuf:"bytes,3,rep,name=jobs,proto3" json:"jobs,omitempty"`
-	Total         int32                  `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
-	Page          int32                  `protobuf:"varint,5,opt,name=page,proto3" json:"page,omitempty"`
-	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListEmailJobsResponse) Reset() {
-	*x = ListEmailJobsResponse{}
-	mi := &file_protos_email_proto_msgTypes[9]
+func (x *CancelEmailJobResponse) Reset() {
+	*x = CancelEmailJobResponse{}
+	mi := &file_protos_email_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListEmailJobsResponse) String() string {
+func (x *CancelEmailJobResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListEmailJobsResponse) ProtoMessage() {}
+func (*CancelEmailJobResponse) ProtoMessage() {}
 
-func (x *ListEmailJobsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[9]
+func (x *CancelEmailJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -869,75 +941,48 @@ func (x *ListEmailJobsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListEmailJobsResponse.ProtoReflect.Descriptor instead.
-func (*ListEmailJobsResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use CancelEmailJobResponse.ProtoReflect.Descriptor instead.
+func (*CancelEmailJobResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *ListEmailJobsResponse) GetSuccess() bool {
+func (x *CancelEmailJobResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *ListEmailJobsResponse) GetMessage() string {
+func (x *CancelEmailJobResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *ListEmailJobsResponse) GetJobs() []*EmailJob {
-	if x != nil {
-		return x.Jobs
-	}
-	return nil
-}
-
-func (x *ListEmailJobsResponse) GetTotal() int32 {
-	if x != nil {
-		return x.Total
-	}
-	return 0
-}
-
-func (x *ListEmailJobsResponse) GetPage() int32 {
-	if x != nil {
-		return x.Page
-	}
-	return 0
-}
-
-func (x *ListEmailJobsResponse) GetLimit() int32 {
-	if x != nil {
-		return x.Limit
-	}
-	return 0
-}
-
-type GetJobStatsRequest struct {
+type RescheduleEmailJobRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TimeRange     string                 `protobuf:"bytes,1,opt,name=time_range,json=timeRange,proto3" json:"time_range,omitempty"` // e.g., "1h", "24h", "7d", "30d"
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	ScheduledAt   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=scheduled_at,json=scheduledAt,proto3" json:"scheduled_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetJobStatsRequest) Reset() {
-	*x = GetJobStatsRequest{}
-	mi := &file_protos_email_proto_msgTypes[10]
+func (x *RescheduleEmailJobRequest) Reset() {
+	*x = RescheduleEmailJobRequest{}
+	mi := &file_protos_email_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetJobStatsRequest) String() string {
+func (x *RescheduleEmailJobRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetJobStatsRequest) ProtoMessage() {}
+func (*RescheduleEmailJobRequest) ProtoMessage() {}
 
-func (x *GetJobStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[10]
+func (x *RescheduleEmailJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -948,22 +993,293 @@ func (x *GetJobStatsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetJobStatsRequest.ProtoReflect.Descriptor instead.
-func (*GetJobStatsRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use RescheduleEmailJobRequest.ProtoReflect.Descriptor instead.
+func (*RescheduleEmailJobRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *GetJobStatsRequest) GetTimeRange() string {
+func (x *RescheduleEmailJobRequest) GetJobId() string {
 	if x != nil {
-		return x.TimeRange
+		return x.JobId
 	}
 	return ""
 }
 
-type GetJobStatsResponse struct {
-	state                 protoimpl.MessageState `protogen:"open.v1"`
-	TotalJobs             int64                  `protobuf:"varint,1,opt,name=total_jobs,json=totalJobs,proto3" json:"total_jobs,omitempty"`
-	CompletedJobs         int64                  `protobuf:"varint,2,opt,name=completed_jobs,json=completedJobs,proto3" json:"completed_jobs,omitempty"`
+func (x *RescheduleEmailJobRequest) GetScheduledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduledAt
+	}
+	return nil
+}
+
+type RescheduleEmailJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RescheduleEmailJobResponse) Reset() {
+	*x = RescheduleEmailJobResponse{}
+	mi := &file_protos_email_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RescheduleEmailJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RescheduleEmailJobResponse) ProtoMessage() {}
+
+func (x *RescheduleEmailJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RescheduleEmailJobResponse.ProtoReflect.Descriptor instead.
+func (*RescheduleEmailJobResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RescheduleEmailJobResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RescheduleEmailJobResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ListEmailJobsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	JobType       string                 `protobuf:"bytes,2,opt,name=job_type,json=jobType,proto3" json:"job_type,omitempty"`
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Email         string                 `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	Page          int32                  `protobuf:"varint,5,opt,name=page,proto3" json:"page,omitempty"`
+	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEmailJobsRequest) Reset() {
+	*x = ListEmailJobsRequest{}
+	mi := &file_protos_email_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEmailJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEmailJobsRequest) ProtoMessage() {}
+
+func (x *ListEmailJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEmailJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListEmailJobsRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListEmailJobsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListEmailJobsRequest) GetJobType() string {
+	if x != nil {
+		return x.JobType
+	}
+	return ""
+}
+
+func (x *ListEmailJobsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListEmailJobsRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *ListEmailJobsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListEmailJobsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListEmailJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Jobs          []*EmailJob            `protobuf:"bytes,3,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	Total         int32                  `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	Page          int32                  `protobuf:"varint,5,opt,name=page,proto3" json:"page,omitempty"`
+	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEmailJobsResponse) Reset() {
+	*x = ListEmailJobsResponse{}
+	mi := &file_protos_email_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEmailJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEmailJobsResponse) ProtoMessage() {}
+
+func (x *ListEmailJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEmailJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListEmailJobsResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListEmailJobsResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ListEmailJobsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ListEmailJobsResponse) GetJobs() []*EmailJob {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+func (x *ListEmailJobsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListEmailJobsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListEmailJobsResponse) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetJobStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TimeRange     string                 `protobuf:"bytes,1,opt,name=time_range,json=timeRange,proto3" json:"time_range,omitempty"` // e.g., "1h", "24h", "7d", "30d"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobStatsRequest) Reset() {
+	*x = GetJobStatsRequest{}
+	mi := &file_protos_email_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobStatsRequest) ProtoMessage() {}
+
+func (x *GetJobStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetJobStatsRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetJobStatsRequest) GetTimeRange() string {
+	if x != nil {
+		return x.TimeRange
+	}
+	return ""
+}
+
+type GetJobStatsResponse struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	TotalJobs             int64                  `protobuf:"varint,1,opt,name=total_jobs,json=totalJobs,proto3" json:"total_jobs,omitempty"`
+	CompletedJobs         int64                  `protobuf:"varint,2,opt,name=completed_jobs,json=completedJobs,proto3" json:"completed_jobs,omitempty"`
 	FailedJobs            int64                  `protobuf:"varint,3,opt,name=failed_jobs,json=failedJobs,proto3" json:"failed_jobs,omitempty"`
 	PendingJobs           int64                  `protobuf:"varint,4,opt,name=pending_jobs,json=pendingJobs,proto3" json:"pending_jobs,omitempty"`
 	RetriedJobs           int64                  `protobuf:"varint,5,opt,name=retried_jobs,json=retriedJobs,proto3" json:"retried_jobs,omitempty"`
@@ -975,21 +1291,288 @@ type GetJobStatsResponse struct {
 	sizeCache             protoimpl.SizeCache
 }
 
-func (x *GetJobStatsResponse) Reset() {
-	*x = GetJobStatsResponse{}
-	mi := &file_protos_email_proto_msgTypes[11]
+func (x *GetJobStatsResponse) Reset() {
+	*x = GetJobStatsResponse{}
+	mi := &file_protos_email_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobStatsResponse) ProtoMessage() {}
+
+func (x *GetJobStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetJobStatsResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetJobStatsResponse) GetTotalJobs() int64 {
+	if x != nil {
+		return x.TotalJobs
+	}
+	return 0
+}
+
+func (x *GetJobStatsResponse) GetCompletedJobs() int64 {
+	if x != nil {
+		return x.CompletedJobs
+	}
+	return 0
+}
+
+func (x *GetJobStatsResponse) GetFailedJobs() int64 {
+	if x != nil {
+		return x.FailedJobs
+	}
+	return 0
+}
+
+func (x *GetJobStatsResponse) GetPendingJobs() int64 {
+	if x != nil {
+		return x.PendingJobs
+	}
+	return 0
+}
+
+func (x *GetJobStatsResponse) GetRetriedJobs() int64 {
+	if x != nil {
+		return x.RetriedJobs
+	}
+	return 0
+}
+
+func (x *GetJobStatsResponse) GetSuccessRate() float64 {
+	if x != nil {
+		return x.SuccessRate
+	}
+	return 0
+}
+
+func (x *GetJobStatsResponse) GetAverageProcessingTime() float64 {
+	if x != nil {
+		return x.AverageProcessingTime
+	}
+	return 0
+}
+
+func (x *GetJobStatsResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetJobStatsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetQueueStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQueueStatsRequest) Reset() {
+	*x = GetQueueStatsRequest{}
+	mi := &file_protos_email_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQueueStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQueueStatsRequest) ProtoMessage() {}
+
+func (x *GetQueueStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQueueStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetQueueStatsRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{17}
+}
+
+type GetQueueStatsResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	QueueSize          int64                  `protobuf:"varint,1,opt,name=queue_size,json=queueSize,proto3" json:"queue_size,omitempty"`
+	ScheduledQueueSize int64                  `protobuf:"varint,2,opt,name=scheduled_queue_size,json=scheduledQueueSize,proto3" json:"scheduled_queue_size,omitempty"`
+	ActiveWorkers      int32                  `protobuf:"varint,3,opt,name=active_workers,json=activeWorkers,proto3" json:"active_workers,omitempty"`
+	Success            bool                   `protobuf:"varint,4,opt,name=success,proto3" json:"success,omitempty"`
+	Message            string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *GetQueueStatsResponse) Reset() {
+	*x = GetQueueStatsResponse{}
+	mi := &file_protos_email_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQueueStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQueueStatsResponse) ProtoMessage() {}
+
+func (x *GetQueueStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQueueStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetQueueStatsResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetQueueStatsResponse) GetQueueSize() int64 {
+	if x != nil {
+		return x.QueueSize
+	}
+	return 0
+}
+
+func (x *GetQueueStatsResponse) GetScheduledQueueSize() int64 {
+	if x != nil {
+		return x.ScheduledQueueSize
+	}
+	return 0
+}
+
+func (x *GetQueueStatsResponse) GetActiveWorkers() int32 {
+	if x != nil {
+		return x.ActiveWorkers
+	}
+	return 0
+}
+
+func (x *GetQueueStatsResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetQueueStatsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Email Templates
+type GetEmailTemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TemplateId    string                 `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEmailTemplateRequest) Reset() {
+	*x = GetEmailTemplateRequest{}
+	mi := &file_protos_email_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEmailTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEmailTemplateRequest) ProtoMessage() {}
+
+func (x *GetEmailTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEmailTemplateRequest.ProtoReflect.Descriptor instead.
+func (*GetEmailTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetEmailTemplateRequest) GetTemplateId() string {
+	if x != nil {
+		return x.TemplateId
+	}
+	return ""
+}
+
+func (x *GetEmailTemplateRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type GetEmailTemplateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Template      *EmailTemplate         `protobuf:"bytes,3,opt,name=template,proto3" json:"template,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEmailTemplateResponse) Reset() {
+	*x = GetEmailTemplateResponse{}
+	mi := &file_protos_email_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetJobStatsResponse) String() string {
+func (x *GetEmailTemplateResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetJobStatsResponse) ProtoMessage() {}
+func (*GetEmailTemplateResponse) ProtoMessage() {}
 
-func (x *GetJobStatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[11]
+func (x *GetEmailTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1000,136 +1583,301 @@ func (x *GetJobStatsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetJobStatsResponse.ProtoReflect.Descriptor instead.
-func (*GetJobStatsResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use GetEmailTemplateResponse.ProtoReflect.Descriptor instead.
+func (*GetEmailTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{20}
 }
 
-func (x *GetJobStatsResponse) GetTotalJobs() int64 {
+func (x *GetEmailTemplateResponse) GetSuccess() bool {
 	if x != nil {
-		return x.TotalJobs
+		return x.Success
 	}
-	return 0
+	return false
 }
 
-func (x *GetJobStatsResponse) GetCompletedJobs() int64 {
+func (x *GetEmailTemplateResponse) GetMessage() string {
 	if x != nil {
-		return x.CompletedJobs
+		return x.Message
 	}
-	return 0
+	return ""
 }
 
-func (x *GetJobStatsResponse) GetFailedJobs() int64 {
+func (x *GetEmailTemplateResponse) GetTemplate() *EmailTemplate {
 	if x != nil {
-		return x.FailedJobs
+		return x.Template
 	}
-	return 0
+	return nil
 }
 
-func (x *GetJobStatsResponse) GetPendingJobs() int64 {
+type ListEmailTemplatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IsActive      bool                   `protobuf:"varint,1,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEmailTemplatesRequest) Reset() {
+	*x = ListEmailTemplatesRequest{}
+	mi := &file_protos_email_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEmailTemplatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEmailTemplatesRequest) ProtoMessage() {}
+
+func (x *ListEmailTemplatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[21]
 	if x != nil {
-		return x.PendingJobs
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *GetJobStatsResponse) GetRetriedJobs() int64 {
+// Deprecated: Use ListEmailTemplatesRequest.ProtoReflect.Descriptor instead.
+func (*ListEmailTemplatesRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListEmailTemplatesRequest) GetIsActive() bool {
 	if x != nil {
-		return x.RetriedJobs
+		return x.IsActive
 	}
-	return 0
+	return false
 }
 
-func (x *GetJobStatsResponse) GetSuccessRate() float64 {
+func (x *ListEmailTemplatesRequest) GetPage() int32 {
 	if x != nil {
-		return x.SuccessRate
+		return x.Page
 	}
 	return 0
 }
 
-func (x *GetJobStatsResponse) GetAverageProcessingTime() float64 {
+func (x *ListEmailTemplatesRequest) GetLimit() int32 {
 	if x != nil {
-		return x.AverageProcessingTime
+		return x.Limit
 	}
 	return 0
 }
 
-func (x *GetJobStatsResponse) GetSuccess() bool {
+type ListEmailTemplatesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Templates     []*EmailTemplate       `protobuf:"bytes,3,rep,name=templates,proto3" json:"templates,omitempty"`
+	Total         int32                  `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEmailTemplatesResponse) Reset() {
+	*x = ListEmailTemplatesResponse{}
+	mi := &file_protos_email_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEmailTemplatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEmailTemplatesResponse) ProtoMessage() {}
+
+func (x *ListEmailTemplatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEmailTemplatesResponse.ProtoReflect.Descriptor instead.
+func (*ListEmailTemplatesResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListEmailTemplatesResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *GetJobStatsResponse) GetMessage() string {
+func (x *ListEmailTemplatesResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-type GetQueueStatsRequest struct {
+func (x *ListEmailTemplatesResponse) GetTemplates() []*EmailTemplate {
+	if x != nil {
+		return x.Templates
+	}
+	return nil
+}
+
+func (x *ListEmailTemplatesResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type CreateEmailTemplateRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Subject       string                 `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`
+	HtmlContent   string                 `protobuf:"bytes,4,opt,name=html_content,json=htmlContent,proto3" json:"html_content,omitempty"`
+	HtmlTemplate  string                 `protobuf:"bytes,5,opt,name=html_template,json=htmlTemplate,proto3" json:"html_template,omitempty"`
+	TextContent   string                 `protobuf:"bytes,6,opt,name=text_content,json=textContent,proto3" json:"text_content,omitempty"`
+	TextTemplate  string                 `protobuf:"bytes,7,opt,name=text_template,json=textTemplate,proto3" json:"text_template,omitempty"`
+	Variables     string                 `protobuf:"bytes,8,opt,name=variables,proto3" json:"variables,omitempty"` // JSON array string
+	VariablesMap  map[string]string      `protobuf:"bytes,9,rep,name=variables_map,json=variablesMap,proto3" json:"variables_map,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	IsActive      bool                   `protobuf:"varint,10,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetQueueStatsRequest) Reset() {
-	*x = GetQueueStatsRequest{}
-	mi := &file_protos_email_proto_msgTypes[12]
+func (x *CreateEmailTemplateRequest) Reset() {
+	*x = CreateEmailTemplateRequest{}
+	mi := &file_protos_email_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetQueueStatsRequest) String() string {
+func (x *CreateEmailTemplateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetQueueStatsRequest) ProtoMessage() {}
+func (*CreateEmailTemplateRequest) ProtoMessage() {}
 
-func (x *GetQueueStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[12]
+func (x *CreateEmailTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateEmailTemplateRequest.ProtoReflect.Descriptor instead.
+func (*CreateEmailTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CreateEmailTemplateRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CreateEmailTemplateRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateEmailTemplateRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *CreateEmailTemplateRequest) GetHtmlContent() string {
+	if x != nil {
+		return x.HtmlContent
+	}
+	return ""
+}
+
+func (x *CreateEmailTemplateRequest) GetHtmlTemplate() string {
+	if x != nil {
+		return x.HtmlTemplate
+	}
+	return ""
+}
+
+func (x *CreateEmailTemplateRequest) GetTextContent() string {
+	if x != nil {
+		return x.TextContent
+	}
+	return ""
+}
+
+func (x *CreateEmailTemplateRequest) GetTextTemplate() string {
+	if x != nil {
+		return x.TextTemplate
+	}
+	return ""
+}
+
+func (x *CreateEmailTemplateRequest) GetVariables() string {
+	if x != nil {
+		return x.Variables
+	}
+	return ""
+}
+
+func (x *CreateEmailTemplateRequest) GetVariablesMap() map[string]string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.VariablesMap
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use GetQueueStatsRequest.ProtoReflect.Descriptor instead.
-func (*GetQueueStatsRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{12}
+func (x *CreateEmailTemplateRequest) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
 }
 
-type GetQueueStatsResponse struct {
-	state              protoimpl.MessageState `protogen:"open.v1"`
-	QueueSize          int64                  `protobuf:"varint,1,opt,name=queue_size,json=queueSize,proto3" json:"queue_size,omitempty"`
-	ScheduledQueueSize int64                  `protobuf:"varint,2,opt,name=scheduled_queue_size,json=scheduledQueueSize,proto3" json:"scheduled_queue_size,omitempty"`
-	ActiveWorkers      int32                  `protobuf:"varint,3,opt,name=active_workers,json=activeWorkers,proto3" json:"active_workers,omitempty"`
-	Success            bool                   `protobuf:"varint,4,opt,name=success,proto3" json:"success,omitempty"`
-	Message            string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+type CreateEmailTemplateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TemplateId    string                 `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Template      *EmailTemplate         `protobuf:"bytes,4,opt,name=template,proto3" json:"template,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetQueueStatsResponse) Reset() {
-	*x = GetQueueStatsResponse{}
-	mi := &file_protos_email_proto_msgTypes[13]
+func (x *CreateEmailTemplateResponse) Reset() {
+	*x = CreateEmailTemplateResponse{}
+	mi := &file_protos_email_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetQueueStatsResponse) String() string {
+func (x *CreateEmailTemplateResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetQueueStatsResponse) ProtoMessage() {}
+func (*CreateEmailTemplateResponse) ProtoMessage() {}
 
-func (x *GetQueueStatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[13]
+func (x *CreateEmailTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1140,70 +1888,71 @@ func (x *GetQueueStatsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetQueueStatsResponse.ProtoReflect.Descriptor instead.
-func (*GetQueueStatsResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{13}
-}
-
-func (x *GetQueueStatsResponse) GetQueueSize() int64 {
-	if x != nil {
-		return x.QueueSize
-	}
-	return 0
-}
-
-func (x *GetQueueStatsResponse) GetScheduledQueueSize() int64 {
-	if x != nil {
-		return x.ScheduledQueueSize
-	}
-	return 0
+// Deprecated: Use CreateEmailTemplateResponse.ProtoReflect.Descriptor instead.
+func (*CreateEmailTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *GetQueueStatsResponse) GetActiveWorkers() int32 {
+func (x *CreateEmailTemplateResponse) GetTemplateId() string {
 	if x != nil {
-		return x.ActiveWorkers
+		return x.TemplateId
 	}
-	return 0
+	return ""
 }
 
-func (x *GetQueueStatsResponse) GetSuccess() bool {
+func (x *CreateEmailTemplateResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *GetQueueStatsResponse) GetMessage() string {
+func (x *CreateEmailTemplateResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-// Email Templates
-type GetEmailTemplateRequest struct {
+func (x *CreateEmailTemplateResponse) GetTemplate() *EmailTemplate {
+	if x != nil {
+		return x.Template
+	}
+	return nil
+}
+
+type UpdateEmailTemplateRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TemplateId    string                 `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Subject       string                 `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
+	HtmlContent   string                 `protobuf:"bytes,5,opt,name=html_content,json=htmlContent,proto3" json:"html_content,omitempty"`
+	HtmlTemplate  string                 `protobuf:"bytes,6,opt,name=html_template,json=htmlTemplate,proto3" json:"html_template,omitempty"`
+	TextContent   string                 `protobuf:"bytes,7,opt,name=text_content,json=textContent,proto3" json:"text_content,omitempty"`
+	TextTemplate  string                 `protobuf:"bytes,8,opt,name=text_template,json=textTemplate,proto3" json:"text_template,omitempty"`
+	Variables     string                 `protobuf:"bytes,9,opt,name=variables,proto3" json:"variables,omitempty"` // JSON array string
+	VariablesMap  map[string]string      `protobuf:"bytes,10,rep,name=variables_map,json=variablesMap,proto3" json:"variables_map,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	IsActive      bool                   `protobuf:"varint,11,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetEmailTemplateRequest) Reset() {
-	*x = GetEmailTemplateRequest{}
-	mi := &file_protos_email_proto_msgTypes[14]
+func (x *UpdateEmailTemplateRequest) Reset() {
+	*x = UpdateEmailTemplateRequest{}
+	mi := &file_protos_email_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetEmailTemplateRequest) String() string {
+func (x *UpdateEmailTemplateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetEmailTemplateRequest) ProtoMessage() {}
+func (*UpdateEmailTemplateRequest) ProtoMessage() {}
 
-func (x *GetEmailTemplateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[14]
+func (x *UpdateEmailTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1214,109 +1963,113 @@ func (x *GetEmailTemplateRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetEmailTemplateRequest.ProtoReflect.Descriptor instead.
-func (*GetEmailTemplateRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use UpdateEmailTemplateRequest.ProtoReflect.Descriptor instead.
+func (*UpdateEmailTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *GetEmailTemplateRequest) GetTemplateId() string {
+func (x *UpdateEmailTemplateRequest) GetTemplateId() string {
 	if x != nil {
 		return x.TemplateId
 	}
 	return ""
 }
 
-func (x *GetEmailTemplateRequest) GetName() string {
+func (x *UpdateEmailTemplateRequest) GetId() string {
 	if x != nil {
-		return x.Name
+		return x.Id
 	}
 	return ""
 }
 
-type GetEmailTemplateResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Template      *EmailTemplate         `protobuf:"bytes,3,opt,name=template,proto3" json:"template,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *UpdateEmailTemplateRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
 }
 
-func (x *GetEmailTemplateResponse) Reset() {
-	*x = GetEmailTemplateResponse{}
-	mi := &file_protos_email_proto_msgTypes[15]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *UpdateEmailTemplateRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
 }
 
-func (x *GetEmailTemplateResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *UpdateEmailTemplateRequest) GetHtmlContent() string {
+	if x != nil {
+		return x.HtmlContent
+	}
+	return ""
 }
 
-func (*GetEmailTemplateResponse) ProtoMessage() {}
-
-func (x *GetEmailTemplateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[15]
+func (x *UpdateEmailTemplateRequest) GetHtmlTemplate() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.HtmlTemplate
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use GetEmailTemplateResponse.ProtoReflect.Descriptor instead.
-func (*GetEmailTemplateResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{15}
+func (x *UpdateEmailTemplateRequest) GetTextContent() string {
+	if x != nil {
+		return x.TextContent
+	}
+	return ""
 }
 
-func (x *GetEmailTemplateResponse) GetSuccess() bool {
+func (x *UpdateEmailTemplateRequest) GetTextTemplate() string {
 	if x != nil {
-		return x.Success
+		return x.TextTemplate
 	}
-	return false
+	return ""
 }
 
-func (x *GetEmailTemplateResponse) GetMessage() string {
+func (x *UpdateEmailTemplateRequest) GetVariables() string {
 	if x != nil {
-		return x.Message
+		return x.Variables
 	}
 	return ""
 }
 
-func (x *GetEmailTemplateResponse) GetTemplate() *EmailTemplate {
+func (x *UpdateEmailTemplateRequest) GetVariablesMap() map[string]string {
 	if x != nil {
-		return x.Template
+		return x.VariablesMap
 	}
 	return nil
 }
 
-type ListEmailTemplatesRequest struct {
+func (x *UpdateEmailTemplateRequest) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+type UpdateEmailTemplateResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	IsActive      bool                   `protobuf:"varint,1,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
-	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
-	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	TemplateId    string                 `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Template      *EmailTemplate         `protobuf:"bytes,4,opt,name=template,proto3" json:"template,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListEmailTemplatesRequest) Reset() {
-	*x = ListEmailTemplatesRequest{}
-	mi := &file_protos_email_proto_msgTypes[16]
+func (x *UpdateEmailTemplateResponse) Reset() {
+	*x = UpdateEmailTemplateResponse{}
+	mi := &file_protos_email_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListEmailTemplatesRequest) String() string {
+func (x *UpdateEmailTemplateResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListEmailTemplatesRequest) ProtoMessage() {}
+func (*UpdateEmailTemplateResponse) ProtoMessage() {}
 
-func (x *ListEmailTemplatesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[16]
+func (x *UpdateEmailTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1327,57 +2080,61 @@ func (x *ListEmailTemplatesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListEmailTemplatesRequest.ProtoReflect.Descriptor instead.
-func (*ListEmailTemplatesRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use UpdateEmailTemplateResponse.ProtoReflect.Descriptor instead.
+func (*UpdateEmailTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *ListEmailTemplatesRequest) GetIsActive() bool {
+func (x *UpdateEmailTemplateResponse) GetTemplateId() string {
 	if x != nil {
-		return x.IsActive
+		return x.TemplateId
+	}
+	return ""
+}
+
+func (x *UpdateEmailTemplateResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
 	}
 	return false
 }
 
-func (x *ListEmailTemplatesRequest) GetPage() int32 {
+func (x *UpdateEmailTemplateResponse) GetMessage() string {
 	if x != nil {
-		return x.Page
+		return x.Message
 	}
-	return 0
+	return ""
 }
 
-func (x *ListEmailTemplatesRequest) GetLimit() int32 {
+func (x *UpdateEmailTemplateResponse) GetTemplate() *EmailTemplate {
 	if x != nil {
-		return x.Limit
+		return x.Template
 	}
-	return 0
+	return nil
 }
 
-type ListEmailTemplatesResponse struct {
+type DeleteEmailTemplateRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Templates     []*EmailTemplate       `protobuf:"bytes,3,rep,name=templates,proto3" json:"templates,omitempty"`
-	Total         int32                  `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	TemplateId    string                 `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListEmailTemplatesResponse) Reset() {
-	*x = ListEmailTemplatesResponse{}
-	mi := &file_protos_email_proto_msgTypes[17]
+func (x *DeleteEmailTemplateRequest) Reset() {
+	*x = DeleteEmailTemplateRequest{}
+	mi := &file_protos_email_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListEmailTemplatesResponse) String() string {
+func (x *DeleteEmailTemplateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListEmailTemplatesResponse) ProtoMessage() {}
+func (*DeleteEmailTemplateRequest) ProtoMessage() {}
 
-func (x *ListEmailTemplatesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[17]
+func (x *DeleteEmailTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1388,70 +2145,41 @@ func (x *ListEmailTemplatesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListEmailTemplatesResponse.ProtoReflect.Descriptor instead.
-func (*ListEmailTemplatesResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{17}
-}
-
-func (x *ListEmailTemplatesResponse) GetSuccess() bool {
-	if x != nil {
-		return x.Success
-	}
-	return false
+// Deprecated: Use DeleteEmailTemplateRequest.ProtoReflect.Descriptor instead.
+func (*DeleteEmailTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *ListEmailTemplatesResponse) GetMessage() string {
+func (x *DeleteEmailTemplateRequest) GetTemplateId() string {
 	if x != nil {
-		return x.Message
+		return x.TemplateId
 	}
 	return ""
 }
 
-func (x *ListEmailTemplatesResponse) GetTemplates() []*EmailTemplate {
-	if x != nil {
-		return x.Templates
-	}
-	return nil
-}
-
-func (x *ListEmailTemplatesResponse) GetTotal() int32 {
-	if x != nil {
-		return x.Total
-	}
-	return 0
-}
-
-type CreateEmailTemplateRequest struct {
+type DeleteEmailTemplateResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Subject       string                 `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`
-	HtmlContent   string                 `protobuf:"bytes,4,opt,name=html_content,json=htmlContent,proto3" json:"html_content,omitempty"`
-	HtmlTemplate  string                 `protobuf:"bytes,5,opt,name=html_template,json=htmlTemplate,proto3" json:"html_template,omitempty"`
-	TextContent   string                 `protobuf:"bytes,6,opt,name=text_content,json=textContent,proto3" json:"text_content,omitempty"`
-	TextTemplate  string                 `protobuf:"bytes,7,opt,name=text_template,json=textTemplate,proto3" json:"text_template,omitempty"`
-	Variables     string                 `protobuf:"bytes,8,opt,name=variables,proto3" json:"variables,omitempty"` // JSON array string
-	VariablesMap  map[string]string      `protobuf:"bytes,9,rep,name=variables_map,json=variablesMap,proto3" json:"variables_map,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	IsActive      bool                   `protobuf:"varint,10,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateEmailTemplateRequest) Reset() {
-	*x = CreateEmailTemplateRequest{}
-	mi := &file_protos_email_proto_msgTypes[18]
+func (x *DeleteEmailTemplateResponse) Reset() {
+	*x = DeleteEmailTemplateResponse{}
+	mi := &file_protos_email_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateEmailTemplateRequest) String() string {
+func (x *DeleteEmailTemplateResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateEmailTemplateRequest) ProtoMessage() {}
+func (*DeleteEmailTemplateResponse) ProtoMessage() {}
 
-func (x *CreateEmailTemplateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[18]
+func (x *DeleteEmailTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1462,106 +2190,102 @@ func (x *CreateEmailTemplateRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateEmailTemplateRequest.ProtoReflect.Descriptor instead.
-func (*CreateEmailTemplateRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use DeleteEmailTemplateResponse.ProtoReflect.Descriptor instead.
+func (*DeleteEmailTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{28}
 }
 
-func (x *CreateEmailTemplateRequest) GetId() string {
+func (x *DeleteEmailTemplateResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Id
+		return x.Success
 	}
-	return ""
+	return false
 }
 
-func (x *CreateEmailTemplateRequest) GetName() string {
+func (x *DeleteEmailTemplateResponse) GetMessage() string {
 	if x != nil {
-		return x.Name
+		return x.Message
 	}
 	return ""
 }
 
-func (x *CreateEmailTemplateRequest) GetSubject() string {
-	if x != nil {
-		return x.Subject
-	}
-	return ""
+// Email Tracking
+type GetEmailTrackingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         int64                  `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	MessageId     string                 `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateEmailTemplateRequest) GetHtmlContent() string {
-	if x != nil {
-		return x.HtmlContent
-	}
-	return ""
+func (x *GetEmailTrackingRequest) Reset() {
+	*x = GetEmailTrackingRequest{}
+	mi := &file_protos_email_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateEmailTemplateRequest) GetHtmlTemplate() string {
-	if x != nil {
-		return x.HtmlTemplate
-	}
-	return ""
+func (x *GetEmailTrackingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *CreateEmailTemplateRequest) GetTextContent() string {
-	if x != nil {
-		return x.TextContent
-	}
-	return ""
-}
+func (*GetEmailTrackingRequest) ProtoMessage() {}
 
-func (x *CreateEmailTemplateRequest) GetTextTemplate() string {
+func (x *GetEmailTrackingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[29]
 	if x != nil {
-		return x.TextTemplate
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *CreateEmailTemplateRequest) GetVariables() string {
-	if x != nil {
-		return x.Variables
-	}
-	return ""
+// Deprecated: Use GetEmailTrackingRequest.ProtoReflect.Descriptor instead.
+func (*GetEmailTrackingRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *CreateEmailTemplateRequest) GetVariablesMap() map[string]string {
+func (x *GetEmailTrackingRequest) GetJobId() int64 {
 	if x != nil {
-		return x.VariablesMap
+		return x.JobId
 	}
-	return nil
+	return 0
 }
 
-func (x *CreateEmailTemplateRequest) GetIsActive() bool {
+func (x *GetEmailTrackingRequest) GetMessageId() string {
 	if x != nil {
-		return x.IsActive
+		return x.MessageId
 	}
-	return false
+	return ""
 }
 
-type CreateEmailTemplateResponse struct {
+type GetEmailTrackingResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TemplateId    string                 `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
-	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
-	Template      *EmailTemplate         `protobuf:"bytes,4,opt,name=template,proto3" json:"template,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Tracking      *EmailTracking         `protobuf:"bytes,3,opt,name=tracking,proto3" json:"tracking,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateEmailTemplateResponse) Reset() {
-	*x = CreateEmailTemplateResponse{}
-	mi := &file_protos_email_proto_msgTypes[19]
+func (x *GetEmailTrackingResponse) Reset() {
+	*x = GetEmailTrackingResponse{}
+	mi := &file_protos_email_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateEmailTemplateResponse) String() string {
+func (x *GetEmailTrackingResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateEmailTemplateResponse) ProtoMessage() {}
+func (*GetEmailTrackingResponse) ProtoMessage() {}
 
-func (x *CreateEmailTemplateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[19]
+func (x *GetEmailTrackingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1572,71 +2296,62 @@ func (x *CreateEmailTemplateResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateEmailTemplateResponse.ProtoReflect.Descriptor instead.
-func (*CreateEmailTemplateResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{19}
-}
-
-func (x *CreateEmailTemplateResponse) GetTemplateId() string {
-	if x != nil {
-		return x.TemplateId
-	}
-	return ""
+// Deprecated: Use GetEmailTrackingResponse.ProtoReflect.Descriptor instead.
+func (*GetEmailTrackingResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *CreateEmailTemplateResponse) GetSuccess() bool {
+func (x *GetEmailTrackingResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *CreateEmailTemplateResponse) GetMessage() string {
+func (x *GetEmailTrackingResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *CreateEmailTemplateResponse) GetTemplate() *EmailTemplate {
+func (x *GetEmailTrackingResponse) GetTracking() *EmailTracking {
 	if x != nil {
-		return x.Template
+		return x.Tracking
 	}
 	return nil
 }
 
-type UpdateEmailTemplateRequest struct {
+type UpdateEmailTrackingRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TemplateId    string                 `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
-	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
-	Subject       string                 `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
-	HtmlContent   string                 `protobuf:"bytes,5,opt,name=html_content,json=htmlContent,proto3" json:"html_content,omitempty"`
-	HtmlTemplate  string                 `protobuf:"bytes,6,opt,name=html_template,json=htmlTemplate,proto3" json:"html_template,omitempty"`
-	TextContent   string                 `protobuf:"bytes,7,opt,name=text_content,json=textContent,proto3" json:"text_content,omitempty"`
-	TextTemplate  string                 `protobuf:"bytes,8,opt,name=text_template,json=textTemplate,proto3" json:"text_template,omitempty"`
-	Variables     string                 `protobuf:"bytes,9,opt,name=variables,proto3" json:"variables,omitempty"` // JSON array string
-	VariablesMap  map[string]string      `protobuf:"bytes,10,rep,name=variables_map,json=variablesMap,proto3" json:"variables_map,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	IsActive      bool                   `protobuf:"varint,11,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	JobId         int64                  `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Provider      string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`
+	MessageId     string                 `protobuf:"bytes,3,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`                              // "sent", "delivered", "bounced", "opened", "clicked"
+	SentAt        string                 `protobuf:"bytes,5,opt,name=sent_at,json=sentAt,proto3" json:"sent_at,omitempty"`                // ISO 8601 timestamp
+	DeliveredAt   string                 `protobuf:"bytes,6,opt,name=delivered_at,json=deliveredAt,proto3" json:"delivered_at,omitempty"` // ISO 8601 timestamp
+	OpenedAt      string                 `protobuf:"bytes,7,opt,name=opened_at,json=openedAt,proto3" json:"opened_at,omitempty"`          // ISO 8601 timestamp
+	ClickedAt     string                 `protobuf:"bytes,8,opt,name=clicked_at,json=clickedAt,proto3" json:"clicked_at,omitempty"`       // ISO 8601 timestamp
+	BounceReason  string                 `protobuf:"bytes,9,opt,name=bounce_reason,json=bounceReason,proto3" json:"bounce_reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateEmailTemplateRequest) Reset() {
-	*x = UpdateEmailTemplateRequest{}
-	mi := &file_protos_email_proto_msgTypes[20]
+func (x *UpdateEmailTrackingRequest) Reset() {
+	*x = UpdateEmailTrackingRequest{}
+	mi := &file_protos_email_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateEmailTemplateRequest) String() string {
+func (x *UpdateEmailTrackingRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateEmailTemplateRequest) ProtoMessage() {}
+func (*UpdateEmailTrackingRequest) ProtoMessage() {}
 
-func (x *UpdateEmailTemplateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[20]
+func (x *UpdateEmailTrackingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1647,113 +2362,160 @@ func (x *UpdateEmailTemplateRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateEmailTemplateRequest.ProtoReflect.Descriptor instead.
-func (*UpdateEmailTemplateRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use UpdateEmailTrackingRequest.ProtoReflect.Descriptor instead.
+func (*UpdateEmailTrackingRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *UpdateEmailTrackingRequest) GetJobId() int64 {
+	if x != nil {
+		return x.JobId
+	}
+	return 0
+}
+
+func (x *UpdateEmailTrackingRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
 }
 
-func (x *UpdateEmailTemplateRequest) GetTemplateId() string {
+func (x *UpdateEmailTrackingRequest) GetMessageId() string {
 	if x != nil {
-		return x.TemplateId
+		return x.MessageId
 	}
 	return ""
 }
 
-func (x *UpdateEmailTemplateRequest) GetId() string {
+func (x *UpdateEmailTrackingRequest) GetStatus() string {
 	if x != nil {
-		return x.Id
+		return x.Status
 	}
 	return ""
 }
 
-func (x *UpdateEmailTemplateRequest) GetName() string {
+func (x *UpdateEmailTrackingRequest) GetSentAt() string {
 	if x != nil {
-		return x.Name
+		return x.SentAt
 	}
 	return ""
 }
 
-func (x *UpdateEmailTemplateRequest) GetSubject() string {
+func (x *UpdateEmailTrackingRequest) GetDeliveredAt() string {
 	if x != nil {
-		return x.Subject
+		return x.DeliveredAt
 	}
 	return ""
 }
 
-func (x *UpdateEmailTemplateRequest) GetHtmlContent() string {
+func (x *UpdateEmailTrackingRequest) GetOpenedAt() string {
 	if x != nil {
-		return x.HtmlContent
+		return x.OpenedAt
 	}
 	return ""
 }
 
-func (x *UpdateEmailTemplateRequest) GetHtmlTemplate() string {
+func (x *UpdateEmailTrackingRequest) GetClickedAt() string {
 	if x != nil {
-		return x.HtmlTemplate
+		return x.ClickedAt
 	}
 	return ""
 }
 
-func (x *UpdateEmailTemplateRequest) GetTextContent() string {
+func (x *UpdateEmailTrackingRequest) GetBounceReason() string {
 	if x != nil {
-		return x.TextContent
+		return x.BounceReason
 	}
 	return ""
 }
 
-func (x *UpdateEmailTemplateRequest) GetTextTemplate() string {
+type UpdateEmailTrackingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Tracking      *EmailTracking         `protobuf:"bytes,3,opt,name=tracking,proto3" json:"tracking,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateEmailTrackingResponse) Reset() {
+	*x = UpdateEmailTrackingResponse{}
+	mi := &file_protos_email_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateEmailTrackingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateEmailTrackingResponse) ProtoMessage() {}
+
+func (x *UpdateEmailTrackingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[32]
 	if x != nil {
-		return x.TextTemplate
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *UpdateEmailTemplateRequest) GetVariables() string {
+// Deprecated: Use UpdateEmailTrackingResponse.ProtoReflect.Descriptor instead.
+func (*UpdateEmailTrackingResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *UpdateEmailTrackingResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Variables
+		return x.Success
 	}
-	return ""
+	return false
 }
 
-func (x *UpdateEmailTemplateRequest) GetVariablesMap() map[string]string {
+func (x *UpdateEmailTrackingResponse) GetMessage() string {
 	if x != nil {
-		return x.VariablesMap
+		return x.Message
 	}
-	return nil
+	return ""
 }
 
-func (x *UpdateEmailTemplateRequest) GetIsActive() bool {
+func (x *UpdateEmailTrackingResponse) GetTracking() *EmailTracking {
 	if x != nil {
-		return x.IsActive
+		return x.Tracking
 	}
-	return false
+	return nil
 }
 
-type UpdateEmailTemplateResponse struct {
+// Dead letters
+type DeadLetterFilter struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TemplateId    string                 `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
-	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
-	Template      *EmailTemplate         `protobuf:"bytes,4,opt,name=template,proto3" json:"template,omitempty"`
+	TemplateName  string                 `protobuf:"bytes,1,opt,name=template_name,json=templateName,proto3" json:"template_name,omitempty"`
+	ErrorContains string                 `protobuf:"bytes,2,opt,name=error_contains,json=errorContains,proto3" json:"error_contains,omitempty"`
+	Since         *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`
+	Until         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=until,proto3" json:"until,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateEmailTemplateResponse) Reset() {
-	*x = UpdateEmailTemplateResponse{}
-	mi := &file_protos_email_proto_msgTypes[21]
+func (x *DeadLetterFilter) Reset() {
+	*x = DeadLetterFilter{}
+	mi := &file_protos_email_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateEmailTemplateResponse) String() string {
+func (x *DeadLetterFilter) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateEmailTemplateResponse) ProtoMessage() {}
+func (*DeadLetterFilter) ProtoMessage() {}
 
-func (x *UpdateEmailTemplateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[21]
+func (x *DeadLetterFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1764,61 +2526,63 @@ func (x *UpdateEmailTemplateResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateEmailTemplateResponse.ProtoReflect.Descriptor instead.
-func (*UpdateEmailTemplateResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use DeadLetterFilter.ProtoReflect.Descriptor instead.
+func (*DeadLetterFilter) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *UpdateEmailTemplateResponse) GetTemplateId() string {
+func (x *DeadLetterFilter) GetTemplateName() string {
 	if x != nil {
-		return x.TemplateId
+		return x.TemplateName
 	}
 	return ""
 }
 
-func (x *UpdateEmailTemplateResponse) GetSuccess() bool {
+func (x *DeadLetterFilter) GetErrorContains() string {
 	if x != nil {
-		return x.Success
+		return x.ErrorContains
 	}
-	return false
+	return ""
 }
 
-func (x *UpdateEmailTemplateResponse) GetMessage() string {
+func (x *DeadLetterFilter) GetSince() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Message
+		return x.Since
 	}
-	return ""
+	return nil
 }
 
-func (x *UpdateEmailTemplateResponse) GetTemplate() *EmailTemplate {
+func (x *DeadLetterFilter) GetUntil() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Template
+		return x.Until
 	}
 	return nil
 }
 
-type DeleteEmailTemplateRequest struct {
+type ListDeadLettersRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TemplateId    string                 `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
+	Filter        *DeadLetterFilter      `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteEmailTemplateRequest) Reset() {
-	*x = DeleteEmailTemplateRequest{}
-	mi := &file_protos_email_proto_msgTypes[22]
+func (x *ListDeadLettersRequest) Reset() {
+	*x = ListDeadLettersRequest{}
+	mi := &file_protos_email_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteEmailTemplateRequest) String() string {
+func (x *ListDeadLettersRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteEmailTemplateRequest) ProtoMessage() {}
+func (*ListDeadLettersRequest) ProtoMessage() {}
 
-func (x *DeleteEmailTemplateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[22]
+func (x *ListDeadLettersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1829,41 +2593,56 @@ func (x *DeleteEmailTemplateRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteEmailTemplateRequest.ProtoReflect.Descriptor instead.
-func (*DeleteEmailTemplateRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use ListDeadLettersRequest.ProtoReflect.Descriptor instead.
+func (*ListDeadLettersRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *DeleteEmailTemplateRequest) GetTemplateId() string {
+func (x *ListDeadLettersRequest) GetFilter() *DeadLetterFilter {
 	if x != nil {
-		return x.TemplateId
+		return x.Filter
 	}
-	return ""
+	return nil
 }
 
-type DeleteEmailTemplateResponse struct {
+func (x *ListDeadLettersRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListDeadLettersRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListDeadLettersResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	DeadLetters   []*DeadLetter          `protobuf:"bytes,3,rep,name=dead_letters,json=deadLetters,proto3" json:"dead_letters,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteEmailTemplateResponse) Reset() {
-	*x = DeleteEmailTemplateResponse{}
-	mi := &file_protos_email_proto_msgTypes[23]
+func (x *ListDeadLettersResponse) Reset() {
+	*x = ListDeadLettersResponse{}
+	mi := &file_protos_email_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteEmailTemplateResponse) String() string {
+func (x *ListDeadLettersResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteEmailTemplateResponse) ProtoMessage() {}
+func (*ListDeadLettersResponse) ProtoMessage() {}
 
-func (x *DeleteEmailTemplateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[23]
+func (x *ListDeadLettersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1874,49 +2653,54 @@ func (x *DeleteEmailTemplateResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteEmailTemplateResponse.ProtoReflect.Descriptor instead.
-func (*DeleteEmailTemplateResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{23}
+// Deprecated: Use ListDeadLettersResponse.ProtoReflect.Descriptor instead.
+func (*ListDeadLettersResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *DeleteEmailTemplateResponse) GetSuccess() bool {
+func (x *ListDeadLettersResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *DeleteEmailTemplateResponse) GetMessage() string {
+func (x *ListDeadLettersResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-// Email Tracking
-type GetEmailTrackingRequest struct {
+func (x *ListDeadLettersResponse) GetDeadLetters() []*DeadLetter {
+	if x != nil {
+		return x.DeadLetters
+	}
+	return nil
+}
+
+type GetDeadLetterRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	JobId         int64                  `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	MessageId     string                 `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetEmailTrackingRequest) Reset() {
-	*x = GetEmailTrackingRequest{}
-	mi := &file_protos_email_proto_msgTypes[24]
+func (x *GetDeadLetterRequest) Reset() {
+	*x = GetDeadLetterRequest{}
+	mi := &file_protos_email_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetEmailTrackingRequest) String() string {
+func (x *GetDeadLetterRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetEmailTrackingRequest) ProtoMessage() {}
+func (*GetDeadLetterRequest) ProtoMessage() {}
 
-func (x *GetEmailTrackingRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[24]
+func (x *GetDeadLetterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1927,49 +2711,42 @@ func (x *GetEmailTrackingRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetEmailTrackingRequest.ProtoReflect.Descriptor instead.
-func (*GetEmailTrackingRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use GetDeadLetterRequest.ProtoReflect.Descriptor instead.
+func (*GetDeadLetterRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *GetEmailTrackingRequest) GetJobId() int64 {
+func (x *GetDeadLetterRequest) GetJobId() string {
 	if x != nil {
 		return x.JobId
 	}
-	return 0
-}
-
-func (x *GetEmailTrackingRequest) GetMessageId() string {
-	if x != nil {
-		return x.MessageId
-	}
 	return ""
 }
 
-type GetEmailTrackingResponse struct {
+type GetDeadLetterResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Tracking      *EmailTracking         `protobuf:"bytes,3,opt,name=tracking,proto3" json:"tracking,omitempty"`
+	DeadLetter    *DeadLetter            `protobuf:"bytes,3,opt,name=dead_letter,json=deadLetter,proto3" json:"dead_letter,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetEmailTrackingResponse) Reset() {
-	*x = GetEmailTrackingResponse{}
-	mi := &file_protos_email_proto_msgTypes[25]
+func (x *GetDeadLetterResponse) Reset() {
+	*x = GetDeadLetterResponse{}
+	mi := &file_protos_email_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetEmailTrackingResponse) String() string {
+func (x *GetDeadLetterResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetEmailTrackingResponse) ProtoMessage() {}
+func (*GetDeadLetterResponse) ProtoMessage() {}
 
-func (x *GetEmailTrackingResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[25]
+func (x *GetDeadLetterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1980,62 +2757,109 @@ func (x *GetEmailTrackingResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetEmailTrackingResponse.ProtoReflect.Descriptor instead.
-func (*GetEmailTrackingResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{25}
+// Deprecated: Use GetDeadLetterResponse.ProtoReflect.Descriptor instead.
+func (*GetDeadLetterResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *GetEmailTrackingResponse) GetSuccess() bool {
+func (x *GetDeadLetterResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *GetEmailTrackingResponse) GetMessage() string {
+func (x *GetDeadLetterResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *GetEmailTrackingResponse) GetTracking() *EmailTracking {
+func (x *GetDeadLetterResponse) GetDeadLetter() *DeadLetter {
 	if x != nil {
-		return x.Tracking
+		return x.DeadLetter
 	}
 	return nil
 }
 
-type UpdateEmailTrackingRequest struct {
+// Jobs are selected by job_ids, or by filter when no IDs are given
+type ReplayDeadLettersRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	JobId         int64                  `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	Provider      string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`
-	MessageId     string                 `protobuf:"bytes,3,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
-	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`                              // "sent", "delivered", "bounced", "opened", "clicked"
-	SentAt        string                 `protobuf:"bytes,5,opt,name=sent_at,json=sentAt,proto3" json:"sent_at,omitempty"`                // ISO 8601 timestamp
-	DeliveredAt   string                 `protobuf:"bytes,6,opt,name=delivered_at,json=deliveredAt,proto3" json:"delivered_at,omitempty"` // ISO 8601 timestamp
-	OpenedAt      string                 `protobuf:"bytes,7,opt,name=opened_at,json=openedAt,proto3" json:"opened_at,omitempty"`          // ISO 8601 timestamp
-	ClickedAt     string                 `protobuf:"bytes,8,opt,name=clicked_at,json=clickedAt,proto3" json:"clicked_at,omitempty"`       // ISO 8601 timestamp
-	BounceReason  string                 `protobuf:"bytes,9,opt,name=bounce_reason,json=bounceReason,proto3" json:"bounce_reason,omitempty"`
+	JobIds        []string               `protobuf:"bytes,1,rep,name=job_ids,json=jobIds,proto3" json:"job_ids,omitempty"`
+	Filter        *DeadLetterFilter      `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayDeadLettersRequest) Reset() {
+	*x = ReplayDeadLettersRequest{}
+	mi := &file_protos_email_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayDeadLettersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayDeadLettersRequest) ProtoMessage() {}
+
+func (x *ReplayDeadLettersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayDeadLettersRequest.ProtoReflect.Descriptor instead.
+func (*ReplayDeadLettersRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ReplayDeadLettersRequest) GetJobIds() []string {
+	if x != nil {
+		return x.JobIds
+	}
+	return nil
+}
+
+func (x *ReplayDeadLettersRequest) GetFilter() *DeadLetterFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type ReplayDeadLettersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Replayed      int32                  `protobuf:"varint,3,opt,name=replayed,proto3" json:"replayed,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateEmailTrackingRequest) Reset() {
-	*x = UpdateEmailTrackingRequest{}
-	mi := &file_protos_email_proto_msgTypes[26]
+func (x *ReplayDeadLettersResponse) Reset() {
+	*x = ReplayDeadLettersResponse{}
+	mi := &file_protos_email_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateEmailTrackingRequest) String() string {
+func (x *ReplayDeadLettersResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateEmailTrackingRequest) ProtoMessage() {}
+func (*ReplayDeadLettersResponse) ProtoMessage() {}
 
-func (x *UpdateEmailTrackingRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[26]
+func (x *ReplayDeadLettersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2046,98 +2870,109 @@ func (x *UpdateEmailTrackingRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateEmailTrackingRequest.ProtoReflect.Descriptor instead.
-func (*UpdateEmailTrackingRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{26}
+// Deprecated: Use ReplayDeadLettersResponse.ProtoReflect.Descriptor instead.
+func (*ReplayDeadLettersResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *UpdateEmailTrackingRequest) GetJobId() int64 {
+func (x *ReplayDeadLettersResponse) GetSuccess() bool {
 	if x != nil {
-		return x.JobId
+		return x.Success
 	}
-	return 0
+	return false
 }
 
-func (x *UpdateEmailTrackingRequest) GetProvider() string {
+func (x *ReplayDeadLettersResponse) GetMessage() string {
 	if x != nil {
-		return x.Provider
+		return x.Message
 	}
 	return ""
 }
 
-func (x *UpdateEmailTrackingRequest) GetMessageId() string {
+func (x *ReplayDeadLettersResponse) GetReplayed() int32 {
 	if x != nil {
-		return x.MessageId
+		return x.Replayed
 	}
-	return ""
+	return 0
 }
 
-func (x *UpdateEmailTrackingRequest) GetStatus() string {
-	if x != nil {
-		return x.Status
-	}
-	return ""
+// Jobs are selected by job_ids, or by filter when no IDs are given
+type PurgeDeadLettersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobIds        []string               `protobuf:"bytes,1,rep,name=job_ids,json=jobIds,proto3" json:"job_ids,omitempty"`
+	Filter        *DeadLetterFilter      `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateEmailTrackingRequest) GetSentAt() string {
-	if x != nil {
-		return x.SentAt
-	}
-	return ""
+func (x *PurgeDeadLettersRequest) Reset() {
+	*x = PurgeDeadLettersRequest{}
+	mi := &file_protos_email_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateEmailTrackingRequest) GetDeliveredAt() string {
-	if x != nil {
-		return x.DeliveredAt
-	}
-	return ""
+func (x *PurgeDeadLettersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *UpdateEmailTrackingRequest) GetOpenedAt() string {
+func (*PurgeDeadLettersRequest) ProtoMessage() {}
+
+func (x *PurgeDeadLettersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[40]
 	if x != nil {
-		return x.OpenedAt
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *UpdateEmailTrackingRequest) GetClickedAt() string {
+// Deprecated: Use PurgeDeadLettersRequest.ProtoReflect.Descriptor instead.
+func (*PurgeDeadLettersRequest) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *PurgeDeadLettersRequest) GetJobIds() []string {
 	if x != nil {
-		return x.ClickedAt
+		return x.JobIds
 	}
-	return ""
+	return nil
 }
 
-func (x *UpdateEmailTrackingRequest) GetBounceReason() string {
+func (x *PurgeDeadLettersRequest) GetFilter() *DeadLetterFilter {
 	if x != nil {
-		return x.BounceReason
+		return x.Filter
 	}
-	return ""
+	return nil
 }
 
-type UpdateEmailTrackingResponse struct {
+type PurgeDeadLettersResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Tracking      *EmailTracking         `protobuf:"bytes,3,opt,name=tracking,proto3" json:"tracking,omitempty"`
+	Purged        int32                  `protobuf:"varint,3,opt,name=purged,proto3" json:"purged,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateEmailTrackingResponse) Reset() {
-	*x = UpdateEmailTrackingResponse{}
-	mi := &file_protos_email_proto_msgTypes[27]
+func (x *PurgeDeadLettersResponse) Reset() {
+	*x = PurgeDeadLettersResponse{}
+	mi := &file_protos_email_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateEmailTrackingResponse) String() string {
+func (x *PurgeDeadLettersResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateEmailTrackingResponse) ProtoMessage() {}
+func (*PurgeDeadLettersResponse) ProtoMessage() {}
 
-func (x *UpdateEmailTrackingResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[27]
+func (x *PurgeDeadLettersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2148,30 +2983,30 @@ func (x *UpdateEmailTrackingResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateEmailTrackingResponse.ProtoReflect.Descriptor instead.
-func (*UpdateEmailTrackingResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{27}
+// Deprecated: Use PurgeDeadLettersResponse.ProtoReflect.Descriptor instead.
+func (*PurgeDeadLettersResponse) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *UpdateEmailTrackingResponse) GetSuccess() bool {
+func (x *PurgeDeadLettersResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *UpdateEmailTrackingResponse) GetMessage() string {
+func (x *PurgeDeadLettersResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *UpdateEmailTrackingResponse) GetTracking() *EmailTracking {
+func (x *PurgeDeadLettersResponse) GetPurged() int32 {
 	if x != nil {
-		return x.Tracking
+		return x.Purged
 	}
-	return nil
+	return 0
 }
 
 // Health check
@@ -2183,7 +3018,7 @@ type HealthRequest struct {
 
 func (x *HealthRequest) Reset() {
 	*x = HealthRequest{}
-	mi := &file_protos_email_proto_msgTypes[28]
+	mi := &file_protos_email_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2195,7 +3030,7 @@ func (x *HealthRequest) String() string {
 func (*HealthRequest) ProtoMessage() {}
 
 func (x *HealthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[28]
+	mi := &file_protos_email_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2208,7 +3043,7 @@ func (x *HealthRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
 func (*HealthRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{28}
+	return file_protos_email_proto_rawDescGZIP(), []int{42}
 }
 
 type HealthResponse struct {
@@ -2222,7 +3057,7 @@ type HealthResponse struct {
 
 func (x *HealthResponse) Reset() {
 	*x = HealthResponse{}
-	mi := &file_protos_email_proto_msgTypes[29]
+	mi := &file_protos_email_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2234,7 +3069,7 @@ func (x *HealthResponse) String() string {
 func (*HealthResponse) ProtoMessage() {}
 
 func (x *HealthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[29]
+	mi := &file_protos_email_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2247,7 +3082,7 @@ func (x *HealthResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
 func (*HealthResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{29}
+	return file_protos_email_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *HealthResponse) GetStatus() string {
@@ -2279,7 +3114,7 @@ type HealthCheckRequest struct {
 
 func (x *HealthCheckRequest) Reset() {
 	*x = HealthCheckRequest{}
-	mi := &file_protos_email_proto_msgTypes[30]
+	mi := &file_protos_email_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2291,7 +3126,7 @@ func (x *HealthCheckRequest) String() string {
 func (*HealthCheckRequest) ProtoMessage() {}
 
 func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[30]
+	mi := &file_protos_email_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2304,7 +3139,7 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
 func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{30}
+	return file_protos_email_proto_rawDescGZIP(), []int{44}
 }
 
 type HealthCheckResponse struct {
@@ -2323,7 +3158,7 @@ type HealthCheckResponse struct {
 
 func (x *HealthCheckResponse) Reset() {
 	*x = HealthCheckResponse{}
-	mi := &file_protos_email_proto_msgTypes[31]
+	mi := &file_protos_email_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2335,7 +3170,7 @@ func (x *HealthCheckResponse) String() string {
 func (*HealthCheckResponse) ProtoMessage() {}
 
 func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[31]
+	mi := &file_protos_email_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2348,7 +3183,7 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
 func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{31}
+	return file_protos_email_proto_rawDescGZIP(), []int{45}
 }
 
 func (x *HealthCheckResponse) GetStatus() string {
@@ -2422,7 +3257,7 @@ type SendVerificationEmailRequest struct {
 
 func (x *SendVerificationEmailRequest) Reset() {
 	*x = SendVerificationEmailRequest{}
-	mi := &file_protos_email_proto_msgTypes[32]
+	mi := &file_protos_email_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2434,7 +3269,7 @@ func (x *SendVerificationEmailRequest) String() string {
 func (*SendVerificationEmailRequest) ProtoMessage() {}
 
 func (x *SendVerificationEmailRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[32]
+	mi := &file_protos_email_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2447,7 +3282,7 @@ func (x *SendVerificationEmailRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SendVerificationEmailRequest.ProtoReflect.Descriptor instead.
 func (*SendVerificationEmailRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{32}
+	return file_protos_email_proto_rawDescGZIP(), []int{46}
 }
 
 func (x *SendVerificationEmailRequest) GetUserId() string {
@@ -2505,7 +3340,7 @@ type SendVerificationEmailResponse struct {
 
 func (x *SendVerificationEmailResponse) Reset() {
 	*x = SendVerificationEmailResponse{}
-	mi := &file_protos_email_proto_msgTypes[33]
+	mi := &file_protos_email_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2517,7 +3352,7 @@ func (x *SendVerificationEmailResponse) String() string {
 func (*SendVerificationEmailResponse) ProtoMessage() {}
 
 func (x *SendVerificationEmailResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[33]
+	mi := &file_protos_email_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2530,7 +3365,7 @@ func (x *SendVerificationEmailResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SendVerificationEmailResponse.ProtoReflect.Descriptor instead.
 func (*SendVerificationEmailResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{33}
+	return file_protos_email_proto_rawDescGZIP(), []int{47}
 }
 
 func (x *SendVerificationEmailResponse) GetSuccess() bool {
@@ -2580,7 +3415,7 @@ type SendVerificationReminderRequest struct {
 
 func (x *SendVerificationReminderRequest) Reset() {
 	*x = SendVerificationReminderRequest{}
-	mi := &file_protos_email_proto_msgTypes[34]
+	mi := &file_protos_email_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2592,7 +3427,7 @@ func (x *SendVerificationReminderRequest) String() string {
 func (*SendVerificationReminderRequest) ProtoMessage() {}
 
 func (x *SendVerificationReminderRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[34]
+	mi := &file_protos_email_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2605,7 +3440,7 @@ func (x *SendVerificationReminderRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SendVerificationReminderRequest.ProtoReflect.Descriptor instead.
 func (*SendVerificationReminderRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{34}
+	return file_protos_email_proto_rawDescGZIP(), []int{48}
 }
 
 func (x *SendVerificationReminderRequest) GetUserId() string {
@@ -2649,7 +3484,7 @@ type SendVerificationReminderResponse struct {
 
 func (x *SendVerificationReminderResponse) Reset() {
 	*x = SendVerificationReminderResponse{}
-	mi := &file_protos_email_proto_msgTypes[35]
+	mi := &file_protos_email_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2661,7 +3496,7 @@ func (x *SendVerificationReminderResponse) String() string {
 func (*SendVerificationReminderResponse) ProtoMessage() {}
 
 func (x *SendVerificationReminderResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[35]
+	mi := &file_protos_email_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2674,7 +3509,7 @@ func (x *SendVerificationReminderResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SendVerificationReminderResponse.ProtoReflect.Descriptor instead.
 func (*SendVerificationReminderResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{35}
+	return file_protos_email_proto_rawDescGZIP(), []int{49}
 }
 
 func (x *SendVerificationReminderResponse) GetSuccess() bool {
@@ -2723,7 +3558,7 @@ type ValidatePinCodeRequest struct {
 
 func (x *ValidatePinCodeRequest) Reset() {
 	*x = ValidatePinCodeRequest{}
-	mi := &file_protos_email_proto_msgTypes[36]
+	mi := &file_protos_email_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2735,7 +3570,7 @@ func (x *ValidatePinCodeRequest) String() string {
 func (*ValidatePinCodeRequest) ProtoMessage() {}
 
 func (x *ValidatePinCodeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[36]
+	mi := &file_protos_email_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2748,7 +3583,7 @@ func (x *ValidatePinCodeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ValidatePinCodeRequest.ProtoReflect.Descriptor instead.
 func (*ValidatePinCodeRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{36}
+	return file_protos_email_proto_rawDescGZIP(), []int{50}
 }
 
 func (x *ValidatePinCodeRequest) GetUserId() string {
@@ -2783,7 +3618,7 @@ type ValidatePinCodeResponse struct {
 
 func (x *ValidatePinCodeResponse) Reset() {
 	*x = ValidatePinCodeResponse{}
-	mi := &file_protos_email_proto_msgTypes[37]
+	mi := &file_protos_email_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2795,7 +3630,7 @@ func (x *ValidatePinCodeResponse) String() string {
 func (*ValidatePinCodeResponse) ProtoMessage() {}
 
 func (x *ValidatePinCodeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[37]
+	mi := &file_protos_email_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2808,7 +3643,7 @@ func (x *ValidatePinCodeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ValidatePinCodeResponse.ProtoReflect.Descriptor instead.
 func (*ValidatePinCodeResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{37}
+	return file_protos_email_proto_rawDescGZIP(), []int{51}
 }
 
 func (x *ValidatePinCodeResponse) GetValid() bool {
@@ -2844,7 +3679,7 @@ type ResendVerificationEmailRequest struct {
 
 func (x *ResendVerificationEmailRequest) Reset() {
 	*x = ResendVerificationEmailRequest{}
-	mi := &file_protos_email_proto_msgTypes[38]
+	mi := &file_protos_email_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2856,7 +3691,7 @@ func (x *ResendVerificationEmailRequest) String() string {
 func (*ResendVerificationEmailRequest) ProtoMessage() {}
 
 func (x *ResendVerificationEmailRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[38]
+	mi := &file_protos_email_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2869,7 +3704,7 @@ func (x *ResendVerificationEmailRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResendVerificationEmailRequest.ProtoReflect.Descriptor instead.
 func (*ResendVerificationEmailRequest) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{38}
+	return file_protos_email_proto_rawDescGZIP(), []int{52}
 }
 
 func (x *ResendVerificationEmailRequest) GetUserId() string {
@@ -2913,7 +3748,7 @@ type ResendVerificationEmailResponse struct {
 
 func (x *ResendVerificationEmailResponse) Reset() {
 	*x = ResendVerificationEmailResponse{}
-	mi := &file_protos_email_proto_msgTypes[39]
+	mi := &file_protos_email_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2925,7 +3760,7 @@ func (x *ResendVerificationEmailResponse) String() string {
 func (*ResendVerificationEmailResponse) ProtoMessage() {}
 
 func (x *ResendVerificationEmailResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[39]
+	mi := &file_protos_email_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2938,7 +3773,7 @@ func (x *ResendVerificationEmailResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResendVerificationEmailResponse.ProtoReflect.Descriptor instead.
 func (*ResendVerificationEmailResponse) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{39}
+	return file_protos_email_proto_rawDescGZIP(), []int{53}
 }
 
 func (x *ResendVerificationEmailResponse) GetSuccess() bool {
@@ -2977,6 +3812,142 @@ func (x *ResendVerificationEmailResponse) GetExpiryTimestamp() int64 {
 }
 
 // Data structures
+type DeadLetter struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Job            *EmailJob              `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	FinalError     string                 `protobuf:"bytes,2,opt,name=final_error,json=finalError,proto3" json:"final_error,omitempty"`
+	Attempts       []*JobAttempt          `protobuf:"bytes,3,rep,name=attempts,proto3" json:"attempts,omitempty"`
+	DeadLetteredAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=dead_lettered_at,json=deadLetteredAt,proto3" json:"dead_lettered_at,omitempty"`
+	Payload        string                 `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"` // original job as JSON
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DeadLetter) Reset() {
+	*x = DeadLetter{}
+	mi := &file_protos_email_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeadLetter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadLetter) ProtoMessage() {}
+
+func (x *DeadLetter) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadLetter.ProtoReflect.Descriptor instead.
+func (*DeadLetter) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *DeadLetter) GetJob() *EmailJob {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+func (x *DeadLetter) GetFinalError() string {
+	if x != nil {
+		return x.FinalError
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetAttempts() []*JobAttempt {
+	if x != nil {
+		return x.Attempts
+	}
+	return nil
+}
+
+func (x *DeadLetter) GetDeadLetteredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeadLetteredAt
+	}
+	return nil
+}
+
+func (x *DeadLetter) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+type JobAttempt struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	FailedAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=failed_at,json=failedAt,proto3" json:"failed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobAttempt) Reset() {
+	*x = JobAttempt{}
+	mi := &file_protos_email_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobAttempt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobAttempt) ProtoMessage() {}
+
+func (x *JobAttempt) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_email_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobAttempt.ProtoReflect.Descriptor instead.
+func (*JobAttempt) Descriptor() ([]byte, []int) {
+	return file_protos_email_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *JobAttempt) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *JobAttempt) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *JobAttempt) GetFailedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FailedAt
+	}
+	return nil
+}
+
 type EmailJob struct {
 	state              protoimpl.MessageState `protogen:"open.v1"`
 	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -2998,13 +3969,15 @@ type EmailJob struct {
 	CreatedTimestamp   *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=created_timestamp,json=createdTimestamp,proto3" json:"created_timestamp,omitempty"`
 	UpdatedTimestamp   *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=updated_timestamp,json=updatedTimestamp,proto3" json:"updated_timestamp,omitempty"`
 	CompletedTimestamp *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=completed_timestamp,json=completedTimestamp,proto3" json:"completed_timestamp,omitempty"`
+	IdempotencyKey     string                 `protobuf:"bytes,20,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	Tenant             string                 `protobuf:"bytes,21,opt,name=tenant,proto3" json:"tenant,omitempty"`
 	unknownFields      protoimpl.UnknownFields
 	sizeCache          protoimpl.SizeCache
 }
 
 func (x *EmailJob) Reset() {
 	*x = EmailJob{}
-	mi := &file_protos_email_proto_msgTypes[40]
+	mi := &file_protos_email_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3016,7 +3989,7 @@ func (x *EmailJob) String() string {
 func (*EmailJob) ProtoMessage() {}
 
 func (x *EmailJob) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[40]
+	mi := &file_protos_email_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3029,7 +4002,7 @@ func (x *EmailJob) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EmailJob.ProtoReflect.Descriptor instead.
 func (*EmailJob) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{40}
+	return file_protos_email_proto_rawDescGZIP(), []int{56}
 }
 
 func (x *EmailJob) GetId() string {
@@ -3165,6 +4138,20 @@ func (x *EmailJob) GetCompletedTimestamp() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *EmailJob) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *EmailJob) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
 type EmailTemplate struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -3184,7 +4171,7 @@ type EmailTemplate struct {
 
 func (x *EmailTemplate) Reset() {
 	*x = EmailTemplate{}
-	mi := &file_protos_email_proto_msgTypes[41]
+	mi := &file_protos_email_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3196,7 +4183,7 @@ func (x *EmailTemplate) String() string {
 func (*EmailTemplate) ProtoMessage() {}
 
 func (x *EmailTemplate) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[41]
+	mi := &file_protos_email_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3209,7 +4196,7 @@ func (x *EmailTemplate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EmailTemplate.ProtoReflect.Descriptor instead.
 func (*EmailTemplate) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{41}
+	return file_protos_email_proto_rawDescGZIP(), []int{57}
 }
 
 func (x *EmailTemplate) GetId() string {
@@ -3309,7 +4296,7 @@ type EmailTracking struct {
 
 func (x *EmailTracking) Reset() {
 	*x = EmailTracking{}
-	mi := &file_protos_email_proto_msgTypes[42]
+	mi := &file_protos_email_proto_msgTypes[58]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3321,7 +4308,7 @@ func (x *EmailTracking) String() string {
 func (*EmailTracking) ProtoMessage() {}
 
 func (x *EmailTracking) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_email_proto_msgTypes[42]
+	mi := &file_protos_email_proto_msgTypes[58]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3334,7 +4321,7 @@ func (x *EmailTracking) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EmailTracking.ProtoReflect.Descriptor instead.
 func (*EmailTracking) Descriptor() ([]byte, []int) {
-	return file_protos_email_proto_rawDescGZIP(), []int{42}
+	return file_protos_email_proto_rawDescGZIP(), []int{58}
 }
 
 func (x *EmailTracking) GetId() int64 {
@@ -3425,7 +4412,7 @@ var File_protos_email_proto protoreflect.FileDescriptor
 
 const file_protos_email_proto_rawDesc = "" +
 	"\n" +
-	"\x12protos/email.proto\x12\x05email\x1a\x1fgoogle/protobuf/timestamp.proto\"\xbb\x05\n" +
+	"\x12protos/email.proto\x12\x05email\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb3\x06\n" +
 	"\x15CreateEmailJobRequest\x12\x19\n" +
 	"\bjob_type\x18\x01 \x01(\tR\ajobType\x12'\n" +
 	"\x0frecipient_email\x18\x02 \x01(\tR\x0erecipientEmail\x12\x0e\n" +
@@ -3444,20 +4431,34 @@ const file_protos_email_proto_rawDesc = "" +
 	"maxRetries\x12=\n" +
 	"\fscheduled_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\vscheduledAt\x12\x1d\n" +
 	"\n" +
-	"is_tracked\x18\x0e \x01(\bR\tisTracked\x1a<\n" +
+	"is_tracked\x18\x0e \x01(\bR\tisTracked\x12'\n" +
+	"\x0fidempotency_key\x18\x0f \x01(\tR\x0eidempotencyKey\x12\x16\n" +
+	"\x06tenant\x18\x10 \x01(\tR\x06tenant\x125\n" +
+	"\n" +
+	"recipients\x18\x11 \x03(\v2\x15.email.BatchRecipientR\n" +
+	"recipients\x1a<\n" +
 	"\x0eVariablesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a?\n" +
 	"\x11TemplateDataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa5\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb0\x01\n" +
+	"\x0eBatchRecipient\x12\x0e\n" +
+	"\x02to\x18\x01 \x03(\tR\x02to\x12\x0e\n" +
+	"\x02cc\x18\x02 \x03(\tR\x02cc\x12\x10\n" +
+	"\x03bcc\x18\x03 \x03(\tR\x03bcc\x123\n" +
+	"\x04data\x18\x04 \x03(\v2\x1f.email.BatchRecipient.DataEntryR\x04data\x1a7\n" +
+	"\tDataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc3\x01\n" +
 	"\x16CreateEmailJobResponse\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x18\n" +
 	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x03 \x01(\tR\amessage\x12\x1d\n" +
 	"\n" +
 	"is_tracked\x18\x04 \x01(\bR\tisTracked\x12!\n" +
-	"\x03job\x18\x05 \x01(\v2\x0f.email.EmailJobR\x03job\"+\n" +
+	"\x03job\x18\x05 \x01(\v2\x0f.email.EmailJobR\x03job\x12\x1c\n" +
+	"\tduplicate\x18\x06 \x01(\bR\tduplicate\"+\n" +
 	"\x12GetEmailJobRequest\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\x03R\x05jobId\"l\n" +
 	"\x13GetEmailJobResponse\x12\x18\n" +
@@ -3465,7 +4466,7 @@ const file_protos_email_proto_rawDesc = "" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12!\n" +
 	"\x03job\x18\x03 \x01(\v2\x0f.email.EmailJobR\x03job\",\n" +
 	"\x13GetJobStatusRequest\x12\x15\n" +
-	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\x86\x03\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\xca\x03\n" +
 	"\x14GetJobStatusResponse\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12(\n" +
 	"\x06status\x18\x02 \x01(\x0e2\x10.email.JobStatusR\x06status\x12\x1f\n" +
@@ -3478,7 +4479,9 @@ const file_protos_email_proto_rawDesc = "" +
 	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12=\n" +
 	"\fcompleted_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12\x18\n" +
 	"\asuccess\x18\b \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\t \x01(\tR\amessage\"\x8a\x01\n" +
+	"\amessage\x18\t \x01(\tR\amessage\x12B\n" +
+	"\x0fnext_attempt_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\rnextAttemptAt\"\x8a\x01\n" +
 	"\x1bUpdateEmailJobStatusRequest\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\x03R\x05jobId\x12\x16\n" +
 	"\x06status\x18\x02 \x01(\tR\x06status\x12#\n" +
@@ -3487,7 +4490,18 @@ const file_protos_email_proto_rawDesc = "" +
 	"\x1cUpdateEmailJobStatusResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12!\n" +
-	"\x03job\x18\x03 \x01(\v2\x0f.email.EmailJobR\x03job\"\xa2\x01\n" +
+	"\x03job\x18\x03 \x01(\v2\x0f.email.EmailJobR\x03job\".\n" +
+	"\x15CancelEmailJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"L\n" +
+	"\x16CancelEmailJobResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"q\n" +
+	"\x19RescheduleEmailJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12=\n" +
+	"\fscheduled_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vscheduledAt\"P\n" +
+	"\x1aRescheduleEmailJobResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xa2\x01\n" +
 	"\x14ListEmailJobsRequest\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x19\n" +
 	"\bjob_type\x18\x02 \x01(\tR\ajobType\x12\x17\n" +
@@ -3615,7 +4629,41 @@ const file_protos_email_proto_rawDesc = "" +
 	"\x1bUpdateEmailTrackingResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x120\n" +
-	"\btracking\x18\x03 \x01(\v2\x14.email.EmailTrackingR\btracking\"\x0f\n" +
+	"\btracking\x18\x03 \x01(\v2\x14.email.EmailTrackingR\btracking\"\xc2\x01\n" +
+	"\x10DeadLetterFilter\x12#\n" +
+	"\rtemplate_name\x18\x01 \x01(\tR\ftemplateName\x12%\n" +
+	"\x0eerror_contains\x18\x02 \x01(\tR\rerrorContains\x120\n" +
+	"\x05since\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x120\n" +
+	"\x05until\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x05until\"w\n" +
+	"\x16ListDeadLettersRequest\x12/\n" +
+	"\x06filter\x18\x01 \x01(\v2\x17.email.DeadLetterFilterR\x06filter\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\"\x83\x01\n" +
+	"\x17ListDeadLettersResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x124\n" +
+	"\fdead_letters\x18\x03 \x03(\v2\x11.email.DeadLetterR\vdeadLetters\"-\n" +
+	"\x14GetDeadLetterRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\x7f\n" +
+	"\x15GetDeadLetterResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x122\n" +
+	"\vdead_letter\x18\x03 \x01(\v2\x11.email.DeadLetterR\n" +
+	"deadLetter\"d\n" +
+	"\x18ReplayDeadLettersRequest\x12\x17\n" +
+	"\ajob_ids\x18\x01 \x03(\tR\x06jobIds\x12/\n" +
+	"\x06filter\x18\x02 \x01(\v2\x17.email.DeadLetterFilterR\x06filter\"k\n" +
+	"\x19ReplayDeadLettersResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1a\n" +
+	"\breplayed\x18\x03 \x01(\x05R\breplayed\"c\n" +
+	"\x17PurgeDeadLettersRequest\x12\x17\n" +
+	"\ajob_ids\x18\x01 \x03(\tR\x06jobIds\x12/\n" +
+	"\x06filter\x18\x02 \x01(\v2\x17.email.DeadLetterFilterR\x06filter\"f\n" +
+	"\x18PurgeDeadLettersResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
+	"\x06purged\x18\x03 \x01(\x05R\x06purged\"\x0f\n" +
 	"\rHealthRequest\"`\n" +
 	"\x0eHealthResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1c\n" +
@@ -3680,7 +4728,20 @@ const file_protos_email_proto_rawDesc = "" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x15\n" +
 	"\x06job_id\x18\x03 \x01(\tR\x05jobId\x12\x19\n" +
 	"\bpin_code\x18\x04 \x01(\tR\apinCode\x12)\n" +
-	"\x10expiry_timestamp\x18\x05 \x01(\x03R\x0fexpiryTimestamp\"\xa8\x06\n" +
+	"\x10expiry_timestamp\x18\x05 \x01(\x03R\x0fexpiryTimestamp\"\xdf\x01\n" +
+	"\n" +
+	"DeadLetter\x12!\n" +
+	"\x03job\x18\x01 \x01(\v2\x0f.email.EmailJobR\x03job\x12\x1f\n" +
+	"\vfinal_error\x18\x02 \x01(\tR\n" +
+	"finalError\x12-\n" +
+	"\battempts\x18\x03 \x03(\v2\x11.email.JobAttemptR\battempts\x12D\n" +
+	"\x10dead_lettered_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x0edeadLetteredAt\x12\x18\n" +
+	"\apayload\x18\x05 \x01(\tR\apayload\"s\n" +
+	"\n" +
+	"JobAttempt\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x127\n" +
+	"\tfailed_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bfailedAt\"\xe9\x06\n" +
 	"\bEmailJob\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x0e\n" +
 	"\x02to\x18\x02 \x03(\tR\x02to\x12\x0e\n" +
@@ -3706,7 +4767,9 @@ const file_protos_email_proto_rawDesc = "" +
 	"updated_at\x18\x10 \x01(\tR\tupdatedAt\x12G\n" +
 	"\x11created_timestamp\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\x10createdTimestamp\x12G\n" +
 	"\x11updated_timestamp\x18\x12 \x01(\v2\x1a.google.protobuf.TimestampR\x10updatedTimestamp\x12K\n" +
-	"\x13completed_timestamp\x18\x13 \x01(\v2\x1a.google.protobuf.TimestampR\x12completedTimestamp\x1a<\n" +
+	"\x13completed_timestamp\x18\x13 \x01(\v2\x1a.google.protobuf.TimestampR\x12completedTimestamp\x12'\n" +
+	"\x0fidempotency_key\x18\x14 \x01(\tR\x0eidempotencyKey\x12\x16\n" +
+	"\x06tenant\x18\x15 \x01(\tR\x06tenant\x1a<\n" +
 	"\x0eVariablesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x85\x04\n" +
@@ -3759,14 +4822,15 @@ const file_protos_email_proto_rawDesc = "" +
 	"\fPRIORITY_LOW\x10\x01\x12\x13\n" +
 	"\x0fPRIORITY_NORMAL\x10\x02\x12\x11\n" +
 	"\rPRIORITY_HIGH\x10\x03\x12\x13\n" +
-	"\x0fPRIORITY_URGENT\x10\x042\xfb\n" +
-	"\n" +
+	"\x0fPRIORITY_URGENT\x10\x042\xf0\x0e\n" +
 	"\fEmailService\x12M\n" +
 	"\x0eCreateEmailJob\x12\x1c.email.CreateEmailJobRequest\x1a\x1d.email.CreateEmailJobResponse\x12T\n" +
 	"\x15CreateTrackedEmailJob\x12\x1c.email.CreateEmailJobRequest\x1a\x1d.email.CreateEmailJobResponse\x12D\n" +
 	"\vGetEmailJob\x12\x19.email.GetEmailJobRequest\x1a\x1a.email.GetEmailJobResponse\x12G\n" +
 	"\fGetJobStatus\x12\x1a.email.GetJobStatusRequest\x1a\x1b.email.GetJobStatusResponse\x12_\n" +
-	"\x14UpdateEmailJobStatus\x12\".email.UpdateEmailJobStatusRequest\x1a#.email.UpdateEmailJobStatusResponse\x12J\n" +
+	"\x14UpdateEmailJobStatus\x12\".email.UpdateEmailJobStatusRequest\x1a#.email.UpdateEmailJobStatusResponse\x12M\n" +
+	"\x0eCancelEmailJob\x12\x1c.email.CancelEmailJobRequest\x1a\x1d.email.CancelEmailJobResponse\x12Y\n" +
+	"\x12RescheduleEmailJob\x12 .email.RescheduleEmailJobRequest\x1a!.email.RescheduleEmailJobResponse\x12J\n" +
 	"\rListEmailJobs\x12\x1b.email.ListEmailJobsRequest\x1a\x1c.email.ListEmailJobsResponse\x12D\n" +
 	"\vGetJobStats\x12\x19.email.GetJobStatsRequest\x1a\x1a.email.GetJobStatsResponse\x12J\n" +
 	"\rGetQueueStats\x12\x1b.email.GetQueueStatsRequest\x1a\x1c.email.GetQueueStatsResponse\x12S\n" +
@@ -3776,7 +4840,11 @@ const file_protos_email_proto_rawDesc = "" +
 	"\x13UpdateEmailTemplate\x12!.email.UpdateEmailTemplateRequest\x1a\".email.UpdateEmailTemplateResponse\x12\\\n" +
 	"\x13DeleteEmailTemplate\x12!.email.DeleteEmailTemplateRequest\x1a\".email.DeleteEmailTemplateResponse\x12S\n" +
 	"\x10GetEmailTracking\x12\x1e.email.GetEmailTrackingRequest\x1a\x1f.email.GetEmailTrackingResponse\x12\\\n" +
-	"\x13UpdateEmailTracking\x12!.email.UpdateEmailTrackingRequest\x1a\".email.UpdateEmailTrackingResponse\x125\n" +
+	"\x13UpdateEmailTracking\x12!.email.UpdateEmailTrackingRequest\x1a\".email.UpdateEmailTrackingResponse\x12P\n" +
+	"\x0fListDeadLetters\x12\x1d.email.ListDeadLettersRequest\x1a\x1e.email.ListDeadLettersResponse\x12J\n" +
+	"\rGetDeadLetter\x12\x1b.email.GetDeadLetterRequest\x1a\x1c.email.GetDeadLetterResponse\x12V\n" +
+	"\x11ReplayDeadLetters\x12\x1f.email.ReplayDeadLettersRequest\x1a .email.ReplayDeadLettersResponse\x12S\n" +
+	"\x10PurgeDeadLetters\x12\x1e.email.PurgeDeadLettersRequest\x1a\x1f.email.PurgeDeadLettersResponse\x125\n" +
 	"\x06Health\x12\x14.email.HealthRequest\x1a\x15.email.HealthResponse\x12D\n" +
 	"\vHealthCheck\x12\x19.email.HealthCheckRequest\x1a\x1a.email.HealthCheckResponse2\xa7\x03\n" +
 	"\x18EmailVerificationService\x12b\n" +
@@ -3798,141 +4866,185 @@ func file_protos_email_proto_rawDescGZIP() []byte {
 }
 
 var file_protos_email_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_protos_email_proto_msgTypes = make([]protoimpl.MessageInfo, 50)
+var file_protos_email_proto_msgTypes = make([]protoimpl.MessageInfo, 67)
 var file_protos_email_proto_goTypes = []any{
 	(JobStatus)(0),                           // 0: email.JobStatus
 	(JobPriority)(0),                         // 1: email.JobPriority
 	(*CreateEmailJobRequest)(nil),            // 2: email.CreateEmailJobRequest
-	(*CreateEmailJobResponse)(nil),           // 3: email.CreateEmailJobResponse
-	(*GetEmailJobRequest)(nil),               // 4: email.GetEmailJobRequest
-	(*GetEmailJobResponse)(nil),              // 5: email.GetEmailJobResponse
-	(*GetJobStatusRequest)(nil),              // 6: email.GetJobStatusRequest
-	(*GetJobStatusResponse)(nil),             // 7: email.GetJobStatusResponse
-	(*UpdateEmailJobStatusRequest)(nil),      // 8: email.UpdateEmailJobStatusRequest
-	(*UpdateEmailJobStatusResponse)(nil),     // 9: email.UpdateEmailJobStatusResponse
-	(*ListEmailJobsRequest)(nil),             // 10: email.ListEmailJobsRequest
-	(*ListEmailJobsResponse)(nil),            // 11: email.ListEmailJobsResponse
-	(*GetJobStatsRequest)(nil),               // 12: email.GetJobStatsRequest
-	(*GetJobStatsResponse)(nil),              // 13: email.GetJobStatsResponse
-	(*GetQueueStatsRequest)(nil),             // 14: email.GetQueueStatsRequest
-	(*GetQueueStatsResponse)(nil),            // 15: email.GetQueueStatsResponse
-	(*GetEmailTemplateRequest)(nil),          // 16: email.GetEmailTemplateRequest
-	(*GetEmailTemplateResponse)(nil),         // 17: email.GetEmailTemplateResponse
-	(*ListEmailTemplatesRequest)(nil),        // 18: email.ListEmailTemplatesRequest
-	(*ListEmailTemplatesResponse)(nil),       // 19: email.ListEmailTemplatesResponse
-	(*CreateEmailTemplateRequest)(nil),       // 20: email.CreateEmailTemplateRequest
-	(*CreateEmailTemplateResponse)(nil),      // 21: email.CreateEmailTemplateResponse
-	(*UpdateEmailTemplateRequest)(nil),       // 22: email.UpdateEmailTemplateRequest
-	(*UpdateEmailTemplateResponse)(nil),      // 23: email.UpdateEmailTemplateResponse
-	(*DeleteEmailTemplateRequest)(nil),       // 24: email.DeleteEmailTemplateRequest
-	(*DeleteEmailTemplateResponse)(nil),      // 25: email.DeleteEmailTemplateResponse
-	(*GetEmailTrackingRequest)(nil),          // 26: email.GetEmailTrackingRequest
-	(*GetEmailTrackingResponse)(nil),         // 27: email.GetEmailTrackingResponse
-	(*UpdateEmailTrackingRequest)(nil),       // 28: email.UpdateEmailTrackingRequest
-	(*UpdateEmailTrackingResponse)(nil),      // 29: email.UpdateEmailTrackingResponse
-	(*HealthRequest)(nil),                    // 30: email.HealthRequest
-	(*HealthResponse)(nil),                   // 31: email.HealthResponse
-	(*HealthCheckRequest)(nil),               // 32: email.HealthCheckRequest
-	(*HealthCheckResponse)(nil),              // 33: email.HealthCheckResponse
-	(*SendVerificationEmailRequest)(nil),     // 34: email.SendVerificationEmailRequest
-	(*SendVerificationEmailResponse)(nil),    // 35: email.SendVerificationEmailResponse
-	(*SendVerificationReminderRequest)(nil),  // 36: email.SendVerificationReminderRequest
-	(*SendVerificationReminderResponse)(nil), // 37: email.SendVerificationReminderResponse
-	(*ValidatePinCodeRequest)(nil),           // 38: email.ValidatePinCodeRequest
-	(*ValidatePinCodeResponse)(nil),          // 39: email.ValidatePinCodeResponse
-	(*ResendVerificationEmailRequest)(nil),   // 40: email.ResendVerificationEmailRequest
-	(*ResendVerificationEmailResponse)(nil),  // 41: email.ResendVerificationEmailResponse
-	(*EmailJob)(nil),                         // 42: email.EmailJob
-	(*EmailTemplate)(nil),                    // 43: email.EmailTemplate
-	(*EmailTracking)(nil),                    // 44: email.EmailTracking
-	nil,                                      // 45: email.CreateEmailJobRequest.VariablesEntry
-	nil,                                      // 46: email.CreateEmailJobRequest.TemplateDataEntry
-	nil,                                      // 47: email.CreateEmailTemplateRequest.VariablesMapEntry
-	nil,                                      // 48: email.UpdateEmailTemplateRequest.VariablesMapEntry
-	nil,                                      // 49: email.HealthCheckResponse.ProvidersHealthyEntry
-	nil,                                      // 50: email.EmailJob.VariablesEntry
-	nil,                                      // 51: email.EmailTemplate.VariablesEntry
-	(*timestamppb.Timestamp)(nil),            // 52: google.protobuf.Timestamp
+	(*BatchRecipient)(nil),                   // 3: email.BatchRecipient
+	(*CreateEmailJobResponse)(nil),           // 4: email.CreateEmailJobResponse
+	(*GetEmailJobRequest)(nil),               // 5: email.GetEmailJobRequest
+	(*GetEmailJobResponse)(nil),              // 6: email.GetEmailJobResponse
+	(*GetJobStatusRequest)(nil),              // 7: email.GetJobStatusRequest
+	(*GetJobStatusResponse)(nil),             // 8: email.GetJobStatusResponse
+	(*UpdateEmailJobStatusRequest)(nil),      // 9: email.UpdateEmailJobStatusRequest
+	(*UpdateEmailJobStatusResponse)(nil),     // 10: email.UpdateEmailJobStatusResponse
+	(*CancelEmailJobRequest)(nil),            // 11: email.CancelEmailJobRequest
+	(*CancelEmailJobResponse)(nil),           // 12: email.CancelEmailJobResponse
+	(*RescheduleEmailJobRequest)(nil),        // 13: email.RescheduleEmailJobRequest
+	(*RescheduleEmailJobResponse)(nil),       // 14: email.RescheduleEmailJobResponse
+	(*ListEmailJobsRequest)(nil),             // 15: email.ListEmailJobsRequest
+	(*ListEmailJobsResponse)(nil),            // 16: email.ListEmailJobsResponse
+	(*GetJobStatsRequest)(nil),               // 17: email.GetJobStatsRequest
+	(*GetJobStatsResponse)(nil),              // 18: email.GetJobStatsResponse
+	(*GetQueueStatsRequest)(nil),             // 19: email.GetQueueStatsRequest
+	(*GetQueueStatsResponse)(nil),            // 20: email.GetQueueStatsResponse
+	(*GetEmailTemplateRequest)(nil),          // 21: email.GetEmailTemplateRequest
+	(*GetEmailTemplateResponse)(nil),         // 22: email.GetEmailTemplateResponse
+	(*ListEmailTemplatesRequest)(nil),        // 23: email.ListEmailTemplatesRequest
+	(*ListEmailTemplatesResponse)(nil),       // 24: email.ListEmailTemplatesResponse
+	(*CreateEmailTemplateRequest)(nil),       // 25: email.CreateEmailTemplateRequest
+	(*CreateEmailTemplateResponse)(nil),      // 26: email.CreateEmailTemplateResponse
+	(*UpdateEmailTemplateRequest)(nil),       // 27: email.UpdateEmailTemplateRequest
+	(*UpdateEmailTemplateResponse)(nil),      // 28: email.UpdateEmailTemplateResponse
+	(*DeleteEmailTemplateRequest)(nil),       // 29: email.DeleteEmailTemplateRequest
+	(*DeleteEmailTemplateResponse)(nil),      // 30: email.DeleteEmailTemplateResponse
+	(*GetEmailTrackingRequest)(nil),          // 31: email.GetEmailTrackingRequest
+	(*GetEmailTrackingResponse)(nil),         // 32: email.GetEmailTrackingResponse
+	(*UpdateEmailTrackingRequest)(nil),       // 33: email.UpdateEmailTrackingRequest
+	(*UpdateEmailTrackingResponse)(nil),      // 34: email.UpdateEmailTrackingResponse
+	(*DeadLetterFilter)(nil),                 // 35: email.DeadLetterFilter
+	(*ListDeadLettersRequest)(nil),           // 36: email.ListDeadLettersRequest
+	(*ListDeadLettersResponse)(nil),          // 37: email.ListDeadLettersResponse
+	(*GetDeadLetterRequest)(nil),             // 38: email.GetDeadLetterRequest
+	(*GetDeadLetterResponse)(nil),            // 39: email.GetDeadLetterResponse
+	(*ReplayDeadLettersRequest)(nil),         // 40: email.ReplayDeadLettersRequest
+	(*ReplayDeadLettersResponse)(nil),        // 41: email.ReplayDeadLettersResponse
+	(*PurgeDeadLettersRequest)(nil),          // 42: email.PurgeDeadLettersRequest
+	(*PurgeDeadLettersResponse)(nil),         // 43: email.PurgeDeadLettersResponse
+	(*HealthRequest)(nil),                    // 44: email.HealthRequest
+	(*HealthResponse)(nil),                   // 45: email.HealthResponse
+	(*HealthCheckRequest)(nil),               // 46: email.HealthCheckRequest
+	(*HealthCheckResponse)(nil),              // 47: email.HealthCheckResponse
+	(*SendVerificationEmailRequest)(nil),     // 48: email.SendVerificationEmailRequest
+	(*SendVerificationEmailResponse)(nil),    // 49: email.SendVerificationEmailResponse
+	(*SendVerificationReminderRequest)(nil),  // 50: email.SendVerificationReminderRequest
+	(*SendVerificationReminderResponse)(nil), // 51: email.SendVerificationReminderResponse
+	(*ValidatePinCodeRequest)(nil),           // 52: email.ValidatePinCodeRequest
+	(*ValidatePinCodeResponse)(nil),          // 53: email.ValidatePinCodeResponse
+	(*ResendVerificationEmailRequest)(nil),   // 54: email.ResendVerificationEmailRequest
+	(*ResendVerificationEmailResponse)(nil),  // 55: email.ResendVerificationEmailResponse
+	(*DeadLetter)(nil),                       // 56: email.DeadLetter
+	(*JobAttempt)(nil),                       // 57: email.JobAttempt
+	(*EmailJob)(nil),                         // 58: email.EmailJob
+	(*EmailTemplate)(nil),                    // 59: email.EmailTemplate
+	(*EmailTracking)(nil),                    // 60: email.EmailTracking
+	nil,                                      // 61: email.CreateEmailJobRequest.VariablesEntry
+	nil,                                      // 62: email.CreateEmailJobRequest.TemplateDataEntry
+	nil,                                      // 63: email.BatchRecipient.DataEntry
+	nil,                                      // 64: email.CreateEmailTemplateRequest.VariablesMapEntry
+	nil,                                      // 65: email.UpdateEmailTemplateRequest.VariablesMapEntry
+	nil,                                      // 66: email.HealthCheckResponse.ProvidersHealthyEntry
+	nil,                                      // 67: email.EmailJob.VariablesEntry
+	nil,                                      // 68: email.EmailTemplate.VariablesEntry
+	(*timestamppb.Timestamp)(nil),            // 69: google.protobuf.Timestamp
 }
 var file_protos_email_proto_depIdxs = []int32{
-	45, // 0: email.CreateEmailJobRequest.variables:type_name -> email.CreateEmailJobRequest.VariablesEntry
-	46, // 1: email.CreateEmailJobRequest.template_data:type_name -> email.CreateEmailJobRequest.TemplateDataEntry
+	61, // 0: email.CreateEmailJobRequest.variables:type_name -> email.CreateEmailJobRequest.VariablesEntry
+	62, // 1: email.CreateEmailJobRequest.template_data:type_name -> email.CreateEmailJobRequest.TemplateDataEntry
 	1,  // 2: email.CreateEmailJobRequest.priority:type_name -> email.JobPriority
-	52, // 3: email.CreateEmailJobRequest.scheduled_at:type_name -> google.protobuf.Timestamp
-	42, // 4: email.CreateEmailJobResponse.job:type_name -> email.EmailJob
-	42, // 5: email.GetEmailJobResponse.job:type_name -> email.EmailJob
-	0,  // 6: email.GetJobStatusResponse.status:type_name -> email.JobStatus
-	52, // 7: email.GetJobStatusResponse.created_at:type_name -> google.protobuf.Timestamp
-	52, // 8: email.GetJobStatusResponse.updated_at:type_name -> google.protobuf.Timestamp
-	52, // 9: email.GetJobStatusResponse.completed_at:type_name -> google.protobuf.Timestamp
-	42, // 10: email.UpdateEmailJobStatusResponse.job:type_name -> email.EmailJob
-	42, // 11: email.ListEmailJobsResponse.jobs:type_name -> email.EmailJob
-	43, // 12: email.GetEmailTemplateResponse.template:type_name -> email.EmailTemplate
-	43, // 13: email.ListEmailTemplatesResponse.templates:type_name -> email.EmailTemplate
-	47, // 14: email.CreateEmailTemplateRequest.variables_map:type_name -> email.CreateEmailTemplateRequest.VariablesMapEntry
-	43, // 15: email.CreateEmailTemplateResponse.template:type_name -> email.EmailTemplate
-	48, // 16: email.UpdateEmailTemplateRequest.variables_map:type_name -> email.UpdateEmailTemplateRequest.VariablesMapEntry
-	43, // 17: email.UpdateEmailTemplateResponse.template:type_name -> email.EmailTemplate
-	44, // 18: email.GetEmailTrackingResponse.tracking:type_name -> email.EmailTracking
-	44, // 19: email.UpdateEmailTrackingResponse.tracking:type_name -> email.EmailTracking
-	52, // 20: email.HealthCheckResponse.timestamp:type_name -> google.protobuf.Timestamp
-	49, // 21: email.HealthCheckResponse.providers_healthy:type_name -> email.HealthCheckResponse.ProvidersHealthyEntry
-	50, // 22: email.EmailJob.variables:type_name -> email.EmailJob.VariablesEntry
-	0,  // 23: email.EmailJob.status:type_name -> email.JobStatus
-	1,  // 24: email.EmailJob.priority:type_name -> email.JobPriority
-	52, // 25: email.EmailJob.created_timestamp:type_name -> google.protobuf.Timestamp
-	52, // 26: email.EmailJob.updated_timestamp:type_name -> google.protobuf.Timestamp
-	52, // 27: email.EmailJob.completed_timestamp:type_name -> google.protobuf.Timestamp
-	51, // 28: email.EmailTemplate.variables:type_name -> email.EmailTemplate.VariablesEntry
-	52, // 29: email.EmailTemplate.created_timestamp:type_name -> google.protobuf.Timestamp
-	52, // 30: email.EmailTemplate.updated_timestamp:type_name -> google.protobuf.Timestamp
-	2,  // 31: email.EmailService.CreateEmailJob:input_type -> email.CreateEmailJobRequest
-	2,  // 32: email.EmailService.CreateTrackedEmailJob:input_type -> email.CreateEmailJobRequest
-	4,  // 33: email.EmailService.GetEmailJob:input_type -> email.GetEmailJobRequest
-	6,  // 34: email.EmailService.GetJobStatus:input_type -> email.GetJobStatusRequest
-	8,  // 35: email.EmailService.UpdateEmailJobStatus:input_type -> email.UpdateEmailJobStatusRequest
-	10, // 36: email.EmailService.ListEmailJobs:input_type -> email.ListEmailJobsRequest
-	12, // 37: email.EmailService.GetJobStats:input_type -> email.GetJobStatsRequest
-	14, // 38: email.EmailService.GetQueueStats:input_type -> email.GetQueueStatsRequest
-	16, // 39: email.EmailService.GetEmailTemplate:input_type -> email.GetEmailTemplateRequest
-	18, // 40: email.EmailService.ListEmailTemplates:input_type -> email.ListEmailTemplatesRequest
-	20, // 41: email.EmailService.CreateEmailTemplate:input_type -> email.CreateEmailTemplateRequest
-	22, // 42: email.EmailService.UpdateEmailTemplate:input_type -> email.UpdateEmailTemplateRequest
-	24, // 43: email.EmailService.DeleteEmailTemplate:input_type -> email.DeleteEmailTemplateRequest
-	26, // 44: email.EmailService.GetEmailTracking:input_type -> email.GetEmailTrackingRequest
-	28, // 45: email.EmailService.UpdateEmailTracking:input_type -> email.UpdateEmailTrackingRequest
-	30, // 46: email.EmailService.Health:input_type -> email.HealthRequest
-	32, // 47: email.EmailService.HealthCheck:input_type -> email.HealthCheckRequest
-	34, // 48: email.EmailVerificationService.SendVerificationEmail:input_type -> email.SendVerificationEmailRequest
-	36, // 49: email.EmailVerificationService.SendVerificationReminder:input_type -> email.SendVerificationReminderRequest
-	38, // 50: email.EmailVerificationService.ValidatePinCode:input_type -> email.ValidatePinCodeRequest
-	40, // 51: email.EmailVerificationService.ResendVerificationEmail:input_type -> email.ResendVerificationEmailRequest
-	3,  // 52: email.EmailService.CreateEmailJob:output_type -> email.CreateEmailJobResponse
-	3,  // 53: email.EmailService.CreateTrackedEmailJob:output_type -> email.CreateEmailJobResponse
-	5,  // 54: email.EmailService.GetEmailJob:output_type -> email.GetEmailJobResponse
-	7,  // 55: email.EmailService.GetJobStatus:output_type -> email.GetJobStatusResponse
-	9,  // 56: email.EmailService.UpdateEmailJobStatus:output_type -> email.UpdateEmailJobStatusResponse
-	11, // 57: email.EmailService.ListEmailJobs:output_type -> email.ListEmailJobsResponse
-	13, // 58: email.EmailService.GetJobStats:output_type -> email.GetJobStatsResponse
-	15, // 59: email.EmailService.GetQueueStats:output_type -> email.GetQueueStatsResponse
-	17, // 60: email.EmailService.GetEmailTemplate:output_type -> email.GetEmailTemplateResponse
-	19, // 61: email.EmailService.ListEmailTemplates:output_type -> email.ListEmailTemplatesResponse
-	21, // 62: email.EmailService.CreateEmailTemplate:output_type -> email.CreateEmailTemplateResponse
-	23, // 63: email.EmailService.UpdateEmailTemplate:output_type -> email.UpdateEmailTemplateResponse
-	25, // 64: email.EmailService.DeleteEmailTemplate:output_type -> email.DeleteEmailTemplateResponse
-	27, // 65: email.EmailService.GetEmailTracking:output_type -> email.GetEmailTrackingResponse
-	29, // 66: email.EmailService.UpdateEmailTracking:output_type -> email.UpdateEmailTrackingResponse
-	31, // 67: email.EmailService.Health:output_type -> email.HealthResponse
-	33, // 68: email.EmailService.HealthCheck:output_type -> email.HealthCheckResponse
-	35, // 69: email.EmailVerificationService.SendVerificationEmail:output_type -> email.SendVerificationEmailResponse
-	37, // 70: email.EmailVerificationService.SendVerificationReminder:output_type -> email.SendVerificationReminderResponse
-	39, // 71: email.EmailVerificationService.ValidatePinCode:output_type -> email.ValidatePinCodeResponse
-	41, // 72: email.EmailVerificationService.ResendVerificationEmail:output_type -> email.ResendVerificationEmailResponse
-	52, // [52:73] is the sub-list for method output_type
-	31, // [31:52] is the sub-list for method input_type
-	31, // [31:31] is the sub-list for extension type_name
-	31, // [31:31] is the sub-list for extension extendee
-	0,  // [0:31] is the sub-list for field type_name
+	69, // 3: email.CreateEmailJobRequest.scheduled_at:type_name -> google.protobuf.Timestamp
+	3,  // 4: email.CreateEmailJobRequest.recipients:type_name -> email.BatchRecipient
+	63, // 5: email.BatchRecipient.data:type_name -> email.BatchRecipient.DataEntry
+	58, // 6: email.CreateEmailJobResponse.job:type_name -> email.EmailJob
+	58, // 7: email.GetEmailJobResponse.job:type_name -> email.EmailJob
+	0,  // 8: email.GetJobStatusResponse.status:type_name -> email.JobStatus
+	69, // 9: email.GetJobStatusResponse.created_at:type_name -> google.protobuf.Timestamp
+	69, // 10: email.GetJobStatusResponse.updated_at:type_name -> google.protobuf.Timestamp
+	69, // 11: email.GetJobStatusResponse.completed_at:type_name -> google.protobuf.Timestamp
+	69, // 12: email.GetJobStatusResponse.next_attempt_at:type_name -> google.protobuf.Timestamp
+	58, // 13: email.UpdateEmailJobStatusResponse.job:type_name -> email.EmailJob
+	69, // 14: email.RescheduleEmailJobRequest.scheduled_at:type_name -> google.protobuf.Timestamp
+	58, // 15: email.ListEmailJobsResponse.jobs:type_name -> email.EmailJob
+	59, // 16: email.GetEmailTemplateResponse.template:type_name -> email.EmailTemplate
+	59, // 17: email.ListEmailTemplatesResponse.templates:type_name -> email.EmailTemplate
+	64, // 18: email.CreateEmailTemplateRequest.variables_map:type_name -> email.CreateEmailTemplateRequest.VariablesMapEntry
+	59, // 19: email.CreateEmailTemplateResponse.template:type_name -> email.EmailTemplate
+	65, // 20: email.UpdateEmailTemplateRequest.variables_map:type_name -> email.UpdateEmailTemplateRequest.VariablesMapEntry
+	59, // 21: email.UpdateEmailTemplateResponse.template:type_name -> email.EmailTemplate
+	60, // 22: email.GetEmailTrackingResponse.tracking:type_name -> email.EmailTracking
+	60, // 23: email.UpdateEmailTrackingResponse.tracking:type_name -> email.EmailTracking
+	69, // 24: email.DeadLetterFilter.since:type_name -> google.protobuf.Timestamp
+	69, // 25: email.DeadLetterFilter.until:type_name -> google.protobuf.Timestamp
+	35, // 26: email.ListDeadLettersRequest.filter:type_name -> email.DeadLetterFilter
+	56, // 27: email.ListDeadLettersResponse.dead_letters:type_name -> email.DeadLetter
+	56, // 28: email.GetDeadLetterResponse.dead_letter:type_name -> email.DeadLetter
+	35, // 29: email.ReplayDeadLettersRequest.filter:type_name -> email.DeadLetterFilter
+	35, // 30: email.PurgeDeadLettersRequest.filter:type_name -> email.DeadLetterFilter
+	69, // 31: email.HealthCheckResponse.timestamp:type_name -> google.protobuf.Timestamp
+	66, // 32: email.HealthCheckResponse.providers_healthy:type_name -> email.HealthCheckResponse.ProvidersHealthyEntry
+	58, // 33: email.DeadLetter.job:type_name -> email.EmailJob
+	57, // 34: email.DeadLetter.attempts:type_name -> email.JobAttempt
+	69, // 35: email.DeadLetter.dead_lettered_at:type_name -> google.protobuf.Timestamp
+	69, // 36: email.JobAttempt.failed_at:type_name -> google.protobuf.Timestamp
+	67, // 37: email.EmailJob.variables:type_name -> email.EmailJob.VariablesEntry
+	0,  // 38: email.EmailJob.status:type_name -> email.JobStatus
+	1,  // 39: email.EmailJob.priority:type_name -> email.JobPriority
+	69, // 40: email.EmailJob.created_timestamp:type_name -> google.protobuf.Timestamp
+	69, // 41: email.EmailJob.updated_timestamp:type_name -> google.protobuf.Timestamp
+	69, // 42: email.EmailJob.completed_timestamp:type_name -> google.protobuf.Timestamp
+	68, // 43: email.EmailTemplate.variables:type_name -> email.EmailTemplate.VariablesEntry
+	69, // 44: email.EmailTemplate.created_timestamp:type_name -> google.protobuf.Timestamp
+	69, // 45: email.EmailTemplate.updated_timestamp:type_name -> google.protobuf.Timestamp
+	2,  // 46: email.EmailService.CreateEmailJob:input_type -> email.CreateEmailJobRequest
+	2,  // 47: email.EmailService.CreateTrackedEmailJob:input_type -> email.CreateEmailJobRequest
+	5,  // 48: email.EmailService.GetEmailJob:input_type -> email.GetEmailJobRequest
+	7,  // 49: email.EmailService.GetJobStatus:input_type -> email.GetJobStatusRequest
+	9,  // 50: email.EmailService.UpdateEmailJobStatus:input_type -> email.UpdateEmailJobStatusRequest
+	11, // 51: email.EmailService.CancelEmailJob:input_type -> email.CancelEmailJobRequest
+	13, // 52: email.EmailService.RescheduleEmailJob:input_type -> email.RescheduleEmailJobRequest
+	15, // 53: email.EmailService.ListEmailJobs:input_type -> email.ListEmailJobsRequest
+	17, // 54: email.EmailService.GetJobStats:input_type -> email.GetJobStatsRequest
+	19, // 55: email.EmailService.GetQueueStats:input_type -> email.GetQueueStatsRequest
+	21, // 56: email.EmailService.GetEmailTemplate:input_type -> email.GetEmailTemplateRequest
+	23, // 57: email.EmailService.ListEmailTemplates:input_type -> email.ListEmailTemplatesRequest
+	25, // 58: email.EmailService.CreateEmailTemplate:input_type -> email.CreateEmailTemplateRequest
+	27, // 59: email.EmailService.UpdateEmailTemplate:input_type -> email.UpdateEmailTemplateRequest
+	29, // 60: email.EmailService.DeleteEmailTemplate:input_type -> email.DeleteEmailTemplateRequest
+	31, // 61: email.EmailService.GetEmailTracking:input_type -> email.GetEmailTrackingRequest
+	33, // 62: email.EmailService.UpdateEmailTracking:input_type -> email.UpdateEmailTrackingRequest
+	36, // 63: email.EmailService.ListDeadLetters:input_type -> email.ListDeadLettersRequest
+	38, // 64: email.EmailService.GetDeadLetter:input_type -> email.GetDeadLetterRequest
+	40, // 65: email.EmailService.ReplayDeadLetters:input_type -> email.ReplayDeadLettersRequest
+	42, // 66: email.EmailService.PurgeDeadLetters:input_type -> email.PurgeDeadLettersRequest
+	44, // 67: email.EmailService.Health:input_type -> email.HealthRequest
+	46, // 68: email.EmailService.HealthCheck:input_type -> email.HealthCheckRequest
+	48, // 69: email.EmailVerificationService.SendVerificationEmail:input_type -> email.SendVerificationEmailRequest
+	50, // 70: email.EmailVerificationService.SendVerificationReminder:input_type -> email.SendVerificationReminderRequest
+	52, // 71: email.EmailVerificationService.ValidatePinCode:input_type -> email.ValidatePinCodeRequest
+	54, // 72: email.EmailVerificationService.ResendVerificationEmail:input_type -> email.ResendVerificationEmailRequest
+	4,  // 73: email.EmailService.CreateEmailJob:output_type -> email.CreateEmailJobResponse
+	4,  // 74: email.EmailService.CreateTrackedEmailJob:output_type -> email.CreateEmailJobResponse
+	6,  // 75: email.EmailService.GetEmailJob:output_type -> email.GetEmailJobResponse
+	8,  // 76: email.EmailService.GetJobStatus:output_type -> email.GetJobStatusResponse
+	10, // 77: email.EmailService.UpdateEmailJobStatus:output_type -> email.UpdateEmailJobStatusResponse
+	12, // 78: email.EmailService.CancelEmailJob:output_type -> email.CancelEmailJobResponse
+	14, // 79: email.EmailService.RescheduleEmailJob:output_type -> email.RescheduleEmailJobResponse
+	16, // 80: email.EmailService.ListEmailJobs:output_type -> email.ListEmailJobsResponse
+	18, // 81: email.EmailService.GetJobStats:output_type -> email.GetJobStatsResponse
+	20, // 82: email.EmailService.GetQueueStats:output_type -> email.GetQueueStatsResponse
+	22, // 83: email.EmailService.GetEmailTemplate:output_type -> email.GetEmailTemplateResponse
+	24, // 84: email.EmailService.ListEmailTemplates:output_type -> email.ListEmailTemplatesResponse
+	26, // 85: email.EmailService.CreateEmailTemplate:output_type -> email.CreateEmailTemplateResponse
+	28, // 86: email.EmailService.UpdateEmailTemplate:output_type -> email.UpdateEmailTemplateResponse
+	30, // 87: email.EmailService.DeleteEmailTemplate:output_type -> email.DeleteEmailTemplateResponse
+	32, // 88: email.EmailService.GetEmailTracking:output_type -> email.GetEmailTrackingResponse
+	34, // 89: email.EmailService.UpdateEmailTracking:output_type -> email.UpdateEmailTrackingResponse
+	37, // 90: email.EmailService.ListDeadLetters:output_type -> email.ListDeadLettersResponse
+	39, // 91: email.EmailService.GetDeadLetter:output_type -> email.GetDeadLetterResponse
+	41, // 92: email.EmailService.ReplayDeadLetters:output_type -> email.ReplayDeadLettersResponse
+	43, // 93: email.EmailService.PurgeDeadLetters:output_type -> email.PurgeDeadLettersResponse
+	45, // 94: email.EmailService.Health:output_type -> email.HealthResponse
+	47, // 95: email.EmailService.HealthCheck:output_type -> email.HealthCheckResponse
+	49, // 96: email.EmailVerificationService.SendVerificationEmail:output_type -> email.SendVerificationEmailResponse
+	51, // 97: email.EmailVerificationService.SendVerificationReminder:output_type -> email.SendVerificationReminderResponse
+	53, // 98: email.EmailVerificationService.ValidatePinCode:output_type -> email.ValidatePinCodeResponse
+	55, // 99: email.EmailVerificationService.ResendVerificationEmail:output_type -> email.ResendVerificationEmailResponse
+	73, // [73:100] is the sub-list for method output_type
+	46, // [46:73] is the sub-list for method input_type
+	46, // [46:46] is the sub-list for extension type_name
+	46, // [46:46] is the sub-list for extension extendee
+	0,  // [0:46] is the sub-list for field type_name
 }
 
 func init() { file_protos_email_proto_init() }
@@ -3946,7 +5058,7 @@ func file_protos_email_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_protos_email_proto_rawDesc), len(file_protos_email_proto_rawDesc)),
 			NumEnums:      2,
-			NumMessages:   50,
+			NumMessages:   67,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
@@ -24,6 +24,8 @@ const (
 	EmailService_GetEmailJob_FullMethodName           = "/email.EmailService/GetEmailJob"
 	EmailService_GetJobStatus_FullMethodName          = "/email.EmailService/GetJobStatus"
 	EmailService_UpdateEmailJobStatus_FullMethodName  = "/email.EmailService/UpdateEmailJobStatus"
+	EmailService_CancelEmailJob_FullMethodName        = "/email.EmailService/CancelEmailJob"
+	EmailService_RescheduleEmailJob_FullMethodName    = "/email.EmailService/RescheduleEmailJob"
 	EmailService_ListEmailJobs_FullMethodName         = "/email.EmailService/ListEmailJobs"
 	EmailService_GetJobStats_FullMethodName           = "/email.EmailService/GetJobStats"
 	EmailService_GetQueueStats_FullMethodName         = "/email.EmailService/GetQueueStats"
@@ -34,6 +36,10 @@ const (
 	EmailService_DeleteEmailTemplate_FullMethodName   = "/email.EmailService/DeleteEmailTemplate"
 	EmailService_GetEmailTracking_FullMethodName      = "/email.EmailService/GetEmailTracking"
 	EmailService_UpdateEmailTracking_FullMethodName   = "/email.EmailService/UpdateEmailTracking"
+	EmailService_ListDeadLetters_FullMethodName       = "/email.EmailService/ListDeadLetters"
+	EmailService_GetDeadLetter_FullMethodName         = "/email.EmailService/GetDeadLetter"
+	EmailService_ReplayDeadLetters_FullMethodName     = "/email.EmailService/ReplayDeadLetters"
+	EmailService_PurgeDeadLetters_FullMethodName      = "/email.EmailService/PurgeDeadLetters"
 	EmailService_Health_FullMethodName                = "/email.EmailService/Health"
 	EmailService_HealthCheck_FullMethodName           = "/email.EmailService/HealthCheck"
 )
@@ -48,6 +54,8 @@ type EmailServiceClient interface {
 	GetEmailJob(ctx context.Context, in *GetEmailJobRequest, opts ...grpc.CallOption) (*GetEmailJobResponse, error)
 	GetJobStatus(ctx context.Context, in *GetJobStatusRequest, opts ...grpc.CallOption) (*GetJobStatusResponse, error)
 	UpdateEmailJobStatus(ctx context.Context, in *UpdateEmailJobStatusRequest, opts ...grpc.CallOption) (*UpdateEmailJobStatusResponse, error)
+	CancelEmailJob(ctx context.Context, in *CancelEmailJobRequest, opts ...grpc.CallOption) (*CancelEmailJobResponse, error)
+	RescheduleEmailJob(ctx context.Context, in *RescheduleEmailJobRequest, opts ...grpc.CallOption) (*RescheduleEmailJobResponse, error)
 	ListEmailJobs(ctx context.Context, in *ListEmailJobsRequest, opts ...grpc.CallOption) (*ListEmailJobsResponse, error)
 	GetJobStats(ctx context.Context, in *GetJobStatsRequest, opts ...grpc.CallOption) (*GetJobStatsResponse, error)
 	GetQueueStats(ctx context.Context, in *GetQueueStatsRequest, opts ...grpc.CallOption) (*GetQueueStatsResponse, error)
@@ -60,6 +68,11 @@ type EmailServiceClient interface {
 	// Email tracking
 	GetEmailTracking(ctx context.Context, in *GetEmailTrackingRequest, opts ...grpc.CallOption) (*GetEmailTrackingResponse, error)
 	UpdateEmailTracking(ctx context.Context, in *UpdateEmailTrackingRequest, opts ...grpc.CallOption) (*UpdateEmailTrackingResponse, error)
+	// Dead-lettered jobs
+	ListDeadLetters(ctx context.Context, in *ListDeadLettersRequest, opts ...grpc.CallOption) (*ListDeadLettersResponse, error)
+	GetDeadLetter(ctx context.Context, in *GetDeadLetterRequest, opts ...grpc.CallOption) (*GetDeadLetterResponse, error)
+	ReplayDeadLetters(ctx context.Context, in *ReplayDeadLettersRequest, opts ...grpc.CallOption) (*ReplayDeadLettersResponse, error)
+	PurgeDeadLetters(ctx context.Context, in *PurgeDeadLettersRequest, opts ...grpc.CallOption) (*PurgeDeadLettersResponse, error)
 	// Health check
 	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
@@ -118,6 +131,24 @@ func (c *emailServiceClient) UpdateEmailJobStatus(ctx context.Context, in *Updat
 	return out, nil
 }
 
+func (c *emailServiceClient) CancelEmailJob(ctx context.Context, in *CancelEmailJobRequest, opts ...grpc.CallOption) (*CancelEmailJobResponse, error) {
+	out := new(CancelEmailJobResponse)
+	err := c.cc.Invoke(ctx, EmailService_CancelEmailJob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *emailServiceClient) RescheduleEmailJob(ctx context.Context, in *RescheduleEmailJobRequest, opts ...grpc.CallOption) (*RescheduleEmailJobResponse, error) {
+	out := new(RescheduleEmailJobResponse)
+	err := c.cc.Invoke(ctx, EmailService_RescheduleEmailJob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *emailServiceClient) ListEmailJobs(ctx context.Context, in *ListEmailJobsRequest, opts ...grpc.CallOption) (*ListEmailJobsResponse, error) {
 	out := new(ListEmailJobsResponse)
 	err := c.cc.Invoke(ctx, EmailService_ListEmailJobs_FullMethodName, in, out, opts...)
@@ -208,6 +239,42 @@ func (c *emailServiceClient) UpdateEmailTracking(ctx context.Context, in *Update
 	return out, nil
 }
 
+func (c *emailServiceClient) ListDeadLetters(ctx context.Context, in *ListDeadLettersRequest, opts ...grpc.CallOption) (*ListDeadLettersResponse, error) {
+	out := new(ListDeadLettersResponse)
+	err := c.cc.Invoke(ctx, EmailService_ListDeadLetters_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *emailServiceClient) GetDeadLetter(ctx context.Context, in *GetDeadLetterRequest, opts ...grpc.CallOption) (*GetDeadLetterResponse, error) {
+	out := new(GetDeadLetterResponse)
+	err := c.cc.Invoke(ctx, EmailService_GetDeadLetter_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *emailServiceClient) ReplayDeadLetters(ctx context.Context, in *ReplayDeadLettersRequest, opts ...grpc.CallOption) (*ReplayDeadLettersResponse, error) {
+	out := new(ReplayDeadLettersResponse)
+	err := c.cc.Invoke(ctx, EmailService_ReplayDeadLetters_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *emailServiceClient) PurgeDeadLetters(ctx context.Context, in *PurgeDeadLettersRequest, opts ...grpc.CallOption) (*PurgeDeadLettersResponse, error) {
+	out := new(PurgeDeadLettersResponse)
+	err := c.cc.Invoke(ctx, EmailService_PurgeDeadLetters_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *emailServiceClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
 	out := new(HealthResponse)
 	err := c.cc.Invoke(ctx, EmailService_Health_FullMethodName, in, out, opts...)
@@ -236,6 +303,8 @@ type EmailServiceServer interface {
 	GetEmailJob(context.Context, *GetEmailJobRequest) (*GetEmailJobResponse, error)
 	GetJobStatus(context.Context, *GetJobStatusRequest) (*GetJobStatusResponse, error)
 	UpdateEmailJobStatus(context.Context, *UpdateEmailJobStatusRequest) (*UpdateEmailJobStatusResponse, error)
+	CancelEmailJob(context.Context, *CancelEmailJobRequest) (*CancelEmailJobResponse, error)
+	RescheduleEmailJob(context.Context, *RescheduleEmailJobRequest) (*RescheduleEmailJobResponse, error)
 	ListEmailJobs(context.Context, *ListEmailJobsRequest) (*ListEmailJobsResponse, error)
 	GetJobStats(context.Context, *GetJobStatsRequest) (*GetJobStatsResponse, error)
 	GetQueueStats(context.Context, *GetQueueStatsRequest) (*GetQueueStatsResponse, error)
@@ -248,6 +317,11 @@ type EmailServiceServer interface {
 	// Email tracking
 	GetEmailTracking(context.Context, *GetEmailTrackingRequest) (*GetEmailTrackingResponse, error)
 	UpdateEmailTracking(context.Context, *UpdateEmailTrackingRequest) (*UpdateEmailTrackingResponse, error)
+	// Dead-lettered jobs
+	ListDeadLetters(context.Context, *ListDeadLettersRequest) (*ListDeadLettersResponse, error)
+	GetDeadLetter(context.Context, *GetDeadLetterRequest) (*GetDeadLetterResponse, error)
+	ReplayDeadLetters(context.Context, *ReplayDeadLettersRequest) (*ReplayDeadLettersResponse, error)
+	PurgeDeadLetters(context.Context, *PurgeDeadLettersRequest) (*PurgeDeadLettersResponse, error)
 	// Health check
 	Health(context.Context, *HealthRequest) (*HealthResponse, error)
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
@@ -273,6 +347,12 @@ func (UnimplementedEmailServiceServer) GetJobStatus(context.Context, *GetJobStat
 func (UnimplementedEmailServiceServer) UpdateEmailJobStatus(context.Context, *UpdateEmailJobStatusRequest) (*UpdateEmailJobStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateEmailJobStatus not implemented")
 }
+func (UnimplementedEmailServiceServer) CancelEmailJob(context.Context, *CancelEmailJobRequest) (*CancelEmailJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelEmailJob not implemented")
+}
+func (UnimplementedEmailServiceServer) RescheduleEmailJob(context.Context, *RescheduleEmailJobRequest) (*RescheduleEmailJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RescheduleEmailJob not implemented")
+}
 func (UnimplementedEmailServiceServer) ListEmailJobs(context.Context, *ListEmailJobsRequest) (*ListEmailJobsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListEmailJobs not implemented")
 }
@@ -303,6 +383,18 @@ func (UnimplementedEmailServiceServer) GetEmailTracking(context.Context, *GetEma
 func (UnimplementedEmailServiceServer) UpdateEmailTracking(context.Context, *UpdateEmailTrackingRequest) (*UpdateEmailTrackingResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateEmailTracking not implemented")
 }
+func (UnimplementedEmailServiceServer) ListDeadLetters(context.Context, *ListDeadLettersRequest) (*ListDeadLettersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeadLetters not implemented")
+}
+func (UnimplementedEmailServiceServer) GetDeadLetter(context.Context, *GetDeadLetterRequest) (*GetDeadLetterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeadLetter not implemented")
+}
+func (UnimplementedEmailServiceServer) ReplayDeadLetters(context.Context, *ReplayDeadLettersRequest) (*ReplayDeadLettersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayDeadLetters not implemented")
+}
+func (UnimplementedEmailServiceServer) PurgeDeadLetters(context.Context, *PurgeDeadLettersRequest) (*PurgeDeadLettersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeDeadLetters not implemented")
+}
 func (UnimplementedEmailServiceServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
 }
@@ -412,6 +504,42 @@ func _EmailService_UpdateEmailJobStatus_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EmailService_CancelEmailJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelEmailJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmailServiceServer).CancelEmailJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmailService_CancelEmailJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmailServiceServer).CancelEmailJob(ctx, req.(*CancelEmailJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmailService_RescheduleEmailJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RescheduleEmailJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmailServiceServer).RescheduleEmailJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmailService_RescheduleEmailJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmailServiceServer).RescheduleEmailJob(ctx, req.(*RescheduleEmailJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _EmailService_ListEmailJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListEmailJobsRequest)
 	if err := dec(in); err != nil {
@@ -592,6 +720,78 @@ func _EmailService_UpdateEmailTracking_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EmailService_ListDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmailServiceServer).ListDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmailService_ListDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmailServiceServer).ListDeadLetters(ctx, req.(*ListDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmailService_GetDeadLetter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeadLetterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmailServiceServer).GetDeadLetter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmailService_GetDeadLetter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmailServiceServer).GetDeadLetter(ctx, req.(*GetDeadLetterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmailService_ReplayDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmailServiceServer).ReplayDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmailService_ReplayDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmailServiceServer).ReplayDeadLetters(ctx, req.(*ReplayDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmailService_PurgeDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmailServiceServer).PurgeDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmailService_PurgeDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmailServiceServer).PurgeDeadLetters(ctx, req.(*PurgeDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _EmailService_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthRequest)
 	if err := dec(in); err != nil {
@@ -655,6 +855,14 @@ var EmailService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateEmailJobStatus",
 			Handler:    _EmailService_UpdateEmailJobStatus_Handler,
 		},
+		{
+			MethodName: "CancelEmailJob",
+			Handler:    _EmailService_CancelEmailJob_Handler,
+		},
+		{
+			MethodName: "RescheduleEmailJob",
+			Handler:    _EmailService_RescheduleEmailJob_Handler,
+		},
 		{
 			MethodName: "ListEmailJobs",
 			Handler:    _EmailService_ListEmailJobs_Handler,
@@ -695,6 +903,22 @@ var EmailService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateEmailTracking",
 			Handler:    _EmailService_UpdateEmailTracking_Handler,
 		},
+		{
+			MethodName: "ListDeadLetters",
+			Handler:    _EmailService_ListDeadLetters_Handler,
+		},
+		{
+			MethodName: "GetDeadLetter",
+			Handler:    _EmailService_GetDeadLetter_Handler,
+		},
+		{
+			MethodName: "ReplayDeadLetters",
+			Handler:    _EmailService_ReplayDeadLetters_Handler,
+		},
+		{
+			MethodName: "PurgeDeadLetters",
+			Handler:    _EmailService_PurgeDeadLetters_Handler,
+		},
 		{
 			MethodName: "Health",
 			Handler:    _EmailService_Health_Handler,
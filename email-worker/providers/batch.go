@@ -0,0 +1,146 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BatchRequest is an email sent to several recipients, each of whom gets a
+// message of their own. Placeholders written as {{key}} in the subject and
+// content of the email are replaced with the data of each recipient.
+type BatchRequest struct {
+	// Email is the message sent to every recipient, its To, CC and BCC are
+	// ignored
+	Email      *EmailRequest    `json:"email"`
+	Recipients []BatchRecipient `json:"recipients"`
+}
+
+// BatchRecipient is a recipient of a batch with the addresses its message is
+// sent to
+type BatchRecipient struct {
+	To   []string          `json:"to"`
+	CC   []string          `json:"cc,omitempty"`
+	BCC  []string          `json:"bcc,omitempty"`
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// BatchResult is the result of a batch for one of its recipients
+type BatchResult struct {
+	Response *EmailResponse
+	Err      error
+}
+
+// SendEach sends the message of each recipient of a batch on its own through
+// provider, for providers that do not send batches natively
+func SendEach(ctx context.Context, provider Provider, batch *BatchRequest) ([]BatchResult, error) {
+	if err := batch.validate(); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(batch.Recipients))
+	for i := range batch.Recipients {
+		if err := ctx.Err(); err != nil {
+			results[i] = failedResult(provider.Name(), NewSendError(ErrorClassTransient, err))
+			continue
+		}
+		resp, err := provider.Send(ctx, batch.Message(i))
+		results[i] = BatchResult{Response: resp, Err: err}
+	}
+	return results, nil
+}
+
+// validate checks that every recipient of a batch has an address to send to
+func (b *BatchRequest) validate() error {
+	if b == nil || b.Email == nil {
+		return NewSendError(ErrorClassPermanent, errors.New("batch has no email"))
+	}
+	if len(b.Recipients) == 0 {
+		return NewSendError(ErrorClassPermanent, errors.New("batch has no recipients"))
+	}
+	for _, recipient := range b.Recipients {
+		if len(recipient.To) == 0 {
+			return NewSendError(ErrorClassPermanent, errors.New("batch recipient has no To address"))
+		}
+	}
+	return nil
+}
+
+// Message returns the email sent to the recipient at index i, with its
+// addresses and its data in place of the placeholders
+func (b *BatchRequest) Message(i int) *EmailRequest {
+	recipient := b.Recipients[i]
+	req := *b.Email
+	req.To, req.CC, req.BCC = recipient.To, recipient.CC, recipient.BCC
+
+	if len(recipient.Data) > 0 {
+		keys := make([]string, 0, len(recipient.Data))
+		for key := range recipient.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, 2*len(keys))
+		for _, key := range keys {
+			pairs = append(pairs, Placeholder(key), recipient.Data[key])
+		}
+		replacer := strings.NewReplacer(pairs...)
+		req.Subject = replacer.Replace(req.Subject)
+		req.HTMLContent = replacer.Replace(req.HTMLContent)
+		req.TextContent = replacer.Replace(req.TextContent)
+	}
+	return &req
+}
+
+// subBatch returns the batch of the recipients at indexes
+func (b *BatchRequest) subBatch(indexes []int) *BatchRequest {
+	recipients := make([]BatchRecipient, len(indexes))
+	for i, index := range indexes {
+		recipients[i] = b.Recipients[index]
+	}
+	return &BatchRequest{Email: b.Email, Recipients: recipients}
+}
+
+// Placeholder returns the placeholder of key, which the data of each
+// recipient of a batch replace in their message
+func Placeholder(key string) string {
+	return "{{" + key + "}}"
+}
+
+// sentResult is the result of a message the provider accepted
+func sentResult(provider, messageID string) BatchResult {
+	return BatchResult{Response: &EmailResponse{
+		MessageID: messageID,
+		Status:    "sent",
+		Provider:  provider,
+		SentAt:    time.Now(),
+	}}
+}
+
+// failedResult is the result of a message the provider did not send
+func failedResult(provider string, err error) BatchResult {
+	return BatchResult{
+		Response: &EmailResponse{
+			Status:   "failed",
+			Provider: provider,
+			Error:    err.Error(),
+			SentAt:   time.Now(),
+		},
+		Err: err,
+	}
+}
+
+// batchError returns nil if any recipient of a batch was sent to, and else
+// the error of the first recipient
+func batchError(results []BatchResult) error {
+	for _, result := range results {
+		if result.Err == nil {
+			return nil
+		}
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	return results[0].Err
+}
@@ -0,0 +1,454 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// sendGridRequest is the part of a SendGrid mail send request the fake API
+// records
+type sendGridRequest struct {
+	Subject          string `json:"subject"`
+	Personalizations []struct {
+		To            []struct{ Email string } `json:"to"`
+		CC            []struct{ Email string } `json:"cc"`
+		BCC           []struct{ Email string } `json:"bcc"`
+		Substitutions map[string]string        `json:"substitutions"`
+	} `json:"personalizations"`
+}
+
+// fakeSendGrid serves the SendGrid mail send API, answering with status
+func fakeSendGrid(t *testing.T, status int) (*SendGridProvider, func() []sendGridRequest) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		requests []sendGridRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/mail/send" || r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, "unexpected request", http.StatusNotFound)
+			return
+		}
+		var req sendGridRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, req)
+		id := len(requests)
+		mu.Unlock()
+
+		w.Header().Set("X-Message-Id", fmt.Sprintf("sg-%d", id))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	provider, err := NewSendGridProvider(map[string]any{"api_key": "key", "from": "noreply@example.com", "endpoint": server.URL})
+	if err != nil {
+		t.Fatalf("NewSendGridProvider: %v", err)
+	}
+	return provider.(*SendGridProvider), func() []sendGridRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]sendGridRequest(nil), requests...)
+	}
+}
+
+// fakeSES serves the SES query API. It keeps the templates created, bulk
+// sends reject the recipients at rejected, and every call is recorded by
+// action.
+func fakeSES(t *testing.T, rejected string) (*SESProvider, func() map[string][]url.Values) {
+	t.Helper()
+	var (
+		mu        sync.Mutex
+		calls     = make(map[string][]url.Values)
+		templates = make(map[string]bool)
+	)
+	sesError := func(w http.ResponseWriter, code, message string) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `<ErrorResponse><Error><Type>Sender</Type><Code>%s</Code><Message>%s</Message></Error></ErrorResponse>`, code, message)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		action := r.PostForm.Get("Action")
+		mu.Lock()
+		defer mu.Unlock()
+		calls[action] = append(calls[action], r.PostForm)
+
+		switch action {
+		case "SendEmail":
+			fmt.Fprint(w, `<SendEmailResponse><SendEmailResult><MessageId>ses-1</MessageId></SendEmailResult></SendEmailResponse>`)
+		case "CreateTemplate":
+			name := r.PostForm.Get("Template.TemplateName")
+			if templates[name] {
+				sesError(w, "AlreadyExists", "Template "+name+" already exists.")
+				return
+			}
+			templates[name] = true
+			fmt.Fprint(w, `<CreateTemplateResponse><CreateTemplateResult/></CreateTemplateResponse>`)
+		case "DeleteTemplate":
+			delete(templates, r.PostForm.Get("TemplateName"))
+			fmt.Fprint(w, `<DeleteTemplateResponse><DeleteTemplateResult/></DeleteTemplateResponse>`)
+		case "SendBulkTemplatedEmail":
+			if name := r.PostForm.Get("Template"); !templates[name] {
+				sesError(w, "TemplateDoesNotExist", "Template "+name+" does not exist.")
+				return
+			}
+			var statuses strings.Builder
+			for i := 1; r.PostForm.Has(fmt.Sprintf("Destinations.member.%d.Destination.ToAddresses.member.1", i)); i++ {
+				if r.PostForm.Get(fmt.Sprintf("Destinations.member.%d.Destination.ToAddresses.member.1", i)) == rejected {
+					statuses.WriteString(`<member><Status>MessageRejected</Status><Error>Address blacklisted.</Error></member>`)
+					continue
+				}
+				fmt.Fprintf(&statuses, `<member><Status>Success</Status><MessageId>ses-%d</MessageId></member>`, i)
+			}
+			fmt.Fprintf(w, `<SendBulkTemplatedEmailResponse><SendBulkTemplatedEmailResult><Status>%s</Status></SendBulkTemplatedEmailResult></SendBulkTemplatedEmailResponse>`, statuses.String())
+		default:
+			sesError(w, "InvalidAction", action)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	provider, err := NewSESProvider(map[string]any{
+		"region":            "us-east-1",
+		"access_key_id":     "id",
+		"secret_access_key": "secret",
+		"from":              "noreply@example.com",
+		"endpoint":          server.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewSESProvider: %v", err)
+	}
+	return provider.(*SESProvider), func() map[string][]url.Values {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+}
+
+func newTestBatch() *BatchRequest {
+	return &BatchRequest{
+		Email: &EmailRequest{
+			Subject:     "Your booking {{ref}}",
+			HTMLContent: "<p>Hello {{name}}</p>",
+			TextContent: "Hello {{name}}",
+		},
+		Recipients: []BatchRecipient{
+			{To: []string{"ann@example.com"}, CC: []string{"ann.boss@example.com"}, Data: map[string]string{"name": "Ann", "ref": "A1"}},
+			{To: []string{"bob@example.com"}, BCC: []string{"audit@example.com"}, Data: map[string]string{"name": "Bob", "ref": "B2"}},
+		},
+	}
+}
+
+func TestSendGridProvider_SendBatchUsesPersonalizations(t *testing.T) {
+	provider, requests := fakeSendGrid(t, http.StatusAccepted)
+
+	results, err := provider.SendBatch(context.Background(), newTestBatch())
+	if err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+	for i, result := range results {
+		if result.Err != nil || result.Response.MessageID != "sg-1" {
+			t.Fatalf("recipient %d: got %+v", i, result)
+		}
+	}
+
+	sent := requests()
+	if len(sent) != 1 || len(sent[0].Personalizations) != 2 {
+		t.Fatalf("got %+v, want one request with a personalization per recipient", sent)
+	}
+	ann, bob := sent[0].Personalizations[0], sent[0].Personalizations[1]
+	if ann.To[0].Email != "ann@example.com" || ann.CC[0].Email != "ann.boss@example.com" || ann.Substitutions["{{name}}"] != "Ann" {
+		t.Fatalf("got personalization %+v", ann)
+	}
+	if bob.BCC[0].Email != "audit@example.com" || bob.Substitutions["{{ref}}"] != "B2" {
+		t.Fatalf("got personalization %+v", bob)
+	}
+}
+
+func TestSendGridProvider_SplitsLargeBatches(t *testing.T) {
+	provider, requests := fakeSendGrid(t, http.StatusAccepted)
+	batch := &BatchRequest{Email: &EmailRequest{Subject: "News"}}
+	for i := 0; i < 600; i++ {
+		batch.Recipients = append(batch.Recipients, BatchRecipient{
+			To: []string{fmt.Sprintf("guest%d@example.com", i)},
+			CC: []string{fmt.Sprintf("host%d@example.com", i)},
+		})
+	}
+
+	results, err := provider.SendBatch(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+	sent := requests()
+	if len(sent) != 2 || len(sent[0].Personalizations) != 500 || len(sent[1].Personalizations) != 100 {
+		t.Fatalf("got %d requests, want 2 within the recipient limit", len(sent))
+	}
+	if results[0].Response.MessageID != "sg-1" || results[599].Response.MessageID != "sg-2" {
+		t.Fatalf("got message IDs %s and %s", results[0].Response.MessageID, results[599].Response.MessageID)
+	}
+}
+
+func TestSendGridProvider_SendsToEveryRecipient(t *testing.T) {
+	provider, requests := fakeSendGrid(t, http.StatusAccepted)
+
+	_, err := provider.Send(context.Background(), &EmailRequest{
+		To:      []string{"a@example.com", "b@example.com"},
+		CC:      []string{"c@example.com"},
+		BCC:     []string{"d@example.com"},
+		Subject: "Hello",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	personalization := requests()[0].Personalizations[0]
+	if len(personalization.To) != 2 || len(personalization.CC) != 1 || len(personalization.BCC) != 1 {
+		t.Fatalf("got personalization %+v", personalization)
+	}
+}
+
+func TestSESProvider_SendBatchUsesBulkTemplatedSend(t *testing.T) {
+	provider, calls := fakeSES(t, "bob@example.com")
+
+	results, err := provider.SendBatch(context.Background(), newTestBatch())
+	if err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+	if results[0].Err != nil || results[0].Response.MessageID != "ses-1" {
+		t.Fatalf("got %+v for the first recipient", results[0])
+	}
+	if class, _ := Classify(results[1].Err); class != ErrorClassPermanent {
+		t.Fatalf("got %v for the rejected recipient, want a permanent error", results[1].Err)
+	}
+
+	recorded := calls()
+	created, bulk := recorded["CreateTemplate"], recorded["SendBulkTemplatedEmail"]
+	if len(created) != 1 || len(bulk) != 1 || len(recorded["DeleteTemplate"]) != 0 {
+		t.Fatalf("got calls %v", recorded)
+	}
+	template := created[0].Get("Template.TemplateName")
+	if bulk[0].Get("Template") != template {
+		t.Fatalf("got template %q, sent with %q", template, bulk[0].Get("Template"))
+	}
+
+	// Triple-stash tags are filled in without HTML escaping, like Message
+	if got := created[0].Get("Template.SubjectPart"); got != "Your booking {{{ref}}}" {
+		t.Fatalf("got subject part %q", got)
+	}
+	if got := created[0].Get("Template.HtmlPart"); got != "<p>Hello {{{name}}}</p>" {
+		t.Fatalf("got HTML part %q", got)
+	}
+	if got := bulk[0].Get("Destinations.member.1.Destination.CcAddresses.member.1"); got != "ann.boss@example.com" {
+		t.Fatalf("got CC %q", got)
+	}
+	if got := bulk[0].Get("Destinations.member.2.Destination.BccAddresses.member.1"); got != "audit@example.com" {
+		t.Fatalf("got BCC %q", got)
+	}
+	if got := bulk[0].Get("Destinations.member.2.ReplacementTemplateData"); got != `{"name":"Bob","ref":"B2"}` {
+		t.Fatalf("got template data %s", got)
+	}
+}
+
+func TestSESProvider_SendBatchReusesTemplates(t *testing.T) {
+	provider, calls := fakeSES(t, "")
+
+	for _, subject := range []string{"Your booking {{ref}}", "Your booking {{ref}}", "Booking {{ref}} changed"} {
+		batch := newTestBatch()
+		batch.Email.Subject = subject
+		if _, err := provider.SendBatch(context.Background(), batch); err != nil {
+			t.Fatalf("SendBatch: %v", err)
+		}
+	}
+
+	recorded := calls()
+	if len(recorded["CreateTemplate"]) != 2 || len(recorded["SendBulkTemplatedEmail"]) != 3 {
+		t.Fatalf("got calls %v, want a template per content", recorded)
+	}
+	if first, second := recorded["SendBulkTemplatedEmail"][0].Get("Template"), recorded["SendBulkTemplatedEmail"][1].Get("Template"); first != second {
+		t.Fatalf("got templates %q and %q for the same content", first, second)
+	}
+
+	// The templates are kept until the provider is closed
+	if len(recorded["DeleteTemplate"]) != 0 {
+		t.Fatalf("got %d deletes before close", len(recorded["DeleteTemplate"]))
+	}
+	if err := provider.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if deleted := len(calls()["DeleteTemplate"]); deleted != 2 {
+		t.Fatalf("got %d deletes on close, want 2", deleted)
+	}
+}
+
+func TestSESProvider_SendBatchDeletesLeastRecentlyUsedTemplate(t *testing.T) {
+	provider, calls := fakeSES(t, "")
+	send := func(subject string) {
+		t.Helper()
+		batch := newTestBatch()
+		batch.Email.Subject = subject
+		results, err := provider.SendBatch(context.Background(), batch)
+		if err != nil || results[0].Err != nil {
+			t.Fatalf("SendBatch: %v, %+v", err, results[0])
+		}
+	}
+
+	for i := 0; i < sesTemplateCacheSize; i++ {
+		send(fmt.Sprintf("Booking %d", i))
+	}
+	// Using the oldest template again keeps it
+	send("Booking 0")
+	send("Booking new")
+
+	recorded := calls()
+	deleted := recorded["DeleteTemplate"]
+	if len(deleted) != 1 {
+		t.Fatalf("got %d deletes, want 1", len(deleted))
+	}
+	if want := recorded["CreateTemplate"][1].Get("Template.TemplateName"); deleted[0].Get("TemplateName") != want {
+		t.Fatalf("deleted %q, want the template of Booking 1 %q", deleted[0].Get("TemplateName"), want)
+	}
+}
+
+func TestSESProvider_SendBatchCreatesDeletedTemplateAgain(t *testing.T) {
+	provider, calls := fakeSES(t, "")
+	if _, err := provider.SendBatch(context.Background(), newTestBatch()); err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+
+	// Another replica deletes the template this provider still has cached
+	provider.deleteTemplate(context.Background(), calls()["CreateTemplate"][0].Get("Template.TemplateName"))
+
+	results, err := provider.SendBatch(context.Background(), newTestBatch())
+	if err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			t.Fatalf("got %v for recipient %d", result.Err, i)
+		}
+	}
+	if created := len(calls()["CreateTemplate"]); created != 2 {
+		t.Fatalf("got %d creates, want the template created again", created)
+	}
+}
+
+func TestSESProvider_SendBatchSendsEachEmailWithHeaders(t *testing.T) {
+	provider, calls := fakeSES(t, "")
+	batch := newTestBatch()
+	batch.Email.Headers = map[string]string{"X-Booking": "A1"}
+
+	results, err := provider.SendBatch(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			t.Fatalf("got %v for recipient %d", result.Err, i)
+		}
+	}
+
+	recorded := calls()
+	if len(recorded["CreateTemplate"]) != 0 || len(recorded["SendBulkTemplatedEmail"]) != 0 {
+		t.Fatalf("got calls %v, want no templated send", recorded)
+	}
+	sent := recorded["SendEmail"]
+	if len(sent) != 2 {
+		t.Fatalf("got %d sends, want one per recipient", len(sent))
+	}
+	if got := sent[1].Get("Message.Subject.Data"); got != "Your booking B2" {
+		t.Fatalf("got subject %q", got)
+	}
+}
+
+func TestSESProvider_SendsToEveryRecipient(t *testing.T) {
+	provider, calls := fakeSES(t, "")
+
+	resp, err := provider.Send(context.Background(), &EmailRequest{
+		To:          []string{"a@example.com"},
+		CC:          []string{"b@example.com"},
+		BCC:         []string{"c@example.com", "d@example.com"},
+		Subject:     "Hello",
+		TextContent: "Hello",
+	})
+	if err != nil || resp.MessageID != "ses-1" {
+		t.Fatalf("Send: %v", err)
+	}
+	sent := calls()["SendEmail"][0]
+	if sent.Get("Destination.CcAddresses.member.1") != "b@example.com" || sent.Get("Destination.BccAddresses.member.2") != "d@example.com" {
+		t.Fatalf("got destination %v", sent)
+	}
+}
+
+func TestSendEach_SendsEachMessage(t *testing.T) {
+	provider := &fakeProvider{name: "smtp"}
+
+	results, err := provider.SendBatch(context.Background(), newTestBatch())
+	if err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+	if len(results) != 2 || provider.sent() != 2 || results[1].Response.Provider != "smtp" {
+		t.Fatalf("got %d results after %d sends", len(results), provider.sent())
+	}
+
+	message := newTestBatch().Message(1)
+	if message.Subject != "Your booking B2" || message.TextContent != "Hello Bob" || message.BCC[0] != "audit@example.com" {
+		t.Fatalf("got message %+v", message)
+	}
+
+	if _, err := provider.SendBatch(context.Background(), &BatchRequest{Email: &EmailRequest{}}); err == nil {
+		t.Fatal("a batch without recipients should fail")
+	}
+}
+
+func TestCompositeProvider_SendBatchFailsOverRecipients(t *testing.T) {
+	primary, _ := fakeSendGrid(t, http.StatusServiceUnavailable)
+	secondary := &fakeProvider{name: "ses"}
+	composite := newTestComposite(t, RoutingFailover, Backend{Provider: primary}, Backend{Provider: secondary})
+
+	results, err := composite.SendBatch(context.Background(), newTestBatch())
+	if err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+	for i, result := range results {
+		if result.Err != nil || result.Response.Provider != "ses" {
+			t.Fatalf("recipient %d: got %+v, want a send through ses", i, result)
+		}
+	}
+	if secondary.sent() != 2 {
+		t.Fatalf("got %d sends, want 2", secondary.sent())
+	}
+}
+
+func TestRouter_SendBatchRoutesEachRecipient(t *testing.T) {
+	fallback := &fakeProvider{name: "fallback"}
+	router := newTestRouter(t, fallback)
+	limiter := &fakeLimiter{}
+	if err := router.factory.SetRateLimits(limiter, []RateLimit{{Domain: "example.com", Rate: 1}}); err != nil {
+		t.Fatalf("SetRateLimits: %v", err)
+	}
+
+	results, err := router.SendBatch(context.Background(), newTestBatch())
+	if err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+	if results[0].Err != nil || results[0].Response.Provider != "fallback" {
+		t.Fatalf("got %+v for the first recipient", results[0])
+	}
+	if !IsRateLimited(results[1].Err) {
+		t.Fatalf("got %v for the second recipient, want the example.com limit", results[1].Err)
+	}
+	if fallback.sent() != 1 {
+		t.Fatalf("got %d sends, want 1", fallback.sent())
+	}
+}
@@ -0,0 +1,278 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"booking-system/email-worker/metrics"
+)
+
+// BreakerState is the state of a circuit breaker
+type BreakerState string
+
+const (
+	// BreakerClosed passes sends through to the provider
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen rejects sends until the provider passes a health probe
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen lets a trial send through, which closes the breaker
+	// when it succeeds and opens it again when it fails
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// defaultBreakerOpenTimeout is used when a breaker has no open timeout
+const defaultBreakerOpenTimeout = 30 * time.Second
+
+// BreakerConfig holds circuit breaker configuration
+type BreakerConfig struct {
+	// FailureThreshold is how many sends in a row may fail before the
+	// breaker opens, zero disables circuit breakers
+	FailureThreshold int
+	// OpenTimeout is how long an open breaker rejects sends before it probes
+	// the health of the provider
+	OpenTimeout time.Duration
+}
+
+// BreakerStatus describes the circuit breaker of a provider
+type BreakerStatus struct {
+	Provider  string       `json:"provider"`
+	State     BreakerState `json:"state"`
+	Failures  int          `json:"failures"`
+	OpenedAt  time.Time    `json:"opened_at,omitempty"`
+	LastError string       `json:"last_error,omitempty"`
+}
+
+// circuit is the state of the circuit breaker of a provider. It is shared by
+// every instance created for the provider, so sends through a rule and
+// through the provider chain trip the same breaker.
+type circuit struct {
+	name   string
+	config BreakerConfig
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	lastErr  error
+	// probing is set while a health probe or trial send is in flight
+	probing bool
+}
+
+// newCircuit creates a closed circuit
+func newCircuit(name string, config BreakerConfig) *circuit {
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaultBreakerOpenTimeout
+	}
+	c := &circuit{name: name, config: config, state: BreakerClosed}
+	metrics.ProviderCircuitState.WithLabelValues(name).Set(0)
+	return c
+}
+
+// setState moves the circuit to state, c.mu must be held
+func (c *circuit) setState(state BreakerState, now time.Time) {
+	if state == BreakerOpen {
+		c.openedAt = now
+	}
+	if state == c.state {
+		return
+	}
+	c.state = state
+
+	var value float64
+	switch state {
+	case BreakerHalfOpen:
+		value = 1
+	case BreakerOpen:
+		value = 2
+	}
+	metrics.ProviderCircuitState.WithLabelValues(c.name).Set(value)
+	metrics.ProviderCircuitTransitions.WithLabelValues(c.name, string(state)).Inc()
+}
+
+// allow decides whether a send may go through. Once an open circuit has
+// waited its open timeout, the first send probes the provider with probe and
+// becomes the trial send if the probe passes. It reports whether the send is
+// the trial.
+func (c *circuit) allow(ctx context.Context, probe func(context.Context) error) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	switch c.state {
+	case BreakerClosed:
+		return false, nil
+	case BreakerHalfOpen:
+		if c.probing {
+			return false, c.openError(c.config.OpenTimeout)
+		}
+		c.probing = true
+		return true, nil
+	}
+
+	if wait := c.openedAt.Add(c.config.OpenTimeout).Sub(now); wait > 0 || c.probing {
+		return false, c.openError(wait)
+	}
+
+	c.probing = true
+	c.mu.Unlock()
+	err := probe(ctx)
+	c.mu.Lock()
+
+	if err != nil {
+		c.probing = false
+		c.lastErr = err
+		c.setState(BreakerOpen, time.Now())
+		return false, c.openError(c.config.OpenTimeout)
+	}
+	c.setState(BreakerHalfOpen, time.Now())
+	return true, nil
+}
+
+// openError is the error of a send rejected by the circuit, retryAfter is
+// when the circuit probes the provider next
+func (c *circuit) openError(retryAfter time.Duration) error {
+	if retryAfter <= 0 {
+		retryAfter = c.config.OpenTimeout
+	}
+	return &ProviderError{
+		Message:    ErrCircuitOpen.Message,
+		Err:        errors.New(c.name),
+		Class:      ErrorClassThrottled,
+		RetryAfter: retryAfter,
+	}
+}
+
+// record counts the result of a send. Permanent errors and sends cut short by
+// ctx say nothing about the provider and are not counted as failures.
+func (c *circuit) record(ctx context.Context, trial bool, err error) {
+	failed := err != nil && ctx.Err() == nil
+	if failed {
+		if class, _ := Classify(err); class == ErrorClassPermanent {
+			failed = false
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if trial {
+		c.probing = false
+		if err != nil && !failed {
+			// Inconclusive, the next send is a trial again
+			return
+		}
+	} else if c.state != BreakerClosed {
+		// A send that started before the circuit opened
+		return
+	}
+
+	if !failed {
+		c.failures = 0
+		c.setState(BreakerClosed, now)
+		return
+	}
+
+	c.failures++
+	c.lastErr = err
+	if trial || c.failures >= c.config.FailureThreshold {
+		c.setState(BreakerOpen, now)
+	}
+}
+
+// probed takes the result of a health check of the provider. An open circuit
+// that waited its open timeout becomes half-open when the check passes, and
+// waits again when it fails.
+func (c *circuit) probed(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.state != BreakerOpen || c.probing || now.Before(c.openedAt.Add(c.config.OpenTimeout)) {
+		return
+	}
+	if err != nil {
+		c.lastErr = err
+		c.setState(BreakerOpen, now)
+		return
+	}
+	c.setState(BreakerHalfOpen, now)
+}
+
+// status returns the state of the circuit
+func (c *circuit) status() BreakerStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := BreakerStatus{
+		Provider: c.name,
+		State:    c.state,
+		Failures: c.failures,
+	}
+	if c.state != BreakerClosed {
+		status.OpenedAt = c.openedAt
+	}
+	if c.lastErr != nil {
+		status.LastError = c.lastErr.Error()
+	}
+	return status
+}
+
+// CircuitBreaker wraps a provider in a circuit breaker. Sends that fail in a
+// row open the breaker, after which sends are rejected with ErrCircuitOpen
+// until the provider passes a health check and a trial send succeeds.
+type CircuitBreaker struct {
+	Provider
+	circuit *circuit
+}
+
+// Send sends an email unless the breaker is open
+func (b *CircuitBreaker) Send(ctx context.Context, req *EmailRequest) (*EmailResponse, error) {
+	trial, err := b.circuit.allow(ctx, b.Provider.Health)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.Provider.Send(ctx, req)
+	b.circuit.record(ctx, trial, err)
+	return resp, err
+}
+
+// SendBatch sends a batch unless the breaker is open. The batch counts as a
+// single send, which fails if no recipient was sent to.
+func (b *CircuitBreaker) SendBatch(ctx context.Context, batch *BatchRequest) ([]BatchResult, error) {
+	if err := batch.validate(); err != nil {
+		return nil, err
+	}
+
+	trial, err := b.circuit.allow(ctx, b.Provider.Health)
+	if err != nil {
+		results := make([]BatchResult, len(batch.Recipients))
+		for i := range results {
+			results[i] = failedResult(b.Name(), err)
+		}
+		return results, nil
+	}
+
+	results, err := b.Provider.SendBatch(ctx, batch)
+	if err != nil {
+		b.circuit.record(ctx, trial, err)
+		return nil, err
+	}
+	b.circuit.record(ctx, trial, batchError(results))
+	return results, nil
+}
+
+// Health checks the provider, which probes an open breaker
+func (b *CircuitBreaker) Health(ctx context.Context) error {
+	err := b.Provider.Health(ctx)
+	b.circuit.probed(err)
+	return err
+}
+
+// Status returns the state of the breaker
+func (b *CircuitBreaker) Status() BreakerStatus {
+	return b.circuit.status()
+}
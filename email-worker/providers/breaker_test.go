@@ -0,0 +1,139 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestBreaker(provider Provider, threshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		Provider: provider,
+		circuit:  newCircuit(provider.Name(), BreakerConfig{FailureThreshold: threshold, OpenTimeout: openTimeout}),
+	}
+}
+
+func (p *fakeProvider) fail(sendErr, healthErr error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sendErr = sendErr
+	p.healthErr = healthErr
+}
+
+func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	ctx := context.Background()
+	provider := &fakeProvider{name: "ses", sendErr: NewHTTPSendError(400, "", "invalid address")}
+	breaker := newTestBreaker(provider, 2, time.Minute)
+
+	// Permanent errors are the email's fault, not the provider's
+	for i := 0; i < 3; i++ {
+		breaker.Send(ctx, &EmailRequest{})
+	}
+	if state := breaker.Status().State; state != BreakerClosed {
+		t.Fatalf("got %s after permanent errors, want closed", state)
+	}
+
+	provider.fail(NewHTTPSendError(503, "", "unavailable"), nil)
+	breaker.Send(ctx, &EmailRequest{})
+	breaker.Send(ctx, &EmailRequest{})
+	if status := breaker.Status(); status.State != BreakerOpen || status.Failures != 2 {
+		t.Fatalf("got %+v, want open after 2 failures", status)
+	}
+
+	_, err := breaker.Send(ctx, &EmailRequest{})
+	if !IsCircuitOpen(err) {
+		t.Fatalf("got %v, want circuit open", err)
+	}
+	class, retryAfter := Classify(err)
+	if class != ErrorClassThrottled || retryAfter <= 0 || retryAfter > time.Minute {
+		t.Fatalf("got %s after %v, want throttled within the open timeout", class, retryAfter)
+	}
+	if provider.sent() != 5 {
+		t.Fatalf("got %d sends, an open breaker must not send", provider.sent())
+	}
+}
+
+func TestCircuitBreaker_ProbesHealthBeforeClosing(t *testing.T) {
+	ctx := context.Background()
+	provider := &fakeProvider{name: "smtp", sendErr: errors.New("connection refused"), healthErr: errors.New("connection refused")}
+	breaker := newTestBreaker(provider, 1, 20*time.Millisecond)
+
+	breaker.Send(ctx, &EmailRequest{})
+	if breaker.Status().State != BreakerOpen {
+		t.Fatal("breaker did not open")
+	}
+
+	// A failed probe keeps the breaker open without sending
+	time.Sleep(30 * time.Millisecond)
+	if _, err := breaker.Send(ctx, &EmailRequest{}); !IsCircuitOpen(err) {
+		t.Fatalf("got %v after a failed probe, want circuit open", err)
+	}
+	if provider.sent() != 1 {
+		t.Fatalf("got %d sends, want 1", provider.sent())
+	}
+
+	// A passed health check lets a trial send through, which reopens the
+	// breaker when it fails
+	provider.fail(errors.New("connection reset"), nil)
+	time.Sleep(30 * time.Millisecond)
+	breaker.Health(ctx)
+	if state := breaker.Status().State; state != BreakerHalfOpen {
+		t.Fatalf("got %s after a passed health check, want half-open", state)
+	}
+	breaker.Send(ctx, &EmailRequest{})
+	if state := breaker.Status().State; state != BreakerOpen {
+		t.Fatalf("got %s after a failed trial, want open", state)
+	}
+
+	// A passed probe and trial send close it
+	provider.fail(nil, nil)
+	time.Sleep(30 * time.Millisecond)
+	if _, err := breaker.Send(ctx, &EmailRequest{}); err != nil {
+		t.Fatalf("trial send: %v", err)
+	}
+	if status := breaker.Status(); status.State != BreakerClosed || status.Failures != 0 {
+		t.Fatalf("got %+v after a successful trial, want closed", status)
+	}
+}
+
+func TestCompositeProvider_CircuitOpenWhenEveryBreakerIsOpen(t *testing.T) {
+	unavailable := NewHTTPSendError(503, "", "unavailable")
+	primary := newTestBreaker(&fakeProvider{name: "sendgrid", sendErr: unavailable}, 1, time.Minute)
+	secondary := newTestBreaker(&fakeProvider{name: "ses", sendErr: unavailable}, 2, time.Minute)
+	composite := newTestComposite(t, RoutingFailover, Backend{Provider: primary}, Backend{Provider: secondary})
+
+	_, err := composite.Send(context.Background(), &EmailRequest{})
+	if IsCircuitOpen(err) {
+		t.Fatalf("got %v, a failed send is not a circuit open", err)
+	}
+	_, err = composite.Send(context.Background(), &EmailRequest{})
+	if IsCircuitOpen(err) {
+		t.Fatalf("got %v with one breaker closed, want a failed send", err)
+	}
+	_, err = composite.Send(context.Background(), &EmailRequest{})
+	if !IsCircuitOpen(err) {
+		t.Fatalf("got %v with every breaker open, want circuit open", err)
+	}
+}
+
+func TestProviderFactory_SharesBreakersByName(t *testing.T) {
+	factory := NewProviderFactory(map[string]any{
+		"sendgrid": map[string]any{"api_key": "key", "from": "noreply@example.com"},
+	})
+	factory.SetCircuitBreaker(BreakerConfig{FailureThreshold: 3})
+
+	first, err := factory.CreateNamedProvider("sendgrid")
+	if err != nil {
+		t.Fatalf("CreateNamedProvider: %v", err)
+	}
+	second, _ := factory.CreateNamedProvider("sendgrid")
+	if first.(*CircuitBreaker).circuit != second.(*CircuitBreaker).circuit {
+		t.Fatal("providers of the same name have separate breakers")
+	}
+
+	statuses := factory.CircuitBreakers()
+	if len(statuses) != 1 || statuses[0].Provider != "sendgrid" || statuses[0].State != BreakerClosed {
+		t.Fatalf("got %+v", statuses)
+	}
+}
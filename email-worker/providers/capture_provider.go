@@ -0,0 +1,381 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CaptureProvider implements the Provider interface by storing the rendered
+// emails instead of sending them, for staging environments. Captured emails
+// can be delivered on through another provider, with the recipients outside
+// of the allowlist redirected to a catch-all address.
+type CaptureProvider struct {
+	store    CaptureStore
+	config   CaptureConfig
+	deliver  Provider
+	from     string
+	fromName string
+}
+
+// CaptureConfig holds the capture settings shared by capture providers
+type CaptureConfig struct {
+	// Allowlist holds the addresses and domains emails are delivered to,
+	// "*.example.com" also matches subdomains. An empty allowlist allows
+	// every recipient.
+	Allowlist []string
+	// CatchAll receives the emails of the recipients outside of the
+	// allowlist, which are dropped when it is empty
+	CatchAll string
+	// Deliver names the provider captured emails are delivered through,
+	// they are only captured when it is empty
+	Deliver string
+}
+
+// CapturedEmail is an email stored by a capture provider. The rendered
+// message is stored next to it.
+type CapturedEmail struct {
+	ID          string               `json:"id"`
+	From        string               `json:"from"`
+	To          []string             `json:"to"`
+	CC          []string             `json:"cc,omitempty"`
+	BCC         []string             `json:"bcc,omitempty"`
+	ReplyTo     string               `json:"reply_to,omitempty"`
+	Subject     string               `json:"subject"`
+	HTMLContent string               `json:"html_content,omitempty"`
+	TextContent string               `json:"text_content,omitempty"`
+	Headers     map[string]string    `json:"headers,omitempty"`
+	Attachments []CapturedAttachment `json:"attachments,omitempty"`
+	Template    string               `json:"template,omitempty"`
+	Tenant      string               `json:"tenant,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Metadata    map[string]string    `json:"metadata,omitempty"`
+	// DeliveredTo lists the recipients the email was delivered to, after
+	// the allowlist redirected the others
+	DeliveredTo []string  `json:"delivered_to,omitempty"`
+	DeliveredBy string    `json:"delivered_by,omitempty"`
+	CapturedAt  time.Time `json:"captured_at"`
+}
+
+// CapturedAttachment describes an attachment of a captured email, its
+// content is part of the rendered message
+type CapturedAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int    `json:"size"`
+}
+
+// CaptureQuery selects captured emails. Search matches the sender, the
+// recipients, the subject and the template, ignoring case.
+type CaptureQuery struct {
+	Search string
+	Limit  int
+	Offset int
+}
+
+// CaptureStore stores captured emails with their rendered messages
+type CaptureStore interface {
+	// Save stores an email and its rendered message
+	Save(ctx context.Context, email *CapturedEmail, raw []byte) error
+
+	// List returns the emails selected by query, newest first
+	List(ctx context.Context, query CaptureQuery) ([]*CapturedEmail, error)
+
+	// Get returns an email
+	Get(ctx context.Context, id string) (*CapturedEmail, error)
+
+	// Raw returns the rendered message of an email in .eml format
+	Raw(ctx context.Context, id string) ([]byte, error)
+}
+
+// ErrCaptureNotFound is returned for captured emails that do not exist
+var ErrCaptureNotFound = fmt.Errorf("captured email not found")
+
+// NewCaptureProvider creates a new capture provider that stores emails in
+// store and delivers them through deliver, if it is not nil
+func NewCaptureProvider(config map[string]any, store CaptureStore, capture CaptureConfig, deliver Provider) (Provider, error) {
+	if store == nil {
+		return nil, fmt.Errorf("%w: capture store is not configured", ErrInvalidConfig)
+	}
+
+	from, _ := config["from"].(string)
+	if from == "" {
+		from = "noreply@localhost"
+	}
+
+	fromName, _ := config["from_name"].(string)
+	if fromName == "" {
+		fromName = "Booking System"
+	}
+
+	allowlist := make([]string, 0, len(capture.Allowlist))
+	for _, pattern := range capture.Allowlist {
+		if pattern = strings.ToLower(strings.TrimSpace(pattern)); pattern != "" {
+			allowlist = append(allowlist, pattern)
+		}
+	}
+	capture.Allowlist = allowlist
+
+	return &CaptureProvider{
+		store:    store,
+		config:   capture,
+		deliver:  deliver,
+		from:     from,
+		fromName: fromName,
+	}, nil
+}
+
+// SetCapture lets the providers of type capture store emails in store.
+// Providers created from now on use config.
+func (f *ProviderFactory) SetCapture(store CaptureStore, config CaptureConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.captureStore = store
+	f.capture = config
+}
+
+// CaptureStore returns the store of captured emails, nil unless capture is
+// set up
+func (f *ProviderFactory) CaptureStore() CaptureStore {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.captureStore
+}
+
+// newCaptureProvider creates a capture provider with the provider it
+// delivers through
+func (f *ProviderFactory) newCaptureProvider(config map[string]any) (Provider, error) {
+	f.mu.Lock()
+	store, capture := f.captureStore, f.capture
+	f.mu.Unlock()
+
+	var deliver Provider
+	if capture.Deliver != "" {
+		settings, _ := f.config[capture.Deliver].(map[string]any)
+		if providerType, _ := settings["type"].(string); capture.Deliver == string(ProviderTypeCapture) || providerType == string(ProviderTypeCapture) {
+			return nil, fmt.Errorf("%w: captured emails cannot be delivered through capture provider %s", ErrInvalidConfig, capture.Deliver)
+		}
+
+		var err error
+		if deliver, err = f.CreateNamedProvider(capture.Deliver); err != nil {
+			return nil, fmt.Errorf("failed to create provider %s: %w", capture.Deliver, err)
+		}
+	}
+
+	provider, err := NewCaptureProvider(config, store, capture, deliver)
+	if err != nil && deliver != nil {
+		deliver.Close()
+	}
+	return provider, err
+}
+
+// Name returns the provider name
+func (p *CaptureProvider) Name() string {
+	return "capture"
+}
+
+// Send captures an email, then delivers it to the allowed recipients and
+// the catch-all. An email is captured again when its delivery is retried.
+func (p *CaptureProvider) Send(ctx context.Context, req *EmailRequest) (*EmailResponse, error) {
+	if len(req.To) == 0 {
+		return nil, fmt.Errorf("%w: no recipients specified", ErrSendFailed)
+	}
+
+	raw, err := p.render(req)
+	if err != nil {
+		return nil, NewSendError(ErrorClassPermanent, fmt.Errorf("failed to render email: %w", err))
+	}
+
+	email := p.captured(req)
+	delivery := req
+	if p.deliver != nil {
+		delivery = p.config.redirect(req)
+		email.DeliveredTo = append(append(append([]string(nil), delivery.To...), delivery.CC...), delivery.BCC...)
+		if len(email.DeliveredTo) > 0 {
+			email.DeliveredBy = p.deliver.Name()
+		}
+	}
+
+	if err := p.store.Save(ctx, email, raw); err != nil {
+		err = NewSendError(ErrorClassTransient, fmt.Errorf("failed to capture email: %w", err))
+		return &EmailResponse{
+			Status:   "failed",
+			Provider: p.Name(),
+			Error:    err.Error(),
+			SentAt:   time.Now(),
+		}, err
+	}
+
+	if email.DeliveredBy == "" {
+		return &EmailResponse{
+			MessageID: email.ID,
+			Status:    "sent",
+			Provider:  p.Name(),
+			SentAt:    email.CapturedAt,
+		}, nil
+	}
+	return p.deliver.Send(ctx, delivery)
+}
+
+// SendBatch sends a batch capturing the message of each recipient
+func (p *CaptureProvider) SendBatch(ctx context.Context, batch *BatchRequest) ([]BatchResult, error) {
+	return SendEach(ctx, p, batch)
+}
+
+// render renders an email as it would be sent, in .eml format
+func (p *CaptureProvider) render(req *EmailRequest) ([]byte, error) {
+	var raw bytes.Buffer
+	if _, err := newMessage(p.sender(), req).WriteTo(&raw); err != nil {
+		return nil, err
+	}
+	return raw.Bytes(), nil
+}
+
+// sender returns the From header of captured emails
+func (p *CaptureProvider) sender() string {
+	return (&mail.Address{Name: p.fromName, Address: p.from}).String()
+}
+
+// captured describes an email for the capture store
+func (p *CaptureProvider) captured(req *EmailRequest) *CapturedEmail {
+	email := &CapturedEmail{
+		ID:          uuid.New().String(),
+		From:        p.sender(),
+		To:          req.To,
+		CC:          req.CC,
+		BCC:         req.BCC,
+		ReplyTo:     req.ReplyTo,
+		Subject:     req.Subject,
+		HTMLContent: req.HTMLContent,
+		TextContent: req.TextContent,
+		Headers:     req.Headers,
+		Template:    req.Template,
+		Tenant:      req.Tenant,
+		Tags:        req.Tags,
+		Metadata:    req.Metadata,
+		CapturedAt:  time.Now().UTC(),
+	}
+	for _, attachment := range req.Attachments {
+		email.Attachments = append(email.Attachments, CapturedAttachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Size:        len(attachment.Content),
+		})
+	}
+	return email
+}
+
+// redirect returns a copy of req whose recipients outside of the allowlist
+// are replaced by the catch-all. The original recipients of a redirected
+// email are listed in its X-Original-To header.
+func (c *CaptureConfig) redirect(req *EmailRequest) *EmailRequest {
+	if len(c.Allowlist) == 0 {
+		return req
+	}
+
+	var redirected []string
+	seen := make(map[string]bool)
+	keep := func(addresses []string) []string {
+		var kept []string
+		for _, address := range addresses {
+			if !c.allowed(address) {
+				redirected = append(redirected, address)
+				if c.CatchAll == "" {
+					continue
+				}
+				address = c.CatchAll
+			}
+			if key := strings.ToLower(address); !seen[key] {
+				seen[key] = true
+				kept = append(kept, address)
+			}
+		}
+		return kept
+	}
+
+	delivery := *req
+	delivery.To, delivery.CC, delivery.BCC = keep(req.To), keep(req.CC), keep(req.BCC)
+	if len(redirected) == 0 {
+		return req
+	}
+
+	// Keep a To recipient when the redirected ones were all in To
+	if len(delivery.To) == 0 {
+		delivery.To, delivery.CC = delivery.CC, nil
+	}
+	if len(delivery.To) == 0 {
+		delivery.To, delivery.BCC = delivery.BCC, nil
+	}
+
+	delivery.Headers = make(map[string]string, len(req.Headers)+1)
+	for key, value := range req.Headers {
+		delivery.Headers[key] = value
+	}
+	delivery.Headers["X-Original-To"] = strings.Join(redirected, ", ")
+	return &delivery
+}
+
+// allowed reports whether address matches the allowlist
+func (c *CaptureConfig) allowed(address string) bool {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		address = parsed.Address
+	}
+	address = strings.ToLower(address)
+
+	domain := recipientDomain(address)
+	for _, pattern := range c.Allowlist {
+		if strings.Contains(pattern, "@") {
+			if address == pattern {
+				return true
+			}
+			continue
+		}
+		if matchDomain(pattern, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the capture provider configuration
+func (p *CaptureProvider) Validate() error {
+	if p.store == nil {
+		return fmt.Errorf("%w: missing capture store", ErrInvalidConfig)
+	}
+
+	if p.deliver != nil {
+		return p.deliver.Validate()
+	}
+
+	return nil
+}
+
+// Health checks the provider captured emails are delivered through
+func (p *CaptureProvider) Health(ctx context.Context) error {
+	if p.deliver != nil {
+		return p.deliver.Health(ctx)
+	}
+	return nil
+}
+
+// Close closes the provider captured emails are delivered through
+func (p *CaptureProvider) Close() error {
+	if p.deliver != nil {
+		return p.deliver.Close()
+	}
+	return nil
+}
+
+// captureSearchText returns the text a capture query searches in
+func captureSearchText(email *CapturedEmail) string {
+	fields := []string{email.From, email.Subject, email.Template}
+	fields = append(fields, email.To...)
+	fields = append(fields, email.CC...)
+	fields = append(fields, email.BCC...)
+	return strings.ToLower(strings.Join(fields, "\n"))
+}
@@ -0,0 +1,154 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func newTestCapture(t *testing.T, capture CaptureConfig, deliver Provider) (*CaptureProvider, *FileCaptureStore) {
+	t.Helper()
+	store, err := NewFileCaptureStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileCaptureStore: %v", err)
+	}
+	provider, err := NewCaptureProvider(map[string]any{"from": "noreply@example.com"}, store, capture, deliver)
+	if err != nil {
+		t.Fatalf("NewCaptureProvider: %v", err)
+	}
+	return provider.(*CaptureProvider), store
+}
+
+func TestCaptureProvider_StoresRenderedEmails(t *testing.T) {
+	provider, store := newTestCapture(t, CaptureConfig{}, nil)
+	ctx := context.Background()
+
+	for _, subject := range []string{"Booking confirmed", "Password reset"} {
+		_, err := provider.Send(ctx, &EmailRequest{
+			To:          []string{"guest@example.com"},
+			BCC:         []string{"audit@example.com"},
+			Subject:     subject,
+			HTMLContent: "<p>Hello</p>",
+			TextContent: "Hello",
+			Template:    "booking_confirmation",
+			Attachments: []Attachment{{Filename: "ticket.txt", ContentType: "text/plain", Content: []byte("seat 12")}},
+		})
+		if err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	emails, err := store.List(ctx, CaptureQuery{Search: "PASSWORD"})
+	if err != nil || len(emails) != 1 || emails[0].Subject != "Password reset" {
+		t.Fatalf("got %v, %v, want the password reset email", emails, err)
+	}
+	if all, _ := store.List(ctx, CaptureQuery{Search: "audit@", Limit: 1}); len(all) != 1 || all[0].Subject != "Password reset" {
+		t.Fatalf("got %v, want the newest email matching a BCC recipient", all)
+	}
+	email, err := store.Get(ctx, emails[0].ID)
+	if err != nil || len(email.Attachments) != 1 || email.Attachments[0].Size != 7 {
+		t.Fatalf("got %+v, %v", email, err)
+	}
+
+	raw, err := store.Raw(ctx, email.ID)
+	if err != nil {
+		t.Fatalf("Raw: %v", err)
+	}
+	message, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("read message: %v", err)
+	}
+	if message.Header.Get("Subject") != "Password reset" || message.Header.Get("Bcc") != "" {
+		t.Fatalf("got headers %v", message.Header)
+	}
+	if !strings.Contains(string(raw), `filename="ticket.txt"`) || !strings.Contains(string(raw), "text/html") {
+		t.Fatalf("got message without its attachment or HTML part:\n%s", raw)
+	}
+
+	if _, err := store.Get(ctx, "../"+email.ID); !errors.Is(err, ErrCaptureNotFound) {
+		t.Fatalf("got %v, want not found", err)
+	}
+}
+
+func TestCaptureProvider_RedirectsRecipientsOutsideAllowlist(t *testing.T) {
+	server := newFakeSMTP(t)
+	provider, store := newTestCapture(t, CaptureConfig{
+		Allowlist: []string{"*.staging.example.com", "QA@example.com"},
+		CatchAll:  "inbox@staging.example.com",
+	}, server.provider(t))
+	ctx := context.Background()
+
+	_, err := provider.Send(ctx, &EmailRequest{
+		To:          []string{"guest@example.com", "other@example.org"},
+		CC:          []string{"Q A <qa@example.com>"},
+		BCC:         []string{"ops@team.staging.example.com"},
+		Subject:     "Hello",
+		TextContent: "Hello",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	messages := server.received()
+	if len(messages) != 1 {
+		t.Fatalf("got %d messages, want 1", len(messages))
+	}
+	want := "inbox@staging.example.com,qa@example.com,ops@team.staging.example.com"
+	if got := strings.Join(messages[0].recipients, ","); got != want {
+		t.Fatalf("got recipients %s, want %s", got, want)
+	}
+	if !strings.Contains(messages[0].data, "X-Original-To: guest@example.com, other@example.org") {
+		t.Fatalf("got message without the original recipients:\n%s", messages[0].data)
+	}
+
+	emails, err := store.List(ctx, CaptureQuery{})
+	if err != nil || len(emails) != 1 {
+		t.Fatalf("got %v, %v", emails, err)
+	}
+	if emails[0].To[0] != "guest@example.com" || len(emails[0].DeliveredTo) != 3 || emails[0].DeliveredBy != "smtp" {
+		t.Fatalf("got %+v, want the original recipients captured", emails[0])
+	}
+}
+
+func TestCaptureProvider_DropsRedirectedRecipientsWithoutCatchAll(t *testing.T) {
+	deliver := &fakeProvider{name: "ses"}
+	provider, _ := newTestCapture(t, CaptureConfig{Allowlist: []string{"example.com"}}, deliver)
+
+	resp, err := provider.Send(context.Background(), &EmailRequest{To: []string{"guest@example.org"}, Subject: "Hello"})
+	if err != nil || resp.Provider != "capture" {
+		t.Fatalf("got %+v, %v, want the email captured only", resp, err)
+	}
+	if deliver.sent() != 0 {
+		t.Fatalf("got %d deliveries, want none", deliver.sent())
+	}
+}
+
+func TestProviderFactory_CreatesCaptureProviders(t *testing.T) {
+	store, err := NewFileCaptureStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileCaptureStore: %v", err)
+	}
+	factory := NewProviderFactory(map[string]any{
+		"capture": map[string]any{},
+		"inbox":   map[string]any{"type": "capture"},
+		"smtp":    map[string]any{"host": "localhost", "port": 25, "username": "user", "password": "secret", "from": "noreply@example.com"},
+	})
+
+	if _, err := factory.CreateNamedProvider("capture"); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("got %v without a capture store, want invalid configuration", err)
+	}
+
+	factory.SetCapture(store, CaptureConfig{Deliver: "smtp"})
+	provider, err := factory.CreateNamedProvider("capture")
+	if err != nil || provider.(*CaptureProvider).deliver.Name() != "smtp" {
+		t.Fatalf("CreateNamedProvider = %v, %v", provider, err)
+	}
+	provider.Close()
+
+	factory.SetCapture(store, CaptureConfig{Deliver: "inbox"})
+	if _, err := factory.CreateNamedProvider("capture"); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("got %v for delivery through a capture provider, want invalid configuration", err)
+	}
+}
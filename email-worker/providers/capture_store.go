@@ -0,0 +1,258 @@
+package providers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// FileCaptureStore implements CaptureStore on a directory. Each email is
+// stored as <id>.eml, which mail clients open, next to <id>.json holding
+// the fields the inbox lists and searches.
+type FileCaptureStore struct {
+	dir string
+}
+
+// NewFileCaptureStore creates a new FileCaptureStore, creating dir if needed
+func NewFileCaptureStore(dir string) (*FileCaptureStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create capture directory: %w", err)
+	}
+	return &FileCaptureStore{dir: dir}, nil
+}
+
+// Save writes the message before its fields, so that listed emails can
+// always be downloaded
+func (s *FileCaptureStore) Save(ctx context.Context, email *CapturedEmail, raw []byte) error {
+	fields, err := json.Marshal(email)
+	if err != nil {
+		return fmt.Errorf("failed to marshal captured email: %w", err)
+	}
+
+	if err := s.write(email.ID+".eml", raw); err != nil {
+		return err
+	}
+	return s.write(email.ID+".json", fields)
+}
+
+// write writes a file of the store atomically
+func (s *FileCaptureStore) write(name string, data []byte) error {
+	tmp, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, name)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// List reads every stored email, the directory is meant for the volume of a
+// staging environment
+func (s *FileCaptureStore) List(ctx context.Context, query CaptureQuery) ([]*CapturedEmail, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list captured emails: %w", err)
+	}
+
+	search := strings.ToLower(query.Search)
+	emails := []*CapturedEmail{}
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		email, err := s.read(path)
+		if err != nil {
+			return nil, err
+		}
+		if search != "" && !strings.Contains(captureSearchText(email), search) {
+			continue
+		}
+		emails = append(emails, email)
+	}
+
+	sort.SliceStable(emails, func(i, j int) bool { return emails[i].CapturedAt.After(emails[j].CapturedAt) })
+	return paginate(emails, query), nil
+}
+
+// Get returns a stored email
+func (s *FileCaptureStore) Get(ctx context.Context, id string) (*CapturedEmail, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrCaptureNotFound
+	}
+	return s.read(filepath.Join(s.dir, id+".json"))
+}
+
+// Raw returns the message of a stored email
+func (s *FileCaptureStore) Raw(ctx context.Context, id string) ([]byte, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrCaptureNotFound
+	}
+
+	raw, err := os.ReadFile(filepath.Join(s.dir, id+".eml"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrCaptureNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read captured email: %w", err)
+	}
+	return raw, nil
+}
+
+// read reads the fields of a stored email
+func (s *FileCaptureStore) read(path string) (*CapturedEmail, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrCaptureNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read captured email: %w", err)
+	}
+
+	var email CapturedEmail
+	if err := json.Unmarshal(data, &email); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal captured email %s: %w", filepath.Base(path), err)
+	}
+	return &email, nil
+}
+
+// paginate returns the page of emails selected by query
+func paginate(emails []*CapturedEmail, query CaptureQuery) []*CapturedEmail {
+	if query.Offset >= len(emails) {
+		return []*CapturedEmail{}
+	}
+	emails = emails[query.Offset:]
+	if query.Limit > 0 && query.Limit < len(emails) {
+		emails = emails[:query.Limit]
+	}
+	return emails
+}
+
+// PostgresCaptureStore implements CaptureStore on the captured_emails table
+// (see migration 010). The fields of an email are kept as JSON next to its
+// message, with the text searched by queries copied to a column.
+type PostgresCaptureStore struct {
+	db *sql.DB
+}
+
+// NewPostgresCaptureStore creates a new PostgresCaptureStore instance
+func NewPostgresCaptureStore(db *sql.DB) *PostgresCaptureStore {
+	return &PostgresCaptureStore{db: db}
+}
+
+// Save stores an email and its message
+func (s *PostgresCaptureStore) Save(ctx context.Context, email *CapturedEmail, raw []byte) error {
+	fields, err := json.Marshal(email)
+	if err != nil {
+		return fmt.Errorf("failed to marshal captured email: %w", err)
+	}
+
+	query := `
+		INSERT INTO captured_emails (id, search_text, email, raw, captured_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err = s.db.ExecContext(ctx, query, email.ID, captureSearchText(email), fields, raw, email.CapturedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save captured email: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the emails selected by query, newest first
+func (s *PostgresCaptureStore) List(ctx context.Context, query CaptureQuery) ([]*CapturedEmail, error) {
+	var args []any
+	sqlQuery := `SELECT email FROM captured_emails`
+	if query.Search != "" {
+		args = append(args, strings.ToLower(query.Search))
+		sqlQuery += " WHERE strpos(search_text, $1) > 0"
+	}
+	sqlQuery += " ORDER BY captured_at DESC"
+	if query.Limit > 0 {
+		args = append(args, query.Limit)
+		sqlQuery += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if query.Offset > 0 {
+		args = append(args, query.Offset)
+		sqlQuery += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list captured emails: %w", err)
+	}
+	defer rows.Close()
+
+	emails := []*CapturedEmail{}
+	for rows.Next() {
+		email, err := scanCapturedEmail(rows)
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list captured emails: %w", err)
+	}
+
+	return emails, nil
+}
+
+// Get returns a stored email
+func (s *PostgresCaptureStore) Get(ctx context.Context, id string) (*CapturedEmail, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT email FROM captured_emails WHERE id::text = $1`, id)
+	email, err := scanCapturedEmail(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCaptureNotFound
+	}
+	return email, err
+}
+
+// Raw returns the message of a stored email
+func (s *PostgresCaptureStore) Raw(ctx context.Context, id string) ([]byte, error) {
+	var raw []byte
+	err := s.db.QueryRowContext(ctx, `SELECT raw FROM captured_emails WHERE id::text = $1`, id).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, ErrCaptureNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get captured email: %w", err)
+	}
+	return raw, nil
+}
+
+// scanCapturedEmail scans the fields of a stored email
+func scanCapturedEmail(row interface{ Scan(...any) error }) (*CapturedEmail, error) {
+	var fields []byte
+	if err := row.Scan(&fields); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan captured email: %w", err)
+	}
+
+	var email CapturedEmail
+	if err := json.Unmarshal(fields, &email); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal captured email: %w", err)
+	}
+	return &email, nil
+}
@@ -0,0 +1,381 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RoutingMode picks the order in which a composite provider tries its backends
+type RoutingMode string
+
+const (
+	// RoutingFailover tries the backends in the order they are configured
+	RoutingFailover RoutingMode = "failover"
+	// RoutingWeighted spreads sends over the backends in proportion to their
+	// weights, and fails over to the others. Backends with weight zero are
+	// only used for failover.
+	RoutingWeighted RoutingMode = "weighted"
+)
+
+// defaultHealthInterval is used when a composite provider has no health interval
+const defaultHealthInterval = 30 * time.Second
+
+// Backend is a provider of a composite provider
+type Backend struct {
+	Provider Provider
+	Weight   int
+}
+
+// BackendStatus describes a backend of a composite provider
+type BackendStatus struct {
+	Name      string    `json:"name"`
+	Weight    int       `json:"weight"`
+	Healthy   bool      `json:"healthy"`
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// CompositeConfig holds composite provider configuration
+type CompositeConfig struct {
+	Mode RoutingMode
+	// HealthInterval is how often the backends are health checked, backends
+	// that fail the check are tried last
+	HealthInterval time.Duration
+}
+
+// backend is a backend with the result of its last health check
+type backend struct {
+	Backend
+
+	mu        sync.RWMutex
+	healthy   bool
+	checkedAt time.Time
+	lastErr   error
+}
+
+// CompositeProvider implements the Provider interface over several backends.
+// A send that fails on one backend is tried on the next, except for permanent
+// errors, which would fail on every backend. The response names the backend
+// that sent the email.
+type CompositeProvider struct {
+	backends []*backend
+	config   CompositeConfig
+
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewCompositeProvider creates a composite provider over backends and starts
+// health checking them
+func NewCompositeProvider(backends []Backend, config CompositeConfig) (*CompositeProvider, error) {
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("%w: composite provider needs at least one backend", ErrInvalidConfig)
+	}
+	switch config.Mode {
+	case "":
+		config.Mode = RoutingFailover
+	case RoutingFailover, RoutingWeighted:
+	default:
+		return nil, fmt.Errorf("%w: unknown routing mode %q", ErrInvalidConfig, config.Mode)
+	}
+	if config.HealthInterval <= 0 {
+		config.HealthInterval = defaultHealthInterval
+	}
+
+	c := &CompositeProvider{
+		config:   config,
+		stopChan: make(chan struct{}),
+	}
+	for i, b := range backends {
+		if b.Provider == nil {
+			return nil, fmt.Errorf("%w: backend %d has no provider", ErrInvalidConfig, i)
+		}
+		if b.Weight < 0 {
+			return nil, fmt.Errorf("%w: backend %s has a negative weight", ErrInvalidConfig, b.Provider.Name())
+		}
+		c.backends = append(c.backends, &backend{Backend: b, healthy: true})
+	}
+
+	c.wg.Add(1)
+	go c.healthLoop()
+	return c, nil
+}
+
+// Name returns the provider name
+func (c *CompositeProvider) Name() string {
+	names := make([]string, len(c.backends))
+	for i, b := range c.backends {
+		names[i] = b.Provider.Name()
+	}
+	return "composite(" + strings.Join(names, ",") + ")"
+}
+
+// Send sends an email through the first backend that accepts it
+func (c *CompositeProvider) Send(ctx context.Context, req *EmailRequest) (*EmailResponse, error) {
+	var (
+		failures []error
+		response *EmailResponse
+	)
+	for _, b := range c.order() {
+		resp, err := b.Provider.Send(ctx, req)
+		if err == nil {
+			if resp.Provider == "" {
+				resp.Provider = b.Provider.Name()
+			}
+			return resp, nil
+		}
+
+		response = resp
+		failures = append(failures, fmt.Errorf("%s: %w", b.Provider.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+		if class, _ := Classify(err); class == ErrorClassPermanent {
+			return resp, err
+		}
+	}
+
+	return response, failoverError(failures)
+}
+
+// SendBatch sends a batch through the first backend, and fails the recipients
+// it could not send to over to the next backends like Send
+func (c *CompositeProvider) SendBatch(ctx context.Context, batch *BatchRequest) ([]BatchResult, error) {
+	if err := batch.validate(); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(batch.Recipients))
+	failures := make([][]error, len(batch.Recipients))
+	pending := make([]int, len(batch.Recipients))
+	for i := range pending {
+		pending[i] = i
+	}
+	for _, b := range c.order() {
+		if len(pending) == 0 {
+			break
+		}
+		sent, err := b.Provider.SendBatch(ctx, batch.subBatch(pending))
+		if err != nil {
+			return nil, err
+		}
+
+		var retry []int
+		for j, result := range sent {
+			i := pending[j]
+			results[i] = result
+			if result.Err == nil {
+				if result.Response != nil && result.Response.Provider == "" {
+					result.Response.Provider = b.Provider.Name()
+				}
+				continue
+			}
+			failures[i] = append(failures[i], fmt.Errorf("%s: %w", b.Provider.Name(), result.Err))
+			if class, _ := Classify(result.Err); class != ErrorClassPermanent {
+				retry = append(retry, i)
+			}
+		}
+		pending = retry
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	for _, i := range pending {
+		results[i].Err = failoverError(failures[i])
+	}
+	return results, nil
+}
+
+// failoverError combines the errors of every backend a send was tried on. It
+// is throttled if every backend throttled, and can be retried after the
+// shortest Retry-After; otherwise it is transient. If every backend rejected
+// the send because its circuit breaker is open or it is over its rate limit,
+// so is the send.
+func failoverError(failures []error) *ProviderError {
+	sendErr := NewSendError(ErrorClassThrottled, errors.Join(failures...))
+	circuitOpen, deferred := true, true
+	for _, err := range failures {
+		circuitOpen = circuitOpen && IsCircuitOpen(err)
+		deferred = deferred && IsDeferred(err)
+		class, retryAfter := Classify(err)
+		if class != ErrorClassThrottled {
+			sendErr.Class = ErrorClassTransient
+			sendErr.RetryAfter = 0
+			break
+		}
+		if sendErr.RetryAfter == 0 || (retryAfter > 0 && retryAfter < sendErr.RetryAfter) {
+			sendErr.RetryAfter = retryAfter
+		}
+	}
+	switch {
+	case circuitOpen:
+		sendErr.Message = ErrCircuitOpen.Message
+	case deferred:
+		sendErr.Message = ErrRateLimited.Message
+	}
+	return sendErr
+}
+
+// order returns the backends in the order a send tries them: the healthy
+// backends by routing mode, then the unhealthy ones, in case their health
+// recovered since the last check
+func (c *CompositeProvider) order() []*backend {
+	var healthy, unhealthy []*backend
+	for _, b := range c.backends {
+		if b.isHealthy() {
+			healthy = append(healthy, b)
+		} else {
+			unhealthy = append(unhealthy, b)
+		}
+	}
+
+	if c.config.Mode == RoutingWeighted {
+		healthy = weightedOrder(healthy)
+		unhealthy = weightedOrder(unhealthy)
+	}
+	return append(healthy, unhealthy...)
+}
+
+// weightedOrder shuffles backends so that each comes first in proportion to
+// its weight, keeping backends without weight last in configured order
+func weightedOrder(backends []*backend) []*backend {
+	ordered := make([]*backend, 0, len(backends))
+	var weighted, unweighted []*backend
+	total := 0
+	for _, b := range backends {
+		if b.Weight > 0 {
+			weighted = append(weighted, b)
+			total += b.Weight
+		} else {
+			unweighted = append(unweighted, b)
+		}
+	}
+
+	for len(weighted) > 0 {
+		pick := rand.Intn(total)
+		for i, b := range weighted {
+			if pick < b.Weight {
+				ordered = append(ordered, b)
+				total -= b.Weight
+				weighted = append(weighted[:i:i], weighted[i+1:]...)
+				break
+			}
+			pick -= b.Weight
+		}
+	}
+	return append(ordered, unweighted...)
+}
+
+// Validate validates the configuration of every backend
+func (c *CompositeProvider) Validate() error {
+	for _, b := range c.backends {
+		if err := b.Provider.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", b.Provider.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Health checks every backend. The composite provider is healthy while any
+// backend is.
+func (c *CompositeProvider) Health(ctx context.Context) error {
+	c.checkHealth(ctx)
+
+	var failures []error
+	for _, b := range c.backends {
+		b.mu.RLock()
+		healthy, lastErr := b.healthy, b.lastErr
+		b.mu.RUnlock()
+		if healthy {
+			return nil
+		}
+		failures = append(failures, fmt.Errorf("%s: %w", b.Provider.Name(), lastErr))
+	}
+	return fmt.Errorf("%w: %w", ErrProviderUnhealthy, errors.Join(failures...))
+}
+
+// checkHealth runs the health checks of the backends concurrently
+func (c *CompositeProvider) checkHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, b := range c.backends {
+		wg.Add(1)
+		go func(b *backend) {
+			defer wg.Done()
+			err := b.Provider.Health(ctx)
+
+			b.mu.Lock()
+			b.healthy = err == nil
+			b.checkedAt = time.Now()
+			b.lastErr = err
+			b.mu.Unlock()
+		}(b)
+	}
+	wg.Wait()
+}
+
+// healthLoop health checks the backends until the provider is closed
+func (c *CompositeProvider) healthLoop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.config.HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopChan:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), c.config.HealthInterval)
+			c.checkHealth(ctx)
+			cancel()
+		}
+	}
+}
+
+// isHealthy reports whether the last health check of the backend passed
+func (b *backend) isHealthy() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.healthy
+}
+
+// Status returns the backends with the result of their last health check
+func (c *CompositeProvider) Status() []BackendStatus {
+	statuses := make([]BackendStatus, len(c.backends))
+	for i, b := range c.backends {
+		b.mu.RLock()
+		statuses[i] = BackendStatus{
+			Name:      b.Provider.Name(),
+			Weight:    b.Weight,
+			Healthy:   b.healthy,
+			CheckedAt: b.checkedAt,
+		}
+		if b.lastErr != nil {
+			statuses[i].Error = b.lastErr.Error()
+		}
+		b.mu.RUnlock()
+	}
+	return statuses
+}
+
+// Close stops the health checks and closes every backend
+func (c *CompositeProvider) Close() error {
+	var errs []error
+	c.closeOnce.Do(func() {
+		close(c.stopChan)
+		c.wg.Wait()
+		for _, b := range c.backends {
+			if err := b.Provider.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", b.Provider.Name(), err))
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
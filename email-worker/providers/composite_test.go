@@ -0,0 +1,190 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProvider is a provider whose sends fail with sendErr and whose health
+// check fails with healthErr
+type fakeProvider struct {
+	name string
+
+	mu        sync.Mutex
+	sendErr   error
+	healthErr error
+	sends     int
+}
+
+func (p *fakeProvider) Name() string    { return p.name }
+func (p *fakeProvider) Validate() error { return nil }
+func (p *fakeProvider) Close() error    { return nil }
+
+func (p *fakeProvider) Send(ctx context.Context, req *EmailRequest) (*EmailResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sends++
+	if p.sendErr != nil {
+		return nil, p.sendErr
+	}
+	return &EmailResponse{MessageID: p.name + "-1", Status: "sent", Provider: p.name}, nil
+}
+
+func (p *fakeProvider) SendBatch(ctx context.Context, batch *BatchRequest) ([]BatchResult, error) {
+	return SendEach(ctx, p, batch)
+}
+
+func (p *fakeProvider) Health(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.healthErr
+}
+
+func (p *fakeProvider) sent() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sends
+}
+
+func newTestComposite(t *testing.T, mode RoutingMode, backends ...Backend) *CompositeProvider {
+	t.Helper()
+	composite, err := NewCompositeProvider(backends, CompositeConfig{Mode: mode, HealthInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewCompositeProvider: %v", err)
+	}
+	t.Cleanup(func() { composite.Close() })
+	return composite
+}
+
+func TestCompositeProvider_FailsOverInOrder(t *testing.T) {
+	primary := &fakeProvider{name: "sendgrid", sendErr: NewHTTPSendError(503, "", "unavailable")}
+	secondary := &fakeProvider{name: "ses"}
+	composite := newTestComposite(t, RoutingFailover, Backend{Provider: primary}, Backend{Provider: secondary})
+
+	resp, err := composite.Send(context.Background(), &EmailRequest{To: []string{"test@example.com"}})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if resp.Provider != "ses" || resp.MessageID != "ses-1" {
+		t.Fatalf("got response from %q, want ses", resp.Provider)
+	}
+	if primary.sent() != 1 || secondary.sent() != 1 {
+		t.Fatalf("got %d/%d sends, want 1/1", primary.sent(), secondary.sent())
+	}
+}
+
+func TestCompositeProvider_DoesNotFailOverPermanentErrors(t *testing.T) {
+	primary := &fakeProvider{name: "sendgrid", sendErr: NewHTTPSendError(400, "", "invalid address")}
+	secondary := &fakeProvider{name: "ses"}
+	composite := newTestComposite(t, RoutingFailover, Backend{Provider: primary}, Backend{Provider: secondary})
+
+	_, err := composite.Send(context.Background(), &EmailRequest{To: []string{"bad"}})
+	if class, _ := Classify(err); class != ErrorClassPermanent {
+		t.Fatalf("got %s error %v, want permanent", class, err)
+	}
+	if secondary.sent() != 0 {
+		t.Fatal("a permanent error was failed over")
+	}
+}
+
+func TestCompositeProvider_ThrottledWhenEveryBackendThrottles(t *testing.T) {
+	composite := newTestComposite(t, RoutingFailover,
+		Backend{Provider: &fakeProvider{name: "sendgrid", sendErr: NewHTTPSendError(429, "30", "slow down")}},
+		Backend{Provider: &fakeProvider{name: "ses", sendErr: NewHTTPSendError(429, "10", "slow down")}},
+	)
+
+	_, err := composite.Send(context.Background(), &EmailRequest{})
+	if !errors.Is(err, ErrSendFailed) {
+		t.Fatalf("got %v, want a send error", err)
+	}
+	class, retryAfter := Classify(err)
+	if class != ErrorClassThrottled || retryAfter != 10*time.Second {
+		t.Fatalf("got %s after %v, want throttled after 10s", class, retryAfter)
+	}
+}
+
+func TestCompositeProvider_TriesUnhealthyBackendsLast(t *testing.T) {
+	primary := &fakeProvider{name: "sendgrid", healthErr: errors.New("down")}
+	secondary := &fakeProvider{name: "ses"}
+	composite := newTestComposite(t, RoutingFailover, Backend{Provider: primary}, Backend{Provider: secondary})
+
+	if err := composite.Health(context.Background()); err != nil {
+		t.Fatalf("Health with one healthy backend: %v", err)
+	}
+	resp, err := composite.Send(context.Background(), &EmailRequest{})
+	if err != nil || resp.Provider != "ses" {
+		t.Fatalf("got %v from %v, want a send through ses", err, resp)
+	}
+	if primary.sent() != 0 {
+		t.Fatal("unhealthy backend was tried first")
+	}
+
+	statuses := composite.Status()
+	if statuses[0].Healthy || statuses[0].Error != "down" || !statuses[1].Healthy {
+		t.Fatalf("unexpected status %+v", statuses)
+	}
+
+	secondary.mu.Lock()
+	secondary.healthErr = errors.New("down too")
+	secondary.mu.Unlock()
+	if err := composite.Health(context.Background()); !errors.Is(err, ErrProviderUnhealthy) {
+		t.Fatalf("got %v, want unhealthy", err)
+	}
+}
+
+func TestCompositeProvider_WeightedRouting(t *testing.T) {
+	heavy := &fakeProvider{name: "sendgrid"}
+	light := &fakeProvider{name: "ses"}
+	standby := &fakeProvider{name: "smtp"}
+	composite := newTestComposite(t, RoutingWeighted,
+		Backend{Provider: heavy, Weight: 3},
+		Backend{Provider: light, Weight: 1},
+		Backend{Provider: standby},
+	)
+
+	for i := 0; i < 400; i++ {
+		if _, err := composite.Send(context.Background(), &EmailRequest{}); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	if standby.sent() != 0 {
+		t.Fatal("backend without weight was used without a failure")
+	}
+	if heavy.sent() < 240 || heavy.sent() > 360 {
+		t.Fatalf("got %d of 400 sends on the weight 3 backend, want about 300", heavy.sent())
+	}
+}
+
+func TestProviderFactory_CreateChain(t *testing.T) {
+	factory := NewProviderFactory(map[string]any{
+		"sendgrid": map[string]any{"api_key": "key", "from": "noreply@example.com", "weight": 2},
+		"relay": map[string]any{
+			"type": "smtp", "host": "localhost", "port": 25,
+			"username": "user", "password": "secret", "from": "noreply@example.com",
+		},
+		"broken": map[string]any{"type": "sendgrid"},
+	})
+
+	provider, err := factory.CreateChain([]string{"sendgrid"}, CompositeConfig{})
+	if err != nil || provider.Name() != "sendgrid" {
+		t.Fatalf("single provider: got %v, %v", provider, err)
+	}
+
+	provider, err = factory.CreateChain([]string{"sendgrid", "relay"}, CompositeConfig{Mode: RoutingWeighted})
+	if err != nil {
+		t.Fatalf("chain: %v", err)
+	}
+	defer provider.Close()
+	if provider.Name() != "composite(sendgrid,smtp)" {
+		t.Fatalf("got %s", provider.Name())
+	}
+
+	for _, names := range [][]string{nil, {"sendgrid", "broken"}, {"mailchimp"}} {
+		if _, err := factory.CreateChain(names, CompositeConfig{}); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("CreateChain(%v) = %v, want invalid configuration", names, err)
+		}
+	}
+}
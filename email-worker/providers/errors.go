@@ -0,0 +1,111 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrorClass tells how a failed send should be retried
+type ErrorClass string
+
+const (
+	// ErrorClassTransient failures may succeed when retried with backoff. Errors
+	// that are not classified are treated as transient.
+	ErrorClassTransient ErrorClass = "transient"
+	// ErrorClassPermanent failures fail again however often they are retried,
+	// such as a rejected recipient address
+	ErrorClassPermanent ErrorClass = "permanent"
+	// ErrorClassThrottled failures were refused by the provider's rate limits,
+	// they are retried once the provider allows it
+	ErrorClassThrottled ErrorClass = "throttled"
+)
+
+// NewSendError creates a send error of the given class wrapping the provider's error
+func NewSendError(class ErrorClass, err error) *ProviderError {
+	return &ProviderError{Message: ErrSendFailed.Message, Err: err, Class: class}
+}
+
+// NewHTTPSendError creates a send error for an HTTP response of a provider
+// API, classified by its status code. retryAfter is the Retry-After header.
+func NewHTTPSendError(statusCode int, retryAfter string, body string) *ProviderError {
+	sendErr := NewSendError(ClassifyHTTPStatus(statusCode), fmt.Errorf("HTTP %d: %s", statusCode, body))
+	sendErr.StatusCode = statusCode
+	sendErr.RetryAfter = ParseRetryAfter(retryAfter, time.Now())
+	return sendErr
+}
+
+// ClassifyHTTPStatus classifies a failed HTTP response of a provider API.
+// Authentication failures are transient: they come from the configuration
+// rather than the email, and dead-lettering every job would not fix it.
+func ClassifyHTTPStatus(statusCode int) ErrorClass {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return ErrorClassThrottled
+	case statusCode == http.StatusRequestTimeout,
+		statusCode == http.StatusUnauthorized,
+		statusCode == http.StatusForbidden:
+		return ErrorClassTransient
+	case statusCode >= 400 && statusCode < 500:
+		return ErrorClassPermanent
+	default:
+		return ErrorClassTransient
+	}
+}
+
+// ParseRetryAfter parses a Retry-After header given in seconds or as an HTTP
+// date. It returns zero if the header is missing or invalid.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// Classify returns the class of a send error and how long the provider asked
+// to wait before retrying. Errors that are not classified are transient.
+func Classify(err error) (ErrorClass, time.Duration) {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) && providerErr.Class != "" {
+		return providerErr.Class, providerErr.RetryAfter
+	}
+	return ErrorClassTransient, 0
+}
+
+// IsCircuitOpen reports whether a send was rejected because the circuit
+// breakers of every provider it could be sent through are open
+func IsCircuitOpen(err error) bool {
+	return isRejection(err, ErrCircuitOpen)
+}
+
+// IsRateLimited reports whether a send was rejected because it was over a
+// rate limit, or the providers it could be sent through were either over
+// their rate limits or had open circuit breakers
+func IsRateLimited(err error) bool {
+	return isRejection(err, ErrRateLimited)
+}
+
+// IsDeferred reports whether a send was rejected before reaching a provider,
+// so it can wait for the providers without counting as a failed attempt
+func IsDeferred(err error) bool {
+	return IsCircuitOpen(err) || IsRateLimited(err)
+}
+
+// isRejection reports whether the outermost provider error of err was
+// created from sentinel. Inner errors are not matched, as a failover error
+// wraps the errors of every backend.
+func isRejection(err error, sentinel *ProviderError) bool {
+	var providerErr *ProviderError
+	return errors.As(err, &providerErr) && providerErr.Message == sentinel.Message
+}
@@ -0,0 +1,110 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+func TestClassifyHTTPStatus(t *testing.T) {
+	cases := map[int]ErrorClass{
+		http.StatusTooManyRequests:     ErrorClassThrottled,
+		http.StatusBadRequest:          ErrorClassPermanent,
+		http.StatusUnprocessableEntity: ErrorClassPermanent,
+		http.StatusUnauthorized:        ErrorClassTransient,
+		http.StatusRequestTimeout:      ErrorClassTransient,
+		http.StatusInternalServerError: ErrorClassTransient,
+		http.StatusServiceUnavailable:  ErrorClassTransient,
+	}
+	for status, want := range cases {
+		if got := ClassifyHTTPStatus(status); got != want {
+			t.Errorf("ClassifyHTTPStatus(%d) = %s, want %s", status, got, want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	if got := ParseRetryAfter("30", now); got != 30*time.Second {
+		t.Errorf("seconds: got %v, want 30s", got)
+	}
+	date := now.Add(2 * time.Minute).Format(http.TimeFormat)
+	if got := ParseRetryAfter(date, now); got != 2*time.Minute {
+		t.Errorf("date: got %v, want 2m", got)
+	}
+	for _, value := range []string{"", "-5", "soon", now.Add(-time.Minute).Format(http.TimeFormat)} {
+		if got := ParseRetryAfter(value, now); got != 0 {
+			t.Errorf("ParseRetryAfter(%q) = %v, want 0", value, got)
+		}
+	}
+}
+
+func TestHTTPSendError(t *testing.T) {
+	err := fmt.Errorf("failed to send email: %w", NewHTTPSendError(429, "10", "rate limited"))
+
+	if !errors.Is(err, ErrSendFailed) {
+		t.Fatal("classified send error no longer matches ErrSendFailed")
+	}
+	class, retryAfter := Classify(err)
+	if class != ErrorClassThrottled || retryAfter != 10*time.Second {
+		t.Fatalf("got %s after %v, want throttled after 10s", class, retryAfter)
+	}
+	if want := "failed to send email: failed to send email: HTTP 429: rate limited"; err.Error() != want {
+		t.Fatalf("got message %q, want %q", err.Error(), want)
+	}
+}
+
+func TestClassifyUnclassifiedErrors(t *testing.T) {
+	for _, err := range []error{
+		errors.New("template not found"),
+		fmt.Errorf("%w: no recipients specified", ErrSendFailed),
+	} {
+		if class, _ := Classify(err); class != ErrorClassTransient {
+			t.Errorf("Classify(%q) = %s, want transient", err, class)
+		}
+	}
+}
+
+func TestClassifySESError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{awserr.New("Throttling", "Maximum sending rate exceeded.", nil), ErrorClassThrottled},
+		{awserr.New("MessageRejected", "Email address is not verified.", nil), ErrorClassPermanent},
+		{awserr.NewRequestFailure(awserr.New("InternalFailure", "boom", nil), 500, "req"), ErrorClassTransient},
+		{awserr.NewRequestFailure(awserr.New("ValidationError", "bad", nil), 400, "req"), ErrorClassPermanent},
+		{errors.New("connection reset by peer"), ErrorClassTransient},
+	}
+	for _, c := range cases {
+		if got := classifySESError(c.err).Class; got != c.want {
+			t.Errorf("classifySESError(%q) = %s, want %s", c.err, got, c.want)
+		}
+	}
+}
+
+func TestClassifySMTPError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want ErrorClass
+		code int
+	}{
+		{&textproto.Error{Code: 421, Msg: "Service not available"}, ErrorClassTransient, 421},
+		{&textproto.Error{Code: 535, Msg: "Authentication failed"}, ErrorClassTransient, 535},
+		{errors.New("gomail: could not send email 1: 550 5.1.1 User unknown"), ErrorClassPermanent, 550},
+		{errors.New("gomail: could not send email 1: 452 4.2.2 Mailbox full"), ErrorClassTransient, 452},
+		{errors.New("dial tcp 10.0.0.1:25: i/o timeout"), ErrorClassTransient, 0},
+	}
+	for _, c := range cases {
+		got := classifySMTPError(c.err)
+		if got.Class != c.want || got.StatusCode != c.code {
+			t.Errorf("classifySMTPError(%q) = %s/%d, want %s/%d", c.err, got.Class, got.StatusCode, c.want, c.code)
+		}
+	}
+}
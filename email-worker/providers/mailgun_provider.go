@@ -0,0 +1,261 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultMailgunEndpoint is the Mailgun API of the US region
+const defaultMailgunEndpoint = "https://api.mailgun.net"
+
+// mailgunMaxTags is how many tags Mailgun accepts on a message
+const mailgunMaxTags = 3
+
+// MailgunProvider implements the Provider interface for Mailgun
+type MailgunProvider struct {
+	client   *http.Client
+	endpoint string
+	apiKey   string
+	domain   string
+	from     string
+	fromName string
+}
+
+// MailgunConfig holds Mailgun configuration
+type MailgunConfig struct {
+	APIKey string `json:"api_key"`
+	// Domain is the sending domain the messages are sent from
+	Domain   string `json:"domain"`
+	From     string `json:"from"`
+	FromName string `json:"from_name"`
+	// Endpoint is the base URL of the Mailgun API, such as the EU region's
+	Endpoint string `json:"endpoint"`
+}
+
+// NewMailgunProvider creates a new Mailgun provider
+func NewMailgunProvider(config map[string]any) (Provider, error) {
+	apiKey, ok := config["api_key"].(string)
+	if !ok || apiKey == "" {
+		return nil, fmt.Errorf("%w: missing or invalid api_key", ErrInvalidConfig)
+	}
+
+	domain, ok := config["domain"].(string)
+	if !ok || domain == "" {
+		return nil, fmt.Errorf("%w: missing or invalid domain", ErrInvalidConfig)
+	}
+
+	from, ok := config["from"].(string)
+	if !ok || from == "" {
+		return nil, fmt.Errorf("%w: missing or invalid from email", ErrInvalidConfig)
+	}
+
+	fromName, _ := config["from_name"].(string)
+	if fromName == "" {
+		fromName = "Booking System"
+	}
+
+	endpoint, _ := config["endpoint"].(string)
+	if endpoint == "" {
+		endpoint = defaultMailgunEndpoint
+	}
+
+	return &MailgunProvider{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		apiKey:   apiKey,
+		domain:   domain,
+		from:     from,
+		fromName: fromName,
+	}, nil
+}
+
+// Name returns the provider name
+func (p *MailgunProvider) Name() string {
+	return "mailgun"
+}
+
+// Send sends an email via the Mailgun messages API
+func (p *MailgunProvider) Send(ctx context.Context, req *EmailRequest) (*EmailResponse, error) {
+	if len(req.To) == 0 {
+		return nil, fmt.Errorf("%w: no recipients specified", ErrSendFailed)
+	}
+
+	body, contentType, err := p.form(req)
+	if err != nil {
+		return nil, NewSendError(ErrorClassPermanent, err)
+	}
+
+	messageID, err := p.post(ctx, body, contentType)
+	if err != nil {
+		return &EmailResponse{
+			Status:   "failed",
+			Provider: p.Name(),
+			Error:    err.Error(),
+			SentAt:   time.Now(),
+		}, err
+	}
+
+	return &EmailResponse{
+		MessageID: messageID,
+		Status:    "sent",
+		Provider:  p.Name(),
+		SentAt:    time.Now(),
+	}, nil
+}
+
+// SendBatch sends a batch with a request per recipient
+func (p *MailgunProvider) SendBatch(ctx context.Context, batch *BatchRequest) ([]BatchResult, error) {
+	return SendEach(ctx, p, batch)
+}
+
+// form encodes an email as the multipart form of the messages API. Headers
+// are passed as h: fields, metadata as v: variables and tags as o:tag.
+func (p *MailgunProvider) form(req *EmailRequest) (*bytes.Buffer, string, error) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	fields := [][2]string{
+		{"from", fmt.Sprintf("%s <%s>", p.fromName, p.from)},
+		{"subject", req.Subject},
+	}
+	for _, address := range req.To {
+		fields = append(fields, [2]string{"to", address})
+	}
+	for _, address := range req.CC {
+		fields = append(fields, [2]string{"cc", address})
+	}
+	for _, address := range req.BCC {
+		fields = append(fields, [2]string{"bcc", address})
+	}
+	if req.TextContent != "" {
+		fields = append(fields, [2]string{"text", req.TextContent})
+	}
+	if req.HTMLContent != "" {
+		fields = append(fields, [2]string{"html", req.HTMLContent})
+	}
+	if req.ReplyTo != "" {
+		fields = append(fields, [2]string{"h:Reply-To", req.ReplyTo})
+	}
+	for key, value := range req.Headers {
+		fields = append(fields, [2]string{"h:" + key, value})
+	}
+	for i, tag := range req.Tags {
+		if i == mailgunMaxTags {
+			break
+		}
+		fields = append(fields, [2]string{"o:tag", tag})
+	}
+	for key, value := range req.Metadata {
+		fields = append(fields, [2]string{"v:" + key, value})
+	}
+
+	for _, field := range fields {
+		if err := w.WriteField(field[0], field[1]); err != nil {
+			return nil, "", fmt.Errorf("failed to encode %s: %w", field[0], err)
+		}
+	}
+	for _, attachment := range req.Attachments {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="attachment"; filename=%q`, attachment.Filename))
+		if attachment.ContentType != "" {
+			header.Set("Content-Type", attachment.ContentType)
+		}
+		part, err := w.CreatePart(header)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode attachment %s: %w", attachment.Filename, err)
+		}
+		if _, err := part.Write(attachment.Content); err != nil {
+			return nil, "", fmt.Errorf("failed to encode attachment %s: %w", attachment.Filename, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return body, w.FormDataContentType(), nil
+}
+
+// post sends a message form to the Mailgun API and returns its message ID
+func (p *MailgunProvider) post(ctx context.Context, body io.Reader, contentType string) (string, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url("messages"), body)
+	if err != nil {
+		return "", NewSendError(ErrorClassPermanent, err)
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.SetBasicAuth("api", p.apiKey)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return "", NewSendError(ErrorClassTransient, err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 400 {
+		return "", NewHTTPSendError(resp.StatusCode, resp.Header.Get("Retry-After"), string(respBody))
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", NewSendError(ErrorClassTransient, fmt.Errorf("failed to decode Mailgun response: %w", err))
+	}
+	return strings.Trim(result.ID, "<>"), nil
+}
+
+// url returns the URL of a resource of the sending domain
+func (p *MailgunProvider) url(resource string) string {
+	return p.endpoint + "/v3/" + url.PathEscape(p.domain) + "/" + resource
+}
+
+// Validate validates the Mailgun configuration
+func (p *MailgunProvider) Validate() error {
+	if p.apiKey == "" {
+		return fmt.Errorf("%w: missing API key", ErrInvalidConfig)
+	}
+
+	if p.domain == "" {
+		return fmt.Errorf("%w: missing domain", ErrInvalidConfig)
+	}
+
+	if p.from == "" {
+		return fmt.Errorf("%w: missing from email", ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// Health checks that the API key can read the sending domain
+func (p *MailgunProvider) Health(ctx context.Context) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint+"/v3/domains/"+url.PathEscape(p.domain), nil)
+	if err != nil {
+		return fmt.Errorf("%w: Mailgun health check failed: %v", ErrProviderUnhealthy, err)
+	}
+	httpReq.SetBasicAuth("api", p.apiKey)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("%w: Mailgun health check failed: %v", ErrProviderUnhealthy, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: Mailgun health check failed: HTTP %d", ErrProviderUnhealthy, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Close closes the Mailgun provider
+func (p *MailgunProvider) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,113 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestMailgun(t *testing.T, handler http.HandlerFunc) *MailgunProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	provider, err := NewMailgunProvider(map[string]any{
+		"api_key": "key", "domain": "mg.example.com", "from": "noreply@example.com", "endpoint": server.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewMailgunProvider: %v", err)
+	}
+	return provider.(*MailgunProvider)
+}
+
+func TestMailgunProvider_Send(t *testing.T) {
+	var (
+		form       map[string][]string
+		attachment string
+	)
+	provider := newTestMailgun(t, func(w http.ResponseWriter, r *http.Request) {
+		if user, key, _ := r.BasicAuth(); r.URL.Path != "/v3/mg.example.com/messages" || user != "api" || key != "key" {
+			http.Error(w, "unexpected request", http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		form = r.MultipartForm.Value
+		file, header, err := r.FormFile("attachment")
+		if err == nil {
+			content, _ := io.ReadAll(file)
+			attachment = header.Filename + ":" + header.Header.Get("Content-Type") + ":" + string(content)
+		}
+		fmt.Fprint(w, `{"id": "<20240101.1@mg.example.com>", "message": "Queued. Thank you."}`)
+	})
+
+	resp, err := provider.Send(context.Background(), &EmailRequest{
+		To:          []string{"a@example.com", "b@example.com"},
+		CC:          []string{"c@example.com"},
+		BCC:         []string{"d@example.com"},
+		Subject:     "Hello",
+		TextContent: "Hello",
+		HTMLContent: "<p>Hello</p>",
+		ReplyTo:     "help@example.com",
+		Headers:     map[string]string{"X-Campaign": "spring"},
+		Tags:        []string{"newsletter", "spring", "eu", "dropped"},
+		Metadata:    map[string]string{"job_id": "42"},
+		Attachments: []Attachment{{Filename: "ticket.txt", ContentType: "text/plain", Content: []byte("seat 12")}},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if resp.MessageID != "20240101.1@mg.example.com" || resp.Provider != "mailgun" {
+		t.Fatalf("got %+v", resp)
+	}
+
+	checks := map[string]int{"to": 2, "cc": 1, "bcc": 1, "o:tag": mailgunMaxTags}
+	for field, want := range checks {
+		if len(form[field]) != want {
+			t.Errorf("got %s %v, want %d values", field, form[field], want)
+		}
+	}
+	if form["h:X-Campaign"][0] != "spring" || form["h:Reply-To"][0] != "help@example.com" || form["v:job_id"][0] != "42" {
+		t.Errorf("got form %v", form)
+	}
+	if attachment != "ticket.txt:text/plain:seat 12" {
+		t.Errorf("got attachment %q", attachment)
+	}
+}
+
+func TestMailgunProvider_ClassifiesErrors(t *testing.T) {
+	status := http.StatusBadRequest
+	provider := newTestMailgun(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(status)
+		fmt.Fprint(w, `{"message": "to parameter is not a valid address"}`)
+	})
+	req := &EmailRequest{To: []string{"bad"}, Subject: "Hello", TextContent: "Hello"}
+
+	if _, err := provider.Send(context.Background(), req); !isClass(err, ErrorClassPermanent) {
+		t.Fatalf("got %v, want a permanent error", err)
+	}
+	status = http.StatusTooManyRequests
+	_, err := provider.Send(context.Background(), req)
+	if class, retryAfter := Classify(err); class != ErrorClassThrottled || retryAfter.Seconds() != 7 {
+		t.Fatalf("got %s after %v, want throttled after 7s", class, retryAfter)
+	}
+	if err := provider.Health(context.Background()); err == nil {
+		t.Fatal("health check with a rejected API key should fail")
+	}
+}
+
+// isClass reports whether err is classified as class
+func isClass(err error, class ErrorClass) bool {
+	got, _ := Classify(err)
+	return err != nil && got == class
+}
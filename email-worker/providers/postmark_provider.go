@@ -0,0 +1,264 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultPostmarkEndpoint is the Postmark API
+const defaultPostmarkEndpoint = "https://api.postmarkapp.com"
+
+// defaultPostmarkStream is the message stream of transactional mail
+const defaultPostmarkStream = "outbound"
+
+// PostmarkProvider implements the Provider interface for Postmark
+type PostmarkProvider struct {
+	client   *http.Client
+	endpoint string
+	token    string
+	stream   string
+	from     string
+	fromName string
+}
+
+// PostmarkConfig holds Postmark configuration
+type PostmarkConfig struct {
+	// APIKey is the server token
+	APIKey   string `json:"api_key"`
+	From     string `json:"from"`
+	FromName string `json:"from_name"`
+	// MessageStream is the stream the emails are sent through
+	MessageStream string `json:"message_stream"`
+	Endpoint      string `json:"endpoint"`
+}
+
+// postmarkEmail is an email of the Postmark email API
+type postmarkEmail struct {
+	From          string               `json:"From"`
+	To            string               `json:"To"`
+	Cc            string               `json:"Cc,omitempty"`
+	Bcc           string               `json:"Bcc,omitempty"`
+	Subject       string               `json:"Subject"`
+	HTMLBody      string               `json:"HtmlBody,omitempty"`
+	TextBody      string               `json:"TextBody,omitempty"`
+	ReplyTo       string               `json:"ReplyTo,omitempty"`
+	Headers       []postmarkHeader     `json:"Headers,omitempty"`
+	Tag           string               `json:"Tag,omitempty"`
+	Metadata      map[string]string    `json:"Metadata,omitempty"`
+	Attachments   []postmarkAttachment `json:"Attachments,omitempty"`
+	MessageStream string               `json:"MessageStream"`
+}
+
+type postmarkHeader struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+type postmarkAttachment struct {
+	Name        string `json:"Name"`
+	Content     []byte `json:"Content"`
+	ContentType string `json:"ContentType"`
+}
+
+// postmarkResponse is the response of the Postmark email API
+type postmarkResponse struct {
+	MessageID string `json:"MessageID"`
+	ErrorCode int    `json:"ErrorCode"`
+	Message   string `json:"Message"`
+}
+
+// NewPostmarkProvider creates a new Postmark provider
+func NewPostmarkProvider(config map[string]any) (Provider, error) {
+	token, ok := config["api_key"].(string)
+	if !ok || token == "" {
+		return nil, fmt.Errorf("%w: missing or invalid api_key", ErrInvalidConfig)
+	}
+
+	from, ok := config["from"].(string)
+	if !ok || from == "" {
+		return nil, fmt.Errorf("%w: missing or invalid from email", ErrInvalidConfig)
+	}
+
+	fromName, _ := config["from_name"].(string)
+	if fromName == "" {
+		fromName = "Booking System"
+	}
+
+	stream, _ := config["message_stream"].(string)
+	if stream == "" {
+		stream = defaultPostmarkStream
+	}
+
+	endpoint, _ := config["endpoint"].(string)
+	if endpoint == "" {
+		endpoint = defaultPostmarkEndpoint
+	}
+
+	return &PostmarkProvider{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		token:    token,
+		stream:   stream,
+		from:     from,
+		fromName: fromName,
+	}, nil
+}
+
+// Name returns the provider name
+func (p *PostmarkProvider) Name() string {
+	return "postmark"
+}
+
+// Send sends an email via the Postmark email API. Postmark takes a single
+// tag, so only the first tag of the email is passed.
+func (p *PostmarkProvider) Send(ctx context.Context, req *EmailRequest) (*EmailResponse, error) {
+	if len(req.To) == 0 {
+		return nil, fmt.Errorf("%w: no recipients specified", ErrSendFailed)
+	}
+
+	email := postmarkEmail{
+		From:          fmt.Sprintf("%s <%s>", p.fromName, p.from),
+		To:            strings.Join(req.To, ","),
+		Cc:            strings.Join(req.CC, ","),
+		Bcc:           strings.Join(req.BCC, ","),
+		Subject:       req.Subject,
+		HTMLBody:      req.HTMLContent,
+		TextBody:      req.TextContent,
+		ReplyTo:       req.ReplyTo,
+		Metadata:      req.Metadata,
+		MessageStream: p.stream,
+	}
+	if len(req.Tags) > 0 {
+		email.Tag = req.Tags[0]
+	}
+
+	// Sort headers so requests are stable
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		email.Headers = append(email.Headers, postmarkHeader{Name: name, Value: req.Headers[name]})
+	}
+
+	// Content is base64 encoded by encoding/json
+	for _, attachment := range req.Attachments {
+		email.Attachments = append(email.Attachments, postmarkAttachment{
+			Name:        attachment.Filename,
+			Content:     attachment.Content,
+			ContentType: attachment.ContentType,
+		})
+	}
+
+	messageID, err := p.post(ctx, &email)
+	if err != nil {
+		return &EmailResponse{
+			Status:   "failed",
+			Provider: p.Name(),
+			Error:    err.Error(),
+			SentAt:   time.Now(),
+		}, err
+	}
+
+	return &EmailResponse{
+		MessageID: messageID,
+		Status:    "sent",
+		Provider:  p.Name(),
+		SentAt:    time.Now(),
+	}, nil
+}
+
+// SendBatch sends a batch with a request per recipient
+func (p *PostmarkProvider) SendBatch(ctx context.Context, batch *BatchRequest) ([]BatchResult, error) {
+	return SendEach(ctx, p, batch)
+}
+
+// post sends an email to the Postmark API and returns its message ID
+func (p *PostmarkProvider) post(ctx context.Context, email *postmarkEmail) (string, error) {
+	body, err := json.Marshal(email)
+	if err != nil {
+		return "", NewSendError(ErrorClassPermanent, err)
+	}
+
+	httpReq, err := p.request(ctx, http.MethodPost, "/email", bytes.NewReader(body))
+	if err != nil {
+		return "", NewSendError(ErrorClassPermanent, err)
+	}
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return "", NewSendError(ErrorClassTransient, err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 400 {
+		return "", NewHTTPSendError(resp.StatusCode, resp.Header.Get("Retry-After"), string(respBody))
+	}
+
+	var result postmarkResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", NewSendError(ErrorClassTransient, fmt.Errorf("failed to decode Postmark response: %w", err))
+	}
+	if result.ErrorCode != 0 {
+		return "", NewSendError(ErrorClassPermanent, fmt.Errorf("Postmark error %d: %s", result.ErrorCode, result.Message))
+	}
+	return result.MessageID, nil
+}
+
+// request creates an authenticated request to the Postmark API
+func (p *PostmarkProvider) request(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, method, p.endpoint+path, body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Postmark-Server-Token", p.token)
+	return httpReq, nil
+}
+
+// Validate validates the Postmark configuration
+func (p *PostmarkProvider) Validate() error {
+	if p.token == "" {
+		return fmt.Errorf("%w: missing server token", ErrInvalidConfig)
+	}
+
+	if p.from == "" {
+		return fmt.Errorf("%w: missing from email", ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// Health checks that the server token can read its server
+func (p *PostmarkProvider) Health(ctx context.Context) error {
+	httpReq, err := p.request(ctx, http.MethodGet, "/server", nil)
+	if err != nil {
+		return fmt.Errorf("%w: Postmark health check failed: %v", ErrProviderUnhealthy, err)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("%w: Postmark health check failed: %v", ErrProviderUnhealthy, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: Postmark health check failed: HTTP %d", ErrProviderUnhealthy, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Close closes the Postmark provider
+func (p *PostmarkProvider) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
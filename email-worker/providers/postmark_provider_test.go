@@ -0,0 +1,109 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestPostmark(t *testing.T, handler http.HandlerFunc) *PostmarkProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	provider, err := NewPostmarkProvider(map[string]any{
+		"api_key": "token", "from": "noreply@example.com", "endpoint": server.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewPostmarkProvider: %v", err)
+	}
+	return provider.(*PostmarkProvider)
+}
+
+func TestPostmarkProvider_Send(t *testing.T) {
+	var email postmarkEmail
+	provider := newTestPostmark(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/email" || r.Header.Get("X-Postmark-Server-Token") != "token" {
+			http.Error(w, "unexpected request", http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&email); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"To": "a@example.com", "MessageID": "b7bc2f4a-e38e-4336-af7d-e6c392c2f817", "ErrorCode": 0, "Message": "OK"}`)
+	})
+
+	resp, err := provider.Send(context.Background(), &EmailRequest{
+		To:          []string{"a@example.com", "b@example.com"},
+		CC:          []string{"c@example.com"},
+		BCC:         []string{"d@example.com"},
+		Subject:     "Hello",
+		HTMLContent: "<p>Hello</p>",
+		Headers:     map[string]string{"X-Campaign": "spring"},
+		Tags:        []string{"booking_confirmation", "ignored"},
+		Metadata:    map[string]string{"job_id": "42"},
+		Attachments: []Attachment{{Filename: "ticket.pdf", ContentType: "application/pdf", Content: []byte("%PDF")}},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if resp.MessageID != "b7bc2f4a-e38e-4336-af7d-e6c392c2f817" || resp.Provider != "postmark" {
+		t.Fatalf("got %+v", resp)
+	}
+
+	if email.To != "a@example.com,b@example.com" || email.Cc != "c@example.com" || email.Bcc != "d@example.com" {
+		t.Errorf("got recipients %q, %q, %q", email.To, email.Cc, email.Bcc)
+	}
+	if email.Tag != "booking_confirmation" || email.Metadata["job_id"] != "42" || email.MessageStream != "outbound" {
+		t.Errorf("got tag %q, metadata %v and stream %q", email.Tag, email.Metadata, email.MessageStream)
+	}
+	if len(email.Headers) != 1 || email.Headers[0].Name != "X-Campaign" {
+		t.Errorf("got headers %+v", email.Headers)
+	}
+	if len(email.Attachments) != 1 || string(email.Attachments[0].Content) != "%PDF" {
+		t.Errorf("got attachments %+v", email.Attachments)
+	}
+}
+
+func TestPostmarkProvider_ClassifiesErrors(t *testing.T) {
+	provider := newTestPostmark(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/server" {
+			fmt.Fprint(w, `{"ID": 1, "Name": "Bookings"}`)
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"ErrorCode": 406, "Message": "You tried to send to a recipient that has been marked as inactive."}`)
+	})
+
+	_, err := provider.Send(context.Background(), &EmailRequest{To: []string{"gone@example.com"}, Subject: "Hello"})
+	var sendErr *ProviderError
+	if !isClass(err, ErrorClassPermanent) || !errors.As(err, &sendErr) || sendErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("got %v, want a permanent error", err)
+	}
+	if err := provider.Health(context.Background()); err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+}
+
+func TestProviderFactory_CreatesHTTPProviders(t *testing.T) {
+	factory := NewProviderFactory(map[string]any{
+		"mailgun":   map[string]any{"api_key": "key", "domain": "mg.example.com", "from": "noreply@example.com"},
+		"postmark":  map[string]any{"api_key": "token", "from": "noreply@example.com"},
+		"marketing": map[string]any{"type": "mailgun", "api_key": "key", "from": "noreply@example.com"},
+	})
+
+	for _, name := range []string{"mailgun", "postmark"} {
+		provider, err := factory.CreateNamedProvider(name)
+		if err != nil || provider.Name() != name {
+			t.Fatalf("CreateNamedProvider(%s) = %v, %v", name, provider, err)
+		}
+	}
+	if _, err := factory.CreateNamedProvider("marketing"); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("got %v for Mailgun without a domain, want invalid configuration", err)
+	}
+}
@@ -2,6 +2,9 @@ package providers
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 )
 
@@ -18,6 +21,15 @@ type EmailRequest struct {
 	ReplyTo     string            `json:"reply_to"`
 	Headers     map[string]string `json:"headers"`
 	Attachments []Attachment      `json:"attachments"`
+	// Template, Priority and Tenant describe the job the email is sent for,
+	// they are only used to route it to a provider
+	Template string `json:"template,omitempty"`
+	Priority string `json:"priority,omitempty"`
+	Tenant   string `json:"tenant,omitempty"`
+	// Tags and Metadata are passed to providers that support them, where
+	// they show up in analytics and webhooks
+	Tags     []string          `json:"tags,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // Attachment represents an email attachment
@@ -44,6 +56,11 @@ type Provider interface {
 	// Send sends an email
 	Send(ctx context.Context, req *EmailRequest) (*EmailResponse, error)
 
+	// SendBatch sends a batch and returns a result per recipient, in the
+	// order of the recipients. It only fails as a whole when the batch is
+	// invalid. Providers without native batches send it with SendEach.
+	SendBatch(ctx context.Context, batch *BatchRequest) ([]BatchResult, error)
+
 	// Validate validates the provider configuration
 	Validate() error
 
@@ -61,30 +78,155 @@ const (
 	ProviderTypeSendGrid ProviderType = "sendgrid"
 	ProviderTypeSES      ProviderType = "ses"
 	ProviderTypeSMTP     ProviderType = "smtp"
+	ProviderTypeMailgun  ProviderType = "mailgun"
+	ProviderTypePostmark ProviderType = "postmark"
+	ProviderTypeCapture  ProviderType = "capture"
 )
 
 // ProviderFactory creates email providers
 type ProviderFactory struct {
 	config map[string]any
+
+	// circuits holds the circuit breaker state of each provider by name
+	mu       sync.Mutex
+	breaker  BreakerConfig
+	circuits map[string]*circuit
+
+	limiter        RateLimiter
+	providerLimits map[string]RateLimit
+	domainLimits   []RateLimit
+
+	captureStore CaptureStore
+	capture      CaptureConfig
 }
 
-// NewProviderFactory creates a new provider factory
+// NewProviderFactory creates a new provider factory. config holds the settings
+// of each provider by name, the type of a provider is its "type" setting or
+// else its name.
 func NewProviderFactory(config map[string]any) *ProviderFactory {
-	return &ProviderFactory{config: config}
+	return &ProviderFactory{config: config, circuits: make(map[string]*circuit)}
+}
+
+// SetCircuitBreaker wraps the providers created from now on in circuit
+// breakers, one per provider name
+func (f *ProviderFactory) SetCircuitBreaker(config BreakerConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.breaker = config
+}
+
+// CircuitBreakers returns the state of the circuit breaker of each provider
+// created with one, by name
+func (f *ProviderFactory) CircuitBreakers() []BreakerStatus {
+	f.mu.Lock()
+	circuits := make([]*circuit, 0, len(f.circuits))
+	for _, c := range f.circuits {
+		circuits = append(circuits, c)
+	}
+	f.mu.Unlock()
+
+	sort.Slice(circuits, func(i, j int) bool { return circuits[i].name < circuits[j].name })
+	statuses := make([]BreakerStatus, len(circuits))
+	for i, c := range circuits {
+		statuses[i] = c.status()
+	}
+	return statuses
+}
+
+// withBreaker wraps the provider created under name in its circuit breaker,
+// if circuit breakers are enabled
+func (f *ProviderFactory) withBreaker(name string, provider Provider) Provider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.breaker.FailureThreshold <= 0 {
+		return provider
+	}
+
+	c, ok := f.circuits[name]
+	if !ok {
+		c = newCircuit(name, f.breaker)
+		f.circuits[name] = c
+	}
+	return &CircuitBreaker{Provider: provider, circuit: c}
 }
 
 // CreateProvider creates a provider based on type
 func (f *ProviderFactory) CreateProvider(providerType ProviderType) (Provider, error) {
+	return f.CreateNamedProvider(string(providerType))
+}
+
+// CreateNamedProvider creates the provider configured under name
+func (f *ProviderFactory) CreateNamedProvider(name string) (Provider, error) {
+	config, ok := f.config[name].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: provider %s is not configured", ErrInvalidConfig, name)
+	}
+
+	providerType := ProviderType(name)
+	if configured, _ := config["type"].(string); configured != "" {
+		providerType = ProviderType(configured)
+	}
+
+	var (
+		provider Provider
+		err      error
+	)
 	switch providerType {
 	case ProviderTypeSendGrid:
-		return NewSendGridProvider(f.config)
+		provider, err = NewSendGridProvider(config)
 	case ProviderTypeSES:
-		return NewSESProvider(f.config)
+		provider, err = NewSESProvider(config)
 	case ProviderTypeSMTP:
-		return NewSMTPProvider(f.config)
+		provider, err = NewSMTPProvider(config)
+	case ProviderTypeMailgun:
+		provider, err = NewMailgunProvider(config)
+	case ProviderTypePostmark:
+		provider, err = NewPostmarkProvider(config)
+	case ProviderTypeCapture:
+		provider, err = f.newCaptureProvider(config)
 	default:
-		return nil, ErrUnsupportedProvider
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProvider, providerType)
 	}
+	if err != nil {
+		return nil, err
+	}
+	return f.withRateLimit(name, f.withBreaker(name, provider)), nil
+}
+
+// CreateChain creates the provider that sends through the named providers. A
+// single provider is returned as is, several are combined into a composite
+// provider weighted by their "weight" settings.
+func (f *ProviderFactory) CreateChain(names []string, config CompositeConfig) (Provider, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: no email provider configured", ErrInvalidConfig)
+	}
+
+	backends := make([]Backend, 0, len(names))
+	closeAll := func() {
+		for _, b := range backends {
+			b.Provider.Close()
+		}
+	}
+	for _, name := range names {
+		provider, err := f.CreateNamedProvider(name)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to create provider %s: %w", name, err)
+		}
+		settings, _ := f.config[name].(map[string]any)
+		weight, _ := settings["weight"].(int)
+		backends = append(backends, Backend{Provider: provider, Weight: weight})
+	}
+
+	if len(backends) == 1 {
+		return backends[0].Provider, nil
+	}
+	composite, err := NewCompositeProvider(backends, config)
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	return composite, nil
 }
 
 // Provider errors
@@ -93,12 +235,20 @@ var (
 	ErrInvalidConfig       = &ProviderError{Message: "invalid configuration"}
 	ErrSendFailed          = &ProviderError{Message: "failed to send email"}
 	ErrProviderUnhealthy   = &ProviderError{Message: "provider is unhealthy"}
+	ErrCircuitOpen         = &ProviderError{Message: "circuit breaker is open"}
+	ErrRateLimited         = &ProviderError{Message: "rate limit exceeded"}
 )
 
 // ProviderError represents a provider-specific error
 type ProviderError struct {
 	Message string
 	Err     error
+	// Class tells the retry policy how to treat a failed send
+	Class ErrorClass
+	// StatusCode is the HTTP status or SMTP reply code of the provider, if any
+	StatusCode int
+	// RetryAfter is how long the provider asked to wait before sending again
+	RetryAfter time.Duration
 }
 
 func (e *ProviderError) Error() string {
@@ -110,4 +260,11 @@ func (e *ProviderError) Error() string {
 
 func (e *ProviderError) Unwrap() error {
 	return e.Err
+}
+
+// Is matches the provider error sentinel e was created from, so classified
+// send errors still match ErrSendFailed
+func (e *ProviderError) Is(target error) bool {
+	sentinel, ok := target.(*ProviderError)
+	return ok && sentinel.Err == nil && sentinel.Message == e.Message
 } 
\ No newline at end of file
@@ -0,0 +1,186 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"booking-system/email-worker/metrics"
+)
+
+// RateLimiter takes send tokens from token buckets. It returns the tokens
+// left in the bucket of key, and how long until a token is available when
+// there was none.
+type RateLimiter interface {
+	Take(ctx context.Context, key string, rate float64, burst int) (float64, time.Duration, error)
+}
+
+// RateLimit limits the send rate of a provider or of a recipient domain
+type RateLimit struct {
+	// Provider names the provider the limit applies to
+	Provider string
+	// Domain is the recipient domain the limit applies to, "*.example.com"
+	// also matches subdomains, and "*" limits every domain on its own
+	Domain string
+	// Rate is how many sends per second the limit allows
+	Rate float64
+	// Burst is how many sends may go at once, it defaults to the rate
+	// rounded up
+	Burst int
+}
+
+// SetRateLimits limits the send rate of providers created from now on, and
+// of the recipient domains routed by a router. Limits take tokens from the
+// buckets of limiter.
+func (f *ProviderFactory) SetRateLimits(limiter RateLimiter, limits []RateLimit) error {
+	providerLimits := make(map[string]RateLimit)
+	var domainLimits []RateLimit
+	for _, limit := range limits {
+		if (limit.Provider == "") == (limit.Domain == "") {
+			return fmt.Errorf("%w: a rate limit needs either a provider or a domain", ErrInvalidConfig)
+		}
+		if limit.Rate <= 0 {
+			return fmt.Errorf("%w: rate limit of %s%s needs a positive rate", ErrInvalidConfig, limit.Provider, limit.Domain)
+		}
+		if limit.Burst <= 0 {
+			limit.Burst = int(math.Ceil(limit.Rate))
+		}
+
+		if limit.Provider != "" {
+			providerLimits[limit.Provider] = limit
+			continue
+		}
+		limit.Domain = strings.ToLower(strings.TrimSpace(limit.Domain))
+		domainLimits = append(domainLimits, limit)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.limiter = limiter
+	f.providerLimits = providerLimits
+	f.domainLimits = domainLimits
+	return nil
+}
+
+// withRateLimit limits the send rate of the provider created under name, if
+// it has a rate limit
+func (f *ProviderFactory) withRateLimit(name string, provider Provider) Provider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	limit, ok := f.providerLimits[name]
+	if !ok || f.limiter == nil {
+		return provider
+	}
+	return &rateLimitedProvider{
+		Provider: provider,
+		limiter:  f.limiter,
+		limit:    limit,
+		bucket:   "provider:" + name,
+	}
+}
+
+// limitDomains takes a send token for each recipient domain of an email that
+// has a rate limit
+func (f *ProviderFactory) limitDomains(ctx context.Context, req *EmailRequest) error {
+	f.mu.Lock()
+	limiter, limits := f.limiter, f.domainLimits
+	f.mu.Unlock()
+	if limiter == nil || len(limits) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, list := range [][]string{req.To, req.CC, req.BCC} {
+		for _, address := range list {
+			domain := recipientDomain(address)
+			if domain == "" || seen[domain] {
+				continue
+			}
+			seen[domain] = true
+
+			for _, limit := range limits {
+				if limit.Domain != "*" && !matchDomain(limit.Domain, domain) {
+					continue
+				}
+				if err := takeToken(ctx, limiter, "domain:"+domain, "domain:"+limit.Domain, limit); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// takeToken takes a send token from bucket, label names the limit in metrics.
+// It returns a rate limit error when the bucket is empty. Sends are not
+// limited when the limiter fails.
+func takeToken(ctx context.Context, limiter RateLimiter, bucket, label string, limit RateLimit) error {
+	tokens, wait, err := limiter.Take(ctx, bucket, limit.Rate, limit.Burst)
+	if err != nil {
+		return nil
+	}
+
+	metrics.RateLimitTokens.WithLabelValues(label).Set(tokens)
+	if wait <= 0 {
+		return nil
+	}
+	metrics.RateLimited.WithLabelValues(label).Inc()
+	return &ProviderError{
+		Message:    ErrRateLimited.Message,
+		Err:        errors.New(bucket),
+		Class:      ErrorClassThrottled,
+		RetryAfter: wait,
+	}
+}
+
+// rateLimitedProvider limits the send rate of a provider
+type rateLimitedProvider struct {
+	Provider
+	limiter RateLimiter
+	limit   RateLimit
+	bucket  string
+}
+
+// Send sends an email if the provider is within its rate limit
+func (p *rateLimitedProvider) Send(ctx context.Context, req *EmailRequest) (*EmailResponse, error) {
+	if err := takeToken(ctx, p.limiter, p.bucket, p.bucket, p.limit); err != nil {
+		return nil, err
+	}
+	return p.Provider.Send(ctx, req)
+}
+
+// SendBatch takes a token for every recipient of a batch and sends the
+// recipients that got one through the provider. The others fail with a rate
+// limit error, so that a chain fails them over.
+func (p *rateLimitedProvider) SendBatch(ctx context.Context, batch *BatchRequest) ([]BatchResult, error) {
+	if err := batch.validate(); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(batch.Recipients))
+	var allowed []int
+	for i := range batch.Recipients {
+		if err := takeToken(ctx, p.limiter, p.bucket, p.bucket, p.limit); err != nil {
+			results[i] = failedResult(p.Name(), err)
+			continue
+		}
+		allowed = append(allowed, i)
+	}
+	if len(allowed) == 0 {
+		return results, nil
+	}
+
+	sent, err := p.Provider.SendBatch(ctx, batch.subBatch(allowed))
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range allowed {
+		results[i] = sent[j]
+	}
+	return results, nil
+}
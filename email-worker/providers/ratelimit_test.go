@@ -0,0 +1,168 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLimiter is a rate limiter whose buckets hold burst tokens and never
+// refill
+type fakeLimiter struct {
+	mu     sync.Mutex
+	tokens map[string]int
+}
+
+func (l *fakeLimiter) Take(ctx context.Context, key string, rate float64, burst int) (float64, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.tokens == nil {
+		l.tokens = make(map[string]int)
+	}
+	tokens, ok := l.tokens[key]
+	if !ok {
+		tokens = burst
+	}
+	if tokens == 0 {
+		return 0, time.Second, nil
+	}
+	l.tokens[key] = tokens - 1
+	return float64(tokens - 1), 0, nil
+}
+
+func (l *fakeLimiter) buckets() map[string]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	buckets := make(map[string]int, len(l.tokens))
+	for key, tokens := range l.tokens {
+		buckets[key] = tokens
+	}
+	return buckets
+}
+
+func newLimitedProvider(provider Provider, limiter RateLimiter, burst int) *rateLimitedProvider {
+	return &rateLimitedProvider{
+		Provider: provider,
+		limiter:  limiter,
+		limit:    RateLimit{Provider: provider.Name(), Rate: 1, Burst: burst},
+		bucket:   "provider:" + provider.Name(),
+	}
+}
+
+func TestRateLimitedProvider_FailsOverAndDefers(t *testing.T) {
+	limiter := &fakeLimiter{}
+	primary := &fakeProvider{name: "ses"}
+	secondary := &fakeProvider{name: "sendgrid"}
+	composite := newTestComposite(t, RoutingFailover,
+		Backend{Provider: newLimitedProvider(primary, limiter, 1)},
+		Backend{Provider: newLimitedProvider(secondary, limiter, 1)},
+	)
+
+	for _, want := range []string{"ses", "sendgrid"} {
+		resp, err := composite.Send(context.Background(), &EmailRequest{})
+		if err != nil || resp.Provider != want {
+			t.Fatalf("got %v from %v, want a send through %s", err, resp, want)
+		}
+	}
+
+	_, err := composite.Send(context.Background(), &EmailRequest{})
+	if !IsRateLimited(err) || !IsDeferred(err) || IsCircuitOpen(err) {
+		t.Fatalf("got %v with every provider over its limit, want rate limited", err)
+	}
+	if _, retryAfter := Classify(err); retryAfter != time.Second {
+		t.Fatalf("got retry after %v, want 1s", retryAfter)
+	}
+	if primary.sent() != 1 || secondary.sent() != 1 {
+		t.Fatalf("got %d/%d sends, want 1/1", primary.sent(), secondary.sent())
+	}
+}
+
+func TestRouter_LimitsRecipientDomains(t *testing.T) {
+	fallback := &fakeProvider{name: "fallback"}
+	router := newTestRouter(t, fallback)
+	limiter := &fakeLimiter{}
+	err := router.factory.SetRateLimits(limiter, []RateLimit{
+		{Domain: "*.outlook.com", Rate: 1},
+		{Domain: "*", Rate: 2},
+	})
+	if err != nil {
+		t.Fatalf("SetRateLimits: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := router.Send(ctx, &EmailRequest{To: []string{"a@eu.outlook.com"}, CC: []string{"b@example.com"}}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	_, err = router.Send(ctx, &EmailRequest{To: []string{"c@eu.outlook.com"}})
+	if !IsRateLimited(err) {
+		t.Fatalf("got %v, want the outlook limit", err)
+	}
+	if _, err := router.Send(ctx, &EmailRequest{To: []string{"d@example.com"}}); err != nil {
+		t.Fatalf("every domain has its own bucket: %v", err)
+	}
+	if _, err := router.Send(ctx, &EmailRequest{To: []string{"e@example.com"}}); !IsRateLimited(err) {
+		t.Fatalf("got %v, want the example.com bucket to be empty", err)
+	}
+
+	if fallback.sent() != 2 {
+		t.Fatalf("got %d sends, want 2", fallback.sent())
+	}
+	buckets := limiter.buckets()
+	if _, ok := buckets["domain:eu.outlook.com"]; !ok || len(buckets) != 2 {
+		t.Fatalf("got buckets %v", buckets)
+	}
+}
+
+func TestProviderFactory_SetRateLimits(t *testing.T) {
+	factory := NewProviderFactory(map[string]any{
+		"sendgrid": map[string]any{"api_key": "key", "from": "noreply@example.com"},
+	})
+	factory.SetCircuitBreaker(BreakerConfig{FailureThreshold: 3})
+	if err := factory.SetRateLimits(&fakeLimiter{}, []RateLimit{{Provider: "sendgrid", Rate: 14}}); err != nil {
+		t.Fatalf("SetRateLimits: %v", err)
+	}
+
+	provider, err := factory.CreateNamedProvider("sendgrid")
+	if err != nil {
+		t.Fatalf("CreateNamedProvider: %v", err)
+	}
+	limited, ok := provider.(*rateLimitedProvider)
+	if !ok || limited.limit.Burst != 14 || limited.bucket != "provider:sendgrid" {
+		t.Fatalf("got %#v, want a rate limited provider", provider)
+	}
+	if _, ok := limited.Provider.(*CircuitBreaker); !ok {
+		t.Fatal("rate limit should wrap the circuit breaker")
+	}
+
+	for _, limits := range [][]RateLimit{
+		{{Rate: 1}},
+		{{Provider: "ses", Domain: "gmail.com", Rate: 1}},
+		{{Domain: "gmail.com"}},
+	} {
+		if err := factory.SetRateLimits(&fakeLimiter{}, limits); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("SetRateLimits(%+v) = %v, want invalid configuration", limits, err)
+		}
+	}
+}
+
+func TestRateLimitedProvider_SendBatchTakesTokenPerRecipient(t *testing.T) {
+	limiter := &fakeLimiter{}
+	provider := &fakeProvider{name: "ses"}
+	limited := newLimitedProvider(provider, limiter, 1)
+
+	results, err := limited.SendBatch(context.Background(), newTestBatch())
+	if err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+	if results[0].Err != nil || results[0].Response.Provider != "ses" {
+		t.Fatalf("got %+v for the first recipient, want a send", results[0])
+	}
+	if !IsRateLimited(results[1].Err) {
+		t.Fatalf("got %v for the second recipient, want the provider limit", results[1].Err)
+	}
+	if provider.sent() != 1 || limiter.buckets()["provider:ses"] != 0 {
+		t.Fatalf("got %d sends and buckets %v, want one send for the one token", provider.sent(), limiter.buckets())
+	}
+}
@@ -0,0 +1,407 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RoutingRule routes the emails it matches to its providers. A rule matches
+// an email when each of its criteria that is set matches, and a criterion
+// matches when any of its values does. Values are matched case-insensitively.
+type RoutingRule struct {
+	Name string `json:"name"`
+	// Domains match when every recipient is at one of the domains, a domain
+	// written as "*.example.com" also matches its subdomains
+	Domains   []string `json:"domains,omitempty"`
+	Templates []string `json:"templates,omitempty"`
+	// Priorities are named urgent, high, normal and low
+	Priorities []string `json:"priorities,omitempty"`
+	Tenants    []string `json:"tenants,omitempty"`
+	// Providers names the configured providers the emails are sent through,
+	// several are combined like the default provider chain
+	Providers []string `json:"providers"`
+}
+
+// DefaultRoute is the rule name of the route of emails that match no rule
+const DefaultRoute = "default"
+
+// Route is where an email is sent
+type Route struct {
+	Rule     string `json:"rule"`
+	Provider string `json:"provider"`
+}
+
+// retireDelay is how long a provider dropped by a reload is kept open, so the
+// sends that were already routed to it can finish
+const retireDelay = time.Minute
+
+// routingTable is a set of loaded rules with their providers
+type routingTable struct {
+	rules     []RoutingRule
+	providers []Provider
+	loadedAt  time.Time
+}
+
+// retiree is a set of providers dropped by a reload, closed once its timer
+// fires
+type retiree struct {
+	timer     *time.Timer
+	providers []Provider
+}
+
+// Router implements the Provider interface by sending each email through the
+// providers of the first rule that matches it, or through the fallback
+// provider when none does. Its rules can be reloaded while it sends.
+type Router struct {
+	factory  *ProviderFactory
+	config   CompositeConfig
+	fallback Provider
+
+	table atomic.Pointer[routingTable]
+
+	// mu serializes reloads, chains caches the providers of the loaded rules
+	// by provider names, so a reload keeps the providers it still uses
+	mu       sync.Mutex
+	chains   map[string]Provider
+	retiring []retiree
+	closed   bool
+}
+
+// NewRouter creates a router that sends the emails matched by rules through
+// providers created by factory, and all others through fallback
+func NewRouter(factory *ProviderFactory, fallback Provider, config CompositeConfig, rules []RoutingRule) (*Router, error) {
+	if fallback == nil {
+		return nil, fmt.Errorf("%w: router needs a fallback provider", ErrInvalidConfig)
+	}
+
+	r := &Router{
+		factory:  factory,
+		config:   config,
+		fallback: fallback,
+		chains:   make(map[string]Provider),
+	}
+	if err := r.Reload(rules); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload replaces the rules of the router. Invalid rules are rejected as a
+// whole and the router keeps its current rules.
+func (r *Router) Reload(rules []RoutingRule) error {
+	normalized := make([]RoutingRule, len(rules))
+	for i, rule := range rules {
+		rule, err := normalizeRule(rule, i)
+		if err != nil {
+			return err
+		}
+		normalized[i] = rule
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return fmt.Errorf("%w: router is closed", ErrInvalidConfig)
+	}
+
+	table := &routingTable{
+		rules:     normalized,
+		providers: make([]Provider, len(normalized)),
+		loadedAt:  time.Now(),
+	}
+	chains := make(map[string]Provider)
+	for i, rule := range normalized {
+		key := strings.Join(rule.Providers, ",")
+		provider, ok := chains[key]
+		if !ok {
+			provider, ok = r.chains[key]
+		}
+		if !ok {
+			var err error
+			provider, err = r.factory.CreateChain(rule.Providers, r.config)
+			if err != nil {
+				closeProviders(created(chains, r.chains))
+				return fmt.Errorf("failed to create providers of rule %s: %w", rule.Name, err)
+			}
+		}
+		chains[key] = provider
+		table.providers[i] = provider
+	}
+
+	r.table.Store(table)
+	if dropped := created(r.chains, chains); len(dropped) > 0 {
+		r.retiring = append(r.retiring, retiree{
+			timer:     time.AfterFunc(retireDelay, func() { closeProviders(dropped) }),
+			providers: dropped,
+		})
+	}
+	r.chains = chains
+	return nil
+}
+
+// normalizeRule validates the rule at index i and lowercases its values
+func normalizeRule(rule RoutingRule, i int) (RoutingRule, error) {
+	if rule.Name == "" {
+		rule.Name = fmt.Sprintf("rule%d", i+1)
+	}
+	if len(rule.Providers) == 0 {
+		return rule, fmt.Errorf("%w: rule %s has no providers", ErrInvalidConfig, rule.Name)
+	}
+
+	rule.Domains = lowerValues(rule.Domains)
+	rule.Templates = lowerValues(rule.Templates)
+	rule.Priorities = lowerValues(rule.Priorities)
+	rule.Tenants = lowerValues(rule.Tenants)
+	for _, priority := range rule.Priorities {
+		switch priority {
+		case "urgent", "high", "normal", "low":
+		default:
+			return rule, fmt.Errorf("%w: rule %s has unknown priority %q", ErrInvalidConfig, rule.Name, priority)
+		}
+	}
+	return rule, nil
+}
+
+// lowerValues returns values trimmed and lowercased
+func lowerValues(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	lowered := make([]string, len(values))
+	for i, value := range values {
+		lowered[i] = strings.ToLower(strings.TrimSpace(value))
+	}
+	return lowered
+}
+
+// created returns the providers of chains that are not in existing
+func created(chains, existing map[string]Provider) []Provider {
+	var providers []Provider
+	for key, provider := range chains {
+		if _, ok := existing[key]; !ok {
+			providers = append(providers, provider)
+		}
+	}
+	return providers
+}
+
+// closeProviders closes providers
+func closeProviders(providers []Provider) error {
+	var errs []error
+	for _, provider := range providers {
+		if err := provider.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Rules returns the loaded rules and when they were loaded
+func (r *Router) Rules() ([]RoutingRule, time.Time) {
+	table := r.table.Load()
+	rules := make([]RoutingRule, len(table.rules))
+	copy(rules, table.rules)
+	return rules, table.loadedAt
+}
+
+// Route returns where an email would be sent, without sending it
+func (r *Router) Route(req *EmailRequest) Route {
+	rule, provider := r.route(req)
+	return Route{Rule: rule, Provider: provider.Name()}
+}
+
+// route returns the name of the rule an email matches and its provider
+func (r *Router) route(req *EmailRequest) (string, Provider) {
+	table := r.table.Load()
+	for i, rule := range table.rules {
+		if rule.matches(req) {
+			return rule.Name, table.providers[i]
+		}
+	}
+	return DefaultRoute, r.fallback
+}
+
+// matches reports whether the rule matches an email
+func (rule *RoutingRule) matches(req *EmailRequest) bool {
+	if len(rule.Templates) > 0 && !contains(rule.Templates, strings.ToLower(req.Template)) {
+		return false
+	}
+	if len(rule.Priorities) > 0 && !contains(rule.Priorities, strings.ToLower(req.Priority)) {
+		return false
+	}
+	if len(rule.Tenants) > 0 && !contains(rule.Tenants, strings.ToLower(req.Tenant)) {
+		return false
+	}
+	if len(rule.Domains) > 0 {
+		return rule.matchesRecipients(req)
+	}
+	return true
+}
+
+// matchesRecipients reports whether every recipient of an email is at one of
+// the domains of the rule
+func (rule *RoutingRule) matchesRecipients(req *EmailRequest) bool {
+	recipients := 0
+	for _, list := range [][]string{req.To, req.CC, req.BCC} {
+		for _, address := range list {
+			recipients++
+			domain := recipientDomain(address)
+			matched := false
+			for _, pattern := range rule.Domains {
+				if matchDomain(pattern, domain) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+		}
+	}
+	return recipients > 0
+}
+
+// recipientDomain returns the lowercased domain of an address
+func recipientDomain(address string) string {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		address = parsed.Address
+	}
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(address[at+1:])
+}
+
+// matchDomain reports whether domain matches pattern, "*.example.com" matches
+// example.com and its subdomains
+func matchDomain(pattern, domain string) bool {
+	if domain == "" {
+		return false
+	}
+	if parent, ok := strings.CutPrefix(pattern, "*."); ok {
+		return domain == parent || strings.HasSuffix(domain, "."+parent)
+	}
+	return domain == pattern
+}
+
+// contains reports whether values contains value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// CircuitBreakers returns the state of the circuit breaker of each provider
+func (r *Router) CircuitBreakers() []BreakerStatus {
+	return r.factory.CircuitBreakers()
+}
+
+// CaptureStore returns the store of captured emails, nil unless capture is
+// set up
+func (r *Router) CaptureStore() CaptureStore {
+	return r.factory.CaptureStore()
+}
+
+// Name returns the provider name
+func (r *Router) Name() string {
+	return "router"
+}
+
+// Send sends an email through the provider it is routed to, if its recipient
+// domains are within their rate limits
+func (r *Router) Send(ctx context.Context, req *EmailRequest) (*EmailResponse, error) {
+	if err := r.factory.limitDomains(ctx, req); err != nil {
+		return nil, err
+	}
+	_, provider := r.route(req)
+	resp, err := provider.Send(ctx, req)
+	if err == nil && resp != nil && resp.Provider == "" {
+		resp.Provider = provider.Name()
+	}
+	return resp, err
+}
+
+// SendBatch routes the message of each recipient of a batch like Send, and
+// sends the recipients routed to the same provider as one batch
+func (r *Router) SendBatch(ctx context.Context, batch *BatchRequest) ([]BatchResult, error) {
+	if err := batch.validate(); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(batch.Recipients))
+	var providers []Provider
+	groups := make(map[Provider][]int)
+	for i := range batch.Recipients {
+		req := batch.Message(i)
+		if err := r.factory.limitDomains(ctx, req); err != nil {
+			results[i] = failedResult(r.Name(), err)
+			continue
+		}
+		_, provider := r.route(req)
+		if _, ok := groups[provider]; !ok {
+			providers = append(providers, provider)
+		}
+		groups[provider] = append(groups[provider], i)
+	}
+
+	for _, provider := range providers {
+		indexes := groups[provider]
+		sent, err := provider.SendBatch(ctx, batch.subBatch(indexes))
+		for j, i := range indexes {
+			if err != nil {
+				results[i] = failedResult(provider.Name(), err)
+				continue
+			}
+			results[i] = sent[j]
+			if response := sent[j].Response; response != nil && response.Provider == "" {
+				response.Provider = provider.Name()
+			}
+		}
+	}
+	return results, nil
+}
+
+// Validate validates the configuration of the fallback provider, the
+// providers of the rules are validated when they are loaded
+func (r *Router) Validate() error {
+	return r.fallback.Validate()
+}
+
+// Health checks the fallback provider, the router is healthy while the
+// emails that match no rule can be sent
+func (r *Router) Health(ctx context.Context) error {
+	return r.fallback.Health(ctx)
+}
+
+// Close closes the providers of the rules, including the ones dropped by a
+// reload, and the fallback provider
+func (r *Router) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+
+	var providers []Provider
+	for _, retired := range r.retiring {
+		if retired.timer.Stop() {
+			providers = append(providers, retired.providers...)
+		}
+	}
+	for _, provider := range r.chains {
+		providers = append(providers, provider)
+	}
+	providers = append(providers, r.fallback)
+	return closeProviders(providers)
+}
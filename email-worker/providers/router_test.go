@@ -0,0 +1,109 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestRouter(t *testing.T, fallback Provider, rules ...RoutingRule) *Router {
+	t.Helper()
+	factory := NewProviderFactory(map[string]any{
+		"sendgrid": map[string]any{"api_key": "key", "from": "noreply@example.com"},
+		"ses": map[string]any{
+			"region": "us-east-1", "access_key_id": "id", "secret_access_key": "secret",
+			"from": "noreply@example.com",
+		},
+		"relay": map[string]any{
+			"type": "smtp", "host": "localhost", "port": 25,
+			"username": "user", "password": "secret", "from": "noreply@example.com",
+		},
+	})
+	router, err := NewRouter(factory, fallback, CompositeConfig{HealthInterval: time.Hour}, rules)
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	t.Cleanup(func() { router.Close() })
+	return router
+}
+
+func TestRouter_RoutesByFirstMatchingRule(t *testing.T) {
+	router := newTestRouter(t, &fakeProvider{name: "fallback"},
+		RoutingRule{Name: "internal", Domains: []string{"*.booking.internal"}, Providers: []string{"relay"}},
+		RoutingRule{Name: "transactional", Templates: []string{"Email_Verification", "booking_confirmation"}, Providers: []string{"ses"}},
+		RoutingRule{Name: "marketing", Templates: []string{"newsletter"}, Priorities: []string{"low"}, Providers: []string{"sendgrid"}},
+		RoutingRule{Name: "acme", Tenants: []string{"acme"}, Providers: []string{"sendgrid", "ses"}},
+	)
+
+	cases := []struct {
+		req  EmailRequest
+		want Route
+	}{
+		{EmailRequest{To: []string{"ops@mail.booking.internal"}, Template: "email_verification"}, Route{"internal", "smtp"}},
+		{EmailRequest{To: []string{"Ops <ops@booking.internal>"}, CC: []string{"dev@booking.internal"}}, Route{"internal", "smtp"}},
+		{EmailRequest{To: []string{"ops@booking.internal"}, BCC: []string{"guest@example.com"}, Template: "email_verification"}, Route{"transactional", "ses"}},
+		{EmailRequest{To: []string{"guest@example.com"}, Template: "newsletter", Priority: "low"}, Route{"marketing", "sendgrid"}},
+		{EmailRequest{To: []string{"guest@example.com"}, Template: "newsletter", Priority: "high"}, Route{DefaultRoute, "fallback"}},
+		{EmailRequest{To: []string{"guest@example.com"}, Tenant: "ACME"}, Route{"acme", "composite(sendgrid,ses)"}},
+		{EmailRequest{}, Route{DefaultRoute, "fallback"}},
+	}
+	for _, c := range cases {
+		if got := router.Route(&c.req); got != c.want {
+			t.Errorf("Route(%+v) = %+v, want %+v", c.req, got, c.want)
+		}
+	}
+}
+
+func TestRouter_SendsUnmatchedEmailsThroughFallback(t *testing.T) {
+	fallback := &fakeProvider{name: "fallback"}
+	router := newTestRouter(t, fallback, RoutingRule{Tenants: []string{"acme"}, Providers: []string{"sendgrid"}})
+
+	resp, err := router.Send(context.Background(), &EmailRequest{To: []string{"guest@example.com"}, Tenant: "globex"})
+	if err != nil || resp.Provider != "fallback" {
+		t.Fatalf("got %v from %v, want a send through the fallback", err, resp)
+	}
+	if fallback.sent() != 1 {
+		t.Fatalf("got %d fallback sends, want 1", fallback.sent())
+	}
+}
+
+func TestRouter_Reload(t *testing.T) {
+	router := newTestRouter(t, &fakeProvider{name: "fallback"},
+		RoutingRule{Name: "transactional", Templates: []string{"password_reset"}, Providers: []string{"ses"}},
+		RoutingRule{Name: "marketing", Templates: []string{"newsletter"}, Providers: []string{"sendgrid"}},
+	)
+	ses := router.chains["ses"]
+	req := &EmailRequest{To: []string{"guest@example.com"}, Template: "newsletter"}
+
+	err := router.Reload([]RoutingRule{
+		{Name: "transactional", Templates: []string{"password_reset", "newsletter"}, Providers: []string{"ses"}},
+	})
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := router.Route(req); got != (Route{"transactional", "ses"}) {
+		t.Fatalf("got %+v after reload, want transactional", got)
+	}
+	if router.chains["ses"] != ses {
+		t.Fatal("reload recreated a provider it kept using")
+	}
+	if len(router.retiring) != 1 || router.retiring[0].providers[0].Name() != "sendgrid" {
+		t.Fatalf("got retiring %+v, want the sendgrid provider", router.retiring)
+	}
+
+	// Invalid rules are rejected and the loaded rules stay in place
+	for _, rules := range [][]RoutingRule{
+		{{Name: "empty"}},
+		{{Name: "unknown", Providers: []string{"mailchimp"}}},
+		{{Name: "priority", Priorities: []string{"asap"}, Providers: []string{"ses"}}},
+	} {
+		if err := router.Reload(rules); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Reload(%+v) = %v, want invalid configuration", rules, err)
+		}
+	}
+	rules, _ := router.Rules()
+	if len(rules) != 1 || rules[0].Name != "transactional" {
+		t.Fatalf("got rules %+v after failed reloads", rules)
+	}
+}
@@ -22,8 +22,14 @@ type SendGridConfig struct {
 	APIKey   string `json:"api_key"`
 	From     string `json:"from"`
 	FromName string `json:"from_name"`
+	// Endpoint is the base URL of the SendGrid API, such as the EU region's
+	Endpoint string `json:"endpoint"`
 }
 
+// sendGridMaxRecipients is how many recipients a SendGrid request may have
+// across its personalizations
+const sendGridMaxRecipients = 1000
+
 // NewSendGridProvider creates a new SendGrid provider
 func NewSendGridProvider(config map[string]any) (Provider, error) {
 	apiKey, ok := config["api_key"].(string)
@@ -41,7 +47,10 @@ func NewSendGridProvider(config map[string]any) (Provider, error) {
 		fromName = "Booking System"
 	}
 
-	client := sendgrid.NewSendClient(apiKey)
+	endpoint, _ := config["endpoint"].(string)
+	request := sendgrid.GetRequest(apiKey, "/v3/mail/send", endpoint)
+	request.Method = "POST"
+	client := &sendgrid.Client{Request: request}
 
 	return &SendGridProvider{
 		client:   client,
@@ -58,18 +67,78 @@ func (p *SendGridProvider) Name() string {
 
 // Send sends an email via SendGrid
 func (p *SendGridProvider) Send(ctx context.Context, req *EmailRequest) (*EmailResponse, error) {
-	// Create email message
-	from := mail.NewEmail(p.fromName, p.from)
-	
-	// Handle multiple recipients
 	if len(req.To) == 0 {
 		return nil, fmt.Errorf("%w: no recipients specified", ErrSendFailed)
 	}
-	
-	// For simplicity, send to first recipient only
-	// In production, you might want to implement batch sending
-	to := mail.NewEmail("", req.To[0])
-	message := mail.NewSingleEmail(from, req.Subject, to, req.TextContent, req.HTMLContent)
+
+	message := p.message(req)
+	message.AddPersonalizations(personalization(BatchRecipient{To: req.To, CC: req.CC, BCC: req.BCC}))
+
+	messageID, err := p.post(ctx, message)
+	if err != nil {
+		return &EmailResponse{
+			Status:    "failed",
+			Provider:  p.Name(),
+			Error:     err.Error(),
+			SentAt:    time.Now(),
+		}, err
+	}
+
+	return &EmailResponse{
+		MessageID: messageID,
+		Status:    "sent",
+		Provider:  p.Name(),
+		SentAt:    time.Now(),
+	}, nil
+}
+
+// SendBatch sends a batch with a personalization per recipient, whose data
+// are substitutions of the placeholders. Batches with more recipients than a
+// request allows are split over several requests.
+func (p *SendGridProvider) SendBatch(ctx context.Context, batch *BatchRequest) ([]BatchResult, error) {
+	if err := batch.validate(); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(batch.Recipients))
+	for start := 0; start < len(batch.Recipients); {
+		message := p.message(batch.Email)
+		end, addresses := start, 0
+		for end < len(batch.Recipients) {
+			recipient := batch.Recipients[end]
+			count := len(recipient.To) + len(recipient.CC) + len(recipient.BCC)
+			if end > start && addresses+count > sendGridMaxRecipients {
+				break
+			}
+			message.AddPersonalizations(personalization(recipient))
+			addresses += count
+			end++
+		}
+
+		messageID, err := p.post(ctx, message)
+		for i := start; i < end; i++ {
+			if err != nil {
+				results[i] = failedResult(p.Name(), err)
+			} else {
+				results[i] = sentResult(p.Name(), messageID)
+			}
+		}
+		start = end
+	}
+	return results, nil
+}
+
+// message builds a SendGrid message of an email without its recipients
+func (p *SendGridProvider) message(req *EmailRequest) *mail.SGMailV3 {
+	message := mail.NewV3Mail()
+	message.SetFrom(mail.NewEmail(p.fromName, p.from))
+	message.Subject = req.Subject
+	if req.TextContent != "" {
+		message.AddContent(mail.NewContent("text/plain", req.TextContent))
+	}
+	if req.HTMLContent != "" {
+		message.AddContent(mail.NewContent("text/html", req.HTMLContent))
+	}
 
 	// Add reply-to if specified
 	if req.ReplyTo != "" {
@@ -77,57 +146,59 @@ func (p *SendGridProvider) Send(ctx context.Context, req *EmailRequest) (*EmailR
 	}
 
 	// Add custom headers
-	if req.Headers != nil {
-		for key, value := range req.Headers {
-			message.SetHeader(key, value)
-		}
+	for key, value := range req.Headers {
+		message.SetHeader(key, value)
 	}
 
 	// Add attachments
-	if len(req.Attachments) > 0 {
-		for _, attachment := range req.Attachments {
-			mailAttachment := mail.NewAttachment()
-			mailAttachment.SetContent(string(attachment.Content))
-			mailAttachment.SetType(attachment.ContentType)
-			mailAttachment.SetFilename(attachment.Filename)
-			mailAttachment.SetDisposition("attachment")
-			message.AddAttachment(mailAttachment)
-		}
+	for _, attachment := range req.Attachments {
+		mailAttachment := mail.NewAttachment()
+		mailAttachment.SetContent(string(attachment.Content))
+		mailAttachment.SetType(attachment.ContentType)
+		mailAttachment.SetFilename(attachment.Filename)
+		mailAttachment.SetDisposition("attachment")
+		message.AddAttachment(mailAttachment)
+	}
+	return message
+}
+
+// personalization addresses a SendGrid message to a recipient and
+// substitutes its data
+func personalization(recipient BatchRecipient) *mail.Personalization {
+	personalization := mail.NewPersonalization()
+	for _, address := range recipient.To {
+		personalization.AddTos(mail.NewEmail("", address))
+	}
+	for _, address := range recipient.CC {
+		personalization.AddCCs(mail.NewEmail("", address))
 	}
+	for _, address := range recipient.BCC {
+		personalization.AddBCCs(mail.NewEmail("", address))
+	}
+	for key, value := range recipient.Data {
+		personalization.SetSubstitution(Placeholder(key), value)
+	}
+	return personalization
+}
 
-	// Send email
+// post sends a message to the SendGrid API and returns its message ID
+func (p *SendGridProvider) post(ctx context.Context, message *mail.SGMailV3) (string, error) {
 	response, err := p.client.SendWithContext(ctx, message)
 	if err != nil {
-		return &EmailResponse{
-			Status:    "failed",
-			Provider:  p.Name(),
-			Error:     err.Error(),
-			SentAt:    time.Now(),
-		}, fmt.Errorf("%w: %v", ErrSendFailed, err)
+		return "", NewSendError(ErrorClassTransient, err)
 	}
-
-	// Check response status
 	if response.StatusCode >= 400 {
-		return &EmailResponse{
-			Status:    "failed",
-			Provider:  p.Name(),
-			Error:     fmt.Sprintf("HTTP %d: %s", response.StatusCode, response.Body),
-			SentAt:    time.Now(),
-		}, fmt.Errorf("%w: HTTP %d: %s", ErrSendFailed, response.StatusCode, response.Body)
+		return "", NewHTTPSendError(response.StatusCode, firstHeader(response.Headers, "Retry-After"), response.Body)
 	}
+	return firstHeader(response.Headers, "X-Message-Id"), nil
+}
 
-	// Extract message ID from response headers
-	messageID := ""
-	if len(response.Headers["X-Message-Id"]) > 0 {
-		messageID = response.Headers["X-Message-Id"][0]
+// firstHeader returns the first value of a response header, or ""
+func firstHeader(headers map[string][]string, name string) string {
+	if values := headers[name]; len(values) > 0 {
+		return values[0]
 	}
-
-	return &EmailResponse{
-		MessageID: messageID,
-		Status:    "sent",
-		Provider:  p.Name(),
-		SentAt:    time.Now(),
-	}, nil
+	return ""
 }
 
 // Validate validates the SendGrid configuration
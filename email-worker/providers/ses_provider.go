@@ -2,10 +2,18 @@ package providers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ses"
@@ -17,6 +25,12 @@ type SESProvider struct {
 	from     string
 	fromName string
 	region   string
+
+	// templates holds the batch templates created by the provider, with
+	// when each was last used
+	templatesMu  sync.Mutex
+	templates    map[string]uint64
+	templateUses uint64
 }
 
 // SESConfig holds AWS SES configuration
@@ -26,8 +40,19 @@ type SESConfig struct {
 	SecretAccessKey string `json:"secret_access_key"`
 	From            string `json:"from"`
 	FromName        string `json:"from_name"`
+	// Endpoint overrides the SES API endpoint of the region
+	Endpoint string `json:"endpoint"`
 }
 
+// sesMaxDestinations is how many destinations a bulk send may have
+const sesMaxDestinations = 50
+
+// sesTemplateCacheSize is how many batch templates a provider keeps for
+// reuse. SES creates at most one template per second and holds 10,000 per
+// region, so batches with the same content share a template and the least
+// recently used one is deleted once there are more.
+const sesTemplateCacheSize = 100
+
 // NewSESProvider creates a new AWS SES provider
 func NewSESProvider(config map[string]any) (Provider, error) {
 	region, ok := config["region"].(string)
@@ -56,10 +81,14 @@ func NewSESProvider(config map[string]any) (Provider, error) {
 	}
 
 	// Create AWS session
-	sess, err := session.NewSession(&aws.Config{
+	awsConfig := &aws.Config{
 		Region:      aws.String(region),
 		Credentials: credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
-	})
+	}
+	if endpoint, _ := config["endpoint"].(string); endpoint != "" {
+		awsConfig.Endpoint = aws.String(endpoint)
+	}
+	sess, err := session.NewSession(awsConfig)
 	if err != nil {
 		return nil, fmt.Errorf("%w: failed to create AWS session: %v", ErrInvalidConfig, err)
 	}
@@ -68,10 +97,11 @@ func NewSESProvider(config map[string]any) (Provider, error) {
 	client := ses.New(sess)
 
 	return &SESProvider{
-		client:   client,
-		from:     from,
-		fromName: fromName,
-		region:   region,
+		client:    client,
+		from:      from,
+		fromName:  fromName,
+		region:    region,
+		templates: make(map[string]uint64),
 	}, nil
 }
 
@@ -82,18 +112,10 @@ func (p *SESProvider) Name() string {
 
 // Send sends an email via AWS SES
 func (p *SESProvider) Send(ctx context.Context, req *EmailRequest) (*EmailResponse, error) {
-	// Convert string slices to AWS string pointers
-	toAddresses := make([]*string, len(req.To))
-	for i, addr := range req.To {
-		toAddresses[i] = aws.String(addr)
-	}
-
 	// Create email input
 	input := &ses.SendEmailInput{
-		Source: aws.String(fmt.Sprintf("%s <%s>", p.fromName, p.from)),
-		Destination: &ses.Destination{
-			ToAddresses: toAddresses,
-		},
+		Source:      aws.String(p.source()),
+		Destination: destination(BatchRecipient{To: req.To, CC: req.CC, BCC: req.BCC}),
 		Message: &ses.Message{
 			Subject: &ses.Content{
 				Data:    aws.String(req.Subject),
@@ -135,7 +157,7 @@ func (p *SESProvider) Send(ctx context.Context, req *EmailRequest) (*EmailRespon
 			Provider:  p.Name(),
 			Error:     err.Error(),
 			SentAt:    time.Now(),
-		}, fmt.Errorf("%w: %v", ErrSendFailed, err)
+		}, classifySESError(err)
 	}
 
 	return &EmailResponse{
@@ -146,6 +168,233 @@ func (p *SESProvider) Send(ctx context.Context, req *EmailRequest) (*EmailRespon
 	}, nil
 }
 
+// SendBatch sends a batch with a bulk templated send. The email is stored as
+// an SES template named after its content, which later batches with the same
+// content reuse, and the data of each recipient fill in its placeholders
+// verbatim like Message does. A template has no attachments or headers, so an
+// email with either is sent to each recipient with Send instead.
+func (p *SESProvider) SendBatch(ctx context.Context, batch *BatchRequest) ([]BatchResult, error) {
+	if err := batch.validate(); err != nil {
+		return nil, err
+	}
+	if len(batch.Email.Attachments) > 0 || len(batch.Email.Headers) > 0 {
+		return SendEach(ctx, p, batch)
+	}
+
+	results := make([]BatchResult, len(batch.Recipients))
+	template := batchTemplate(batch)
+	if err := p.useTemplate(ctx, template); err != nil {
+		for i := range results {
+			results[i] = failedResult(p.Name(), err)
+		}
+		return results, nil
+	}
+	name := aws.StringValue(template.TemplateName)
+
+	for start := 0; start < len(batch.Recipients); start += sesMaxDestinations {
+		end := min(start+sesMaxDestinations, len(batch.Recipients))
+		input := &ses.SendBulkTemplatedEmailInput{
+			Source:              aws.String(p.source()),
+			Template:            aws.String(name),
+			DefaultTemplateData: aws.String("{}"),
+		}
+		if batch.Email.ReplyTo != "" {
+			input.ReplyToAddresses = []*string{aws.String(batch.Email.ReplyTo)}
+		}
+		for _, recipient := range batch.Recipients[start:end] {
+			data, err := json.Marshal(recipient.Data)
+			if err != nil || recipient.Data == nil {
+				data = []byte("{}")
+			}
+			input.Destinations = append(input.Destinations, &ses.BulkEmailDestination{
+				Destination:             destination(recipient),
+				ReplacementTemplateData: aws.String(string(data)),
+			})
+		}
+
+		output, err := p.client.SendBulkTemplatedEmailWithContext(ctx, input)
+		if isSESErrorCode(err, ses.ErrCodeTemplateDoesNotExistException) {
+			// The template was deleted by another replica or to make room
+			// for a newer one, create it again
+			p.forgetTemplate(name)
+			if err = p.useTemplate(ctx, template); err == nil {
+				output, err = p.client.SendBulkTemplatedEmailWithContext(ctx, input)
+			}
+		}
+		for i := start; i < end; i++ {
+			switch {
+			case err != nil:
+				results[i] = failedResult(p.Name(), classifySESError(err))
+			case i-start >= len(output.Status):
+				results[i] = failedResult(p.Name(), NewSendError(ErrorClassTransient, errors.New("SES returned no status")))
+			default:
+				results[i] = bulkResult(p.Name(), output.Status[i-start])
+			}
+		}
+	}
+	return results, nil
+}
+
+// batchTemplate returns the SES template of the email of a batch. The
+// placeholders of the recipients' data become triple-stash Handlebars tags,
+// which SES fills in without HTML escaping, and the template is named after
+// its content.
+func batchTemplate(batch *BatchRequest) *ses.Template {
+	keys := make(map[string]bool)
+	for _, recipient := range batch.Recipients {
+		for key := range recipient.Data {
+			keys[key] = true
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	pairs := make([]string, 0, 2*len(sorted))
+	for _, key := range sorted {
+		pairs = append(pairs, Placeholder(key), "{{{"+key+"}}}")
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	subject := replacer.Replace(batch.Email.Subject)
+	html := replacer.Replace(batch.Email.HTMLContent)
+	text := replacer.Replace(batch.Email.TextContent)
+	hash := sha256.Sum256([]byte(subject + "\x00" + html + "\x00" + text))
+
+	template := &ses.Template{
+		TemplateName: aws.String("email-worker-batch-" + hex.EncodeToString(hash[:20])),
+		SubjectPart:  aws.String(subject),
+	}
+	if html != "" {
+		template.HtmlPart = aws.String(html)
+	}
+	if text != "" {
+		template.TextPart = aws.String(text)
+	}
+	return template
+}
+
+// useTemplate makes sure template exists in SES, creating it unless the
+// provider already did. A template created by another replica is used as is.
+// Once more than sesTemplateCacheSize templates are kept, the least recently
+// used one is deleted.
+func (p *SESProvider) useTemplate(ctx context.Context, template *ses.Template) error {
+	name := aws.StringValue(template.TemplateName)
+
+	p.templatesMu.Lock()
+	defer p.templatesMu.Unlock()
+	p.templateUses++
+	if _, ok := p.templates[name]; ok {
+		p.templates[name] = p.templateUses
+		return nil
+	}
+
+	_, err := p.client.CreateTemplateWithContext(ctx, &ses.CreateTemplateInput{Template: template})
+	if err != nil && !isSESErrorCode(err, ses.ErrCodeAlreadyExistsException) {
+		return classifySESError(err)
+	}
+	p.templates[name] = p.templateUses
+
+	if len(p.templates) > sesTemplateCacheSize {
+		oldest := name
+		for cached, used := range p.templates {
+			if used < p.templates[oldest] {
+				oldest = cached
+			}
+		}
+		delete(p.templates, oldest)
+		p.deleteTemplate(context.WithoutCancel(ctx), oldest)
+	}
+	return nil
+}
+
+// forgetTemplate drops a template that no longer exists in SES
+func (p *SESProvider) forgetTemplate(name string) {
+	p.templatesMu.Lock()
+	defer p.templatesMu.Unlock()
+	delete(p.templates, name)
+}
+
+// deleteTemplate deletes a template from SES. A batch of another replica
+// still using it creates it again.
+func (p *SESProvider) deleteTemplate(ctx context.Context, name string) {
+	p.client.DeleteTemplateWithContext(ctx, &ses.DeleteTemplateInput{TemplateName: aws.String(name)})
+}
+
+// isSESErrorCode reports whether err is an SES API error with code
+func isSESErrorCode(err error, code string) bool {
+	var awsErr awserr.Error
+	return errors.As(err, &awsErr) && awsErr.Code() == code
+}
+
+// bulkResult turns the status of a bulk send destination into its result
+func bulkResult(provider string, status *ses.BulkEmailDestinationStatus) BatchResult {
+	code := aws.StringValue(status.Status)
+	if code == ses.BulkEmailStatusSuccess {
+		return sentResult(provider, aws.StringValue(status.MessageId))
+	}
+
+	err := fmt.Errorf("%s: %s", code, aws.StringValue(status.Error))
+	switch code {
+	case ses.BulkEmailStatusAccountThrottled, ses.BulkEmailStatusAccountDailyQuotaExceeded:
+		return failedResult(provider, NewSendError(ErrorClassThrottled, err))
+	case ses.BulkEmailStatusMessageRejected,
+		ses.BulkEmailStatusMailFromDomainNotVerified,
+		ses.BulkEmailStatusInvalidParameterValue:
+		return failedResult(provider, NewSendError(ErrorClassPermanent, err))
+	}
+	return failedResult(provider, NewSendError(ErrorClassTransient, err))
+}
+
+// source is the sender of the emails of the provider
+func (p *SESProvider) source() string {
+	return fmt.Sprintf("%s <%s>", p.fromName, p.from)
+}
+
+// destination addresses an SES email to a recipient
+func destination(recipient BatchRecipient) *ses.Destination {
+	return &ses.Destination{
+		ToAddresses:  addresses(recipient.To),
+		CcAddresses:  addresses(recipient.CC),
+		BccAddresses: addresses(recipient.BCC),
+	}
+}
+
+// addresses converts a list of addresses, leaving out an empty list
+func addresses(list []string) []*string {
+	if len(list) == 0 {
+		return nil
+	}
+	return aws.StringSlice(list)
+}
+
+// classifySESError turns an SES API error into a classified send error
+func classifySESError(err error) *ProviderError {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return NewSendError(ErrorClassTransient, err)
+	}
+
+	switch awsErr.Code() {
+	case "Throttling", "ThrottlingException", "MaxSendingRateExceeded":
+		return NewSendError(ErrorClassThrottled, err)
+	case ses.ErrCodeMessageRejected,
+		ses.ErrCodeMailFromDomainNotVerifiedException,
+		ses.ErrCodeConfigurationSetDoesNotExistException,
+		"InvalidParameterValue":
+		return NewSendError(ErrorClassPermanent, err)
+	}
+
+	var requestErr awserr.RequestFailure
+	if errors.As(err, &requestErr) {
+		sendErr := NewSendError(ClassifyHTTPStatus(requestErr.StatusCode()), err)
+		sendErr.StatusCode = requestErr.StatusCode()
+		return sendErr
+	}
+	return NewSendError(ErrorClassTransient, err)
+}
+
 // Validate validates the SES configuration
 func (p *SESProvider) Validate() error {
 	if p.from == "" {
@@ -170,8 +419,13 @@ func (p *SESProvider) Health(ctx context.Context) error {
 	return nil
 }
 
-// Close closes the SES provider (no cleanup needed)
+// Close deletes the batch templates the provider kept
 func (p *SESProvider) Close() error {
-	// SES client doesn't need explicit cleanup
+	p.templatesMu.Lock()
+	defer p.templatesMu.Unlock()
+	for name := range p.templates {
+		p.deleteTemplate(context.Background(), name)
+	}
+	p.templates = make(map[string]uint64)
 	return nil
 } 
\ No newline at end of file
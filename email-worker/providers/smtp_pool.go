@@ -0,0 +1,219 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"sync"
+	"time"
+)
+
+// smtpConn is an SMTP connection that can send several messages. It
+// implements gomail.SendCloser.
+type smtpConn struct {
+	conn      net.Conn
+	client    *smtp.Client
+	createdAt time.Time
+	usedAt    time.Time
+	// broken is set once the session is in an unknown state, such as after a
+	// write that timed out, and the connection must not be reused
+	broken bool
+}
+
+// expiredAt is a deadline in the past, which interrupts blocked reads and
+// writes
+var expiredAt = time.Unix(1, 0)
+
+// do runs fn with the deadline of ctx on the connection. Cancelling ctx
+// interrupts fn and breaks the connection.
+func (c *smtpConn) do(ctx context.Context, fn func() error) error {
+	deadline, _ := ctx.Deadline()
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		c.broken = true
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { c.conn.SetDeadline(expiredAt) })
+
+	err := fn()
+	if !stop() {
+		c.broken = true
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) {
+		c.broken = true
+	}
+	return err
+}
+
+// Send sends a message in a mail transaction. A transaction the server
+// refused is reset, so the connection can send the next message.
+func (c *smtpConn) Send(from string, to []string, msg io.WriterTo) error {
+	err := c.send(from, to, msg)
+	if err == nil || c.broken {
+		return err
+	}
+
+	var reply *textproto.Error
+	if !errors.As(err, &reply) || c.client.Reset() != nil {
+		c.broken = true
+	}
+	return err
+}
+
+// send runs a mail transaction
+func (c *smtpConn) send(from string, to []string, msg io.WriterTo) error {
+	if err := c.client.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.client.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := msg.WriteTo(w); err != nil {
+		// The message was cut off, the server cannot tell where it ends
+		c.broken = true
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// check tells whether the server still answers on the connection
+func (c *smtpConn) check(ctx context.Context) error {
+	return c.do(ctx, c.client.Noop)
+}
+
+// Close ends the session and closes the connection
+func (c *smtpConn) Close() error {
+	if c.broken {
+		return c.conn.Close()
+	}
+	c.conn.SetDeadline(time.Now().Add(time.Second))
+	if err := c.client.Quit(); err != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// SMTPPoolConfig holds SMTP connection pool configuration
+type SMTPPoolConfig struct {
+	// MaxIdle is how many idle connections are kept open
+	MaxIdle int
+	// IdleTimeout closes connections that have been idle for longer, before
+	// the server drops them
+	IdleTimeout time.Duration
+	// MaxLifetime closes connections that have been open for longer
+	MaxLifetime time.Duration
+}
+
+// Default SMTP pool settings
+const (
+	defaultSMTPMaxIdle     = 2
+	defaultSMTPIdleTimeout = 30 * time.Second
+	defaultSMTPMaxLifetime = 5 * time.Minute
+)
+
+// smtpPool keeps idle SMTP connections to reuse them for later sends
+type smtpPool struct {
+	dial   func(ctx context.Context) (*smtpConn, error)
+	config SMTPPoolConfig
+
+	mu     sync.Mutex
+	idle   []*smtpConn
+	closed bool
+}
+
+// newSMTPPool creates a pool that opens connections with dial
+func newSMTPPool(dial func(ctx context.Context) (*smtpConn, error), config SMTPPoolConfig) *smtpPool {
+	if config.MaxIdle <= 0 {
+		config.MaxIdle = defaultSMTPMaxIdle
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = defaultSMTPIdleTimeout
+	}
+	if config.MaxLifetime <= 0 {
+		config.MaxLifetime = defaultSMTPMaxLifetime
+	}
+	return &smtpPool{dial: dial, config: config}
+}
+
+// get returns the most recently used idle connection that passes a NOOP
+// check, or a new connection
+func (p *smtpPool) get(ctx context.Context) (*smtpConn, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, errors.New("smtp: connection pool is closed")
+		}
+		if len(p.idle) == 0 {
+			p.mu.Unlock()
+			return p.dial(ctx)
+		}
+		c := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		p.mu.Unlock()
+
+		if p.expired(c, time.Now()) {
+			c.Close()
+			continue
+		}
+		if err := c.check(ctx); err != nil {
+			c.broken = true
+			c.Close()
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			continue
+		}
+		return c, nil
+	}
+}
+
+// put returns a connection to the pool, or closes it if it is broken, has
+// expired or the pool is full
+func (p *smtpPool) put(c *smtpConn) {
+	now := time.Now()
+	p.mu.Lock()
+	if c.broken || p.closed || len(p.idle) >= p.config.MaxIdle || now.Sub(c.createdAt) >= p.config.MaxLifetime {
+		p.mu.Unlock()
+		c.Close()
+		return
+	}
+	c.usedAt = now
+	p.idle = append(p.idle, c)
+	p.mu.Unlock()
+}
+
+// expired reports whether a connection has been idle or open for too long
+func (p *smtpPool) expired(c *smtpConn, now time.Time) bool {
+	return now.Sub(c.usedAt) >= p.config.IdleTimeout || now.Sub(c.createdAt) >= p.config.MaxLifetime
+}
+
+// close closes the idle connections, connections in use are closed when they
+// are returned
+func (p *smtpPool) close() error {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+
+	var errs []error
+	for _, c := range idle {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
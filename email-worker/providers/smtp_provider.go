@@ -2,22 +2,52 @@ package providers
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/gomail.v2"
 )
 
-// SMTPProvider implements the Provider interface for SMTP
+// SMTPTLSMode is how an SMTP provider secures its connections
+type SMTPTLSMode string
+
+const (
+	// SMTPTLSAuto uses implicit TLS on port 465 or when tls is set, and
+	// otherwise STARTTLS when the server offers it
+	SMTPTLSAuto SMTPTLSMode = "auto"
+	// SMTPTLSStartTLS upgrades every connection with STARTTLS, and fails
+	// when the server does not offer it
+	SMTPTLSStartTLS SMTPTLSMode = "starttls"
+	// SMTPTLSImplicit starts every connection with a TLS handshake
+	SMTPTLSImplicit SMTPTLSMode = "implicit"
+	// SMTPTLSNone never uses TLS
+	SMTPTLSNone SMTPTLSMode = "none"
+)
+
+// smtpDialTimeout bounds connecting to the server when ctx has no deadline
+const smtpDialTimeout = 10 * time.Second
+
+// SMTPProvider implements the Provider interface for SMTP. It keeps its
+// connections open in a pool and reuses them for later sends.
 type SMTPProvider struct {
-	host     string
-	port     int
-	username string
-	password string
-	tls      bool
-	from     string
-	fromName string
+	host      string
+	port      int
+	username  string
+	password  string
+	tlsMode   SMTPTLSMode
+	tlsConfig *tls.Config
+	from      string
+	fromName  string
+	pool      *smtpPool
 }
 
 // SMTPConfig holds SMTP configuration
@@ -29,6 +59,11 @@ type SMTPConfig struct {
 	TLS      bool   `json:"tls"`
 	From     string `json:"from"`
 	FromName string `json:"from_name"`
+	// TLSMode is auto, starttls, implicit or none
+	TLSMode      string        `json:"tls_mode"`
+	MaxIdleConns int           `json:"max_idle_conns"`
+	IdleTimeout  time.Duration `json:"idle_timeout"`
+	MaxLifetime  time.Duration `json:"max_lifetime"`
 }
 
 // NewSMTPProvider creates a new SMTP provider
@@ -53,7 +88,7 @@ func NewSMTPProvider(config map[string]any) (Provider, error) {
 		return nil, fmt.Errorf("%w: missing or invalid password", ErrInvalidConfig)
 	}
 
-	tls, _ := config["tls"].(bool)
+	useTLS, _ := config["tls"].(bool)
 
 	from, ok := config["from"].(string)
 	if !ok || from == "" {
@@ -65,15 +100,44 @@ func NewSMTPProvider(config map[string]any) (Provider, error) {
 		fromName = "Booking System"
 	}
 
-	return &SMTPProvider{
-		host:     host,
-		port:     port,
-		username: username,
-		password: password,
-		tls:      tls,
-		from:     from,
-		fromName: fromName,
-	}, nil
+	tlsMode := SMTPTLSMode(strings.ToLower(stringSetting(config, "tls_mode")))
+	switch tlsMode {
+	case "", SMTPTLSAuto:
+		tlsMode = SMTPTLSAuto
+		if useTLS || port == 465 {
+			tlsMode = SMTPTLSImplicit
+		}
+	case SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone:
+	default:
+		return nil, fmt.Errorf("%w: unknown tls_mode %q", ErrInvalidConfig, tlsMode)
+	}
+
+	maxIdle, _ := config["max_idle_conns"].(int)
+	idleTimeout, _ := config["idle_timeout"].(time.Duration)
+	maxLifetime, _ := config["max_lifetime"].(time.Duration)
+
+	p := &SMTPProvider{
+		host:      host,
+		port:      port,
+		username:  username,
+		password:  password,
+		tlsMode:   tlsMode,
+		tlsConfig: &tls.Config{ServerName: host},
+		from:      from,
+		fromName:  fromName,
+	}
+	p.pool = newSMTPPool(p.dial, SMTPPoolConfig{
+		MaxIdle:     maxIdle,
+		IdleTimeout: idleTimeout,
+		MaxLifetime: maxLifetime,
+	})
+	return p, nil
+}
+
+// stringSetting returns a string setting of a provider, or ""
+func stringSetting(config map[string]any, key string) string {
+	value, _ := config[key].(string)
+	return value
 }
 
 // Name returns the provider name
@@ -83,10 +147,46 @@ func (p *SMTPProvider) Name() string {
 
 // Send sends an email via SMTP
 func (p *SMTPProvider) Send(ctx context.Context, req *EmailRequest) (*EmailResponse, error) {
-	// Create email message
+	m := newMessage(fmt.Sprintf("%s <%s>", p.fromName, p.from), req)
+
+	// Send email over a pooled connection
+	if err := p.send(ctx, m); err != nil {
+		return &EmailResponse{
+			Status:    "failed",
+			Provider:  p.Name(),
+			Error:     err.Error(),
+			SentAt:    time.Now(),
+		}, classifySMTPError(err)
+	}
+
+	// Generate a simple message ID for SMTP
+	messageID := fmt.Sprintf("%d@%s", time.Now().UnixNano(), p.host)
+
+	return &EmailResponse{
+		MessageID: messageID,
+		Status:    "sent",
+		Provider:  p.Name(),
+		SentAt:    time.Now(),
+	}, nil
+}
+
+// SendBatch sends a batch with a message per recipient
+func (p *SMTPProvider) SendBatch(ctx context.Context, batch *BatchRequest) ([]BatchResult, error) {
+	return SendEach(ctx, p, batch)
+}
+
+// newMessage renders an email as a MIME message from the sender from
+func newMessage(from string, req *EmailRequest) *gomail.Message {
 	m := gomail.NewMessage()
-	m.SetHeader("From", fmt.Sprintf("%s <%s>", p.fromName, p.from))
+	m.SetHeader("From", from)
 	m.SetHeader("To", req.To...)
+	if len(req.CC) > 0 {
+		m.SetHeader("Cc", req.CC...)
+	}
+	// gomail sends to Bcc recipients without writing the header
+	if len(req.BCC) > 0 {
+		m.SetHeader("Bcc", req.BCC...)
+	}
 	m.SetHeader("Subject", req.Subject)
 
 	// Set email body
@@ -105,47 +205,159 @@ func (p *SMTPProvider) Send(ctx context.Context, req *EmailRequest) (*EmailRespo
 	}
 
 	// Add custom headers
-	if req.Headers != nil {
-		for key, value := range req.Headers {
-			m.SetHeader(key, value)
-		}
+	for key, value := range req.Headers {
+		m.SetHeader(key, value)
 	}
 
 	// Add attachments
-	if len(req.Attachments) > 0 {
-		for _, attachment := range req.Attachments {
-			m.Attach(attachment.Filename, gomail.SetCopyFunc(func(w io.Writer) error {
-				_, err := w.Write(attachment.Content)
+	for _, attachment := range req.Attachments {
+		content := attachment.Content
+		settings := []gomail.FileSetting{gomail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(content)
+			return err
+		})}
+		if attachment.ContentType != "" {
+			settings = append(settings, gomail.SetHeader(map[string][]string{"Content-Type": {attachment.ContentType}}))
+		}
+		m.Attach(attachment.Filename, settings...)
+	}
+	return m
+}
+
+// send sends a message over a connection from the pool, which goes back to
+// the pool unless the send broke it
+func (p *SMTPProvider) send(ctx context.Context, m *gomail.Message) error {
+	conn, err := p.pool.get(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.pool.put(conn)
+	return conn.do(ctx, func() error { return gomail.Send(conn, m) })
+}
+
+// dial opens a connection to the server, secures it according to the TLS
+// mode and authenticates when the server supports it
+func (p *SMTPProvider) dial(ctx context.Context) (*smtpConn, error) {
+	addr := net.JoinHostPort(p.host, strconv.Itoa(p.port))
+	dialer := &net.Dialer{Timeout: smtpDialTimeout}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if p.tlsMode == SMTPTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: p.tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c := &smtpConn{conn: conn, createdAt: now, usedAt: now}
+	err = c.do(ctx, func() error {
+		client, err := smtp.NewClient(conn, p.host)
+		if err != nil {
+			return err
+		}
+		c.client = client
+		if err := client.Hello("localhost"); err != nil {
+			return err
+		}
+
+		if p.tlsMode == SMTPTLSAuto || p.tlsMode == SMTPTLSStartTLS {
+			if ok, _ := client.Extension("STARTTLS"); ok {
+				if err := client.StartTLS(p.tlsConfig); err != nil {
+					return err
+				}
+			} else if p.tlsMode == SMTPTLSStartTLS {
+				return errors.New("smtp: server does not support STARTTLS")
+			}
+		}
+
+		if ok, mechanisms := client.Extension("AUTH"); ok && p.username != "" {
+			if err := client.Auth(p.auth(mechanisms)); err != nil {
 				return err
-			}))
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
 	}
+	return c, nil
+}
 
-	// Create dialer
-	dialer := gomail.NewDialer(p.host, p.port, p.username, p.password)
-	if p.tls {
-		dialer.SSL = true
+// auth picks the authentication mechanism offered by the server, like gomail
+func (p *SMTPProvider) auth(mechanisms string) smtp.Auth {
+	switch {
+	case strings.Contains(mechanisms, "CRAM-MD5"):
+		return smtp.CRAMMD5Auth(p.username, p.password)
+	case strings.Contains(mechanisms, "LOGIN") && !strings.Contains(mechanisms, "PLAIN"):
+		return &loginAuth{username: p.username, password: p.password}
 	}
+	return smtp.PlainAuth("", p.username, p.password, p.host)
+}
 
-	// Send email
-	if err := dialer.DialAndSend(m); err != nil {
-		return &EmailResponse{
-			Status:    "failed",
-			Provider:  p.Name(),
-			Error:     err.Error(),
-			SentAt:    time.Now(),
-		}, fmt.Errorf("%w: %v", ErrSendFailed, err)
+// loginAuth implements the LOGIN authentication mechanism
+type loginAuth struct {
+	username string
+	password string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
 	}
+	switch strings.ToLower(strings.TrimSuffix(string(fromServer), ":")) {
+	case "username":
+		return []byte(a.username), nil
+	case "password":
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("smtp: unexpected server challenge: %s", fromServer)
+}
 
-	// Generate a simple message ID for SMTP
-	messageID := fmt.Sprintf("%d@%s", time.Now().UnixNano(), p.host)
+// smtpReplyPattern finds an SMTP reply code in an error that gomail flattened
+// into text
+var smtpReplyPattern = regexp.MustCompile(`(?:^|: )([2-5][0-9]{2})[ -]`)
+
+// classifySMTPError turns an SMTP error into a classified send error. 4xx
+// replies are transient and 5xx replies permanent, except for authentication
+// failures, which come from the configuration rather than the email.
+func classifySMTPError(err error) *ProviderError {
+	code := smtpReplyCode(err)
+	class := ErrorClassTransient
+	switch {
+	case code == 530 || code == 534 || code == 535:
+		class = ErrorClassTransient
+	case code >= 500:
+		class = ErrorClassPermanent
+	}
 
-	return &EmailResponse{
-		MessageID: messageID,
-		Status:    "sent",
-		Provider:  p.Name(),
-		SentAt:    time.Now(),
-	}, nil
+	sendErr := NewSendError(class, err)
+	sendErr.StatusCode = code
+	return sendErr
+}
+
+// smtpReplyCode returns the SMTP reply code of err, or zero if the server did
+// not reply, such as when it could not be reached
+func smtpReplyCode(err error) int {
+	var replyErr *textproto.Error
+	if errors.As(err, &replyErr) {
+		return replyErr.Code
+	}
+	if match := smtpReplyPattern.FindStringSubmatch(err.Error()); match != nil {
+		code, _ := strconv.Atoi(match[1])
+		return code
+	}
+	return 0
 }
 
 // Validate validates the SMTP configuration
@@ -175,24 +387,17 @@ func (p *SMTPProvider) Validate() error {
 
 // Health checks if SMTP is healthy
 func (p *SMTPProvider) Health(ctx context.Context) error {
-	// Create dialer for health check
-	dialer := gomail.NewDialer(p.host, p.port, p.username, p.password)
-	if p.tls {
-		dialer.SSL = true
-	}
-
-	// Try to connect to SMTP server
-	s, err := dialer.Dial()
+	// Check a pooled connection with NOOP, or open a new one
+	conn, err := p.pool.get(ctx)
 	if err != nil {
 		return fmt.Errorf("%w: SMTP health check failed: %v", ErrProviderUnhealthy, err)
 	}
-	defer s.Close()
+	p.pool.put(conn)
 
 	return nil
 }
 
-// Close closes the SMTP provider (no cleanup needed)
+// Close closes the idle connections of the SMTP provider
 func (p *SMTPProvider) Close() error {
-	// SMTP provider doesn't need explicit cleanup
-	return nil
+	return p.pool.close()
 } 
\ No newline at end of file
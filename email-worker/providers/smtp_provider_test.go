@@ -0,0 +1,342 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptest"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpMessage is a message received by the fake SMTP server
+type smtpMessage struct {
+	from       string
+	recipients []string
+	data       string
+	secure     bool
+}
+
+// fakeSMTP is an in-process SMTP server. It rejects the recipient at
+// rejected, offers STARTTLS with startTLS and never answers DATA with stall.
+type fakeSMTP struct {
+	listener net.Listener
+	startTLS *tls.Config
+	rejected string
+	stall    bool
+
+	mu          sync.Mutex
+	messages    []smtpMessage
+	connections int
+	commands    []string
+}
+
+// smtpOption configures a fake SMTP server
+type smtpOption func(s *fakeSMTP)
+
+func newFakeSMTP(t *testing.T, options ...smtpOption) *fakeSMTP {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeSMTP{listener: listener}
+	for _, option := range options {
+		option(s)
+	}
+	t.Cleanup(func() { s.listener.Close() })
+	listener = s.listener
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// withTLS serves the fake with a test certificate, over implicit TLS or
+// with STARTTLS. It returns the client configuration that trusts it.
+func withTLS(t *testing.T, implicit bool, clientConfig *tls.Config) smtpOption {
+	server := httptest.NewUnstartedServer(nil)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	clientConfig.ServerName = "127.0.0.1"
+	clientConfig.RootCAs = x509.NewCertPool()
+	clientConfig.RootCAs.AddCert(server.Certificate())
+
+	config := &tls.Config{Certificates: server.TLS.Certificates}
+	return func(s *fakeSMTP) {
+		if implicit {
+			s.listener = tls.NewListener(s.listener, config)
+		} else {
+			s.startTLS = config
+		}
+	}
+}
+
+// provider creates an SMTP provider that sends to the server
+func (s *fakeSMTP) provider(t *testing.T, settings ...any) *SMTPProvider {
+	t.Helper()
+	addr := s.listener.Addr().(*net.TCPAddr)
+	config := map[string]any{
+		"host": "127.0.0.1", "port": addr.Port,
+		"username": "user", "password": "secret", "from": "noreply@example.com",
+	}
+	for i := 0; i+1 < len(settings); i += 2 {
+		config[settings[i].(string)] = settings[i+1]
+	}
+	provider, err := NewSMTPProvider(config)
+	if err != nil {
+		t.Fatalf("NewSMTPProvider: %v", err)
+	}
+	t.Cleanup(func() { provider.Close() })
+	return provider.(*SMTPProvider)
+}
+
+func (s *fakeSMTP) received() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+// stats returns how many connections the server accepted and the commands
+// it received without their arguments
+func (s *fakeSMTP) stats() (int, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections, strings.Join(s.commands, " ")
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer func() { conn.Close() }()
+	s.mu.Lock()
+	s.connections++
+	s.mu.Unlock()
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost ESMTP")
+
+	var message smtpMessage
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command, arg, _ := strings.Cut(line, " ")
+		command = strings.ToUpper(command)
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		s.mu.Unlock()
+
+		switch command {
+		case "EHLO", "HELO":
+			if _, secure := conn.(*tls.Conn); s.startTLS != nil && !secure {
+				text.PrintfLine("250-localhost")
+				text.PrintfLine("250 STARTTLS")
+				continue
+			}
+			text.PrintfLine("250 localhost")
+		case "STARTTLS":
+			text.PrintfLine("220 Ready to start TLS")
+			conn = tls.Server(conn, s.startTLS)
+			text = textproto.NewConn(conn)
+		case "MAIL":
+			message = smtpMessage{from: smtpPath(arg)}
+			text.PrintfLine("250 OK")
+		case "RCPT":
+			if smtpPath(arg) == s.rejected {
+				text.PrintfLine("550 5.1.1 No such user")
+				continue
+			}
+			message.recipients = append(message.recipients, smtpPath(arg))
+			text.PrintfLine("250 OK")
+		case "DATA":
+			if s.stall {
+				io.Copy(io.Discard, conn)
+				return
+			}
+			text.PrintfLine("354 Go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			_, message.secure = conn.(*tls.Conn)
+			message.data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, message)
+			id := len(s.messages)
+			s.mu.Unlock()
+			text.PrintfLine("250 OK queued as " + strconv.Itoa(id))
+		case "RSET", "NOOP":
+			text.PrintfLine("250 OK")
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+// smtpPath returns the address of a MAIL FROM or RCPT TO argument
+func smtpPath(arg string) string {
+	_, path, _ := strings.Cut(arg, ":")
+	return strings.Trim(strings.TrimSpace(path), "<>")
+}
+
+func TestSMTPProvider_SendsToEveryRecipient(t *testing.T) {
+	server := newFakeSMTP(t)
+	provider := server.provider(t)
+
+	_, err := provider.Send(context.Background(), &EmailRequest{
+		To:          []string{"a@example.com"},
+		CC:          []string{"b@example.com"},
+		BCC:         []string{"c@example.com"},
+		Subject:     "Hello",
+		TextContent: "Hello",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	messages := server.received()
+	if len(messages) != 1 {
+		t.Fatalf("got %d messages, want 1", len(messages))
+	}
+	message := messages[0]
+	if strings.Join(message.recipients, ",") != "a@example.com,b@example.com,c@example.com" {
+		t.Fatalf("got recipients %v", message.recipients)
+	}
+	headers, err := textproto.NewReader(bufio.NewReader(strings.NewReader(message.data))).ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("read headers: %v", err)
+	}
+	if headers.Get("Cc") != "b@example.com" || headers.Get("Bcc") != "" {
+		t.Fatalf("got Cc %q and Bcc %q, BCC recipients must stay hidden", headers.Get("Cc"), headers.Get("Bcc"))
+	}
+}
+
+func TestSMTPProvider_ReusesPooledConnections(t *testing.T) {
+	server := newFakeSMTP(t, func(s *fakeSMTP) { s.rejected = "gone@example.com" })
+	provider := server.provider(t)
+	ctx := context.Background()
+
+	for _, to := range []string{"a@example.com", "gone@example.com", "b@example.com"} {
+		_, err := provider.Send(ctx, &EmailRequest{To: []string{to}, Subject: "Hello", TextContent: "Hello"})
+		if to == "gone@example.com" {
+			if class, _ := Classify(err); class != ErrorClassPermanent {
+				t.Fatalf("got %v for a rejected recipient, want a permanent error", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Send to %s: %v", to, err)
+		}
+	}
+	if err := provider.Health(ctx); err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+
+	connections, commands := server.stats()
+	if connections != 1 {
+		t.Fatalf("got %d connections, want every send over one", connections)
+	}
+	want := "EHLO MAIL RCPT DATA NOOP MAIL RCPT RSET NOOP MAIL RCPT DATA NOOP"
+	if commands != want {
+		t.Fatalf("got commands %s, want %s", commands, want)
+	}
+	if len(server.received()) != 2 {
+		t.Fatalf("got %d messages, want 2", len(server.received()))
+	}
+}
+
+func TestSMTPProvider_ExpiresIdleConnections(t *testing.T) {
+	server := newFakeSMTP(t)
+	provider := server.provider(t, "idle_timeout", 20*time.Millisecond, "max_idle_conns", 1)
+	ctx := context.Background()
+
+	send := func() {
+		t.Helper()
+		if _, err := provider.Send(ctx, &EmailRequest{To: []string{"a@example.com"}, TextContent: "Hello"}); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	send()
+	time.Sleep(30 * time.Millisecond)
+	send()
+	if connections, commands := server.stats(); connections != 2 || strings.Contains(commands, "NOOP") {
+		t.Fatalf("got %d connections and commands %s, want a new connection after the idle timeout", connections, commands)
+	}
+
+	provider.pool.config.MaxLifetime = time.Nanosecond
+	send()
+	if connections, _ := server.stats(); connections != 3 {
+		t.Fatalf("got %d connections, want a new connection past the max lifetime", connections)
+	}
+}
+
+func TestSMTPProvider_SendStopsAtContextDeadline(t *testing.T) {
+	server := newFakeSMTP(t, func(s *fakeSMTP) { s.stall = true })
+	provider := server.provider(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := provider.Send(ctx, &EmailRequest{To: []string{"a@example.com"}, TextContent: "Hello"})
+	if err == nil || time.Since(start) > 2*time.Second {
+		t.Fatalf("got %v after %v, want the send to stop at the deadline", err, time.Since(start))
+	}
+	if class, _ := Classify(err); class != ErrorClassTransient {
+		t.Fatalf("got %s, want transient", class)
+	}
+	if len(provider.pool.idle) != 0 {
+		t.Fatal("a connection that timed out must not be reused")
+	}
+}
+
+func TestSMTPProvider_TLSModes(t *testing.T) {
+	ctx := context.Background()
+	req := &EmailRequest{To: []string{"a@example.com"}, TextContent: "Hello"}
+
+	for _, mode := range []SMTPTLSMode{SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSAuto} {
+		t.Run(string(mode), func(t *testing.T) {
+			clientConfig := &tls.Config{}
+			server := newFakeSMTP(t, withTLS(t, mode == SMTPTLSImplicit, clientConfig))
+			provider := server.provider(t, "tls_mode", string(mode))
+			provider.tlsConfig = clientConfig
+
+			if _, err := provider.Send(ctx, req); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			if messages := server.received(); len(messages) != 1 || !messages[0].secure {
+				t.Fatalf("got %+v, want a message over TLS", messages)
+			}
+		})
+	}
+
+	t.Run("starttls required", func(t *testing.T) {
+		provider := newFakeSMTP(t).provider(t, "tls_mode", "starttls")
+		if _, err := provider.Send(ctx, req); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+			t.Fatalf("got %v, want STARTTLS to be required", err)
+		}
+	})
+
+	if _, err := NewSMTPProvider(map[string]any{
+		"host": "localhost", "port": 25, "username": "user", "password": "secret",
+		"from": "noreply@example.com", "tls_mode": "ssl",
+	}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("got %v for an unknown TLS mode, want invalid configuration", err)
+	}
+}
@@ -0,0 +1,78 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/queue"
+)
+
+// testCancelAndReschedule runs the cancellation behaviour every queue with
+// cancellation support must have
+func testCancelAndReschedule(t *testing.T, q queue.Queue) {
+	ctx := context.Background()
+
+	pending := newTestJob(models.JobPriorityNormal)
+	reminder := newTestJob(models.JobPriorityNormal)
+	leased := newTestJob(models.JobPriorityHigh)
+	require.NoError(t, q.Publish(ctx, leased))
+	consumed, err := q.Consume(ctx)
+	require.NoError(t, err)
+	require.Equal(t, leased.ID, consumed.ID)
+
+	require.NoError(t, q.Publish(ctx, pending))
+	require.NoError(t, q.PublishScheduled(ctx, reminder, time.Now().Add(time.Hour)))
+
+	// Pending and scheduled jobs are removed from the queue
+	require.NoError(t, q.Cancel(ctx, pending.ID.String()))
+	assert.ErrorIs(t, q.Cancel(ctx, pending.ID.String()), queue.ErrJobNotFound)
+	assertSize(t, q, 0)
+
+	// A leased job is marked until its worker acks it
+	require.NoError(t, q.Cancel(ctx, leased.ID.String()))
+	cancelled, err := q.Cancelled(ctx, leased.ID.String())
+	require.NoError(t, err)
+	assert.True(t, cancelled)
+	assert.ErrorIs(t, q.Reschedule(ctx, leased.ID.String(), time.Now()), queue.ErrJobLeased)
+	require.NoError(t, q.Ack(ctx, consumed.Receipt))
+
+	// A scheduled job can be moved, to now it is ready right away
+	require.NoError(t, q.Reschedule(ctx, reminder.ID.String(), time.Now().Add(-time.Second)))
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+	consumed, err = q.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, reminder.ID, consumed.ID)
+	require.NoError(t, q.Ack(ctx, consumed.Receipt))
+
+	assert.ErrorIs(t, q.Reschedule(ctx, "00000000-0000-0000-0000-000000000000", time.Now()), queue.ErrJobNotFound)
+	assert.ErrorIs(t, q.Cancel(ctx, "00000000-0000-0000-0000-000000000000"), queue.ErrJobNotFound)
+}
+
+func TestQueue_CancelAndReschedule(t *testing.T) {
+	testEachQueue(t, time.Minute, testCancelAndReschedule)
+}
+
+func TestRedisQueue_RescheduleToLater(t *testing.T) {
+	q, _ := newTestRedisQueue(t, time.Minute)
+	ctx := context.Background()
+
+	job := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, q.Publish(ctx, job))
+
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, q.Reschedule(ctx, job.ID.String(), later))
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+	_, err := q.Consume(ctx)
+	assert.Equal(t, queue.ErrQueueEmpty, err)
+
+	// Cancelling a job that waits removes it, it is not marked
+	require.NoError(t, q.Cancel(ctx, job.ID.String()))
+	cancelled, err := q.Cancelled(ctx, job.ID.String())
+	require.NoError(t, err)
+	assert.False(t, cancelled)
+}
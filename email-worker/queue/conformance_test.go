@@ -0,0 +1,182 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/queue"
+)
+
+// queueBackend creates the queues of one backend for the behaviour every
+// queue must have
+type queueBackend struct {
+	name     string
+	newQueue func(t *testing.T, visibilityTimeout time.Duration) queue.Queue
+}
+
+// queueBackends are the queues the conformance tests run against. The
+// postgres queue is skipped unless its test database is set.
+var queueBackends = []queueBackend{
+	{
+		name: "memory",
+		newQueue: func(t *testing.T, visibilityTimeout time.Duration) queue.Queue {
+			return queue.NewMemoryQueue(visibilityTimeout, zap.NewNop())
+		},
+	},
+	{
+		name: "redis",
+		newQueue: func(t *testing.T, visibilityTimeout time.Duration) queue.Queue {
+			q, _ := newTestRedisQueue(t, visibilityTimeout)
+			return q
+		},
+	},
+	{
+		name: "postgres",
+		newQueue: func(t *testing.T, visibilityTimeout time.Duration) queue.Queue {
+			return newTestPostgresQueue(t, visibilityTimeout)
+		},
+	},
+}
+
+// testEachQueue runs test against a new queue of every backend
+func testEachQueue(t *testing.T, visibilityTimeout time.Duration, test func(t *testing.T, q queue.Queue)) {
+	for _, backend := range queueBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			test(t, backend.newQueue(t, visibilityTimeout))
+		})
+	}
+}
+
+// assertSize asserts how many jobs are ready to be consumed
+func assertSize(t *testing.T, q queue.Queue, want int64) {
+	t.Helper()
+	size, err := q.Size(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, want, size)
+}
+
+// testLeases runs the lease behaviour every queue must have, on a queue with
+// a visibility timeout of 50ms
+func testLeases(t *testing.T, q queue.Queue) {
+	ctx := context.Background()
+
+	job := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, q.Publish(ctx, job))
+
+	// A nacked job is requeued at once and its receipt is spent
+	first, err := q.Consume(ctx)
+	require.NoError(t, err)
+	require.NoError(t, q.Nack(ctx, first.Receipt, 0))
+	assert.ErrorIs(t, q.Ack(ctx, first.Receipt), queue.ErrLeaseLost)
+	assert.ErrorIs(t, q.Nack(ctx, first.Receipt, 0), queue.ErrLeaseLost)
+
+	second, err := q.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, second.ID)
+
+	// A job nacked with a delay is requeued once the delay has passed
+	require.NoError(t, q.Nack(ctx, second.Receipt, 100*time.Millisecond))
+	assertSize(t, q, 0)
+	time.Sleep(150 * time.Millisecond)
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+	assertSize(t, q, 1)
+
+	// An extended lease survives the visibility timeout
+	third, err := q.Consume(ctx)
+	require.NoError(t, err)
+	require.NoError(t, q.ExtendLease(ctx, third.Receipt, time.Minute))
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+	assertSize(t, q, 0)
+	require.NoError(t, q.Ack(ctx, third.Receipt))
+
+	// A delivery whose lease expired can no longer settle its job
+	stale := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, q.Publish(ctx, stale))
+	expired, err := q.Consume(ctx)
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+
+	redelivered, err := q.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, stale.ID, redelivered.ID)
+	assert.NotEqual(t, expired.Receipt, redelivered.Receipt)
+	assert.ErrorIs(t, q.Ack(ctx, expired.Receipt), queue.ErrLeaseLost)
+	assert.ErrorIs(t, q.ExtendLease(ctx, expired.Receipt, time.Minute), queue.ErrLeaseLost)
+	require.NoError(t, q.Ack(ctx, redelivered.Receipt))
+
+	assert.ErrorIs(t, q.Ack(ctx, "not-a-receipt"), queue.ErrInvalidReceipt)
+}
+
+func TestQueue_Leases(t *testing.T) {
+	testEachQueue(t, 50*time.Millisecond, testLeases)
+}
+
+// testRetryKeepsNextAttempt runs the retry behaviour every queue must have: a
+// retry published for later keeps its retry state
+func testRetryKeepsNextAttempt(t *testing.T, q queue.Queue) {
+	ctx := context.Background()
+
+	job := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, q.Publish(ctx, job))
+
+	consumed, err := q.Consume(ctx)
+	require.NoError(t, err)
+
+	consumed.IncrementRetry()
+	nextAttemptAt := time.Now().Add(200 * time.Millisecond)
+	consumed.MarkAsRetrying(nextAttemptAt, "smtp unavailable")
+	require.NoError(t, q.PublishScheduled(ctx, consumed, nextAttemptAt))
+	require.NoError(t, q.Ack(ctx, consumed.Receipt))
+
+	assertSize(t, q, 0)
+	time.Sleep(300 * time.Millisecond)
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+
+	retried, err := q.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, retried.ID)
+	assert.Equal(t, 1, retried.RetryCount)
+	assert.Equal(t, "smtp unavailable", retried.ErrorMessage)
+	require.NotNil(t, retried.NextAttemptAt)
+	assert.WithinDuration(t, nextAttemptAt, *retried.NextAttemptAt, time.Millisecond)
+}
+
+func TestQueue_RetryKeepsNextAttempt(t *testing.T) {
+	testEachQueue(t, time.Minute, testRetryKeepsNextAttempt)
+}
+
+// testPromotesOneLevelPerInterval runs the aging behaviour every queue with
+// priority lanes must have: a promoted job waits another interval before it
+// is promoted again
+func testPromotesOneLevelPerInterval(t *testing.T, q queue.Queue) {
+	ctx := context.Background()
+
+	low := newTestJob(models.JobPriorityLow)
+	require.NoError(t, q.Publish(ctx, low))
+	time.Sleep(150 * time.Millisecond)
+
+	// The second tick comes before the promoted job waited another interval
+	for tick, want := range []int{1, 0} {
+		promoted, err := q.PromoteAged(ctx, 100*time.Millisecond, models.JobPriorityUrgent)
+		require.NoError(t, err)
+		assert.Equal(t, want, promoted, "tick %d", tick)
+	}
+
+	jobs, err := q.ConsumeLane(ctx, models.JobPriorityNormal, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, low.ID, jobs[0].ID)
+	assert.Equal(t, models.JobPriorityNormal, jobs[0].Priority)
+}
+
+func TestQueue_PromotesOneLevelPerInterval(t *testing.T) {
+	testEachQueue(t, time.Minute, testPromotesOneLevelPerInterval)
+}
@@ -0,0 +1,119 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"booking-system/email-worker/models"
+)
+
+// DeadLetter is a job that failed permanently, kept for inspection and replay
+type DeadLetter struct {
+	// Job is the original payload, including its attempt history
+	Job            *models.EmailJob `json:"job"`
+	FinalError     string           `json:"final_error"`
+	DeadLetteredAt time.Time        `json:"dead_lettered_at"`
+}
+
+// NewDeadLetter creates a dead letter for a job that exhausted its retries
+func NewDeadLetter(job *models.EmailJob, finalError string) *DeadLetter {
+	stored := *job
+	stored.Receipt = ""
+
+	return &DeadLetter{
+		Job:            &stored,
+		FinalError:     finalError,
+		DeadLetteredAt: time.Now(),
+	}
+}
+
+// JobID returns the ID of the dead-lettered job
+func (d *DeadLetter) JobID() string {
+	return d.Job.ID.String()
+}
+
+// DeadLetterFilter selects dead letters. Zero fields match everything.
+type DeadLetterFilter struct {
+	TemplateName  string
+	ErrorContains string
+	Since         time.Time
+	Until         time.Time
+	Limit         int
+	Offset        int
+}
+
+// Matches checks if a dead letter is selected by the filter, ignoring paging
+func (f DeadLetterFilter) Matches(entry *DeadLetter) bool {
+	if f.TemplateName != "" && entry.Job.TemplateName != f.TemplateName {
+		return false
+	}
+	if f.ErrorContains != "" && !strings.Contains(entry.FinalError, f.ErrorContains) {
+		return false
+	}
+	if !f.Since.IsZero() && entry.DeadLetteredAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.DeadLetteredAt.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// page applies the filter's offset and limit to entries that already match it
+func (f DeadLetterFilter) page(entries []*DeadLetter) []*DeadLetter {
+	if f.Offset >= len(entries) {
+		return []*DeadLetter{}
+	}
+	entries = entries[f.Offset:]
+	if f.Limit > 0 && f.Limit < len(entries) {
+		entries = entries[:f.Limit]
+	}
+	return entries
+}
+
+// DeadLetterStore keeps jobs that failed permanently.
+//
+// Each queue backend has its own store, so dead letters live next to the jobs
+// they came from. Replaying a dead letter publishes its job again and deletes
+// it from the store.
+type DeadLetterStore interface {
+	// Add stores a dead letter, replacing an earlier one for the same job
+	Add(ctx context.Context, entry *DeadLetter) error
+
+	// Get returns the dead letter of a job, or ErrDeadLetterNotFound
+	Get(ctx context.Context, jobID string) (*DeadLetter, error)
+
+	// List returns the dead letters selected by filter, newest first
+	List(ctx context.Context, filter DeadLetterFilter) ([]*DeadLetter, error)
+
+	// Delete removes the dead letters of the given jobs and returns how many existed
+	Delete(ctx context.Context, jobIDs ...string) (int, error)
+
+	// Close closes the store
+	Close() error
+}
+
+// CreateDeadLetterStore creates the dead-letter store matching a queue configuration.
+// Kafka topics cannot be listed or purged per job, so the Kafka backend keeps
+// its dead letters in the service database.
+func (f *QueueFactory) CreateDeadLetterStore(config QueueConfig) (DeadLetterStore, error) {
+	switch config.Type {
+	case "redis":
+		addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
+		return NewRedisDeadLetterStore(addr, config.Password, config.Database, config.QueueName, f.logger), nil
+	case "memory":
+		return NewMemoryDeadLetterStore(), nil
+	case "postgres", "kafka":
+		if config.DB == nil {
+			return nil, fmt.Errorf("%s dead-letter store requires a database connection", config.Type)
+		}
+		return NewPostgresDeadLetterStore(config.DB, f.logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported queue type: %s", config.Type)
+	}
+}
+
+// ErrDeadLetterNotFound is returned when a job has no dead letter
+var ErrDeadLetterNotFound = fmt.Errorf("dead letter not found")
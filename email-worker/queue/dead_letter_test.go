@@ -0,0 +1,110 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/queue"
+)
+
+func newFailedJob(templateName string, reasons ...string) *models.EmailJob {
+	job := models.NewEmailJob([]string{"user@example.com"}, nil, nil, templateName, map[string]any{"Name": "Test"}, models.JobPriorityNormal)
+	for _, reason := range reasons {
+		job.RecordAttempt(reason)
+	}
+	job.MarkAsFailed()
+	return job
+}
+
+// testDeadLetterStore runs the behaviour every dead-letter store must have
+func testDeadLetterStore(t *testing.T, store queue.DeadLetterStore) {
+	ctx := context.Background()
+
+	older := queue.NewDeadLetter(newFailedJob("welcome", "timeout", "smtp 421"), "smtp 421")
+	older.DeadLetteredAt = time.Now().Add(-time.Hour)
+	newer := queue.NewDeadLetter(newFailedJob("email_verification", "provider outage"), "provider outage")
+	require.NoError(t, store.Add(ctx, older))
+	require.NoError(t, store.Add(ctx, newer))
+
+	// Inspect keeps the payload and the attempt history
+	entry, err := store.Get(ctx, older.JobID())
+	require.NoError(t, err)
+	assert.Equal(t, "smtp 421", entry.FinalError)
+	assert.Equal(t, older.Job.To, entry.Job.To)
+	require.Len(t, entry.Job.Attempts, 2)
+	assert.Equal(t, "timeout", entry.Job.Attempts[0].Error)
+	assert.Equal(t, 2, entry.Job.Attempts[1].Number)
+
+	_, err = store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, queue.ErrDeadLetterNotFound)
+
+	// Newest first, filtered and paged
+	entries, err := store.List(ctx, queue.DeadLetterFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, newer.JobID(), entries[0].JobID())
+
+	entries, err = store.List(ctx, queue.DeadLetterFilter{TemplateName: "welcome"})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, older.JobID(), entries[0].JobID())
+
+	entries, err = store.List(ctx, queue.DeadLetterFilter{ErrorContains: "outage"})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, newer.JobID(), entries[0].JobID())
+
+	entries, err = store.List(ctx, queue.DeadLetterFilter{Since: time.Now().Add(-time.Minute)})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, newer.JobID(), entries[0].JobID())
+
+	entries, err = store.List(ctx, queue.DeadLetterFilter{Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, older.JobID(), entries[0].JobID())
+
+	deleted, err := store.Delete(ctx, older.JobID(), "missing")
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	entries, err = store.List(ctx, queue.DeadLetterFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, newer.JobID(), entries[0].JobID())
+}
+
+func TestMemoryDeadLetterStore(t *testing.T) {
+	testDeadLetterStore(t, queue.NewMemoryDeadLetterStore())
+}
+
+func TestRedisDeadLetterStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := queue.NewRedisDeadLetterStore(mr.Addr(), "", 0, "email-jobs", zap.NewNop())
+	t.Cleanup(func() { store.Close() })
+
+	testDeadLetterStore(t, store)
+}
+
+func TestPostgresDeadLetterStore(t *testing.T) {
+	db, _ := newTestPostgresDB(t, "email_dead_letters")
+	testDeadLetterStore(t, queue.NewPostgresDeadLetterStore(db, zap.NewNop()))
+}
+
+func TestQueueFactory_CreateDeadLetterStore(t *testing.T) {
+	factory := queue.NewQueueFactory(zap.NewNop())
+
+	store, err := factory.CreateDeadLetterStore(queue.QueueConfig{Type: "memory"})
+	require.NoError(t, err)
+	assert.IsType(t, &queue.MemoryDeadLetterStore{}, store)
+
+	_, err = factory.CreateDeadLetterStore(queue.QueueConfig{Type: "kafka"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"booking-system/email-worker/models"
+)
+
+// Heartbeat is the last sign of life of a worker, with the jobs it was
+// sending at the time
+type Heartbeat struct {
+	// Worker identifies the worker across replicas
+	Worker string          `json:"worker"`
+	Jobs   []*HeartbeatJob `json:"jobs"`
+	BeatAt time.Time       `json:"beat_at"`
+}
+
+// HeartbeatJob is a job a worker was sending and the receipt of its lease
+type HeartbeatJob struct {
+	Job     *models.EmailJob `json:"job"`
+	Receipt string           `json:"receipt"`
+}
+
+// NewHeartbeat creates a heartbeat of worker sending jobs. The jobs must not
+// be changed while the heartbeat is stored.
+func NewHeartbeat(worker string, jobs []*models.EmailJob) *Heartbeat {
+	heartbeat := &Heartbeat{
+		Worker: worker,
+		Jobs:   make([]*HeartbeatJob, len(jobs)),
+		// Stores keep millisecond precision in UTC
+		BeatAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	for i, job := range jobs {
+		heartbeat.Jobs[i] = &HeartbeatJob{Job: job, Receipt: job.Receipt}
+	}
+	return heartbeat
+}
+
+// HeartbeatStore keeps the heartbeats of the workers of all replicas, so the
+// jobs of a worker that died mid-send can be found and recovered. Heartbeats
+// are kept until removed, a worker that stops beating leaves its last one.
+type HeartbeatStore interface {
+	// Beat stores a heartbeat, replacing the worker's previous one
+	Beat(ctx context.Context, heartbeat *Heartbeat) error
+
+	// Expired returns the heartbeats that were not renewed since before
+	Expired(ctx context.Context, before time.Time) ([]*Heartbeat, error)
+
+	// Remove deletes a heartbeat unless the worker has beaten again since
+	Remove(ctx context.Context, heartbeat *Heartbeat) error
+
+	// Close closes the store
+	Close() error
+}
+
+// CreateHeartbeatStore creates the heartbeat store matching a queue
+// configuration. The Kafka backend keeps its heartbeats in the service database.
+func (f *QueueFactory) CreateHeartbeatStore(config QueueConfig) (HeartbeatStore, error) {
+	switch config.Type {
+	case "redis":
+		addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
+		return NewRedisHeartbeatStore(addr, config.Password, config.Database, config.QueueName, f.logger), nil
+	case "memory":
+		return NewMemoryHeartbeatStore(), nil
+	case "postgres", "kafka":
+		if config.DB == nil {
+			return nil, fmt.Errorf("%s heartbeat store requires a database connection", config.Type)
+		}
+		return NewPostgresHeartbeatStore(config.DB, f.logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported queue type: %s", config.Type)
+	}
+}
@@ -0,0 +1,71 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/queue"
+)
+
+// testHeartbeatStore runs the behaviour every heartbeat store must have
+func testHeartbeatStore(t *testing.T, store queue.HeartbeatStore) {
+	ctx := context.Background()
+
+	job := newTestJob(models.JobPriorityUrgent)
+	job.SetReceipt("receipt-1")
+	dead := queue.NewHeartbeat("replica-a/1", []*models.EmailJob{job})
+	require.NoError(t, store.Beat(ctx, dead))
+
+	time.Sleep(10 * time.Millisecond)
+	cutoff := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, store.Beat(ctx, queue.NewHeartbeat("replica-a/2", nil)))
+
+	// Only the worker that stopped beating before the cutoff is expired
+	expired, err := store.Expired(ctx, cutoff)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "replica-a/1", expired[0].Worker)
+	require.Len(t, expired[0].Jobs, 1)
+	assert.Equal(t, job.ID, expired[0].Jobs[0].Job.ID)
+	assert.Equal(t, "receipt-1", expired[0].Jobs[0].Receipt)
+
+	// A worker that beats again keeps its heartbeat
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, store.Beat(ctx, queue.NewHeartbeat("replica-a/1", nil)))
+	require.NoError(t, store.Remove(ctx, expired[0]))
+	expired, err = store.Expired(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Len(t, expired, 2)
+
+	for _, heartbeat := range expired {
+		require.NoError(t, store.Remove(ctx, heartbeat))
+	}
+	expired, err = store.Expired(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+}
+
+func TestMemoryHeartbeatStore(t *testing.T) {
+	testHeartbeatStore(t, queue.NewMemoryHeartbeatStore())
+}
+
+func TestRedisHeartbeatStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := queue.NewRedisHeartbeatStore(mr.Addr(), "", 0, "email-jobs", zap.NewNop())
+	t.Cleanup(func() { store.Close() })
+
+	testHeartbeatStore(t, store)
+}
+
+func TestPostgresHeartbeatStore(t *testing.T) {
+	db, _ := newTestPostgresDB(t, "email_worker_heartbeats")
+	testHeartbeatStore(t, queue.NewPostgresHeartbeatStore(db, zap.NewNop()))
+}
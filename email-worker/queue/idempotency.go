@@ -0,0 +1,57 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"booking-system/email-worker/models"
+)
+
+// IdempotencyStore remembers which job an idempotency key created, so that a
+// caller retrying a submission gets the original job back instead of a second
+// email. Keys are held for a deduplication window and then forgotten.
+//
+// Like dead letters, keys live next to the queue backend, so they survive
+// worker restarts wherever the queue itself does.
+type IdempotencyStore interface {
+	// Reserve claims key for job until window has passed. If the key is still
+	// held by an earlier job, that job is returned and nothing is reserved.
+	Reserve(ctx context.Context, key string, job *models.EmailJob, window time.Duration) (*models.EmailJob, error)
+
+	// Release frees key if it is held by the given job, so that a submission
+	// that could not be published can be retried
+	Release(ctx context.Context, key, jobID string) error
+
+	// DeleteExpired removes keys whose window has passed and returns how many there were
+	DeleteExpired(ctx context.Context) (int, error)
+
+	// Close closes the store
+	Close() error
+}
+
+// CreateIdempotencyStore creates the idempotency store matching a queue
+// configuration. The Kafka backend keeps its keys in the service database.
+func (f *QueueFactory) CreateIdempotencyStore(config QueueConfig) (IdempotencyStore, error) {
+	switch config.Type {
+	case "redis":
+		addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
+		return NewRedisIdempotencyStore(addr, config.Password, config.Database, config.QueueName, f.logger), nil
+	case "memory":
+		return NewMemoryIdempotencyStore(), nil
+	case "postgres", "kafka":
+		if config.DB == nil {
+			return nil, fmt.Errorf("%s idempotency store requires a database connection", config.Type)
+		}
+		return NewPostgresIdempotencyStore(config.DB, f.logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported queue type: %s", config.Type)
+	}
+}
+
+// idempotentJob returns the copy of a job kept for an idempotency key
+func idempotentJob(job *models.EmailJob) *models.EmailJob {
+	stored := *job
+	stored.Receipt = ""
+	return &stored
+}
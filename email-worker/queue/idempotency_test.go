@@ -0,0 +1,96 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/queue"
+)
+
+// testIdempotencyStore runs the behaviour every idempotency store must have.
+// expire makes the keys of the store outlive their window.
+func testIdempotencyStore(t *testing.T, store queue.IdempotencyStore, expire func()) {
+	ctx := context.Background()
+
+	first := newTestJob(models.JobPriorityUrgent)
+	first.IdempotencyKey = "verify:42"
+	original, err := store.Reserve(ctx, "verify:42", first, 200*time.Millisecond)
+	require.NoError(t, err)
+	assert.Nil(t, original)
+
+	// A retried submission gets the first job back
+	retry := newTestJob(models.JobPriorityUrgent)
+	original, err = store.Reserve(ctx, "verify:42", retry, 200*time.Millisecond)
+	require.NoError(t, err)
+	require.NotNil(t, original)
+	assert.Equal(t, first.ID, original.ID)
+	assert.Equal(t, first.To, original.To)
+	assert.Equal(t, "verify:42", original.IdempotencyKey)
+
+	// Other keys are independent
+	other := newTestJob(models.JobPriorityNormal)
+	original, err = store.Reserve(ctx, "verify:43", other, time.Hour)
+	require.NoError(t, err)
+	assert.Nil(t, original)
+
+	// Only the holder can release a key
+	require.NoError(t, store.Release(ctx, "verify:43", retry.ID.String()))
+	original, err = store.Reserve(ctx, "verify:43", retry, time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, original)
+	assert.Equal(t, other.ID, original.ID)
+
+	require.NoError(t, store.Release(ctx, "verify:43", other.ID.String()))
+	original, err = store.Reserve(ctx, "verify:43", retry, time.Hour)
+	require.NoError(t, err)
+	assert.Nil(t, original)
+
+	// Once the window has passed the key can be used again
+	expire()
+	_, err = store.DeleteExpired(ctx)
+	require.NoError(t, err)
+	original, err = store.Reserve(ctx, "verify:42", retry, time.Hour)
+	require.NoError(t, err)
+	assert.Nil(t, original)
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	testIdempotencyStore(t, queue.NewMemoryIdempotencyStore(), func() {
+		time.Sleep(250 * time.Millisecond)
+	})
+}
+
+func TestRedisIdempotencyStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := queue.NewRedisIdempotencyStore(mr.Addr(), "", 0, "email-jobs", zap.NewNop())
+	t.Cleanup(func() { store.Close() })
+
+	testIdempotencyStore(t, store, func() {
+		mr.FastForward(250 * time.Millisecond)
+	})
+}
+
+func TestPostgresIdempotencyStore(t *testing.T) {
+	db, _ := newTestPostgresDB(t, "email_idempotency_keys")
+	testIdempotencyStore(t, queue.NewPostgresIdempotencyStore(db, zap.NewNop()), func() {
+		time.Sleep(250 * time.Millisecond)
+	})
+}
+
+func TestQueueFactory_CreateIdempotencyStore(t *testing.T) {
+	factory := queue.NewQueueFactory(zap.NewNop())
+
+	store, err := factory.CreateIdempotencyStore(queue.QueueConfig{Type: "memory"})
+	require.NoError(t, err)
+	assert.IsType(t, &queue.MemoryIdempotencyStore{}, store)
+
+	_, err = factory.CreateIdempotencyStore(queue.QueueConfig{Type: "postgres"})
+	assert.Error(t, err)
+}
@@ -2,7 +2,9 @@ package queue
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -10,20 +12,54 @@ import (
 	"booking-system/email-worker/models"
 )
 
-// Queue defines the interface for email job queues
+// Queue defines the interface for email job queues.
+//
+// Consumption is lease based: a consumed job carries a receipt (see
+// models.EmailJob.Receipt) and stays invisible to other consumers until the
+// receipt is settled with Ack or Nack. A job whose lease expires is delivered
+// again, so delivery is at-least-once.
 type Queue interface {
 	// Publish adds an email job to the queue
 	Publish(ctx context.Context, job *models.EmailJob) error
 	
-	// Consume retrieves and removes the next job from the queue
+	// Consume leases the next job from the queue
 	Consume(ctx context.Context) (*models.EmailJob, error)
 	
-	// ConsumeBatch retrieves multiple jobs from the queue
+	// Subscribe returns a channel that receives a signal whenever jobs may
+	// have become ready, so consumers can wait instead of polling. Signals are
+	// coalesced and may be spurious, a consumer should consume until the queue
+	// is empty after each one. The channel is closed once ctx is done. Queues
+	// that can only be polled return ErrNotSupported.
+	Subscribe(ctx context.Context) (<-chan struct{}, error)
+	
+	// ConsumeBatch leases multiple jobs from the queue
 	ConsumeBatch(ctx context.Context, batchSize int) ([]*models.EmailJob, error)
 	
+	// ConsumeLane leases up to batchSize jobs of a single priority without
+	// waiting for new ones. Queues without priority lanes return ErrNotSupported.
+	ConsumeLane(ctx context.Context, priority models.JobPriority, batchSize int) ([]*models.EmailJob, error)
+	
+	// PromoteAged moves jobs that have been waiting for at least agingInterval
+	// up one priority, but not above ceiling. It returns how many jobs moved.
+	// Queues without priority lanes return ErrNotSupported.
+	PromoteAged(ctx context.Context, agingInterval time.Duration, ceiling models.JobPriority) (int, error)
+	
+	// Ack removes a leased job from the queue once it has been handled
+	Ack(ctx context.Context, receipt string) error
+	
+	// Nack releases a leased job, it becomes available again after requeueAfter
+	Nack(ctx context.Context, receipt string, requeueAfter time.Duration) error
+	
+	// ExtendLease keeps a leased job invisible for another extension from now
+	ExtendLease(ctx context.Context, receipt string, extension time.Duration) error
+	
 	// Size returns the current queue size
 	Size(ctx context.Context) (int64, error)
 	
+	// OldestAge returns how long the oldest job ready for delivery has been
+	// waiting, zero when no job is waiting
+	OldestAge(ctx context.Context) (time.Duration, error)
+	
 	// Clear removes all jobs from the queue
 	Clear(ctx context.Context) error
 	
@@ -36,20 +72,51 @@ type Queue interface {
 	// PublishScheduled publishes a job for scheduled delivery
 	PublishScheduled(ctx context.Context, job *models.EmailJob, scheduledAt time.Time) error
 	
-	// ProcessScheduledJobs moves ready scheduled jobs to the main queue
+	// ProcessScheduledJobs moves ready scheduled jobs to the main queue and
+	// makes jobs with an expired lease available again
 	ProcessScheduledJobs(ctx context.Context) error
+	
+	// Cancel removes a pending or scheduled job from the queue. A leased job
+	// stays with its worker but is reported by Cancelled, so the worker drops
+	// it instead of sending it. Returns ErrJobNotFound if the job is not queued.
+	Cancel(ctx context.Context, jobID string) error
+	
+	// Cancelled reports whether a leased job was cancelled
+	Cancelled(ctx context.Context, jobID string) (bool, error)
+	
+	// Reschedule moves a pending or scheduled job to scheduledAt. Returns
+	// ErrJobNotFound if the job is not queued and ErrJobLeased if a worker holds it.
+	Reschedule(ctx context.Context, jobID string, scheduledAt time.Time) error
+}
+
+// JobStoringQueue is implemented by queues that keep their jobs in the
+// email_jobs table, where publishing a job already stores it
+type JobStoringQueue interface {
+	Queue
+
+	// StoresJobs reports whether published jobs are stored in email_jobs
+	StoresJobs() bool
 }
 
 // QueueConfig holds configuration for queue implementations
 type QueueConfig struct {
-	Type         string `mapstructure:"type"`          // redis, kafka, etc.
-	Host         string `mapstructure:"host"`
-	Port         int    `mapstructure:"port"`
-	Password     string `mapstructure:"password"`
-	Database     int    `mapstructure:"database"`
-	QueueName    string `mapstructure:"queue_name"`
-	BatchSize    int    `mapstructure:"batch_size"`
-	PollInterval string `mapstructure:"poll_interval"`
+	Type              string `mapstructure:"type"`          // redis, kafka, postgres, memory
+	Host              string `mapstructure:"host"`
+	Port              int    `mapstructure:"port"`
+	Password          string `mapstructure:"password"`
+	Database          int    `mapstructure:"database"`
+	QueueName         string `mapstructure:"queue_name"`
+	BatchSize         int    `mapstructure:"batch_size"`
+	PollInterval      string `mapstructure:"poll_interval"`
+	VisibilityTimeout string `mapstructure:"visibility_timeout"`
+
+	// Kafka
+	Brokers []string `mapstructure:"brokers"`
+	GroupID string   `mapstructure:"group_id"`
+
+	// Postgres, the queue shares the service database
+	DB  *sql.DB `mapstructure:"-"`
+	DSN string  `mapstructure:"-"`
 }
 
 // QueueFactory creates queue instances based on configuration
@@ -68,158 +135,87 @@ func NewQueueFactory(logger *zap.Logger) *QueueFactory {
 func (f *QueueFactory) CreateQueue(config QueueConfig) (Queue, error) {
 	switch config.Type {
 	case "redis":
+		visibilityTimeout, err := parseDuration(config.VisibilityTimeout, defaultVisibilityTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid visibility timeout: %w", err)
+		}
 		addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
-		return NewRedisQueue(addr, config.Password, config.Database, config.QueueName, f.logger), nil
+		return NewRedisQueue(addr, config.Password, config.Database, config.QueueName, visibilityTimeout, f.logger), nil
 	case "kafka":
-		// TODO: Implement Kafka queue
-		return nil, fmt.Errorf("kafka queue not implemented yet")
+		if len(config.Brokers) == 0 {
+			return nil, fmt.Errorf("kafka queue requires at least one broker")
+		}
+		fetchWait, err := parseDuration(config.PollInterval, defaultKafkaFetchWait)
+		if err != nil {
+			return nil, fmt.Errorf("invalid poll interval: %w", err)
+		}
+		return NewKafkaQueue(config.Brokers, config.QueueName, config.GroupID, fetchWait, f.logger), nil
+	case "memory":
+		visibilityTimeout, err := parseDuration(config.VisibilityTimeout, defaultVisibilityTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid visibility timeout: %w", err)
+		}
+		return NewMemoryQueue(visibilityTimeout, f.logger), nil
+	case "postgres":
+		if config.DB == nil || config.DSN == "" {
+			return nil, fmt.Errorf("postgres queue requires a database connection")
+		}
+		visibilityTimeout, err := parseDuration(config.VisibilityTimeout, defaultVisibilityTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid visibility timeout: %w", err)
+		}
+		fetchWait, err := parseDuration(config.PollInterval, defaultPostgresFetchWait)
+		if err != nil {
+			return nil, fmt.Errorf("invalid poll interval: %w", err)
+		}
+		return NewPostgresQueue(config.DB, config.DSN, visibilityTimeout, fetchWait, f.logger)
 	default:
 		return nil, fmt.Errorf("unsupported queue type: %s", config.Type)
 	}
 }
 
-// RedisQueue implements the Queue interface for Redis
-type RedisQueue struct {
-	addr       string
-	password   string
-	database   int
-	queueName  string
-	logger     *zap.Logger
-}
-
-// NewRedisQueue creates a new RedisQueue instance
-func NewRedisQueue(addr, password string, database int, queueName string, logger *zap.Logger) *RedisQueue {
-	return &RedisQueue{
-		addr:     addr,
-		password: password,
-		database: database,
-		queueName: queueName,
-		logger:   logger,
-	}
-}
-
-// Publish adds an email job to the queue
-func (q *RedisQueue) Publish(ctx context.Context, job *models.EmailJob) error {
-	// Implementation of Publish method
-	return nil
-}
-
-// Consume retrieves and removes the next job from the queue
-func (q *RedisQueue) Consume(ctx context.Context) (*models.EmailJob, error) {
-	// Implementation of Consume method
-	return nil, nil
-}
-
-// ConsumeBatch retrieves multiple jobs from the queue
-func (q *RedisQueue) ConsumeBatch(ctx context.Context, batchSize int) ([]*models.EmailJob, error) {
-	// Implementation of ConsumeBatch method
-	return nil, nil
-}
+// receiptSeparator separates the queue ID from the lease token in a receipt
+const receiptSeparator = ":"
 
-// Size returns the current queue size
-func (q *RedisQueue) Size(ctx context.Context) (int64, error) {
-	// Implementation of Size method
-	return 0, nil
+// newReceipt builds the receipt of a lease from the queue ID and lease token
+func newReceipt(queueID, token string) string {
+	return queueID + receiptSeparator + token
 }
 
-// Clear removes all jobs from the queue
-func (q *RedisQueue) Clear(ctx context.Context) error {
-	// Implementation of Clear method
-	return nil
-}
-
-// Health checks if the queue is healthy
-func (q *RedisQueue) Health(ctx context.Context) error {
-	// Implementation of Health method
-	return nil
-}
-
-// Close closes the queue connection
-func (q *RedisQueue) Close() error {
-	// Implementation of Close method
-	return nil
-}
-
-// PublishScheduled publishes a job for scheduled delivery
-func (q *RedisQueue) PublishScheduled(ctx context.Context, job *models.EmailJob, scheduledAt time.Time) error {
-	// Implementation of PublishScheduled method
-	return nil
-}
-
-// ProcessScheduledJobs moves ready scheduled jobs to the main queue
-func (q *RedisQueue) ProcessScheduledJobs(ctx context.Context) error {
-	// Implementation of ProcessScheduledJobs method
-	return nil
-}
-
-// KafkaQueue implements the Queue interface for Kafka
-type KafkaQueue struct {
-	// Kafka-specific fields
-}
-
-// NewKafkaQueue creates a new KafkaQueue instance
-func NewKafkaQueue(brokers []string, topic string, logger *zap.Logger) *KafkaQueue {
-	// Kafka-specific initialization
-	return &KafkaQueue{}
-}
-
-// Publish adds an email job to the queue
-func (q *KafkaQueue) Publish(ctx context.Context, job *models.EmailJob) error {
-	// Implementation of Publish method
-	return nil
-}
-
-// Consume retrieves and removes the next job from the queue
-func (q *KafkaQueue) Consume(ctx context.Context) (*models.EmailJob, error) {
-	// Implementation of Consume method
-	return nil, nil
-}
-
-// ConsumeBatch retrieves multiple jobs from the queue
-func (q *KafkaQueue) ConsumeBatch(ctx context.Context, batchSize int) ([]*models.EmailJob, error) {
-	// Implementation of ConsumeBatch method
-	return nil, nil
-}
-
-// Size returns the current queue size
-func (q *KafkaQueue) Size(ctx context.Context) (int64, error) {
-	// Implementation of Size method
-	return 0, nil
-}
-
-// Clear removes all jobs from the queue
-func (q *KafkaQueue) Clear(ctx context.Context) error {
-	// Implementation of Clear method
-	return nil
-}
-
-// Health checks if the queue is healthy
-func (q *KafkaQueue) Health(ctx context.Context) error {
-	// Implementation of Health method
-	return nil
-}
-
-// Close closes the queue connection
-func (q *KafkaQueue) Close() error {
-	// Implementation of Close method
-	return nil
+// parseReceipt splits a receipt into its queue ID and lease token
+func parseReceipt(receipt string) (queueID, token string, err error) {
+	queueID, token, ok := strings.Cut(receipt, receiptSeparator)
+	if !ok || queueID == "" || token == "" {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidReceipt, receipt)
+	}
+	return queueID, token, nil
 }
 
-// PublishScheduled publishes a job for scheduled delivery
-func (q *KafkaQueue) PublishScheduled(ctx context.Context, job *models.EmailJob, scheduledAt time.Time) error {
-	// Implementation of PublishScheduled method
-	return nil
+// notify sends a coalesced signal to a subscriber without blocking
+func notify(signals chan<- struct{}) {
+	select {
+	case signals <- struct{}{}:
+	default:
+	}
 }
 
-// ProcessScheduledJobs moves ready scheduled jobs to the main queue
-func (q *KafkaQueue) ProcessScheduledJobs(ctx context.Context) error {
-	// Implementation of ProcessScheduledJobs method
-	return nil
+// parseDuration parses an optional duration string, falling back to the given default
+func parseDuration(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return time.ParseDuration(value)
 }
 
 // Queue errors
 var (
-	ErrQueueEmpty = fmt.Errorf("queue is empty")
+	ErrQueueEmpty     = fmt.Errorf("queue is empty")
+	ErrQueueClosed    = fmt.Errorf("queue is closed")
+	ErrNotSupported   = fmt.Errorf("operation not supported by queue")
+	ErrInvalidReceipt = fmt.Errorf("invalid receipt")
+	ErrLeaseLost      = fmt.Errorf("lease expired or already settled")
+	ErrJobNotFound    = fmt.Errorf("job not found in queue")
+	ErrJobLeased      = fmt.Errorf("job is leased by a worker")
 )
 
  
\ No newline at end of file
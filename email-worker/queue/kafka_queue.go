@@ -0,0 +1,582 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+)
+
+const (
+	// kafkaDelayTopicSuffix is appended to the job topic to name the delay topic
+	kafkaDelayTopicSuffix = ".delay"
+
+	// kafkaScheduledAtHeader carries the delivery time of messages on the delay topic
+	kafkaScheduledAtHeader = "scheduled-at"
+
+	// kafkaDrainWait is how long a batch waits for further buffered messages
+	// once the first one has arrived
+	kafkaDrainWait = 10 * time.Millisecond
+
+	defaultKafkaFetchWait = time.Second
+)
+
+// KafkaQueue implements the Queue interface for Kafka.
+//
+// Jobs are consumed through a consumer group and the offset of a message is
+// only committed once the job has been settled, so a worker crash leads to
+// redelivery rather than a lost email. Kafka has no per-message leases: a
+// delivery stays outstanding until it is settled or the consumer group
+// rebalances, which is why ExtendLease only validates the receipt. Receipts
+// carry the group generation they were fetched in, so deliveries of an
+// earlier generation can no longer be settled. Messages are keyed by the
+// first recipient, which keeps all mail for one recipient on the same
+// partition and therefore in order. Kafka has no notion of priority, so jobs
+// are delivered in partition order regardless of their priority.
+type KafkaQueue struct {
+	writer      kafkaWriter
+	reader      kafkaReader
+	delayReader kafkaReader
+	brokers     []string
+	topic       string
+	delayTopic  string
+	fetchWait   time.Duration
+	logger      *zap.Logger
+
+	mu         sync.Mutex
+	generation int
+	partitions map[int]*partitionLog
+
+	// scheduleMu serializes ProcessScheduledJobs runs, heldDelay is the delay
+	// topic message a run stopped at because it was not due yet
+	scheduleMu sync.Mutex
+	heldDelay  *kafka.Message
+}
+
+// kafkaReader is the part of a consumer group *kafka.Reader the queue uses
+type kafkaReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, messages ...kafka.Message) error
+	Stats() kafka.ReaderStats
+	Close() error
+}
+
+// kafkaWriter is the part of a *kafka.Writer the queue uses
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafka.Message) error
+	Close() error
+}
+
+// partitionLog tracks fetched messages of one partition in offset order, so
+// that offsets are only committed once every earlier message was acknowledged
+type partitionLog struct {
+	messages []kafka.Message
+	acked    map[int64]bool
+}
+
+// NewKafkaQueue creates a new KafkaQueue instance
+func NewKafkaQueue(brokers []string, topic, groupID string, fetchWait time.Duration, logger *zap.Logger) *KafkaQueue {
+	if fetchWait <= 0 {
+		fetchWait = defaultKafkaFetchWait
+	}
+
+	delayTopic := topic + kafkaDelayTopicSuffix
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		GroupID:     groupID,
+		Topic:       topic,
+		StartOffset: kafka.FirstOffset,
+	})
+
+	delayReader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		GroupID:     groupID + kafkaDelayTopicSuffix,
+		Topic:       delayTopic,
+		StartOffset: kafka.FirstOffset,
+	})
+
+	return newKafkaQueue(reader, delayReader, writer, brokers, topic, fetchWait, logger)
+}
+
+// newKafkaQueue creates a KafkaQueue over a reader of the job topic, a reader
+// of the delay topic and a writer
+func newKafkaQueue(reader, delayReader kafkaReader, writer kafkaWriter, brokers []string, topic string, fetchWait time.Duration, logger *zap.Logger) *KafkaQueue {
+	return &KafkaQueue{
+		writer:      writer,
+		reader:      reader,
+		delayReader: delayReader,
+		brokers:     brokers,
+		topic:       topic,
+		delayTopic:  topic + kafkaDelayTopicSuffix,
+		fetchWait:   fetchWait,
+		logger:      logger,
+		partitions:  make(map[int]*partitionLog),
+	}
+}
+
+// Publish adds an email job to the queue
+func (q *KafkaQueue) Publish(ctx context.Context, job *models.EmailJob) error {
+	message, err := q.newMessage(q.topic, job)
+	if err != nil {
+		return err
+	}
+
+	if err := q.writer.WriteMessages(ctx, message); err != nil {
+		return fmt.Errorf("failed to publish job: %w", err)
+	}
+
+	return nil
+}
+
+// Consume retrieves the next job from the queue
+func (q *KafkaQueue) Consume(ctx context.Context) (*models.EmailJob, error) {
+	jobs, err := q.ConsumeBatch(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, ErrQueueEmpty
+	}
+	return jobs[0], nil
+}
+
+// ConsumeBatch retrieves multiple jobs from the queue. The offsets of the
+// returned jobs stay uncommitted until they are settled with Ack or Nack.
+func (q *KafkaQueue) ConsumeBatch(ctx context.Context, batchSize int) ([]*models.EmailJob, error) {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	jobs := make([]*models.EmailJob, 0, batchSize)
+	wait := q.fetchWait
+
+	for len(jobs) < batchSize {
+		fetchCtx, cancel := context.WithTimeout(ctx, wait)
+		message, err := q.reader.FetchMessage(fetchCtx)
+		cancel()
+
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				break
+			}
+			if len(jobs) > 0 {
+				break
+			}
+			return nil, fmt.Errorf("failed to fetch message: %w", err)
+		}
+
+		queueID, receipt := q.track(message)
+
+		var job models.EmailJob
+		if err := json.Unmarshal(message.Value, &job); err != nil {
+			q.logger.Error("Dropping undecodable job payload",
+				zap.String("queue_id", queueID),
+				zap.Error(err))
+			q.Ack(ctx, receipt)
+			continue
+		}
+
+		job.SetQueueID(queueID)
+		job.SetReceipt(receipt)
+		jobs = append(jobs, &job)
+
+		// Only wait for the first message, then drain what is already buffered
+		wait = kafkaDrainWait
+	}
+
+	return jobs, nil
+}
+
+// ConsumeLane is not supported, a partition is consumed strictly in offset order
+func (q *KafkaQueue) ConsumeLane(ctx context.Context, priority models.JobPriority, batchSize int) ([]*models.EmailJob, error) {
+	return nil, fmt.Errorf("kafka queue: %w", ErrNotSupported)
+}
+
+// PromoteAged is not supported, messages cannot be reordered once written
+func (q *KafkaQueue) PromoteAged(ctx context.Context, agingInterval time.Duration, ceiling models.JobPriority) (int, error) {
+	return 0, fmt.Errorf("kafka queue: %w", ErrNotSupported)
+}
+
+// Cancel is not supported, Kafka records cannot be removed from a topic
+func (q *KafkaQueue) Cancel(ctx context.Context, jobID string) error {
+	return fmt.Errorf("kafka queue: %w", ErrNotSupported)
+}
+
+// Cancelled is not supported, jobs on Kafka cannot be cancelled
+func (q *KafkaQueue) Cancelled(ctx context.Context, jobID string) (bool, error) {
+	return false, fmt.Errorf("kafka queue: %w", ErrNotSupported)
+}
+
+// Reschedule is not supported, Kafka records cannot be moved
+func (q *KafkaQueue) Reschedule(ctx context.Context, jobID string, scheduledAt time.Time) error {
+	return fmt.Errorf("kafka queue: %w", ErrNotSupported)
+}
+
+// Ack marks a job as handled and commits every offset of its partition that
+// is no longer preceded by an unacknowledged message
+func (q *KafkaQueue) Ack(ctx context.Context, receipt string) error {
+	generation, partition, offset, err := parseKafkaReceipt(receipt)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	log := q.outstanding(generation, partition, offset)
+	if log == nil {
+		q.mu.Unlock()
+		return fmt.Errorf("failed to ack job %s: %w", receipt, ErrLeaseLost)
+	}
+
+	log.acked[offset] = true
+
+	var commit *kafka.Message
+	for len(log.messages) > 0 && log.acked[log.messages[0].Offset] {
+		head := log.messages[0]
+		delete(log.acked, head.Offset)
+		log.messages = log.messages[1:]
+		commit = &head
+	}
+	q.mu.Unlock()
+
+	if commit == nil {
+		return nil
+	}
+
+	if err := q.reader.CommitMessages(ctx, *commit); err != nil {
+		return fmt.Errorf("failed to commit offset %d on partition %d: %w", commit.Offset, commit.Partition, err)
+	}
+
+	return nil
+}
+
+// Nack hands a job back to the queue. Kafka cannot un-read a message, so a
+// copy is appended to the job topic (or the delay topic when requeueAfter is
+// positive) before the original offset is acknowledged.
+func (q *KafkaQueue) Nack(ctx context.Context, receipt string, requeueAfter time.Duration) error {
+	generation, partition, offset, err := parseKafkaReceipt(receipt)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	var original *kafka.Message
+	if log := q.outstanding(generation, partition, offset); log != nil {
+		original = log.find(offset)
+	}
+	q.mu.Unlock()
+
+	if original == nil {
+		return fmt.Errorf("failed to nack job %s: %w", receipt, ErrLeaseLost)
+	}
+
+	requeued := kafka.Message{Topic: q.topic, Key: original.Key, Value: original.Value}
+	if requeueAfter > 0 {
+		requeued.Topic = q.delayTopic
+		requeued.Headers = []kafka.Header{{
+			Key:   kafkaScheduledAtHeader,
+			Value: []byte(time.Now().Add(requeueAfter).UTC().Format(time.RFC3339Nano)),
+		}}
+	}
+
+	if err := q.writer.WriteMessages(ctx, requeued); err != nil {
+		return fmt.Errorf("failed to requeue job %s: %w", receipt, err)
+	}
+
+	return q.Ack(ctx, receipt)
+}
+
+// ExtendLease only checks that the delivery is still outstanding, Kafka
+// deliveries do not expire while the consumer keeps its partitions
+func (q *KafkaQueue) ExtendLease(ctx context.Context, receipt string, extension time.Duration) error {
+	generation, partition, offset, err := parseKafkaReceipt(receipt)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.outstanding(generation, partition, offset) == nil {
+		return fmt.Errorf("failed to extend lease of job %s: %w", receipt, ErrLeaseLost)
+	}
+	return nil
+}
+
+// Size returns the consumer group lag on the job topic
+func (q *KafkaQueue) Size(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.readerStats().Lag, nil
+}
+
+// OldestAge is not supported, the consumer only sees the lag in messages
+func (q *KafkaQueue) OldestAge(ctx context.Context) (time.Duration, error) {
+	return 0, fmt.Errorf("kafka queue: %w", ErrNotSupported)
+}
+
+// Subscribe is not supported, ConsumeBatch already waits on the broker for
+// new messages
+func (q *KafkaQueue) Subscribe(ctx context.Context) (<-chan struct{}, error) {
+	return nil, fmt.Errorf("kafka queue: %w", ErrNotSupported)
+}
+
+// Clear is not supported, Kafka topics cannot be truncated by a consumer
+func (q *KafkaQueue) Clear(ctx context.Context) error {
+	return fmt.Errorf("kafka queue: %w", ErrNotSupported)
+}
+
+// Health checks if the queue is healthy
+func (q *KafkaQueue) Health(ctx context.Context) error {
+	var lastErr error
+	for _, broker := range q.brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		_, err = conn.ReadPartitions(q.topic)
+		conn.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("kafka health check failed: %w", lastErr)
+}
+
+// Close closes the queue connection
+func (q *KafkaQueue) Close() error {
+	if err := q.reader.Close(); err != nil {
+		return fmt.Errorf("failed to close kafka reader: %w", err)
+	}
+	if err := q.delayReader.Close(); err != nil {
+		return fmt.Errorf("failed to close kafka delay reader: %w", err)
+	}
+	if err := q.writer.Close(); err != nil {
+		return fmt.Errorf("failed to close kafka writer: %w", err)
+	}
+	return nil
+}
+
+// PublishScheduled publishes a job to the delay topic for scheduled delivery
+func (q *KafkaQueue) PublishScheduled(ctx context.Context, job *models.EmailJob, scheduledAt time.Time) error {
+	job.SetScheduledAt(scheduledAt)
+	if !scheduledAt.After(time.Now()) {
+		return q.Publish(ctx, job)
+	}
+
+	message, err := q.newMessage(q.delayTopic, job)
+	if err != nil {
+		return err
+	}
+	message.Headers = append(message.Headers, kafka.Header{
+		Key:   kafkaScheduledAtHeader,
+		Value: []byte(scheduledAt.UTC().Format(time.RFC3339Nano)),
+	})
+
+	if err := q.writer.WriteMessages(ctx, message); err != nil {
+		return fmt.Errorf("failed to publish scheduled job: %w", err)
+	}
+
+	return nil
+}
+
+// ProcessScheduledJobs drains the delay topic. Due jobs are forwarded to the
+// job topic in order. A consumer cannot skip a message without committing past
+// it, so the run stops at the first job that is not due yet and holds it until
+// it is: jobs behind a later one wait for it, but nothing is rewritten to the
+// delay topic and no offset past the held job is committed.
+func (q *KafkaQueue) ProcessScheduledJobs(ctx context.Context) error {
+	q.scheduleMu.Lock()
+	defer q.scheduleMu.Unlock()
+
+	// After a rebalance the held job is fetched again from its committed
+	// offset by whichever consumer owns its partition now
+	if q.delayReader.Stats().Rebalances > 0 {
+		q.heldDelay = nil
+	}
+
+	var forward []kafka.Message
+	commits := make(map[int]kafka.Message)
+
+	now := time.Now()
+	wait := q.fetchWait
+
+	for len(forward) < maxMovesPerRun {
+		message := q.heldDelay
+		q.heldDelay = nil
+
+		if message == nil {
+			fetchCtx, cancel := context.WithTimeout(ctx, wait)
+			fetched, err := q.delayReader.FetchMessage(fetchCtx)
+			cancel()
+
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+					break
+				}
+				return fmt.Errorf("failed to fetch scheduled job: %w", err)
+			}
+			message = &fetched
+			wait = kafkaDrainWait
+		}
+
+		scheduledAt, err := scheduledAtHeader(*message)
+		if err != nil {
+			q.logger.Error("Forwarding scheduled job without valid schedule header", zap.Error(err))
+		}
+		if err == nil && scheduledAt.After(now) {
+			q.heldDelay = message
+			break
+		}
+
+		forward = append(forward, kafka.Message{Topic: q.topic, Key: message.Key, Value: message.Value, Headers: message.Headers})
+		commits[message.Partition] = *message
+	}
+
+	if len(forward) == 0 {
+		return nil
+	}
+
+	if err := q.writer.WriteMessages(ctx, forward...); err != nil {
+		return fmt.Errorf("failed to move scheduled jobs: %w", err)
+	}
+
+	committed := make([]kafka.Message, 0, len(commits))
+	for _, message := range commits {
+		committed = append(committed, message)
+	}
+	if err := q.delayReader.CommitMessages(ctx, committed...); err != nil {
+		return fmt.Errorf("failed to commit scheduled jobs: %w", err)
+	}
+
+	q.logger.Info("Moved scheduled jobs to queue", zap.Int("count", len(forward)))
+
+	return nil
+}
+
+// newMessage encodes a job as a Kafka message keyed by its first recipient
+func (q *KafkaQueue) newMessage(topic string, job *models.EmailJob) (kafka.Message, error) {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	return kafka.Message{
+		Topic: topic,
+		Key:   []byte(partitionKey(job)),
+		Value: payload,
+	}, nil
+}
+
+// track registers a fetched message for ordered commits and returns its
+// queue ID and receipt
+func (q *KafkaQueue) track(message kafka.Message) (string, string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.readerStats()
+	log, ok := q.partitions[message.Partition]
+	if !ok {
+		log = &partitionLog{acked: make(map[int64]bool)}
+		q.partitions[message.Partition] = log
+	}
+	log.messages = append(log.messages, message)
+
+	queueID := fmt.Sprintf("%d:%d", message.Partition, message.Offset)
+	return queueID, fmt.Sprintf("%d:%s", q.generation, queueID)
+}
+
+// readerStats returns the stats of the job topic reader. The reader counts
+// the generations it joined since the last call, and on a new one the
+// outstanding deliveries are forgotten: their partitions may belong to
+// another consumer now, which redelivers them from the committed offset.
+// Must be called with q.mu held.
+func (q *KafkaQueue) readerStats() kafka.ReaderStats {
+	stats := q.reader.Stats()
+	if stats.Rebalances > 0 {
+		q.generation++
+		q.partitions = make(map[int]*partitionLog)
+	}
+	return stats
+}
+
+// outstanding returns the log of partition if the delivery at offset of
+// generation is still outstanding, or nil. Must be called with q.mu held.
+func (q *KafkaQueue) outstanding(generation, partition int, offset int64) *partitionLog {
+	q.readerStats()
+	if generation != q.generation {
+		return nil
+	}
+	log, ok := q.partitions[partition]
+	if !ok || !log.holds(offset) {
+		return nil
+	}
+	return log
+}
+
+// find returns the outstanding message at offset, or nil
+func (l *partitionLog) find(offset int64) *kafka.Message {
+	if l.acked[offset] {
+		return nil
+	}
+	for i := range l.messages {
+		if l.messages[i].Offset == offset {
+			message := l.messages[i]
+			return &message
+		}
+	}
+	return nil
+}
+
+// holds reports whether the message at offset is still outstanding
+func (l *partitionLog) holds(offset int64) bool {
+	return l.find(offset) != nil
+}
+
+// parseKafkaReceipt splits a "generation:partition:offset" receipt
+func parseKafkaReceipt(receipt string) (int, int, int64, error) {
+	var generation, partition int
+	var offset int64
+	if _, err := fmt.Sscanf(receipt, "%d:%d:%d", &generation, &partition, &offset); err != nil {
+		return 0, 0, 0, fmt.Errorf("%w: %q", ErrInvalidReceipt, receipt)
+	}
+	return generation, partition, offset, nil
+}
+
+// partitionKey returns the key that keeps one recipient's mail on one partition
+func partitionKey(job *models.EmailJob) string {
+	if len(job.To) == 0 {
+		return job.ID.String()
+	}
+	return strings.ToLower(strings.TrimSpace(job.To[0]))
+}
+
+// scheduledAtHeader reads the delivery time of a delay topic message
+func scheduledAtHeader(message kafka.Message) (time.Time, error) {
+	for _, header := range message.Headers {
+		if header.Key == kafkaScheduledAtHeader {
+			return time.Parse(time.RFC3339Nano, string(header.Value))
+		}
+	}
+	return time.Time{}, fmt.Errorf("missing %s header", kafkaScheduledAtHeader)
+}
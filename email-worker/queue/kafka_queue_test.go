@@ -0,0 +1,266 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+)
+
+// fakeKafkaReader hands out pushed messages and records the committed offset
+// of each partition
+type fakeKafkaReader struct {
+	mu         sync.Mutex
+	messages   []kafka.Message
+	committed  map[int]int64
+	rebalances int64
+}
+
+func newFakeKafkaReader() *fakeKafkaReader {
+	return &fakeKafkaReader{committed: make(map[int]int64)}
+}
+
+// push adds a message holding job at offset of partition
+func (r *fakeKafkaReader) push(t *testing.T, partition int, offset int64, job *models.EmailJob, headers ...kafka.Header) {
+	t.Helper()
+	payload, err := json.Marshal(job)
+	require.NoError(t, err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, kafka.Message{
+		Partition: partition,
+		Offset:    offset,
+		Key:       []byte(partitionKey(job)),
+		Value:     payload,
+		Headers:   headers,
+	})
+}
+
+func (r *fakeKafkaReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	r.mu.Lock()
+	if len(r.messages) > 0 {
+		message := r.messages[0]
+		r.messages = r.messages[1:]
+		r.mu.Unlock()
+		return message, nil
+	}
+	r.mu.Unlock()
+
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *fakeKafkaReader) CommitMessages(ctx context.Context, messages ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, message := range messages {
+		if committed, ok := r.committed[message.Partition]; !ok || message.Offset > committed {
+			r.committed[message.Partition] = message.Offset
+		}
+	}
+	return nil
+}
+
+// commit returns the committed offset of partition, or -1
+func (r *fakeKafkaReader) commit(partition int) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if offset, ok := r.committed[partition]; ok {
+		return offset
+	}
+	return -1
+}
+
+func (r *fakeKafkaReader) Stats() kafka.ReaderStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := kafka.ReaderStats{Rebalances: r.rebalances}
+	r.rebalances = 0
+	return stats
+}
+
+func (r *fakeKafkaReader) Close() error { return nil }
+
+// fakeKafkaWriter records the messages written
+type fakeKafkaWriter struct {
+	mu       sync.Mutex
+	messages []kafka.Message
+}
+
+func (w *fakeKafkaWriter) WriteMessages(ctx context.Context, messages ...kafka.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, messages...)
+	return nil
+}
+
+func (w *fakeKafkaWriter) written() []kafka.Message {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]kafka.Message(nil), w.messages...)
+}
+
+func (w *fakeKafkaWriter) Close() error { return nil }
+
+func newTestKafkaQueue() (*KafkaQueue, *fakeKafkaReader, *fakeKafkaReader, *fakeKafkaWriter) {
+	reader, delayReader, writer := newFakeKafkaReader(), newFakeKafkaReader(), &fakeKafkaWriter{}
+	q := newKafkaQueue(reader, delayReader, writer, nil, "email-jobs", 10*time.Millisecond, zap.NewNop())
+	return q, reader, delayReader, writer
+}
+
+func newKafkaTestJob(to string) *models.EmailJob {
+	return models.NewEmailJob([]string{to}, nil, nil, "email_verification", nil, models.JobPriorityNormal)
+}
+
+func TestKafkaQueue_CommitsPartitionsInOrder(t *testing.T) {
+	q, reader, _, _ := newTestKafkaQueue()
+	ctx := context.Background()
+	for offset := int64(0); offset < 3; offset++ {
+		reader.push(t, 0, offset, newKafkaTestJob("a@example.com"))
+	}
+	reader.push(t, 1, 0, newKafkaTestJob("b@example.com"))
+
+	jobs, err := q.ConsumeBatch(ctx, 4)
+	require.NoError(t, err)
+	require.Len(t, jobs, 4)
+
+	// An offset is only committed once every earlier one of its partition is
+	require.NoError(t, q.Ack(ctx, jobs[1].Receipt))
+	assert.Equal(t, int64(-1), reader.commit(0))
+	require.NoError(t, q.Ack(ctx, jobs[3].Receipt))
+	assert.Equal(t, int64(0), reader.commit(1), "partitions are committed independently")
+	require.NoError(t, q.Ack(ctx, jobs[0].Receipt))
+	assert.Equal(t, int64(1), reader.commit(0))
+	require.NoError(t, q.Ack(ctx, jobs[2].Receipt))
+	assert.Equal(t, int64(2), reader.commit(0))
+}
+
+func TestKafkaQueue_SettlesReceiptsOnce(t *testing.T) {
+	q, reader, _, _ := newTestKafkaQueue()
+	ctx := context.Background()
+	reader.push(t, 0, 0, newKafkaTestJob("a@example.com"))
+	reader.push(t, 0, 1, newKafkaTestJob("a@example.com"))
+
+	jobs, err := q.ConsumeBatch(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	require.NoError(t, q.ExtendLease(ctx, jobs[0].Receipt, time.Minute))
+
+	require.NoError(t, q.Ack(ctx, jobs[0].Receipt))
+	assert.True(t, errors.Is(q.Ack(ctx, jobs[0].Receipt), ErrLeaseLost))
+	assert.True(t, errors.Is(q.Nack(ctx, jobs[0].Receipt, 0), ErrLeaseLost))
+	assert.True(t, errors.Is(q.ExtendLease(ctx, jobs[0].Receipt, time.Minute), ErrLeaseLost))
+	assert.True(t, errors.Is(q.Ack(ctx, "not-a-receipt"), ErrInvalidReceipt))
+
+	require.NoError(t, q.Nack(ctx, jobs[1].Receipt, 0))
+	assert.True(t, errors.Is(q.Ack(ctx, jobs[1].Receipt), ErrLeaseLost))
+	assert.Equal(t, int64(1), reader.commit(0))
+}
+
+func TestKafkaQueue_NackRequeues(t *testing.T) {
+	q, reader, _, writer := newTestKafkaQueue()
+	ctx := context.Background()
+	reader.push(t, 0, 0, newKafkaTestJob("a@example.com"))
+	reader.push(t, 0, 1, newKafkaTestJob("b@example.com"))
+
+	jobs, err := q.ConsumeBatch(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+
+	before := time.Now()
+	require.NoError(t, q.Nack(ctx, jobs[0].Receipt, 0))
+	require.NoError(t, q.Nack(ctx, jobs[1].Receipt, time.Minute))
+
+	written := writer.written()
+	require.Len(t, written, 2)
+	assert.Equal(t, "email-jobs", written[0].Topic, "a job handed back at once goes to the job topic")
+	assert.Equal(t, "a@example.com", string(written[0].Key))
+	assert.Empty(t, written[0].Headers)
+
+	assert.Equal(t, "email-jobs.delay", written[1].Topic)
+	assert.Equal(t, "b@example.com", string(written[1].Key))
+	scheduledAt, err := scheduledAtHeader(written[1])
+	require.NoError(t, err)
+	assert.WithinDuration(t, before.Add(time.Minute), scheduledAt, 5*time.Second)
+
+	var requeued models.EmailJob
+	require.NoError(t, json.Unmarshal(written[1].Value, &requeued))
+	assert.Equal(t, jobs[1].ID, requeued.ID)
+	assert.Equal(t, int64(1), reader.commit(0), "the original offsets are committed once requeued")
+}
+
+func TestKafkaQueue_HoldsScheduledJobsUntilDue(t *testing.T) {
+	q, _, delayReader, writer := newTestKafkaQueue()
+	ctx := context.Background()
+	scheduledAt := func(at time.Time) kafka.Header {
+		return kafka.Header{Key: kafkaScheduledAtHeader, Value: []byte(at.UTC().Format(time.RFC3339Nano))}
+	}
+	delayReader.push(t, 0, 0, newKafkaTestJob("due@example.com"), scheduledAt(time.Now().Add(-time.Second)))
+	delayReader.push(t, 0, 1, newKafkaTestJob("later@example.com"), scheduledAt(time.Now().Add(time.Hour)))
+	delayReader.push(t, 0, 2, newKafkaTestJob("behind@example.com"), scheduledAt(time.Now().Add(-time.Second)))
+
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+	require.Len(t, writer.written(), 1)
+	assert.Equal(t, "email-jobs", writer.written()[0].Topic)
+	assert.Equal(t, int64(0), delayReader.commit(0), "nothing past the job that is not due is committed")
+
+	// The held job is neither rewritten nor committed while it is not due
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+	require.Len(t, writer.written(), 1)
+	assert.Equal(t, int64(0), delayReader.commit(0))
+
+	q.heldDelay.Headers = []kafka.Header{scheduledAt(time.Now().Add(-time.Second))}
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+
+	written := writer.written()
+	require.Len(t, written, 3)
+	assert.Equal(t, "later@example.com", string(written[1].Key))
+	assert.Equal(t, "behind@example.com", string(written[2].Key))
+	assert.Equal(t, int64(2), delayReader.commit(0))
+}
+
+func TestKafkaQueue_ForgetsDeliveriesOnRebalance(t *testing.T) {
+	q, reader, delayReader, _ := newTestKafkaQueue()
+	ctx := context.Background()
+	reader.push(t, 0, 0, newKafkaTestJob("a@example.com"))
+
+	jobs, err := q.ConsumeBatch(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+
+	// The partition is redelivered from its committed offset after the rebalance
+	reader.mu.Lock()
+	reader.rebalances = 1
+	reader.mu.Unlock()
+	reader.push(t, 0, 0, newKafkaTestJob("a@example.com"))
+
+	redelivered, err := q.ConsumeBatch(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, redelivered, 1)
+	assert.Equal(t, jobs[0].QueueID, redelivered[0].QueueID)
+
+	assert.True(t, errors.Is(q.Ack(ctx, jobs[0].Receipt), ErrLeaseLost))
+	assert.True(t, errors.Is(q.Nack(ctx, jobs[0].Receipt, 0), ErrLeaseLost))
+	assert.Equal(t, int64(-1), reader.commit(0), "a delivery of an earlier generation is not committed")
+	require.NoError(t, q.Ack(ctx, redelivered[0].Receipt))
+	assert.Equal(t, int64(0), reader.commit(0))
+
+	// A job held on the delay topic is dropped when the delay reader rebalances
+	q.heldDelay = &kafka.Message{Partition: 0, Offset: 5}
+	delayReader.mu.Lock()
+	delayReader.rebalances = 1
+	delayReader.mu.Unlock()
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+	assert.Nil(t, q.heldDelay)
+	assert.Equal(t, int64(-1), delayReader.commit(0))
+}
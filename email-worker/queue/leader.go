@@ -0,0 +1,59 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Lease is the leadership of one replica among the replicas sharing a queue
+type Lease struct {
+	Holder string `json:"holder"`
+	// Token is the fencing token of the lease. Every new leader gets a higher
+	// token than the one before, so work started under an older lease can be
+	// told apart and refused.
+	Token int64 `json:"token"`
+	// ExpiresAt is when the lease lapses unless it is renewed
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LeaderElector elects one leader among the replicas sharing a queue, for
+// background tasks that must not run on every replica at once. Like dead
+// letters, the lease lives next to the queue backend.
+type LeaderElector interface {
+	// Acquire takes the lease for holder, or renews it if holder already
+	// leads, for ttl. It returns ErrNotLeader while another holder leads.
+	Acquire(ctx context.Context, holder string, ttl time.Duration) (*Lease, error)
+
+	// Check returns ErrNotLeader unless lease is still the current lease.
+	// Leaders check their fencing token with it right before singleton work.
+	Check(ctx context.Context, lease *Lease) error
+
+	// Release gives up lease so another replica can take over at once
+	Release(ctx context.Context, lease *Lease) error
+
+	// Close closes the elector
+	Close() error
+}
+
+// ErrNotLeader is returned when another replica holds the lease
+var ErrNotLeader = fmt.Errorf("lease is held by another replica")
+
+// CreateLeaderElector creates the leader elector matching a queue
+// configuration. The Kafka backend elects its leader in the service database.
+func (f *QueueFactory) CreateLeaderElector(config QueueConfig) (LeaderElector, error) {
+	switch config.Type {
+	case "redis":
+		addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
+		return NewRedisLeaderElector(addr, config.Password, config.Database, config.QueueName, f.logger), nil
+	case "memory":
+		return NewMemoryLeaderElector(), nil
+	case "postgres", "kafka":
+		if config.DB == nil {
+			return nil, fmt.Errorf("%s leader elector requires a database connection", config.Type)
+		}
+		return NewPostgresLeaderElector(config.DB, config.QueueName, f.logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported queue type: %s", config.Type)
+	}
+}
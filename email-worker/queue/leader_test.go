@@ -0,0 +1,96 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/queue"
+)
+
+// testLeaderElector runs the behaviour every leader elector must have, with
+// two replicas electing through a and b. expire makes the current lease
+// lapse, the expiry part is skipped when it is nil.
+func testLeaderElector(t *testing.T, a, b queue.LeaderElector, expire func()) {
+	ctx := context.Background()
+	ttl := 200 * time.Millisecond
+
+	first, err := a.Acquire(ctx, "replica-a", ttl)
+	require.NoError(t, err)
+	assert.Equal(t, "replica-a", first.Holder)
+
+	_, err = b.Acquire(ctx, "replica-b", ttl)
+	assert.ErrorIs(t, err, queue.ErrNotLeader)
+
+	// Renewing keeps the term and its token
+	renewed, err := a.Acquire(ctx, "replica-a", ttl)
+	require.NoError(t, err)
+	assert.Equal(t, first.Token, renewed.Token)
+	assert.NoError(t, a.Check(ctx, renewed))
+
+	// After a release the other replica takes over with a higher token, and
+	// the old lease is fenced off
+	require.NoError(t, a.Release(ctx, renewed))
+	second, err := b.Acquire(ctx, "replica-b", ttl)
+	require.NoError(t, err)
+	assert.Greater(t, second.Token, first.Token)
+	assert.ErrorIs(t, a.Check(ctx, first), queue.ErrNotLeader)
+	assert.NoError(t, b.Check(ctx, second))
+
+	// Releasing a lease that is not current does nothing
+	require.NoError(t, a.Release(ctx, first))
+	assert.NoError(t, b.Check(ctx, second))
+
+	if expire == nil {
+		return
+	}
+
+	// A leader that stops renewing loses the lease
+	expire()
+	third, err := a.Acquire(ctx, "replica-a", ttl)
+	require.NoError(t, err)
+	assert.Greater(t, third.Token, second.Token)
+	assert.ErrorIs(t, b.Check(ctx, second), queue.ErrNotLeader)
+}
+
+func TestMemoryLeaderElector(t *testing.T) {
+	elector := queue.NewMemoryLeaderElector()
+	testLeaderElector(t, elector, elector, func() {
+		time.Sleep(250 * time.Millisecond)
+	})
+}
+
+func TestRedisLeaderElector(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a := queue.NewRedisLeaderElector(mr.Addr(), "", 0, "email-jobs", zap.NewNop())
+	b := queue.NewRedisLeaderElector(mr.Addr(), "", 0, "email-jobs", zap.NewNop())
+	t.Cleanup(func() {
+		a.Close()
+		b.Close()
+	})
+
+	testLeaderElector(t, a, b, func() {
+		mr.FastForward(250 * time.Millisecond)
+	})
+}
+
+func TestPostgresLeaderElector(t *testing.T) {
+	db, _ := newTestPostgresDB(t)
+	a := queue.NewPostgresLeaderElector(db, "email-jobs-test", zap.NewNop())
+	b := queue.NewPostgresLeaderElector(db, "email-jobs-test", zap.NewNop())
+	t.Cleanup(func() {
+		a.Close()
+		b.Close()
+	})
+
+	// The lock lives as long as the leader's session, closing it stands in
+	// for a leader that went away
+	testLeaderElector(t, a, b, func() {
+		require.NoError(t, b.Close())
+	})
+}
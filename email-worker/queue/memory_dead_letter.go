@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryDeadLetterStore implements DeadLetterStore in process memory.
+// Dead letters are lost when the process exits.
+type MemoryDeadLetterStore struct {
+	mu      sync.Mutex
+	entries map[string]*DeadLetter
+}
+
+// NewMemoryDeadLetterStore creates a new MemoryDeadLetterStore instance
+func NewMemoryDeadLetterStore() *MemoryDeadLetterStore {
+	return &MemoryDeadLetterStore{
+		entries: make(map[string]*DeadLetter),
+	}
+}
+
+// Add stores a dead letter, replacing an earlier one for the same job
+func (s *MemoryDeadLetterStore) Add(ctx context.Context, entry *DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[entry.JobID()] = copyDeadLetter(entry)
+	return nil
+}
+
+// Get returns the dead letter of a job
+func (s *MemoryDeadLetterStore) Get(ctx context.Context, jobID string) (*DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[jobID]
+	if !ok {
+		return nil, ErrDeadLetterNotFound
+	}
+	return copyDeadLetter(entry), nil
+}
+
+// List returns the dead letters selected by filter, newest first
+func (s *MemoryDeadLetterStore) List(ctx context.Context, filter DeadLetterFilter) ([]*DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]*DeadLetter, 0, len(s.entries))
+	for _, entry := range s.entries {
+		if filter.Matches(entry) {
+			entries = append(entries, copyDeadLetter(entry))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].DeadLetteredAt.After(entries[j].DeadLetteredAt)
+	})
+
+	return filter.page(entries), nil
+}
+
+// Delete removes the dead letters of the given jobs
+func (s *MemoryDeadLetterStore) Delete(ctx context.Context, jobIDs ...string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := 0
+	for _, jobID := range jobIDs {
+		if _, ok := s.entries[jobID]; ok {
+			delete(s.entries, jobID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// Close closes the store
+func (s *MemoryDeadLetterStore) Close() error {
+	return nil
+}
+
+// copyDeadLetter copies a dead letter and its job, so callers cannot modify stored entries
+func copyDeadLetter(entry *DeadLetter) *DeadLetter {
+	job := *entry.Job
+	copied := *entry
+	copied.Job = &job
+	return &copied
+}
@@ -0,0 +1,59 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryHeartbeatStore implements HeartbeatStore in process memory
+type MemoryHeartbeatStore struct {
+	mu         sync.Mutex
+	heartbeats map[string]*Heartbeat
+}
+
+// NewMemoryHeartbeatStore creates a new MemoryHeartbeatStore instance
+func NewMemoryHeartbeatStore() *MemoryHeartbeatStore {
+	return &MemoryHeartbeatStore{
+		heartbeats: make(map[string]*Heartbeat),
+	}
+}
+
+// Beat stores a heartbeat, replacing the worker's previous one
+func (s *MemoryHeartbeatStore) Beat(ctx context.Context, heartbeat *Heartbeat) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.heartbeats[heartbeat.Worker] = heartbeat
+	return nil
+}
+
+// Expired returns the heartbeats that were not renewed since before
+func (s *MemoryHeartbeatStore) Expired(ctx context.Context, before time.Time) ([]*Heartbeat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expired []*Heartbeat
+	for _, heartbeat := range s.heartbeats {
+		if heartbeat.BeatAt.Before(before) {
+			expired = append(expired, heartbeat)
+		}
+	}
+	return expired, nil
+}
+
+// Remove deletes a heartbeat unless the worker has beaten again since
+func (s *MemoryHeartbeatStore) Remove(ctx context.Context, heartbeat *Heartbeat) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, ok := s.heartbeats[heartbeat.Worker]; ok && current.BeatAt.Equal(heartbeat.BeatAt) {
+		delete(s.heartbeats, heartbeat.Worker)
+	}
+	return nil
+}
+
+// Close closes the store
+func (s *MemoryHeartbeatStore) Close() error {
+	return nil
+}
@@ -0,0 +1,78 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"booking-system/email-worker/models"
+)
+
+// MemoryIdempotencyStore implements IdempotencyStore in process memory.
+// Keys are lost when the process exits.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryIdempotencyEntry
+}
+
+// memoryIdempotencyEntry is the job holding a key and when the key expires
+type memoryIdempotencyEntry struct {
+	job       *models.EmailJob
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore creates a new MemoryIdempotencyStore instance
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries: make(map[string]memoryIdempotencyEntry),
+	}
+}
+
+// Reserve claims key for job unless an earlier job still holds it
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, key string, job *models.EmailJob, window time.Duration) (*models.EmailJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		return idempotentJob(entry.job), nil
+	}
+
+	s.entries[key] = memoryIdempotencyEntry{
+		job:       idempotentJob(job),
+		expiresAt: now.Add(window),
+	}
+	return nil, nil
+}
+
+// Release frees key if it is held by the given job
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key, jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && entry.job.ID.String() == jobID {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// DeleteExpired removes keys whose window has passed
+func (s *MemoryIdempotencyStore) DeleteExpired(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	deleted := 0
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// Close closes the store
+func (s *MemoryIdempotencyStore) Close() error {
+	return nil
+}
@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryLeaderElector implements LeaderElector in process memory. It only
+// elects among the processors of one process, which is all the memory queue
+// is shared by.
+type MemoryLeaderElector struct {
+	mu    sync.Mutex
+	lease *Lease
+	token int64
+}
+
+// NewMemoryLeaderElector creates a new MemoryLeaderElector instance
+func NewMemoryLeaderElector() *MemoryLeaderElector {
+	return &MemoryLeaderElector{}
+}
+
+// Acquire takes or renews the lease for holder
+func (e *MemoryLeaderElector) Acquire(ctx context.Context, holder string, ttl time.Duration) (*Lease, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	if e.lease != nil && e.lease.Holder != holder && now.Before(e.lease.ExpiresAt) {
+		return nil, ErrNotLeader
+	}
+
+	if e.lease == nil || e.lease.Holder != holder || !now.Before(e.lease.ExpiresAt) {
+		e.token++
+		e.lease = &Lease{Holder: holder, Token: e.token}
+	}
+	e.lease.ExpiresAt = now.Add(ttl)
+
+	lease := *e.lease
+	return &lease, nil
+}
+
+// Check returns ErrNotLeader unless lease is current
+func (e *MemoryLeaderElector) Check(ctx context.Context, lease *Lease) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.lease == nil || e.lease.Token != lease.Token || !time.Now().Before(e.lease.ExpiresAt) {
+		return ErrNotLeader
+	}
+	return nil
+}
+
+// Release gives up lease if it is current
+func (e *MemoryLeaderElector) Release(ctx context.Context, lease *Lease) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.lease != nil && e.lease.Token == lease.Token {
+		e.lease = nil
+	}
+	return nil
+}
+
+// Close closes the elector
+func (e *MemoryLeaderElector) Close() error {
+	return nil
+}
@@ -0,0 +1,592 @@
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+)
+
+// MemoryQueue implements the Queue interface in process memory.
+//
+// It mirrors the semantics of RedisQueue (priority then FIFO ordering,
+// scheduled delivery, leases that expire unless acknowledged) so the
+// processor can be run locally and in tests without any external service.
+// Pending jobs are kept in one FIFO heap per priority lane.
+// Jobs are lost when the process exits.
+type MemoryQueue struct {
+	mu        sync.Mutex
+	pending   map[models.JobPriority]*memoryHeap
+	scheduled memoryHeap
+	leased    map[string]*memoryItem
+	// cancelled holds the IDs of leased jobs cancelled before they were acked
+	cancelled map[string]struct{}
+	// subscribers are signalled whenever a job becomes pending
+	subscribers       map[chan struct{}]struct{}
+	visibilityTimeout time.Duration
+	seq               uint64
+	closed            bool
+	logger            *zap.Logger
+}
+
+// memoryItem is a queued job together with its ordering keys
+type memoryItem struct {
+	job *models.EmailJob
+	seq uint64
+	// due is the scheduled time for scheduled jobs and the lease deadline for leased ones
+	due time.Time
+	// since is when the job last became pending, used for aging
+	since time.Time
+	// lease is the lease token of the current delivery
+	lease uint64
+}
+
+// NewMemoryQueue creates a new MemoryQueue instance
+func NewMemoryQueue(visibilityTimeout time.Duration, logger *zap.Logger) *MemoryQueue {
+	if visibilityTimeout <= 0 {
+		visibilityTimeout = defaultVisibilityTimeout
+	}
+
+	return &MemoryQueue{
+		pending:           make(map[models.JobPriority]*memoryHeap),
+		scheduled:         memoryHeap{less: dueLess},
+		leased:            make(map[string]*memoryItem),
+		cancelled:         make(map[string]struct{}),
+		subscribers:       make(map[chan struct{}]struct{}),
+		visibilityTimeout: visibilityTimeout,
+		logger:            logger,
+	}
+}
+
+// Publish adds an email job to the queue
+func (q *MemoryQueue) Publish(ctx context.Context, job *models.EmailJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	q.pushPending(q.newItem(job), time.Now())
+	return nil
+}
+
+// Subscribe signals whenever a job becomes pending or a scheduled job is due
+func (q *MemoryQueue) Subscribe(ctx context.Context) (<-chan struct{}, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, ErrQueueClosed
+	}
+
+	signals := make(chan struct{}, 1)
+	q.subscribers[signals] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		if _, ok := q.subscribers[signals]; ok {
+			delete(q.subscribers, signals)
+			close(signals)
+		}
+	}()
+
+	return signals, nil
+}
+
+// Consume retrieves the next job from the queue and leases it to the caller
+func (q *MemoryQueue) Consume(ctx context.Context) (*models.EmailJob, error) {
+	jobs, err := q.ConsumeBatch(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, ErrQueueEmpty
+	}
+	return jobs[0], nil
+}
+
+// ConsumeBatch retrieves up to batchSize jobs in priority order and leases them
+// to the caller. Scheduled jobs that are due are promoted first.
+func (q *MemoryQueue) ConsumeBatch(ctx context.Context, batchSize int) ([]*models.EmailJob, error) {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, ErrQueueClosed
+	}
+
+	now := time.Now()
+	q.promoteDue(now)
+
+	jobs := make([]*models.EmailJob, 0, batchSize)
+	for _, priority := range q.lanes() {
+		jobs = q.lease(jobs, q.pending[priority], batchSize, now)
+	}
+
+	return jobs, nil
+}
+
+// ConsumeLane retrieves up to batchSize jobs of a single priority and leases them
+func (q *MemoryQueue) ConsumeLane(ctx context.Context, priority models.JobPriority, batchSize int) ([]*models.EmailJob, error) {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, ErrQueueClosed
+	}
+
+	now := time.Now()
+	q.promoteDue(now)
+
+	jobs := make([]*models.EmailJob, 0, batchSize)
+	if lane, ok := q.pending[priority]; ok {
+		jobs = q.lease(jobs, lane, batchSize, now)
+	}
+	return jobs, nil
+}
+
+// PromoteAged moves jobs pending for at least agingInterval up one priority,
+// but not above ceiling. A promoted job waits agingInterval again before its
+// next promotion.
+func (q *MemoryQueue) PromoteAged(ctx context.Context, agingInterval time.Duration, ceiling models.JobPriority) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return 0, ErrQueueClosed
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-agingInterval)
+	promoted := 0
+	for _, priority := range q.lanes() {
+		if priority <= ceiling {
+			continue
+		}
+
+		lane := q.pending[priority]
+		kept := lane.items[:0]
+		var aged []*memoryItem
+		for _, item := range lane.items {
+			if item.since.After(cutoff) {
+				kept = append(kept, item)
+			} else {
+				aged = append(aged, item)
+			}
+		}
+		if len(aged) == 0 {
+			continue
+		}
+
+		lane.items = kept
+		heap.Init(lane)
+		// Promoted jobs keep their place in line, but start waiting anew
+		for _, item := range aged {
+			item.job.Priority = priority - 1
+			item.since = now
+			heap.Push(q.lane(item.job.Priority), item)
+		}
+		promoted += len(aged)
+	}
+
+	return promoted, nil
+}
+
+// Ack removes a leased job from the queue once it has been handled
+func (q *MemoryQueue) Ack(ctx context.Context, receipt string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item, err := q.leaseOf(receipt)
+	if err != nil {
+		return fmt.Errorf("failed to ack job: %w", err)
+	}
+
+	delete(q.leased, item.job.QueueID)
+	delete(q.cancelled, item.job.ID.String())
+	return nil
+}
+
+// Nack releases a leased job. It is pending again right away, or scheduled
+// when requeueAfter is positive.
+func (q *MemoryQueue) Nack(ctx context.Context, receipt string, requeueAfter time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item, err := q.leaseOf(receipt)
+	if err != nil {
+		return fmt.Errorf("failed to nack job: %w", err)
+	}
+
+	delete(q.leased, item.job.QueueID)
+	item.lease = 0
+	if requeueAfter > 0 {
+		item.due = time.Now().Add(requeueAfter)
+		q.pushScheduled(item)
+		return nil
+	}
+	q.pushPending(item, time.Now())
+	return nil
+}
+
+// ExtendLease pushes the lease deadline of a leased job to extension from now
+func (q *MemoryQueue) ExtendLease(ctx context.Context, receipt string, extension time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item, err := q.leaseOf(receipt)
+	if err != nil {
+		return fmt.Errorf("failed to extend lease: %w", err)
+	}
+
+	item.due = time.Now().Add(extension)
+	return nil
+}
+
+// Size returns the number of jobs ready to be consumed
+func (q *MemoryQueue) Size(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.promoteDue(time.Now())
+
+	size := 0
+	for _, lane := range q.pending {
+		size += lane.Len()
+	}
+	return int64(size), nil
+}
+
+// OldestAge returns how long the longest waiting pending job has been pending
+func (q *MemoryQueue) OldestAge(ctx context.Context) (time.Duration, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	q.promoteDue(now)
+
+	var oldest time.Time
+	for _, lane := range q.pending {
+		for _, item := range lane.items {
+			if oldest.IsZero() || item.since.Before(oldest) {
+				oldest = item.since
+			}
+		}
+	}
+	if oldest.IsZero() {
+		return 0, nil
+	}
+	return now.Sub(oldest), nil
+}
+
+// Clear removes all jobs from the queue, including leased and scheduled ones
+func (q *MemoryQueue) Clear(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending = make(map[models.JobPriority]*memoryHeap)
+	q.scheduled.items = nil
+	q.leased = make(map[string]*memoryItem)
+	q.cancelled = make(map[string]struct{})
+	return nil
+}
+
+// Health checks if the queue is healthy
+func (q *MemoryQueue) Health(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+	return nil
+}
+
+// Close closes the queue, further operations fail with ErrQueueClosed
+func (q *MemoryQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	for signals := range q.subscribers {
+		delete(q.subscribers, signals)
+		close(signals)
+	}
+	return nil
+}
+
+// PublishScheduled publishes a job for scheduled delivery
+func (q *MemoryQueue) PublishScheduled(ctx context.Context, job *models.EmailJob, scheduledAt time.Time) error {
+	job.SetScheduledAt(scheduledAt)
+	if !scheduledAt.After(time.Now()) {
+		return q.Publish(ctx, job)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	item := q.newItem(job)
+	item.due = scheduledAt
+	q.pushScheduled(item)
+	return nil
+}
+
+// Cancel removes a pending or scheduled job from the queue, or marks it
+// cancelled if it is leased
+func (q *MemoryQueue) Cancel(ctx context.Context, jobID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	if q.remove(jobID) != nil {
+		return nil
+	}
+	for _, item := range q.leased {
+		if item.job.ID.String() == jobID {
+			q.cancelled[jobID] = struct{}{}
+			return nil
+		}
+	}
+	return ErrJobNotFound
+}
+
+// Cancelled reports whether a leased job was cancelled
+func (q *MemoryQueue) Cancelled(ctx context.Context, jobID string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	_, ok := q.cancelled[jobID]
+	return ok, nil
+}
+
+// Reschedule moves a pending or scheduled job to scheduledAt
+func (q *MemoryQueue) Reschedule(ctx context.Context, jobID string, scheduledAt time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	item := q.remove(jobID)
+	if item == nil {
+		for _, leased := range q.leased {
+			if leased.job.ID.String() == jobID {
+				return ErrJobLeased
+			}
+		}
+		return ErrJobNotFound
+	}
+
+	item.job.SetScheduledAt(scheduledAt)
+	now := time.Now()
+	if !scheduledAt.After(now) {
+		q.pushPending(item, now)
+		return nil
+	}
+	item.due = scheduledAt
+	q.pushScheduled(item)
+	return nil
+}
+
+// ProcessScheduledJobs moves ready scheduled jobs to the main queue and
+// reclaims jobs whose lease has expired
+func (q *MemoryQueue) ProcessScheduledJobs(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	if moved := q.promoteDue(now); moved > 0 {
+		q.logger.Info("Moved scheduled jobs to queue", zap.Int("count", moved))
+	}
+
+	reclaimed := 0
+	for queueID, item := range q.leased {
+		if item.due.After(now) {
+			continue
+		}
+		delete(q.leased, queueID)
+		item.lease = 0
+		q.pushPending(item, now)
+		reclaimed++
+	}
+	if reclaimed > 0 {
+		q.logger.Warn("Reclaimed jobs with expired lease", zap.Int("count", reclaimed))
+	}
+
+	return nil
+}
+
+// newItem stores a copy of the job under a fresh queue ID. Callers must hold q.mu.
+func (q *MemoryQueue) newItem(job *models.EmailJob) *memoryItem {
+	q.seq++
+	job.SetQueueID(strconv.FormatUint(q.seq, 10))
+
+	stored := *job
+	return &memoryItem{job: &stored, seq: q.seq}
+}
+
+// leaseOf returns the leased item a receipt refers to. Callers must hold q.mu.
+func (q *MemoryQueue) leaseOf(receipt string) (*memoryItem, error) {
+	queueID, token, err := parseReceipt(receipt)
+	if err != nil {
+		return nil, err
+	}
+
+	item, ok := q.leased[queueID]
+	if !ok || strconv.FormatUint(item.lease, 10) != token {
+		return nil, ErrLeaseLost
+	}
+	return item, nil
+}
+
+// promoteDue moves scheduled jobs that are due to the pending heap. Callers must hold q.mu.
+func (q *MemoryQueue) promoteDue(now time.Time) int {
+	moved := 0
+	for q.scheduled.Len() > 0 && !q.scheduled.items[0].due.After(now) {
+		q.pushPending(heap.Pop(&q.scheduled).(*memoryItem), now)
+		moved++
+	}
+	return moved
+}
+
+// pushPending adds a job to the lane of its priority and signals the
+// subscribers. Callers must hold q.mu.
+func (q *MemoryQueue) pushPending(item *memoryItem, now time.Time) {
+	item.since = now
+	heap.Push(q.lane(item.job.Priority), item)
+	q.signal()
+}
+
+// pushScheduled adds a job to the scheduled heap and signals the subscribers
+// once it is due, consuming then promotes it. Callers must hold q.mu.
+func (q *MemoryQueue) pushScheduled(item *memoryItem) {
+	heap.Push(&q.scheduled, item)
+	time.AfterFunc(time.Until(item.due), func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.signal()
+	})
+}
+
+// signal wakes every subscriber. Callers must hold q.mu.
+func (q *MemoryQueue) signal() {
+	for signals := range q.subscribers {
+		notify(signals)
+	}
+}
+
+// lane returns the pending heap of a priority, creating it if needed. Callers must hold q.mu.
+func (q *MemoryQueue) lane(priority models.JobPriority) *memoryHeap {
+	lane, ok := q.pending[priority]
+	if !ok {
+		lane = &memoryHeap{less: seqLess}
+		q.pending[priority] = lane
+	}
+	return lane
+}
+
+// remove takes a pending or scheduled job off its heap and returns it, or nil
+// if the job is neither. Callers must hold q.mu.
+func (q *MemoryQueue) remove(jobID string) *memoryItem {
+	heaps := []*memoryHeap{&q.scheduled}
+	for _, lane := range q.pending {
+		heaps = append(heaps, lane)
+	}
+
+	for _, h := range heaps {
+		for i, item := range h.items {
+			if item.job.ID.String() == jobID {
+				heap.Remove(h, i)
+				return item
+			}
+		}
+	}
+	return nil
+}
+
+// lanes returns the priorities that have a lane, most urgent first. Callers must hold q.mu.
+func (q *MemoryQueue) lanes() []models.JobPriority {
+	priorities := make([]models.JobPriority, 0, len(q.pending))
+	for priority := range q.pending {
+		priorities = append(priorities, priority)
+	}
+	sort.Slice(priorities, func(i, j int) bool { return priorities[i] < priorities[j] })
+	return priorities
+}
+
+// lease pops jobs from a lane until jobs holds batchSize of them and leases
+// them to the caller. Callers must hold q.mu.
+func (q *MemoryQueue) lease(jobs []*models.EmailJob, lane *memoryHeap, batchSize int, now time.Time) []*models.EmailJob {
+	for len(jobs) < batchSize && lane.Len() > 0 {
+		item := heap.Pop(lane).(*memoryItem)
+		item.due = now.Add(q.visibilityTimeout)
+		q.seq++
+		item.lease = q.seq
+		q.leased[item.job.QueueID] = item
+
+		// Hand out a copy, like a serializing queue would
+		job := *item.job
+		job.SetReceipt(newReceipt(job.QueueID, strconv.FormatUint(item.lease, 10)))
+		jobs = append(jobs, &job)
+	}
+	return jobs
+}
+
+// seqLess orders jobs of a lane by enqueue order
+func seqLess(a, b *memoryItem) bool {
+	return a.seq < b.seq
+}
+
+// dueLess orders jobs by their due time
+func dueLess(a, b *memoryItem) bool {
+	if !a.due.Equal(b.due) {
+		return a.due.Before(b.due)
+	}
+	return a.seq < b.seq
+}
+
+// memoryHeap is a container/heap of queued jobs with a configurable order
+type memoryHeap struct {
+	items []*memoryItem
+	less  func(a, b *memoryItem) bool
+}
+
+func (h memoryHeap) Len() int           { return len(h.items) }
+func (h memoryHeap) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h memoryHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *memoryHeap) Push(x any) { h.items = append(h.items, x.(*memoryItem)) }
+
+func (h *memoryHeap) Pop() any {
+	old := h.items
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	h.items = old[:len(old)-1]
+	return item
+}
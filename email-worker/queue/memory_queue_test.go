@@ -0,0 +1,222 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/queue"
+)
+
+func TestMemoryQueue_ConsumeBatchPriorityOrder(t *testing.T) {
+	q := queue.NewMemoryQueue(time.Minute, zap.NewNop())
+	ctx := context.Background()
+
+	low := newTestJob(models.JobPriorityLow)
+	normal1 := newTestJob(models.JobPriorityNormal)
+	normal2 := newTestJob(models.JobPriorityNormal)
+	high := newTestJob(models.JobPriorityHigh)
+	for _, job := range []*models.EmailJob{low, normal1, normal2, high} {
+		require.NoError(t, q.Publish(ctx, job))
+	}
+
+	size, err := q.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), size)
+
+	jobs, err := q.ConsumeBatch(ctx, 3)
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+	assert.Equal(t, high.ID, jobs[0].ID)
+	assert.Equal(t, normal1.ID, jobs[1].ID)
+	assert.Equal(t, normal2.ID, jobs[2].ID)
+
+	consumed, err := q.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, low.ID, consumed.ID)
+
+	_, err = q.Consume(ctx)
+	assert.Equal(t, queue.ErrQueueEmpty, err)
+}
+
+func TestMemoryQueue_ScheduledJobs(t *testing.T) {
+	q := queue.NewMemoryQueue(time.Minute, zap.NewNop())
+	ctx := context.Background()
+
+	late := newTestJob(models.JobPriorityHigh)
+	early := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, q.PublishScheduled(ctx, late, time.Now().Add(time.Hour)))
+	require.NoError(t, q.PublishScheduled(ctx, early, time.Now().Add(50*time.Millisecond)))
+
+	size, err := q.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+
+	time.Sleep(100 * time.Millisecond)
+
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+	jobs, err := q.ConsumeBatch(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, early.ID, jobs[0].ID)
+	require.NotNil(t, jobs[0].ProcessedAt)
+}
+
+func TestMemoryQueue_LeaseAndAck(t *testing.T) {
+	q := queue.NewMemoryQueue(50*time.Millisecond, zap.NewNop())
+	ctx := context.Background()
+
+	acked := newTestJob(models.JobPriorityNormal)
+	abandoned := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, q.Publish(ctx, acked))
+	require.NoError(t, q.Publish(ctx, abandoned))
+
+	jobs, err := q.ConsumeBatch(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	require.NoError(t, q.Ack(ctx, jobs[0].Receipt))
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+
+	redelivered, err := q.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, abandoned.ID, redelivered.ID)
+
+	_, err = q.Consume(ctx)
+	assert.Equal(t, queue.ErrQueueEmpty, err)
+}
+
+func TestMemoryQueue_ConsumedJobIsACopy(t *testing.T) {
+	q := queue.NewMemoryQueue(time.Minute, zap.NewNop())
+	ctx := context.Background()
+
+	job := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, q.Publish(ctx, job))
+	job.RetryCount = 5
+
+	consumed, err := q.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, consumed.RetryCount)
+	assert.Equal(t, job.QueueID, consumed.QueueID)
+}
+
+func TestMemoryQueue_ClearAndClose(t *testing.T) {
+	q := queue.NewMemoryQueue(time.Minute, zap.NewNop())
+	ctx := context.Background()
+
+	require.NoError(t, q.Publish(ctx, newTestJob(models.JobPriorityNormal)))
+	require.NoError(t, q.PublishScheduled(ctx, newTestJob(models.JobPriorityNormal), time.Now().Add(time.Hour)))
+	require.NoError(t, q.Health(ctx))
+
+	require.NoError(t, q.Clear(ctx))
+	size, err := q.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+
+	require.NoError(t, q.Close())
+	assert.ErrorIs(t, q.Health(ctx), queue.ErrQueueClosed)
+	assert.ErrorIs(t, q.Publish(ctx, newTestJob(models.JobPriorityNormal)), queue.ErrQueueClosed)
+}
+
+func TestQueueFactory_CreateMemoryQueue(t *testing.T) {
+	factory := queue.NewQueueFactory(zap.NewNop())
+
+	q, err := factory.CreateQueue(queue.QueueConfig{Type: "memory", VisibilityTimeout: "30s"})
+	require.NoError(t, err)
+	assert.IsType(t, &queue.MemoryQueue{}, q)
+
+	_, err = factory.CreateQueue(queue.QueueConfig{Type: "memory", VisibilityTimeout: "soon"})
+	assert.Error(t, err)
+}
+
+func TestMemoryQueue_ConsumeLane(t *testing.T) {
+	q := queue.NewMemoryQueue(time.Minute, zap.NewNop())
+	ctx := context.Background()
+
+	urgent := newTestJob(models.JobPriorityUrgent)
+	low1 := newTestJob(models.JobPriorityLow)
+	low2 := newTestJob(models.JobPriorityLow)
+	for _, job := range []*models.EmailJob{low1, urgent, low2} {
+		require.NoError(t, q.Publish(ctx, job))
+	}
+
+	jobs, err := q.ConsumeLane(ctx, models.JobPriorityLow, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, low1.ID, jobs[0].ID)
+	assert.Equal(t, low2.ID, jobs[1].ID)
+	require.NoError(t, q.Ack(ctx, jobs[0].Receipt))
+
+	jobs, err = q.ConsumeLane(ctx, models.JobPriorityHigh, 10)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	jobs, err = q.ConsumeBatch(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, urgent.ID, jobs[0].ID)
+}
+
+func TestMemoryQueue_PromoteAged(t *testing.T) {
+	q := queue.NewMemoryQueue(time.Minute, zap.NewNop())
+	ctx := context.Background()
+
+	high := newTestJob(models.JobPriorityHigh)
+	low := newTestJob(models.JobPriorityLow)
+	normal := newTestJob(models.JobPriorityNormal)
+	for _, job := range []*models.EmailJob{high, low, normal} {
+		require.NoError(t, q.Publish(ctx, job))
+	}
+
+	promoted, err := q.PromoteAged(ctx, time.Hour, models.JobPriorityHigh)
+	require.NoError(t, err)
+	assert.Equal(t, 0, promoted)
+
+	// High is the ceiling, so only the normal and low jobs move up
+	promoted, err = q.PromoteAged(ctx, 0, models.JobPriorityHigh)
+	require.NoError(t, err)
+	assert.Equal(t, 2, promoted)
+
+	jobs, err := q.ConsumeLane(ctx, models.JobPriorityHigh, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, high.ID, jobs[0].ID)
+	assert.Equal(t, normal.ID, jobs[1].ID)
+	assert.Equal(t, models.JobPriorityHigh, jobs[1].Priority)
+
+	jobs, err = q.ConsumeLane(ctx, models.JobPriorityNormal, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, low.ID, jobs[0].ID)
+	assert.Equal(t, models.JobPriorityNormal, jobs[0].Priority)
+}
+
+func TestMemoryQueue_OldestAge(t *testing.T) {
+	q := queue.NewMemoryQueue(time.Minute, zap.NewNop())
+	ctx := context.Background()
+
+	age, err := q.OldestAge(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, age)
+
+	// The oldest job is found across lanes, not just in the most urgent one
+	require.NoError(t, q.Publish(ctx, newTestJob(models.JobPriorityLow)))
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, q.Publish(ctx, newTestJob(models.JobPriorityUrgent)))
+
+	age, err = q.OldestAge(ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, age, 20*time.Millisecond)
+
+	_, err = q.ConsumeBatch(ctx, 2)
+	require.NoError(t, err)
+	age, err = q.OldestAge(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, age)
+}
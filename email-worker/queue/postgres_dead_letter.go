@@ -0,0 +1,172 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// PostgresDeadLetterStore implements DeadLetterStore on the email_dead_letters
+// table (see migration 005). The original job is kept as a JSON payload, the
+// columns the store filters on are copied next to it.
+type PostgresDeadLetterStore struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewPostgresDeadLetterStore creates a new PostgresDeadLetterStore instance
+func NewPostgresDeadLetterStore(db *sql.DB, logger *zap.Logger) *PostgresDeadLetterStore {
+	return &PostgresDeadLetterStore{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Add stores a dead letter, replacing an earlier one for the same job
+func (s *PostgresDeadLetterStore) Add(ctx context.Context, entry *DeadLetter) error {
+	payload, err := json.Marshal(entry.Job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	query := `
+		INSERT INTO email_dead_letters (job_id, template_name, final_error, payload, dead_lettered_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (job_id) DO UPDATE SET
+			template_name = EXCLUDED.template_name,
+			final_error = EXCLUDED.final_error,
+			payload = EXCLUDED.payload,
+			dead_lettered_at = EXCLUDED.dead_lettered_at
+	`
+
+	_, err = s.db.ExecContext(ctx, query,
+		entry.Job.ID, entry.Job.TemplateName, entry.FinalError, payload, entry.DeadLetteredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add dead letter: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the dead letter of a job
+func (s *PostgresDeadLetterStore) Get(ctx context.Context, jobID string) (*DeadLetter, error) {
+	query := `
+		SELECT final_error, payload, dead_lettered_at
+		FROM email_dead_letters WHERE job_id::text = $1
+	`
+
+	entry, err := s.scan(s.db.QueryRowContext(ctx, query, jobID))
+	if err == sql.ErrNoRows {
+		return nil, ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+	return entry, nil
+}
+
+// List returns the dead letters selected by filter, newest first
+func (s *PostgresDeadLetterStore) List(ctx context.Context, filter DeadLetterFilter) ([]*DeadLetter, error) {
+	var conditions []string
+	var args []any
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.TemplateName != "" {
+		addCondition("template_name = $%d", filter.TemplateName)
+	}
+	if filter.ErrorContains != "" {
+		addCondition("strpos(final_error, $%d) > 0", filter.ErrorContains)
+	}
+	if !filter.Since.IsZero() {
+		addCondition("dead_lettered_at >= $%d", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		addCondition("dead_lettered_at <= $%d", filter.Until)
+	}
+
+	query := `SELECT final_error, payload, dead_lettered_at FROM email_dead_letters`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY dead_lettered_at DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*DeadLetter{}
+	for rows.Next() {
+		entry, err := s.scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Delete removes the dead letters of the given jobs
+func (s *PostgresDeadLetterStore) Delete(ctx context.Context, jobIDs ...string) (int, error) {
+	if len(jobIDs) == 0 {
+		return 0, nil
+	}
+
+	placeholders := make([]string, len(jobIDs))
+	args := make([]any, len(jobIDs))
+	for i, jobID := range jobIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = jobID
+	}
+
+	query := `DELETE FROM email_dead_letters WHERE job_id::text IN (` + strings.Join(placeholders, ", ") + `)`
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete dead letters: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(deleted), nil
+}
+
+// Close closes the store. The database connection is owned by the service.
+func (s *PostgresDeadLetterStore) Close() error {
+	return nil
+}
+
+// scan reads a dead letter row
+func (s *PostgresDeadLetterStore) scan(row interface{ Scan(...any) error }) (*DeadLetter, error) {
+	var entry DeadLetter
+	var payload []byte
+	if err := row.Scan(&entry.FinalError, &payload, &entry.DeadLetteredAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(payload, &entry.Job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dead letter: %w", err)
+	}
+	return &entry, nil
+}
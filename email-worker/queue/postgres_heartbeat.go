@@ -0,0 +1,86 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PostgresHeartbeatStore implements HeartbeatStore on the
+// email_worker_heartbeats table (see migration 008)
+type PostgresHeartbeatStore struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewPostgresHeartbeatStore creates a new PostgresHeartbeatStore instance
+func NewPostgresHeartbeatStore(db *sql.DB, logger *zap.Logger) *PostgresHeartbeatStore {
+	return &PostgresHeartbeatStore{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Beat stores a heartbeat, replacing the worker's previous one
+func (s *PostgresHeartbeatStore) Beat(ctx context.Context, heartbeat *Heartbeat) error {
+	jobs, err := json.Marshal(heartbeat.Jobs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal heartbeat jobs: %w", err)
+	}
+
+	query := `
+		INSERT INTO email_worker_heartbeats (worker, jobs, beat_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (worker) DO UPDATE SET jobs = EXCLUDED.jobs, beat_at = EXCLUDED.beat_at
+	`
+	if _, err := s.db.ExecContext(ctx, query, heartbeat.Worker, jobs, heartbeat.BeatAt.UTC()); err != nil {
+		return fmt.Errorf("failed to store heartbeat: %w", err)
+	}
+	return nil
+}
+
+// Expired returns the heartbeats that were not renewed since before
+func (s *PostgresHeartbeatStore) Expired(ctx context.Context, before time.Time) ([]*Heartbeat, error) {
+	query := `SELECT worker, jobs, beat_at FROM email_worker_heartbeats WHERE beat_at < $1`
+	rows, err := s.db.QueryContext(ctx, query, before.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired heartbeats: %w", err)
+	}
+	defer rows.Close()
+
+	var expired []*Heartbeat
+	for rows.Next() {
+		var heartbeat Heartbeat
+		var jobs []byte
+		if err := rows.Scan(&heartbeat.Worker, &jobs, &heartbeat.BeatAt); err != nil {
+			return nil, fmt.Errorf("failed to scan heartbeat: %w", err)
+		}
+		if err := json.Unmarshal(jobs, &heartbeat.Jobs); err != nil {
+			s.logger.Error("Failed to unmarshal heartbeat jobs", zap.String("worker", heartbeat.Worker), zap.Error(err))
+			continue
+		}
+		expired = append(expired, &heartbeat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list expired heartbeats: %w", err)
+	}
+	return expired, nil
+}
+
+// Remove deletes a heartbeat unless the worker has beaten again since
+func (s *PostgresHeartbeatStore) Remove(ctx context.Context, heartbeat *Heartbeat) error {
+	query := `DELETE FROM email_worker_heartbeats WHERE worker = $1 AND beat_at = $2`
+	if _, err := s.db.ExecContext(ctx, query, heartbeat.Worker, heartbeat.BeatAt.UTC()); err != nil {
+		return fmt.Errorf("failed to remove heartbeat: %w", err)
+	}
+	return nil
+}
+
+// Close closes the store. The database connection is owned by the service.
+func (s *PostgresHeartbeatStore) Close() error {
+	return nil
+}
@@ -0,0 +1,112 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+)
+
+// reserveAttempts bounds how often Reserve retries when a key is released or
+// expires between claiming and reading it
+const reserveAttempts = 3
+
+// PostgresIdempotencyStore implements IdempotencyStore on the
+// email_idempotency_keys table (see migration 006)
+type PostgresIdempotencyStore struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewPostgresIdempotencyStore creates a new PostgresIdempotencyStore instance
+func NewPostgresIdempotencyStore(db *sql.DB, logger *zap.Logger) *PostgresIdempotencyStore {
+	return &PostgresIdempotencyStore{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Reserve claims key for job unless an earlier job still holds it. An expired
+// key is taken over in the same statement.
+func (s *PostgresIdempotencyStore) Reserve(ctx context.Context, key string, job *models.EmailJob, window time.Duration) (*models.EmailJob, error) {
+	payload, err := json.Marshal(idempotentJob(job))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	claim := `
+		INSERT INTO email_idempotency_keys (idempotency_key, job_id, payload, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (idempotency_key) DO UPDATE SET
+			job_id = EXCLUDED.job_id,
+			payload = EXCLUDED.payload,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE email_idempotency_keys.expires_at <= EXCLUDED.created_at
+		RETURNING job_id
+	`
+	lookup := `SELECT payload FROM email_idempotency_keys WHERE idempotency_key = $1`
+
+	for attempt := 0; attempt < reserveAttempts; attempt++ {
+		now := time.Now()
+		var jobID string
+		err := s.db.QueryRowContext(ctx, claim, key, job.ID, payload, now, now.Add(window)).Scan(&jobID)
+		if err == nil {
+			return nil, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+		}
+
+		// The key is held by a live job
+		var existing []byte
+		err = s.db.QueryRowContext(ctx, lookup, key).Scan(&existing)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+
+		var original models.EmailJob
+		if err := json.Unmarshal(existing, &original); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal job: %w", err)
+		}
+		return &original, nil
+	}
+
+	return nil, fmt.Errorf("failed to reserve idempotency key %s: key changed concurrently", key)
+}
+
+// Release frees key if it is held by the given job
+func (s *PostgresIdempotencyStore) Release(ctx context.Context, key, jobID string) error {
+	query := `DELETE FROM email_idempotency_keys WHERE idempotency_key = $1 AND job_id::text = $2`
+	if _, err := s.db.ExecContext(ctx, query, key, jobID); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes keys whose window has passed
+func (s *PostgresIdempotencyStore) DeleteExpired(ctx context.Context) (int, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM email_idempotency_keys WHERE expires_at <= $1`, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(deleted), nil
+}
+
+// Close closes the store. The database connection is owned by the service.
+func (s *PostgresIdempotencyStore) Close() error {
+	return nil
+}
@@ -0,0 +1,182 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PostgresLeaderElector implements LeaderElector with a session advisory lock.
+// The leader holds the lock on a dedicated connection, and each new leader
+// starts a term in the email_leader_terms table (see migration 007) that
+// hands out its fencing token.
+//
+// The lock is released by the server when the leader's session ends. On
+// PostgreSQL 14 and later the session is also ended once the leader has not
+// renewed for the lease TTL, through idle_session_timeout.
+type PostgresLeaderElector struct {
+	db     *sql.DB
+	name   string
+	lockID int64
+	logger *zap.Logger
+
+	mu    sync.Mutex
+	conn  *sql.Conn
+	lease *Lease
+}
+
+// NewPostgresLeaderElector creates a new PostgresLeaderElector instance
+// electing among the replicas of queue name
+func NewPostgresLeaderElector(db *sql.DB, name string, logger *zap.Logger) *PostgresLeaderElector {
+	hash := fnv.New64a()
+	hash.Write([]byte("email-worker:leader:" + name))
+
+	return &PostgresLeaderElector{
+		db:     db,
+		name:   name,
+		lockID: int64(hash.Sum64()),
+		logger: logger,
+	}
+}
+
+// Acquire takes the advisory lock for holder, or renews it by checking that
+// the session holding it is still alive
+func (e *PostgresLeaderElector) Acquire(ctx context.Context, holder string, ttl time.Duration) (*Lease, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn != nil {
+		if e.lease.Holder != holder {
+			return nil, ErrNotLeader
+		}
+		if err := e.renew(ctx, ttl); err != nil {
+			e.logger.Warn("Lost leader session", zap.Error(err))
+			e.dropSession()
+			return nil, ErrNotLeader
+		}
+		lease := *e.lease
+		return &lease, nil
+	}
+
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open leader session: %w", err)
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, e.lockID).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to acquire leader lock: %w", err)
+	}
+	if !locked {
+		conn.Close()
+		return nil, ErrNotLeader
+	}
+
+	query := `
+		INSERT INTO email_leader_terms (name, holder, token, elected_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (name) DO UPDATE SET
+			holder = EXCLUDED.holder,
+			token = email_leader_terms.token + 1,
+			elected_at = EXCLUDED.elected_at
+		RETURNING token
+	`
+	var token int64
+	if err := conn.QueryRowContext(ctx, query, e.name, holder, time.Now()).Scan(&token); err != nil {
+		// Closing the session releases the lock
+		conn.Close()
+		return nil, fmt.Errorf("failed to start leader term: %w", err)
+	}
+
+	e.conn = conn
+	e.lease = &Lease{Holder: holder, Token: token}
+	if err := e.renew(ctx, ttl); err != nil {
+		e.dropSession()
+		return nil, fmt.Errorf("failed to renew leader session: %w", err)
+	}
+
+	lease := *e.lease
+	return &lease, nil
+}
+
+// renew confirms the leader session is alive and extends the lease by ttl
+func (e *PostgresLeaderElector) renew(ctx context.Context, ttl time.Duration) error {
+	expiresAt := time.Now().Add(ttl)
+
+	// idle_session_timeout only exists from PostgreSQL 14, older servers keep
+	// the lock for as long as the session lives
+	timeout := fmt.Sprintf("SET idle_session_timeout = %d", ttl.Milliseconds())
+	if _, err := e.conn.ExecContext(ctx, timeout); err != nil {
+		if _, pingErr := e.conn.ExecContext(ctx, `SELECT 1`); pingErr != nil {
+			return pingErr
+		}
+	}
+
+	e.lease.ExpiresAt = expiresAt
+	return nil
+}
+
+// dropSession closes the leader session, which releases the lock
+func (e *PostgresLeaderElector) dropSession() {
+	if e.conn != nil {
+		e.conn.Close()
+	}
+	e.conn = nil
+	e.lease = nil
+}
+
+// Check returns ErrNotLeader unless lease is the current term and this
+// elector still holds the lock
+func (e *PostgresLeaderElector) Check(ctx context.Context, lease *Lease) error {
+	e.mu.Lock()
+	held := e.lease != nil && e.lease.Token == lease.Token && time.Now().Before(e.lease.ExpiresAt)
+	e.mu.Unlock()
+	if !held {
+		return ErrNotLeader
+	}
+
+	var token int64
+	err := e.db.QueryRowContext(ctx, `SELECT token FROM email_leader_terms WHERE name = $1`, e.name).Scan(&token)
+	if err == sql.ErrNoRows {
+		return ErrNotLeader
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check leader term: %w", err)
+	}
+	if token != lease.Token {
+		return ErrNotLeader
+	}
+	return nil
+}
+
+// Release unlocks the advisory lock if lease is current
+func (e *PostgresLeaderElector) Release(ctx context.Context, lease *Lease) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.lease == nil || e.lease.Token != lease.Token {
+		return nil
+	}
+
+	_, err := e.conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, e.lockID)
+	e.dropSession()
+	if err != nil {
+		return fmt.Errorf("failed to release leader lock: %w", err)
+	}
+	return nil
+}
+
+// Close ends the leader session. The database connection is owned by the service.
+func (e *PostgresLeaderElector) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.dropSession()
+	return nil
+}
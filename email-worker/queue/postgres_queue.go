@@ -0,0 +1,520 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+)
+
+const (
+	// postgresNotifyChannel is the channel the email_jobs trigger notifies on
+	// whenever a job becomes pending (see migration 002)
+	postgresNotifyChannel = "email_jobs_pending"
+
+	defaultPostgresFetchWait = time.Second
+)
+
+// postgresJobColumns lists the email_jobs columns scanned into a job
+const postgresJobColumns = `id, to_emails, cc_emails, bcc_emails, template_name, variables,
+	status, priority, retry_count, max_retries, COALESCE(error_message, ''),
+	processed_at, sent_at, next_attempt_at, attempts, COALESCE(idempotency_key, ''), COALESCE(tenant, ''), recipients, created_at, updated_at`
+
+// PostgresQueue implements the Queue interface on top of the email_jobs table.
+//
+// A pending row is the queue entry: jobs are claimed with
+// SELECT ... FOR UPDATE SKIP LOCKED in priority order, honouring processed_at
+// as the scheduled delivery time, and leased through the locked_until column.
+// A trigger on email_jobs notifies waiting consumers as soon as a job becomes
+// pending, so workers do not have to wait for the next poll.
+type PostgresQueue struct {
+	db                *sql.DB
+	listener          *pq.Listener
+	visibilityTimeout time.Duration
+	fetchWait         time.Duration
+	logger            *zap.Logger
+
+	wakeMu    sync.Mutex
+	wake      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPostgresQueue creates a new PostgresQueue instance. The DSN is used for
+// the dedicated LISTEN connection, jobs are read and written through db.
+func NewPostgresQueue(db *sql.DB, dsn string, visibilityTimeout, fetchWait time.Duration, logger *zap.Logger) (*PostgresQueue, error) {
+	if visibilityTimeout <= 0 {
+		visibilityTimeout = defaultVisibilityTimeout
+	}
+	if fetchWait <= 0 {
+		fetchWait = defaultPostgresFetchWait
+	}
+
+	q := &PostgresQueue{
+		db:                db,
+		visibilityTimeout: visibilityTimeout,
+		fetchWait:         fetchWait,
+		logger:            logger,
+		wake:              make(chan struct{}),
+		done:              make(chan struct{}),
+	}
+
+	q.listener = pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("Postgres queue listener event", zap.Int("event", int(event)), zap.Error(err))
+		}
+	})
+	if err := q.listener.Listen(postgresNotifyChannel); err != nil {
+		q.listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", postgresNotifyChannel, err)
+	}
+
+	go q.forwardNotifications()
+
+	return q, nil
+}
+
+// Publish adds an email job to the queue by upserting it as a pending row.
+// Recipients are bound as native TEXT[] values. Re-publishing a leased job
+// keeps its lease token, so the delivery that re-published it can still ack.
+// A cancelled job stays cancelled, publishing it returns ErrJobNotFound.
+func (q *PostgresQueue) Publish(ctx context.Context, job *models.EmailJob) error {
+	job.SetQueueID(job.ID.String())
+	job.Status = models.JobStatusPending
+	job.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO email_jobs (
+			id, to_emails, cc_emails, bcc_emails, template_name, variables,
+			status, priority, retry_count, max_retries, error_message,
+			processed_at, sent_at, next_attempt_at, attempts, idempotency_key, tenant, recipients, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NULLIF($16, ''), NULLIF($17, ''), $18, $19, $20)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			priority = EXCLUDED.priority,
+			retry_count = EXCLUDED.retry_count,
+			max_retries = EXCLUDED.max_retries,
+			error_message = EXCLUDED.error_message,
+			processed_at = EXCLUDED.processed_at,
+			next_attempt_at = EXCLUDED.next_attempt_at,
+			attempts = EXCLUDED.attempts,
+			recipients = EXCLUDED.recipients,
+			locked_until = NULL,
+			updated_at = EXCLUDED.updated_at
+		WHERE email_jobs.status <> 'cancelled'
+	`
+
+	result, err := q.db.ExecContext(ctx, query,
+		job.ID, pq.Array([]string(job.To)), pq.Array([]string(job.CC)), pq.Array([]string(job.BCC)), job.TemplateName, job.Variables,
+		job.Status, job.Priority, job.RetryCount, job.MaxRetries, job.ErrorMessage,
+		job.ProcessedAt, job.SentAt, job.NextAttemptAt, job.Attempts, job.IdempotencyKey, job.Tenant, job.Recipients, job.CreatedAt, job.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish job: %w", err)
+	}
+
+	published, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if published == 0 {
+		return ErrJobNotFound
+	}
+
+	return nil
+}
+
+// Consume retrieves the next job from the queue and leases it to the caller
+func (q *PostgresQueue) Consume(ctx context.Context) (*models.EmailJob, error) {
+	jobs, err := q.ConsumeBatch(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, ErrQueueEmpty
+	}
+	return jobs[0], nil
+}
+
+// ConsumeBatch claims up to batchSize ready jobs. If none are ready it waits
+// up to the fetch wait for a notification and tries once more.
+func (q *PostgresQueue) ConsumeBatch(ctx context.Context, batchSize int) ([]*models.EmailJob, error) {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	wake := q.wakeChan()
+
+	jobs, err := q.claim(ctx, batchSize, sql.NullInt64{})
+	if err != nil || len(jobs) > 0 {
+		return jobs, err
+	}
+
+	timer := time.NewTimer(q.fetchWait)
+	defer timer.Stop()
+
+	select {
+	case <-wake:
+		return q.claim(ctx, batchSize, sql.NullInt64{})
+	case <-timer.C:
+		return nil, nil
+	case <-ctx.Done():
+		return nil, nil
+	}
+}
+
+// Subscribe signals whenever the email_jobs trigger reports a pending job.
+// Scheduled jobs that become due are not notified, consumers still have to
+// poll for them.
+func (q *PostgresQueue) Subscribe(ctx context.Context) (<-chan struct{}, error) {
+	signals := make(chan struct{}, 1)
+	go func() {
+		defer close(signals)
+		for {
+			select {
+			case <-q.wakeChan():
+				notify(signals)
+			case <-ctx.Done():
+				return
+			case <-q.done:
+				return
+			}
+		}
+	}()
+	return signals, nil
+}
+
+// ConsumeLane claims up to batchSize ready jobs of a single priority. Unlike
+// ConsumeBatch it does not wait, so a worker can move on to the next lane.
+func (q *PostgresQueue) ConsumeLane(ctx context.Context, priority models.JobPriority, batchSize int) ([]*models.EmailJob, error) {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	return q.claim(ctx, batchSize, sql.NullInt64{Int64: int64(priority), Valid: true})
+}
+
+// PromoteAged moves jobs that have been ready for at least agingInterval up
+// one priority, but not above ceiling. processed_at is moved to now, so a
+// promoted job waits agingInterval again before its next promotion.
+func (q *PostgresQueue) PromoteAged(ctx context.Context, agingInterval time.Duration, ceiling models.JobPriority) (int, error) {
+	now := time.Now()
+	query := `
+		UPDATE email_jobs SET priority = priority - 1, processed_at = $1, updated_at = $1
+		WHERE status = 'pending'
+		  AND priority > $2
+		  AND COALESCE(processed_at, created_at) <= $3
+	`
+
+	result, err := q.db.ExecContext(ctx, query, now, int(ceiling), now.Add(-agingInterval))
+	if err != nil {
+		return 0, fmt.Errorf("failed to promote aged jobs: %w", err)
+	}
+
+	promoted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(promoted), nil
+}
+
+// Ack releases the lease of a handled job. The job's status is owned by the
+// email service, which workers record the outcome through before they ack,
+// the queue only stops treating the row as in flight.
+func (q *PostgresQueue) Ack(ctx context.Context, receipt string) error {
+	query := `UPDATE email_jobs SET locked_until = NULL, lease_token = NULL WHERE id = $1 AND lease_token = $2`
+	return q.settle(ctx, "ack", receipt, query)
+}
+
+// Nack puts a leased job back to pending. When requeueAfter is positive the
+// job is only claimable again once that time has passed.
+func (q *PostgresQueue) Nack(ctx context.Context, receipt string, requeueAfter time.Duration) error {
+	var availableAt *time.Time
+	if requeueAfter > 0 {
+		at := time.Now().Add(requeueAfter)
+		availableAt = &at
+	}
+
+	// A job cancelled while leased stays cancelled
+	query := `
+		UPDATE email_jobs
+		SET status = CASE WHEN status = 'cancelled' THEN status ELSE 'pending' END,
+			locked_until = NULL, lease_token = NULL,
+			processed_at = COALESCE($3, processed_at)
+		WHERE id = $1 AND lease_token = $2
+	`
+	return q.settle(ctx, "nack", receipt, query, availableAt)
+}
+
+// ExtendLease pushes the lease deadline of a leased job to extension from now
+func (q *PostgresQueue) ExtendLease(ctx context.Context, receipt string, extension time.Duration) error {
+	query := `UPDATE email_jobs SET locked_until = $3 WHERE id = $1 AND lease_token = $2`
+	return q.settle(ctx, "extend lease of", receipt, query, time.Now().Add(extension))
+}
+
+// Size returns the number of jobs that are ready to be consumed
+func (q *PostgresQueue) Size(ctx context.Context) (int64, error) {
+	query := `
+		SELECT COUNT(*) FROM email_jobs
+		WHERE status = 'pending' AND (processed_at IS NULL OR processed_at <= $1)
+	`
+
+	var size int64
+	if err := q.db.QueryRowContext(ctx, query, time.Now()).Scan(&size); err != nil {
+		return 0, fmt.Errorf("failed to get queue size: %w", err)
+	}
+	return size, nil
+}
+
+// OldestAge returns how long the longest waiting ready job has been pending
+func (q *PostgresQueue) OldestAge(ctx context.Context) (time.Duration, error) {
+	query := `
+		SELECT MIN(COALESCE(processed_at, created_at)) FROM email_jobs
+		WHERE status = 'pending' AND (processed_at IS NULL OR processed_at <= $1)
+	`
+
+	now := time.Now()
+	var oldest sql.NullTime
+	if err := q.db.QueryRowContext(ctx, query, now).Scan(&oldest); err != nil {
+		return 0, fmt.Errorf("failed to get oldest job age: %w", err)
+	}
+	if !oldest.Valid {
+		return 0, nil
+	}
+	return now.Sub(oldest.Time), nil
+}
+
+// Clear cancels all pending jobs. Rows are kept so the job history stays intact.
+func (q *PostgresQueue) Clear(ctx context.Context) error {
+	query := `UPDATE email_jobs SET status = 'cancelled', updated_at = $1 WHERE status = 'pending'`
+	if _, err := q.db.ExecContext(ctx, query, time.Now()); err != nil {
+		return fmt.Errorf("failed to clear queue: %w", err)
+	}
+	return nil
+}
+
+// StoresJobs reports that published jobs are rows of email_jobs
+func (q *PostgresQueue) StoresJobs() bool {
+	return true
+}
+
+// Health checks if the queue is healthy
+func (q *PostgresQueue) Health(ctx context.Context) error {
+	if err := q.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("postgres ping failed: %w", err)
+	}
+	if err := q.listener.Ping(); err != nil {
+		return fmt.Errorf("postgres listener ping failed: %w", err)
+	}
+	return nil
+}
+
+// Close closes the LISTEN connection. The shared database handle is owned by
+// the caller. Closing the queue again does nothing.
+func (q *PostgresQueue) Close() error {
+	var err error
+	q.closeOnce.Do(func() {
+		close(q.done)
+		err = q.listener.Close()
+	})
+	return err
+}
+
+// PublishScheduled publishes a job for scheduled delivery. Scheduled jobs are
+// pending rows whose processed_at lies in the future.
+func (q *PostgresQueue) PublishScheduled(ctx context.Context, job *models.EmailJob, scheduledAt time.Time) error {
+	job.SetScheduledAt(scheduledAt)
+	return q.Publish(ctx, job)
+}
+
+// Cancel marks a pending, scheduled or leased job cancelled. Cancelled rows
+// are never claimed, a worker holding one finds it through Cancelled.
+func (q *PostgresQueue) Cancel(ctx context.Context, jobID string) error {
+	query := `
+		UPDATE email_jobs SET status = 'cancelled', updated_at = $2
+		WHERE id::text = $1 AND (status = 'pending' OR (status = 'processing' AND lease_token IS NOT NULL))
+	`
+
+	result, err := q.db.ExecContext(ctx, query, jobID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to cancel job %s: %w", jobID, err)
+	}
+
+	cancelled, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if cancelled == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// Cancelled reports whether a leased job was cancelled
+func (q *PostgresQueue) Cancelled(ctx context.Context, jobID string) (bool, error) {
+	var cancelled bool
+	err := q.db.QueryRowContext(ctx, `SELECT status = 'cancelled' FROM email_jobs WHERE id::text = $1`, jobID).Scan(&cancelled)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check cancellation of job %s: %w", jobID, err)
+	}
+	return cancelled, nil
+}
+
+// Reschedule moves the delivery time of a pending or scheduled job
+func (q *PostgresQueue) Reschedule(ctx context.Context, jobID string, scheduledAt time.Time) error {
+	query := `
+		UPDATE email_jobs SET processed_at = $2, updated_at = $3
+		WHERE id::text = $1 AND status = 'pending'
+		RETURNING id
+	`
+
+	var id string
+	err := q.db.QueryRowContext(ctx, query, jobID, scheduledAt, time.Now()).Scan(&id)
+	if err == nil {
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to reschedule job %s: %w", jobID, err)
+	}
+
+	var status string
+	err = q.db.QueryRowContext(ctx, `SELECT status FROM email_jobs WHERE id::text = $1`, jobID).Scan(&status)
+	if err == nil && status == string(models.JobStatusProcessing) {
+		return ErrJobLeased
+	}
+	return ErrJobNotFound
+}
+
+// ProcessScheduledJobs returns jobs whose lease has expired to the pending
+// state. Scheduled jobs need no moving, they become claimable once due.
+func (q *PostgresQueue) ProcessScheduledJobs(ctx context.Context) error {
+	query := `
+		UPDATE email_jobs SET status = 'pending', locked_until = NULL, lease_token = NULL, updated_at = $1
+		WHERE status = 'processing' AND locked_until IS NOT NULL AND locked_until < $1
+	`
+
+	result, err := q.db.ExecContext(ctx, query, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to reclaim expired jobs: %w", err)
+	}
+
+	if reclaimed, err := result.RowsAffected(); err == nil && reclaimed > 0 {
+		q.logger.Warn("Reclaimed jobs with expired lease", zap.Int64("count", reclaimed))
+	}
+
+	return nil
+}
+
+// claim locks and leases up to limit ready jobs in priority order, restricted
+// to a single priority when lane is set
+func (q *PostgresQueue) claim(ctx context.Context, limit int, lane sql.NullInt64) ([]*models.EmailJob, error) {
+	now := time.Now()
+	token := uuid.NewString()
+	query := `
+		UPDATE email_jobs SET status = 'processing', locked_until = $1, lease_token = $4, updated_at = $2
+		WHERE id IN (
+			SELECT id FROM email_jobs
+			WHERE status = 'pending'
+			  AND (processed_at IS NULL OR processed_at <= $2)
+			  AND ($5::int IS NULL OR priority = $5)
+			ORDER BY priority ASC, created_at ASC
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + postgresJobColumns
+
+	rows, err := q.db.QueryContext(ctx, query, now.Add(q.visibilityTimeout), now, limit, token, lane)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*models.EmailJob
+	for rows.Next() {
+		var job models.EmailJob
+		err := rows.Scan(
+			&job.ID, pq.Array((*[]string)(&job.To)), pq.Array((*[]string)(&job.CC)), pq.Array((*[]string)(&job.BCC)), &job.TemplateName, &job.Variables,
+			&job.Status, &job.Priority, &job.RetryCount, &job.MaxRetries, &job.ErrorMessage,
+			&job.ProcessedAt, &job.SentAt, &job.NextAttemptAt, &job.Attempts, &job.IdempotencyKey, &job.Tenant, &job.Recipients, &job.CreatedAt, &job.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		job.SetQueueID(job.ID.String())
+		job.SetReceipt(newReceipt(job.QueueID, token))
+		jobs = append(jobs, &job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim jobs: %w", err)
+	}
+
+	// RETURNING does not preserve the ORDER BY of the subquery
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].Priority != jobs[j].Priority {
+			return jobs[i].Priority < jobs[j].Priority
+		}
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+
+	return jobs, nil
+}
+
+// settle runs a lease update for the job a receipt refers to. The query gets the
+// job ID and lease token as $1 and $2, followed by args.
+func (q *PostgresQueue) settle(ctx context.Context, action, receipt, query string, args ...any) error {
+	queueID, token, err := parseReceipt(receipt)
+	if err != nil {
+		return err
+	}
+
+	result, err := q.db.ExecContext(ctx, query, append([]any{queueID, token}, args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to %s job %s: %w", action, queueID, err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to %s job %s: %w", action, queueID, err)
+	}
+	if updated == 0 {
+		return fmt.Errorf("failed to %s job %s: %w", action, queueID, ErrLeaseLost)
+	}
+	return nil
+}
+
+// wakeChan returns the channel that is closed on the next notification
+func (q *PostgresQueue) wakeChan() <-chan struct{} {
+	q.wakeMu.Lock()
+	defer q.wakeMu.Unlock()
+	return q.wake
+}
+
+// forwardNotifications wakes every waiting consumer when a job becomes pending.
+// A nil notification means the listener reconnected and may have missed some,
+// so it wakes consumers as well.
+func (q *PostgresQueue) forwardNotifications() {
+	for {
+		select {
+		case <-q.done:
+			return
+		case _, ok := <-q.listener.Notify:
+			if !ok {
+				return
+			}
+			q.wakeMu.Lock()
+			close(q.wake)
+			q.wake = make(chan struct{})
+			q.wakeMu.Unlock()
+		}
+	}
+}
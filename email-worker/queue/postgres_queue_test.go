@@ -0,0 +1,268 @@
+package queue_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/database/migrations"
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/queue"
+)
+
+// postgresTestDSNEnv names the database the postgres queue tests run against.
+// The tests are skipped when it is not set.
+const postgresTestDSNEnv = "EMAIL_WORKER_TEST_POSTGRES_DSN"
+
+// newTestPostgresDB opens the test database with the migrations run and
+// tables emptied, returning it with its DSN. The test is skipped when the
+// database is not set.
+func newTestPostgresDB(t *testing.T, tables ...string) (*sql.DB, string) {
+	t.Helper()
+
+	dsn := os.Getenv(postgresTestDSNEnv)
+	if dsn == "" || testing.Short() {
+		t.Skipf("%s not set, skipping postgres test", postgresTestDSNEnv)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, migrations.NewMigrationRunner(db).RunMigrations("../database/migrations"))
+	for _, table := range tables {
+		_, err = db.Exec(`DELETE FROM ` + table)
+		require.NoError(t, err)
+	}
+
+	return db, dsn
+}
+
+func newTestPostgresQueue(t *testing.T, visibilityTimeout time.Duration) *queue.PostgresQueue {
+	t.Helper()
+
+	db, dsn := newTestPostgresDB(t, "email_jobs")
+	q, err := queue.NewPostgresQueue(db, dsn, visibilityTimeout, 200*time.Millisecond, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { q.Close() })
+
+	return q
+}
+
+func TestPostgresQueue_CloseTwice(t *testing.T) {
+	q := newTestPostgresQueue(t, time.Minute)
+
+	require.NoError(t, q.Close())
+	assert.NotPanics(t, func() { assert.NoError(t, q.Close()) })
+}
+
+func TestPostgresQueue_ConsumeBatchPriorityOrder(t *testing.T) {
+	q := newTestPostgresQueue(t, time.Minute)
+	ctx := context.Background()
+
+	low := newTestJob(models.JobPriorityLow)
+	normal := newTestJob(models.JobPriorityNormal)
+	high := newTestJob(models.JobPriorityHigh)
+	for _, job := range []*models.EmailJob{low, normal, high} {
+		require.NoError(t, q.Publish(ctx, job))
+	}
+
+	size, err := q.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), size)
+
+	jobs, err := q.ConsumeBatch(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, high.ID, jobs[0].ID)
+	assert.Equal(t, normal.ID, jobs[1].ID)
+	assert.Equal(t, high.To, jobs[0].To)
+	assert.Equal(t, high.ID.String(), jobs[0].QueueID)
+
+	jobs, err = q.ConsumeBatch(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, low.ID, jobs[0].ID)
+}
+
+func TestPostgresQueue_ConcurrentConsumersSkipLockedRows(t *testing.T) {
+	q := newTestPostgresQueue(t, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, q.Publish(ctx, newTestJob(models.JobPriorityNormal)))
+	}
+
+	var mu sync.Mutex
+	seen := make(map[string]int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			jobs, err := q.ConsumeBatch(ctx, 5)
+			assert.NoError(t, err)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, job := range jobs {
+				seen[job.QueueID]++
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, seen, 20)
+	for queueID, count := range seen {
+		assert.Equal(t, 1, count, "job %s claimed more than once", queueID)
+	}
+}
+
+func TestPostgresQueue_ScheduledJobs(t *testing.T) {
+	q := newTestPostgresQueue(t, time.Minute)
+	ctx := context.Background()
+
+	job := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, q.PublishScheduled(ctx, job, time.Now().Add(300*time.Millisecond)))
+
+	jobs, err := q.ConsumeBatch(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	time.Sleep(400 * time.Millisecond)
+
+	consumed, err := q.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, consumed.ID)
+}
+
+func TestPostgresQueue_ReclaimExpiredLease(t *testing.T) {
+	q := newTestPostgresQueue(t, 100*time.Millisecond)
+	ctx := context.Background()
+
+	job := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, q.Publish(ctx, job))
+
+	_, err := q.Consume(ctx)
+	require.NoError(t, err)
+
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+
+	redelivered, err := q.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, redelivered.ID)
+
+	// An acknowledged job is no longer reclaimed
+	require.NoError(t, q.Ack(ctx, redelivered.Receipt))
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+
+	_, err = q.Consume(ctx)
+	assert.Equal(t, queue.ErrQueueEmpty, err)
+}
+
+func TestPostgresQueue_NotifyWakesConsumer(t *testing.T) {
+	q := newTestPostgresQueue(t, time.Minute)
+	ctx := context.Background()
+
+	job := newTestJob(models.JobPriorityNormal)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		q.Publish(ctx, job)
+	}()
+
+	// The job is published while Consume waits, only the notification lets
+	// it claim the job before the fetch wait runs out
+	consumed, err := q.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, consumed.ID)
+}
+
+func TestPostgresQueue_PublishKeepsCancelledJobs(t *testing.T) {
+	q := newTestPostgresQueue(t, time.Minute)
+	ctx := context.Background()
+
+	pending := newTestJob(models.JobPriorityNormal)
+	leased := newTestJob(models.JobPriorityHigh)
+	require.NoError(t, q.Publish(ctx, leased))
+	consumed, err := q.Consume(ctx)
+	require.NoError(t, err)
+	require.NoError(t, q.Publish(ctx, pending))
+
+	require.NoError(t, q.Cancel(ctx, pending.ID.String()))
+	require.NoError(t, q.Cancel(ctx, leased.ID.String()))
+
+	// Neither a cancelled job nor the retry of its delivery makes it pending again
+	assert.ErrorIs(t, q.Publish(ctx, pending), queue.ErrJobNotFound)
+	consumed.IncrementRetry()
+	assert.ErrorIs(t, q.PublishScheduled(ctx, consumed, time.Now()), queue.ErrJobNotFound)
+
+	cancelled, err := q.Cancelled(ctx, leased.ID.String())
+	require.NoError(t, err)
+	assert.True(t, cancelled)
+	_, err = q.Consume(ctx)
+	assert.Equal(t, queue.ErrQueueEmpty, err)
+}
+
+func TestPostgresQueue_LanesAndAging(t *testing.T) {
+	q := newTestPostgresQueue(t, time.Minute)
+	ctx := context.Background()
+
+	urgent := newTestJob(models.JobPriorityUrgent)
+	normal := newTestJob(models.JobPriorityNormal)
+	low := newTestJob(models.JobPriorityLow)
+	for _, job := range []*models.EmailJob{urgent, normal, low} {
+		require.NoError(t, q.Publish(ctx, job))
+	}
+
+	promoted, err := q.PromoteAged(ctx, time.Hour, models.JobPriorityHigh)
+	require.NoError(t, err)
+	assert.Equal(t, 0, promoted)
+
+	promoted, err = q.PromoteAged(ctx, 0, models.JobPriorityHigh)
+	require.NoError(t, err)
+	assert.Equal(t, 2, promoted)
+
+	jobs, err := q.ConsumeLane(ctx, models.JobPriorityNormal, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, low.ID, jobs[0].ID)
+	assert.Equal(t, models.JobPriorityNormal, jobs[0].Priority)
+
+	jobs, err = q.ConsumeLane(ctx, models.JobPriorityHigh, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, normal.ID, jobs[0].ID)
+
+	jobs, err = q.ConsumeLane(ctx, models.JobPriorityUrgent, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, urgent.ID, jobs[0].ID)
+}
+
+func TestPostgresQueue_OldestAge(t *testing.T) {
+	q := newTestPostgresQueue(t, time.Minute)
+	ctx := context.Background()
+
+	age, err := q.OldestAge(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, age)
+
+	job := newTestJob(models.JobPriorityLow)
+	job.CreatedAt = time.Now().Add(-time.Minute)
+	require.NoError(t, q.Publish(ctx, job))
+
+	age, err = q.OldestAge(ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, age, time.Minute)
+}
@@ -0,0 +1,109 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter takes send tokens from token buckets shared by the replicas,
+// so a send rate holds for the whole service rather than each replica
+type RateLimiter interface {
+	// Take takes a token from the bucket of key, which holds up to burst
+	// tokens and refills at rate tokens per second. It returns the tokens
+	// left, and how long until a token is available when there was none.
+	Take(ctx context.Context, key string, rate float64, burst int) (float64, time.Duration, error)
+
+	// Close closes the limiter
+	Close() error
+}
+
+// CreateRateLimiter creates the rate limiter matching a queue configuration.
+// Only Redis shares buckets between replicas, the other backends limit each
+// replica on its own.
+func (f *QueueFactory) CreateRateLimiter(config QueueConfig) (RateLimiter, error) {
+	switch config.Type {
+	case "redis":
+		addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
+		return NewRedisRateLimiter(addr, config.Password, config.Database, config.QueueName, f.logger), nil
+	case "memory", "postgres", "kafka":
+		return NewMemoryRateLimiter(), nil
+	default:
+		return nil, fmt.Errorf("unsupported queue type: %s", config.Type)
+	}
+}
+
+// refill returns the tokens of a bucket that held tokens elapsed ago, then
+// takes one if it can. It returns the tokens left and how long until a token
+// is available when there was none.
+func refill(tokens float64, elapsed time.Duration, rate float64, burst int) (float64, time.Duration) {
+	tokens = math.Min(float64(burst), tokens+elapsed.Seconds()*rate)
+	if tokens >= 1 {
+		return tokens - 1, 0
+	}
+	return tokens, time.Duration((1 - tokens) / rate * float64(time.Second))
+}
+
+// maxMemoryBuckets is how many buckets the memory limiter holds before it
+// forgets the full ones
+const maxMemoryBuckets = 10000
+
+// MemoryRateLimiter implements RateLimiter in process memory
+type MemoryRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+}
+
+// memoryBucket is the tokens of a bucket when it was last taken from
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	full      time.Duration
+}
+
+// NewMemoryRateLimiter creates a new MemoryRateLimiter instance
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		buckets: make(map[string]*memoryBucket),
+	}
+}
+
+// Take takes a token from the bucket of key
+func (l *MemoryRateLimiter) Take(ctx context.Context, key string, rate float64, burst int) (float64, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	bucket, ok := l.buckets[key]
+	if !ok {
+		l.prune(now)
+		bucket = &memoryBucket{tokens: float64(burst), updatedAt: now}
+		l.buckets[key] = bucket
+	}
+
+	var wait time.Duration
+	bucket.tokens, wait = refill(bucket.tokens, now.Sub(bucket.updatedAt), rate, burst)
+	bucket.updatedAt = now
+	bucket.full = time.Duration(float64(burst) / rate * float64(time.Second))
+	return bucket.tokens, wait, nil
+}
+
+// prune forgets the buckets that have filled up again once there are too
+// many, a new bucket starts full anyway. l.mu must be held.
+func (l *MemoryRateLimiter) prune(now time.Time) {
+	if len(l.buckets) < maxMemoryBuckets {
+		return
+	}
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.updatedAt) >= bucket.full {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Close does nothing, the memory limiter holds no resources
+func (l *MemoryRateLimiter) Close() error {
+	return nil
+}
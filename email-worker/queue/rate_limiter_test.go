@@ -0,0 +1,87 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/queue"
+)
+
+// testRateLimiter runs the behaviour every rate limiter must have
+func testRateLimiter(t *testing.T, limiter queue.RateLimiter) {
+	ctx := context.Background()
+
+	// A new bucket starts full
+	for i := 2; i >= 0; i-- {
+		tokens, wait, err := limiter.Take(ctx, "provider:ses", 10, 3)
+		require.NoError(t, err)
+		assert.Zero(t, wait)
+		assert.InDelta(t, float64(i), tokens, 0.2)
+	}
+
+	// An empty bucket tells how long until the next token
+	_, wait, err := limiter.Take(ctx, "provider:ses", 10, 3)
+	require.NoError(t, err)
+	assert.Greater(t, wait, time.Duration(0))
+	assert.LessOrEqual(t, wait, 100*time.Millisecond)
+
+	// Buckets are independent
+	_, wait, err = limiter.Take(ctx, "domain:gmail.com", 10, 3)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+
+	// The bucket refills at its rate
+	time.Sleep(150 * time.Millisecond)
+	_, wait, err = limiter.Take(ctx, "provider:ses", 10, 3)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+}
+
+func TestMemoryRateLimiter(t *testing.T) {
+	testRateLimiter(t, queue.NewMemoryRateLimiter())
+}
+
+func TestRedisRateLimiter(t *testing.T) {
+	mr := miniredis.RunT(t)
+	limiter := queue.NewRedisRateLimiter(mr.Addr(), "", 0, "email-jobs", zap.NewNop())
+	t.Cleanup(func() { limiter.Close() })
+
+	testRateLimiter(t, limiter)
+	assert.True(t, mr.Exists("email-jobs:ratelimit:provider:ses"))
+}
+
+func TestRedisRateLimiter_SharesBucketsBetweenReplicas(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	first := queue.NewRedisRateLimiter(mr.Addr(), "", 0, "email-jobs", zap.NewNop())
+	second := queue.NewRedisRateLimiter(mr.Addr(), "", 0, "email-jobs", zap.NewNop())
+	t.Cleanup(func() { first.Close(); second.Close() })
+
+	_, wait, err := first.Take(ctx, "provider:ses", 1, 1)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	_, wait, err = second.Take(ctx, "provider:ses", 1, 1)
+	require.NoError(t, err)
+	assert.Greater(t, wait, time.Duration(0), "the other replica emptied the bucket")
+}
+
+func TestRedisRateLimiter_FallsBackToMemory(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	limiter := queue.NewRedisRateLimiter(mr.Addr(), "", 0, "email-jobs", zap.NewNop())
+	t.Cleanup(func() { limiter.Close() })
+	mr.Close()
+
+	_, wait, err := limiter.Take(ctx, "provider:ses", 1, 1)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	_, wait, err = limiter.Take(ctx, "provider:ses", 1, 1)
+	require.NoError(t, err)
+	assert.Greater(t, wait, time.Duration(0), "the fallback limits the replica")
+}
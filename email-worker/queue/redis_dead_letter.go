@@ -0,0 +1,151 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// Redis key layout of the dead-letter store (prefixed with the queue name):
+//
+//	<name>:dead        hash  job ID -> dead letter (JSON)
+//	<name>:dead:index  zset  job ID scored by dead-lettering time (unix ms)
+const (
+	redisDeadSuffix      = ":dead"
+	redisDeadIndexSuffix = ":dead:index"
+)
+
+// RedisDeadLetterStore implements DeadLetterStore next to a RedisQueue
+type RedisDeadLetterStore struct {
+	client    *redis.Client
+	queueName string
+	logger    *zap.Logger
+}
+
+// NewRedisDeadLetterStore creates a new RedisDeadLetterStore instance
+func NewRedisDeadLetterStore(addr, password string, database int, queueName string, logger *zap.Logger) *RedisDeadLetterStore {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       database,
+	})
+
+	return &RedisDeadLetterStore{
+		client:    client,
+		queueName: queueName,
+		logger:    logger,
+	}
+}
+
+// Add stores a dead letter, replacing an earlier one for the same job
+func (s *RedisDeadLetterStore) Add(ctx context.Context, entry *DeadLetter) error {
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, s.key(redisDeadSuffix), entry.JobID(), payload)
+	pipe.ZAdd(ctx, s.key(redisDeadIndexSuffix), &redis.Z{
+		Score:  float64(entry.DeadLetteredAt.UnixMilli()),
+		Member: entry.JobID(),
+	})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to add dead letter: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the dead letter of a job
+func (s *RedisDeadLetterStore) Get(ctx context.Context, jobID string) (*DeadLetter, error) {
+	payload, err := s.client.HGet(ctx, s.key(redisDeadSuffix), jobID).Result()
+	if err == redis.Nil {
+		return nil, ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+
+	var entry DeadLetter
+	if err := json.Unmarshal([]byte(payload), &entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dead letter: %w", err)
+	}
+	return &entry, nil
+}
+
+// List returns the dead letters selected by filter, newest first
+func (s *RedisDeadLetterStore) List(ctx context.Context, filter DeadLetterFilter) ([]*DeadLetter, error) {
+	rangeBy := &redis.ZRangeBy{Min: "-inf", Max: "+inf"}
+	if !filter.Since.IsZero() {
+		rangeBy.Min = fmt.Sprintf("%d", filter.Since.UnixMilli())
+	}
+	if !filter.Until.IsZero() {
+		rangeBy.Max = fmt.Sprintf("%d", filter.Until.UnixMilli())
+	}
+
+	jobIDs, err := s.client.ZRevRangeByScore(ctx, s.key(redisDeadIndexSuffix), rangeBy).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	if len(jobIDs) == 0 {
+		return []*DeadLetter{}, nil
+	}
+
+	payloads, err := s.client.HMGet(ctx, s.key(redisDeadSuffix), jobIDs...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letters: %w", err)
+	}
+
+	entries := make([]*DeadLetter, 0, len(payloads))
+	for i, payload := range payloads {
+		raw, ok := payload.(string)
+		if !ok {
+			continue
+		}
+
+		var entry DeadLetter
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			s.logger.Error("Failed to unmarshal dead letter", zap.String("job_id", jobIDs[i]), zap.Error(err))
+			continue
+		}
+		if filter.Matches(&entry) {
+			entries = append(entries, &entry)
+		}
+	}
+
+	return filter.page(entries), nil
+}
+
+// Delete removes the dead letters of the given jobs
+func (s *RedisDeadLetterStore) Delete(ctx context.Context, jobIDs ...string) (int, error) {
+	if len(jobIDs) == 0 {
+		return 0, nil
+	}
+
+	pipe := s.client.TxPipeline()
+	deleted := pipe.HDel(ctx, s.key(redisDeadSuffix), jobIDs...)
+	members := make([]any, len(jobIDs))
+	for i, jobID := range jobIDs {
+		members[i] = jobID
+	}
+	pipe.ZRem(ctx, s.key(redisDeadIndexSuffix), members...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to delete dead letters: %w", err)
+	}
+
+	return int(deleted.Val()), nil
+}
+
+// Close closes the Redis connection
+func (s *RedisDeadLetterStore) Close() error {
+	return s.client.Close()
+}
+
+// key returns the Redis key for the given suffix
+func (s *RedisDeadLetterStore) key(suffix string) string {
+	return s.queueName + suffix
+}
@@ -0,0 +1,129 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// Redis key layout of the heartbeat store (prefixed with the queue name):
+//
+//	<name>:heartbeats        hash  worker -> heartbeat (JSON)
+//	<name>:heartbeats:index  zset  worker scored by beat time (unix ms)
+const (
+	redisHeartbeatsSuffix      = ":heartbeats"
+	redisHeartbeatsIndexSuffix = ":heartbeats:index"
+)
+
+// removeHeartbeatScript deletes worker ARGV[1] from the hash KEYS[1] and the
+// index KEYS[2] if it last beat at ARGV[2]
+var removeHeartbeatScript = redis.NewScript(`
+local score = redis.call('ZSCORE', KEYS[2], ARGV[1])
+if score and tonumber(score) == tonumber(ARGV[2]) then
+	redis.call('HDEL', KEYS[1], ARGV[1])
+	return redis.call('ZREM', KEYS[2], ARGV[1])
+end
+return 0
+`)
+
+// RedisHeartbeatStore implements HeartbeatStore next to a RedisQueue
+type RedisHeartbeatStore struct {
+	client    *redis.Client
+	queueName string
+	logger    *zap.Logger
+}
+
+// NewRedisHeartbeatStore creates a new RedisHeartbeatStore instance
+func NewRedisHeartbeatStore(addr, password string, database int, queueName string, logger *zap.Logger) *RedisHeartbeatStore {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       database,
+	})
+
+	return &RedisHeartbeatStore{
+		client:    client,
+		queueName: queueName,
+		logger:    logger,
+	}
+}
+
+// Beat stores a heartbeat, replacing the worker's previous one
+func (s *RedisHeartbeatStore) Beat(ctx context.Context, heartbeat *Heartbeat) error {
+	payload, err := json.Marshal(heartbeat)
+	if err != nil {
+		return fmt.Errorf("failed to marshal heartbeat: %w", err)
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, s.key(redisHeartbeatsSuffix), heartbeat.Worker, payload)
+	pipe.ZAdd(ctx, s.key(redisHeartbeatsIndexSuffix), &redis.Z{
+		Score:  float64(heartbeat.BeatAt.UnixMilli()),
+		Member: heartbeat.Worker,
+	})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store heartbeat: %w", err)
+	}
+	return nil
+}
+
+// Expired returns the heartbeats that were not renewed since before
+func (s *RedisHeartbeatStore) Expired(ctx context.Context, before time.Time) ([]*Heartbeat, error) {
+	workers, err := s.client.ZRangeByScore(ctx, s.key(redisHeartbeatsIndexSuffix), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: "(" + strconv.FormatInt(before.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired heartbeats: %w", err)
+	}
+	if len(workers) == 0 {
+		return nil, nil
+	}
+
+	payloads, err := s.client.HMGet(ctx, s.key(redisHeartbeatsSuffix), workers...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired heartbeats: %w", err)
+	}
+
+	expired := make([]*Heartbeat, 0, len(payloads))
+	for i, payload := range payloads {
+		data, ok := payload.(string)
+		if !ok {
+			// Removed since it was listed
+			continue
+		}
+
+		var heartbeat Heartbeat
+		if err := json.Unmarshal([]byte(data), &heartbeat); err != nil {
+			s.logger.Error("Failed to unmarshal heartbeat", zap.String("worker", workers[i]), zap.Error(err))
+			continue
+		}
+		expired = append(expired, &heartbeat)
+	}
+	return expired, nil
+}
+
+// Remove deletes a heartbeat unless the worker has beaten again since
+func (s *RedisHeartbeatStore) Remove(ctx context.Context, heartbeat *Heartbeat) error {
+	keys := []string{s.key(redisHeartbeatsSuffix), s.key(redisHeartbeatsIndexSuffix)}
+	score := strconv.FormatInt(heartbeat.BeatAt.UnixMilli(), 10)
+	if err := removeHeartbeatScript.Run(ctx, s.client, keys, heartbeat.Worker, score).Err(); err != nil {
+		return fmt.Errorf("failed to remove heartbeat: %w", err)
+	}
+	return nil
+}
+
+// Close closes the store connection
+func (s *RedisHeartbeatStore) Close() error {
+	return s.client.Close()
+}
+
+// key builds a Redis key of the store
+func (s *RedisHeartbeatStore) key(suffix string) string {
+	return s.queueName + suffix
+}
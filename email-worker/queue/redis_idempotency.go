@@ -0,0 +1,104 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+)
+
+// Redis key layout of the idempotency store (prefixed with the queue name):
+//
+//	<name>:idempotency:<key>  hash  job_id, payload (JSON), expiring with the window
+const redisIdempotencySuffix = ":idempotency:"
+
+// reserveScript stores job ARGV[1] with payload ARGV[2] under KEYS[1] for
+// ARGV[3] milliseconds unless the key exists, in which case it returns the
+// payload of the job holding it
+var reserveScript = redis.NewScript(`
+local payload = redis.call('HGET', KEYS[1], 'payload')
+if payload then
+	return payload
+end
+redis.call('HSET', KEYS[1], 'job_id', ARGV[1], 'payload', ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return false
+`)
+
+// releaseKeyScript deletes KEYS[1] if it is held by job ARGV[1]
+var releaseKeyScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'job_id') == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisIdempotencyStore implements IdempotencyStore next to a RedisQueue.
+// Keys expire on their own once the window has passed.
+type RedisIdempotencyStore struct {
+	client    *redis.Client
+	queueName string
+	logger    *zap.Logger
+}
+
+// NewRedisIdempotencyStore creates a new RedisIdempotencyStore instance
+func NewRedisIdempotencyStore(addr, password string, database int, queueName string, logger *zap.Logger) *RedisIdempotencyStore {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       database,
+	})
+
+	return &RedisIdempotencyStore{
+		client:    client,
+		queueName: queueName,
+		logger:    logger,
+	}
+}
+
+// Reserve claims key for job unless an earlier job still holds it
+func (s *RedisIdempotencyStore) Reserve(ctx context.Context, key string, job *models.EmailJob, window time.Duration) (*models.EmailJob, error) {
+	payload, err := json.Marshal(idempotentJob(job))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	keys := []string{s.key(key)}
+	existing, err := reserveScript.Run(ctx, s.client, keys, job.ID.String(), payload, window.Milliseconds()).Text()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	return decodeJob(existing)
+}
+
+// Release frees key if it is held by the given job
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key, jobID string) error {
+	if err := releaseKeyScript.Run(ctx, s.client, []string{s.key(key)}, jobID).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired does nothing, Redis expires keys by itself
+func (s *RedisIdempotencyStore) DeleteExpired(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// Close closes the store connection
+func (s *RedisIdempotencyStore) Close() error {
+	return s.client.Close()
+}
+
+// key builds the Redis key of an idempotency key
+func (s *RedisIdempotencyStore) key(key string) string {
+	return s.queueName + redisIdempotencySuffix + key
+}
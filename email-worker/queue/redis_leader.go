@@ -0,0 +1,113 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// Redis key layout of the leader elector (prefixed with the queue name):
+//
+//	<name>:leader        hash    holder, token, expiring with the lease
+//	<name>:leader:token  string  last fencing token handed out
+const (
+	redisLeaderSuffix      = ":leader"
+	redisLeaderTokenSuffix = ":leader:token"
+)
+
+// acquireLeaseScript renews the lease KEYS[1] for holder ARGV[1] by ARGV[2]
+// milliseconds, or takes it with the next token of KEYS[2] if nobody holds it.
+// It returns the token, or false while another holder leads.
+var acquireLeaseScript = redis.NewScript(`
+local holder = redis.call('HGET', KEYS[1], 'holder')
+if holder and holder ~= ARGV[1] then
+	return false
+end
+local token
+if holder then
+	token = tonumber(redis.call('HGET', KEYS[1], 'token'))
+else
+	token = redis.call('INCR', KEYS[2])
+	redis.call('HSET', KEYS[1], 'holder', ARGV[1], 'token', token)
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return token
+`)
+
+// releaseLeaseScript deletes the lease KEYS[1] if it has token ARGV[1]
+var releaseLeaseScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'token') == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisLeaderElector implements LeaderElector with an expiring lease next to
+// a RedisQueue. A leader that stops renewing loses the lease after its TTL.
+type RedisLeaderElector struct {
+	client    *redis.Client
+	queueName string
+	logger    *zap.Logger
+}
+
+// NewRedisLeaderElector creates a new RedisLeaderElector instance
+func NewRedisLeaderElector(addr, password string, database int, queueName string, logger *zap.Logger) *RedisLeaderElector {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       database,
+	})
+
+	return &RedisLeaderElector{
+		client:    client,
+		queueName: queueName,
+		logger:    logger,
+	}
+}
+
+// Acquire takes or renews the lease for holder
+func (e *RedisLeaderElector) Acquire(ctx context.Context, holder string, ttl time.Duration) (*Lease, error) {
+	keys := []string{e.queueName + redisLeaderSuffix, e.queueName + redisLeaderTokenSuffix}
+	expiresAt := time.Now().Add(ttl)
+
+	token, err := acquireLeaseScript.Run(ctx, e.client, keys, holder, ttl.Milliseconds()).Int64()
+	if err == redis.Nil {
+		return nil, ErrNotLeader
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire leader lease: %w", err)
+	}
+
+	return &Lease{Holder: holder, Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// Check returns ErrNotLeader unless lease is current
+func (e *RedisLeaderElector) Check(ctx context.Context, lease *Lease) error {
+	token, err := e.client.HGet(ctx, e.queueName+redisLeaderSuffix, "token").Int64()
+	if err == redis.Nil {
+		return ErrNotLeader
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check leader lease: %w", err)
+	}
+	if token != lease.Token {
+		return ErrNotLeader
+	}
+	return nil
+}
+
+// Release gives up lease if it is current
+func (e *RedisLeaderElector) Release(ctx context.Context, lease *Lease) error {
+	if err := releaseLeaseScript.Run(ctx, e.client, []string{e.queueName + redisLeaderSuffix}, lease.Token).Err(); err != nil {
+		return fmt.Errorf("failed to release leader lease: %w", err)
+	}
+	return nil
+}
+
+// Close closes the elector connection
+func (e *RedisLeaderElector) Close() error {
+	return e.client.Close()
+}
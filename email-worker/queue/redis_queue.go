@@ -0,0 +1,798 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+)
+
+// Redis key layout (all keys are prefixed with the queue name):
+//
+//	<name>:jobs       hash  queue ID -> job payload (JSON)
+//	<name>:pending    zset  queue ID scored by priority, then enqueue time
+//	<name>:processing zset  queue ID scored by lease deadline (unix ms)
+//	<name>:scheduled  zset  queue ID scored by scheduled time (unix ms)
+//	<name>:leases     hash  queue ID -> lease token of the current delivery
+//	<name>:ids        hash  job ID -> queue ID of its latest publish
+//	<name>:cancelled  set   job IDs cancelled while leased
+//	<name>:signal     list  wake-up tokens for subscribers, one per ready job
+const (
+	redisJobsSuffix       = ":jobs"
+	redisPendingSuffix    = ":pending"
+	redisProcessingSuffix = ":processing"
+	redisScheduledSuffix  = ":scheduled"
+	redisLeasesSuffix     = ":leases"
+	redisIDsSuffix        = ":ids"
+	redisCancelledSuffix  = ":cancelled"
+	redisSignalSuffix     = ":signal"
+
+	// priorityScoreWeight separates priorities in the pending set so that a
+	// lower priority value always sorts first, and FIFO order is kept within
+	// a priority through the enqueue timestamp.
+	priorityScoreWeight = 1e13
+
+	// maxMovesPerRun bounds how many entries a single scheduled/reclaim pass moves
+	maxMovesPerRun = 500
+
+	// maxSignals bounds the wake-up tokens kept while no subscriber is waiting
+	maxSignals = 1000
+
+	// subscribeBlock is how long a subscriber blocks on the signal list per call
+	subscribeBlock = 5 * time.Second
+
+	defaultVisibilityTimeout = 5 * time.Minute
+)
+
+// consumeScript atomically pops up to ARGV[1] jobs from the pending set and
+// leases them in the processing set until ARGV[2] under lease token ARGV[3].
+var consumeScript = redis.NewScript(`
+local popped = redis.call('ZPOPMIN', KEYS[1], ARGV[1])
+local payloads = {}
+for i = 1, #popped, 2 do
+	local id = popped[i]
+	local payload = redis.call('HGET', KEYS[3], id)
+	if payload then
+		redis.call('ZADD', KEYS[2], ARGV[2], id)
+		redis.call('HSET', KEYS[4], id, ARGV[3])
+		table.insert(payloads, payload)
+	end
+end
+return payloads
+`)
+
+// consumeRangeScript atomically pops up to ARGV[1] jobs scored between ARGV[4]
+// and ARGV[5] from the pending set and leases them like consumeScript.
+var consumeRangeScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], ARGV[4], ARGV[5], 'LIMIT', 0, ARGV[1])
+local payloads = {}
+for _, id in ipairs(ids) do
+	redis.call('ZREM', KEYS[1], id)
+	local payload = redis.call('HGET', KEYS[3], id)
+	if payload then
+		redis.call('ZADD', KEYS[2], ARGV[2], id)
+		redis.call('HSET', KEYS[4], id, ARGV[3])
+		table.insert(payloads, payload)
+	end
+end
+return payloads
+`)
+
+// promoteScript stores the promoted payload ARGV[3] of pending job ARGV[1] and
+// rescores it with ARGV[4], if its score is still ARGV[2]
+var promoteScript = redis.NewScript(`
+local score = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not score or tonumber(score) ~= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[3])
+redis.call('ZADD', KEYS[1], ARGV[4], ARGV[1])
+return 1
+`)
+
+// moveScript moves a member between two sorted sets only if it is still in the
+// source set, and drops any lease token it still has in KEYS[3]
+var moveScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 1 then
+	redis.call('ZADD', KEYS[2], ARGV[2], ARGV[1])
+	redis.call('HDEL', KEYS[3], ARGV[1])
+	return 1
+end
+return 0
+`)
+
+// ackScript deletes a leased job if lease token ARGV[2] still holds its lease,
+// together with its job ID index entry and cancellation mark
+var ackScript = redis.NewScript(`
+if redis.call('HGET', KEYS[3], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+local payload = redis.call('HGET', KEYS[2], ARGV[1])
+if payload then
+	local jobID = cjson.decode(payload).id
+	if redis.call('HGET', KEYS[4], jobID) == ARGV[1] then
+		redis.call('HDEL', KEYS[4], jobID)
+	end
+	redis.call('SREM', KEYS[5], jobID)
+end
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+return 1
+`)
+
+// releaseScript moves a leased job to the sorted set KEYS[3] with score ARGV[3]
+// if lease token ARGV[2] still holds its lease
+var releaseScript = redis.NewScript(`
+if redis.call('HGET', KEYS[2], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('ZADD', KEYS[3], ARGV[3], ARGV[1])
+return 1
+`)
+
+// extendScript moves the lease deadline to ARGV[3] if lease token ARGV[2] still holds the lease
+var extendScript = redis.NewScript(`
+if redis.call('HGET', KEYS[2], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[1])
+return 1
+`)
+
+// cancelScript removes job ARGV[1] from the pending and scheduled sets, or
+// marks it cancelled if it is leased. It returns 0 if the job is not queued.
+var cancelScript = redis.NewScript(`
+local id = redis.call('HGET', KEYS[1], ARGV[1])
+if not id then
+	return 0
+end
+if redis.call('ZREM', KEYS[3], id) + redis.call('ZREM', KEYS[4], id) > 0 then
+	redis.call('HDEL', KEYS[2], id)
+	redis.call('HDEL', KEYS[1], ARGV[1])
+	return 1
+end
+if redis.call('ZSCORE', KEYS[5], id) then
+	redis.call('SADD', KEYS[6], ARGV[1])
+	return 1
+end
+redis.call('HDEL', KEYS[1], ARGV[1])
+return 0
+`)
+
+// rescheduleScript moves job ARGV[1], published as ARGV[2], from the pending
+// or scheduled set to KEYS[6] with score ARGV[4] and stores payload ARGV[3].
+// It returns 0 if the job is not queued and 2 if it is leased.
+var rescheduleScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+if redis.call('ZSCORE', KEYS[5], ARGV[2]) then
+	return 2
+end
+if redis.call('ZREM', KEYS[3], ARGV[2]) + redis.call('ZREM', KEYS[4], ARGV[2]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[2], ARGV[2], ARGV[3])
+redis.call('ZADD', KEYS[6], ARGV[4], ARGV[2])
+return 1
+`)
+
+// RedisQueue implements the Queue interface for Redis
+type RedisQueue struct {
+	client            *redis.Client
+	queueName         string
+	visibilityTimeout time.Duration
+	logger            *zap.Logger
+}
+
+// NewRedisQueue creates a new RedisQueue instance
+func NewRedisQueue(addr, password string, database int, queueName string, visibilityTimeout time.Duration, logger *zap.Logger) *RedisQueue {
+	if visibilityTimeout <= 0 {
+		visibilityTimeout = defaultVisibilityTimeout
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       database,
+	})
+
+	return &RedisQueue{
+		client:            client,
+		queueName:         queueName,
+		visibilityTimeout: visibilityTimeout,
+		logger:            logger,
+	}
+}
+
+// Publish adds an email job to the queue
+func (q *RedisQueue) Publish(ctx context.Context, job *models.EmailJob) error {
+	job.SetQueueID(uuid.NewString())
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	pipe := q.client.TxPipeline()
+	pipe.HSet(ctx, q.key(redisJobsSuffix), job.QueueID, payload)
+	pipe.HSet(ctx, q.key(redisIDsSuffix), job.ID.String(), job.QueueID)
+	pipe.ZAdd(ctx, q.key(redisPendingSuffix), &redis.Z{
+		Score:  pendingScore(job.Priority, time.Now()),
+		Member: job.QueueID,
+	})
+	q.pushSignals(ctx, pipe, 1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish job: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe blocks on the signal list that publishing fills with one token per
+// ready job. Each token wakes a single subscriber, and a subscriber only takes
+// the next token once its previous signal was received, so idle workers are
+// woken before busy ones.
+func (q *RedisQueue) Subscribe(ctx context.Context) (<-chan struct{}, error) {
+	signals := make(chan struct{})
+	go func() {
+		defer close(signals)
+		for ctx.Err() == nil {
+			err := q.client.BLPop(ctx, subscribeBlock, q.key(redisSignalSuffix)).Err()
+			if err == redis.Nil {
+				continue
+			}
+			if err != nil {
+				if ctx.Err() != nil || err == redis.ErrClosed {
+					return
+				}
+				q.logger.Warn("Failed to wait for queue signal", zap.Error(err))
+				select {
+				case <-time.After(time.Second):
+				case <-ctx.Done():
+				}
+				continue
+			}
+
+			select {
+			case signals <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return signals, nil
+}
+
+// Consume retrieves the next job from the queue and leases it to the caller
+func (q *RedisQueue) Consume(ctx context.Context) (*models.EmailJob, error) {
+	jobs, err := q.ConsumeBatch(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, ErrQueueEmpty
+	}
+	return jobs[0], nil
+}
+
+// ConsumeBatch retrieves multiple jobs from the queue and leases them to the caller.
+// Leased jobs stay in the processing set until they are settled with Ack or Nack,
+// or until the visibility timeout expires and ReclaimExpired puts them back.
+func (q *RedisQueue) ConsumeBatch(ctx context.Context, batchSize int) ([]*models.EmailJob, error) {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	deadline := time.Now().Add(q.visibilityTimeout)
+	token := uuid.NewString()
+	keys := []string{q.key(redisPendingSuffix), q.key(redisProcessingSuffix), q.key(redisJobsSuffix), q.key(redisLeasesSuffix)}
+
+	result, err := consumeScript.Run(ctx, q.client, keys, batchSize, deadline.UnixMilli(), token).StringSlice()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to consume jobs: %w", err)
+	}
+
+	return q.decodeLeased(result, token), nil
+}
+
+// ConsumeLane retrieves up to batchSize jobs of a single priority and leases
+// them to the caller. A priority is a band of priorityScoreWeight in the pending set.
+func (q *RedisQueue) ConsumeLane(ctx context.Context, priority models.JobPriority, batchSize int) ([]*models.EmailJob, error) {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	deadline := time.Now().Add(q.visibilityTimeout)
+	token := uuid.NewString()
+	keys := []string{q.key(redisPendingSuffix), q.key(redisProcessingSuffix), q.key(redisJobsSuffix), q.key(redisLeasesSuffix)}
+	min := strconv.FormatFloat(float64(priority)*priorityScoreWeight, 'f', 0, 64)
+	max := "(" + strconv.FormatFloat(float64(priority+1)*priorityScoreWeight, 'f', 0, 64)
+
+	result, err := consumeRangeScript.Run(ctx, q.client, keys, batchSize, deadline.UnixMilli(), token, min, max).StringSlice()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to consume jobs: %w", err)
+	}
+
+	return q.decodeLeased(result, token), nil
+}
+
+// PromoteAged moves jobs pending for at least agingInterval up one priority,
+// but not above ceiling. Promoted jobs are scored as enqueued now, so they
+// wait agingInterval again before their next promotion.
+func (q *RedisQueue) PromoteAged(ctx context.Context, agingInterval time.Duration, ceiling models.JobPriority) (int, error) {
+	now := time.Now()
+	cutoff := now.Add(-agingInterval).UnixMilli()
+	pending := q.key(redisPendingSuffix)
+
+	promoted := 0
+	for priority := ceiling + 1; priority <= models.JobPriorityLow; priority++ {
+		band := float64(priority) * priorityScoreWeight
+		entries, err := q.client.ZRangeByScoreWithScores(ctx, pending, &redis.ZRangeBy{
+			Min:   strconv.FormatFloat(band, 'f', 0, 64),
+			Max:   strconv.FormatFloat(band+float64(cutoff), 'f', 0, 64),
+			Count: maxMovesPerRun,
+		}).Result()
+		if err != nil {
+			return promoted, fmt.Errorf("failed to promote aged jobs: %w", err)
+		}
+
+		for _, entry := range entries {
+			id := entry.Member.(string)
+			ok, err := q.promote(ctx, id, entry.Score, now)
+			if err != nil {
+				return promoted, fmt.Errorf("failed to promote job %s: %w", id, err)
+			}
+			if ok {
+				promoted++
+			}
+		}
+	}
+
+	return promoted, nil
+}
+
+// promote moves a pending job scored score one priority up, as enqueued at
+// now. It reports false if the job was consumed or rescored in the meantime.
+func (q *RedisQueue) promote(ctx context.Context, id string, score float64, now time.Time) (bool, error) {
+	payload, err := q.client.HGet(ctx, q.key(redisJobsSuffix), id).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	job, err := decodeJob(payload)
+	if err != nil {
+		return false, err
+	}
+	job.Priority--
+
+	promoted, err := json.Marshal(job)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	keys := []string{q.key(redisPendingSuffix), q.key(redisJobsSuffix)}
+	oldScore := strconv.FormatFloat(score, 'f', -1, 64)
+	newScore := strconv.FormatFloat(pendingScore(job.Priority, now), 'f', -1, 64)
+	ok, err := promoteScript.Run(ctx, q.client, keys, id, oldScore, promoted, newScore).Int()
+	if err != nil {
+		return false, err
+	}
+	return ok == 1, nil
+}
+
+// decodeLeased decodes the payloads of jobs leased under token
+func (q *RedisQueue) decodeLeased(result []string, token string) []*models.EmailJob {
+	jobs := make([]*models.EmailJob, 0, len(result))
+	for _, payload := range result {
+		job, err := decodeJob(payload)
+		if err != nil {
+			q.logger.Error("Dropping undecodable job payload", zap.Error(err))
+			continue
+		}
+		job.SetReceipt(newReceipt(job.QueueID, token))
+		jobs = append(jobs, job)
+	}
+
+	return jobs
+}
+
+// Ack removes a leased job from the queue once it has been handled
+func (q *RedisQueue) Ack(ctx context.Context, receipt string) error {
+	queueID, token, err := parseReceipt(receipt)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{q.key(redisProcessingSuffix), q.key(redisJobsSuffix), q.key(redisLeasesSuffix), q.key(redisIDsSuffix), q.key(redisCancelledSuffix)}
+	ok, err := ackScript.Run(ctx, q.client, keys, queueID, token).Int()
+	if err != nil {
+		return fmt.Errorf("failed to ack job %s: %w", queueID, err)
+	}
+	if ok == 0 {
+		return fmt.Errorf("failed to ack job %s: %w", queueID, ErrLeaseLost)
+	}
+	return nil
+}
+
+// Nack releases a leased job. It goes back to the pending set right away, or
+// to the scheduled set when requeueAfter is positive.
+func (q *RedisQueue) Nack(ctx context.Context, receipt string, requeueAfter time.Duration) error {
+	queueID, token, err := parseReceipt(receipt)
+	if err != nil {
+		return err
+	}
+
+	payload, err := q.client.HGet(ctx, q.key(redisJobsSuffix), queueID).Result()
+	if err == redis.Nil {
+		return fmt.Errorf("failed to nack job %s: %w", queueID, ErrLeaseLost)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to nack job %s: %w", queueID, err)
+	}
+
+	target, score := q.key(redisPendingSuffix), 0.0
+	if requeueAfter > 0 {
+		target, score = q.key(redisScheduledSuffix), float64(time.Now().Add(requeueAfter).UnixMilli())
+	} else {
+		job, err := decodeJob(payload)
+		if err != nil {
+			return fmt.Errorf("failed to nack job %s: %w", queueID, err)
+		}
+		score = pendingScore(job.Priority, time.Now())
+	}
+
+	keys := []string{q.key(redisProcessingSuffix), q.key(redisLeasesSuffix), target}
+	ok, err := releaseScript.Run(ctx, q.client, keys, queueID, token, score).Int()
+	if err != nil {
+		return fmt.Errorf("failed to nack job %s: %w", queueID, err)
+	}
+	if ok == 0 {
+		return fmt.Errorf("failed to nack job %s: %w", queueID, ErrLeaseLost)
+	}
+	if requeueAfter <= 0 {
+		q.signal(ctx, 1)
+	}
+	return nil
+}
+
+// ExtendLease pushes the lease deadline of a leased job to extension from now
+func (q *RedisQueue) ExtendLease(ctx context.Context, receipt string, extension time.Duration) error {
+	queueID, token, err := parseReceipt(receipt)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(extension).UnixMilli()
+	keys := []string{q.key(redisProcessingSuffix), q.key(redisLeasesSuffix)}
+	ok, err := extendScript.Run(ctx, q.client, keys, queueID, token, deadline).Int()
+	if err != nil {
+		return fmt.Errorf("failed to extend lease of job %s: %w", queueID, err)
+	}
+	if ok == 0 {
+		return fmt.Errorf("failed to extend lease of job %s: %w", queueID, ErrLeaseLost)
+	}
+	return nil
+}
+
+// Size returns the number of jobs waiting to be consumed
+func (q *RedisQueue) Size(ctx context.Context) (int64, error) {
+	size, err := q.client.ZCard(ctx, q.key(redisPendingSuffix)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get queue size: %w", err)
+	}
+	return size, nil
+}
+
+// OldestAge returns how long the longest waiting pending job has been pending.
+// The pending set is ordered by priority first, so the head of every priority
+// band is checked.
+func (q *RedisQueue) OldestAge(ctx context.Context) (time.Duration, error) {
+	pending := q.key(redisPendingSuffix)
+
+	pipe := q.client.Pipeline()
+	heads := make([]*redis.ZSliceCmd, 0, len(models.JobPriorities))
+	for _, priority := range models.JobPriorities {
+		band := float64(priority) * priorityScoreWeight
+		heads = append(heads, pipe.ZRangeByScoreWithScores(ctx, pending, &redis.ZRangeBy{
+			Min:   strconv.FormatFloat(band, 'f', 0, 64),
+			Max:   "(" + strconv.FormatFloat(band+priorityScoreWeight, 'f', 0, 64),
+			Count: 1,
+		}))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, fmt.Errorf("failed to get oldest job age: %w", err)
+	}
+
+	oldest := int64(0)
+	for i, head := range heads {
+		entries, err := head.Result()
+		if err != nil || len(entries) == 0 {
+			continue
+		}
+		enqueuedAt := int64(entries[0].Score - float64(models.JobPriorities[i])*priorityScoreWeight)
+		if oldest == 0 || enqueuedAt < oldest {
+			oldest = enqueuedAt
+		}
+	}
+	if oldest == 0 {
+		return 0, nil
+	}
+	return time.Since(time.UnixMilli(oldest)), nil
+}
+
+// Clear removes all jobs from the queue, including leased and scheduled ones
+func (q *RedisQueue) Clear(ctx context.Context) error {
+	err := q.client.Del(ctx,
+		q.key(redisJobsSuffix),
+		q.key(redisPendingSuffix),
+		q.key(redisProcessingSuffix),
+		q.key(redisScheduledSuffix),
+		q.key(redisLeasesSuffix),
+		q.key(redisIDsSuffix),
+		q.key(redisCancelledSuffix),
+		q.key(redisSignalSuffix),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to clear queue: %w", err)
+	}
+	return nil
+}
+
+// Health checks if the queue is healthy
+func (q *RedisQueue) Health(ctx context.Context) error {
+	if err := q.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis ping failed: %w", err)
+	}
+	return nil
+}
+
+// Close closes the queue connection
+func (q *RedisQueue) Close() error {
+	return q.client.Close()
+}
+
+// PublishScheduled publishes a job for scheduled delivery
+func (q *RedisQueue) PublishScheduled(ctx context.Context, job *models.EmailJob, scheduledAt time.Time) error {
+	job.SetScheduledAt(scheduledAt)
+	if !scheduledAt.After(time.Now()) {
+		return q.Publish(ctx, job)
+	}
+
+	job.SetQueueID(uuid.NewString())
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	pipe := q.client.TxPipeline()
+	pipe.HSet(ctx, q.key(redisJobsSuffix), job.QueueID, payload)
+	pipe.HSet(ctx, q.key(redisIDsSuffix), job.ID.String(), job.QueueID)
+	pipe.ZAdd(ctx, q.key(redisScheduledSuffix), &redis.Z{
+		Score:  float64(scheduledAt.UnixMilli()),
+		Member: job.QueueID,
+	})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish scheduled job: %w", err)
+	}
+
+	return nil
+}
+
+// Cancel removes a pending or scheduled job from the queue, or marks it
+// cancelled if it is leased
+func (q *RedisQueue) Cancel(ctx context.Context, jobID string) error {
+	keys := []string{
+		q.key(redisIDsSuffix), q.key(redisJobsSuffix), q.key(redisPendingSuffix),
+		q.key(redisScheduledSuffix), q.key(redisProcessingSuffix), q.key(redisCancelledSuffix),
+	}
+	ok, err := cancelScript.Run(ctx, q.client, keys, jobID).Int()
+	if err != nil {
+		return fmt.Errorf("failed to cancel job %s: %w", jobID, err)
+	}
+	if ok == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// Cancelled reports whether a leased job was cancelled
+func (q *RedisQueue) Cancelled(ctx context.Context, jobID string) (bool, error) {
+	cancelled, err := q.client.SIsMember(ctx, q.key(redisCancelledSuffix), jobID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check cancellation of job %s: %w", jobID, err)
+	}
+	return cancelled, nil
+}
+
+// Reschedule moves a pending or scheduled job to scheduledAt. A job due now
+// goes straight to the pending set.
+func (q *RedisQueue) Reschedule(ctx context.Context, jobID string, scheduledAt time.Time) error {
+	queueID, err := q.client.HGet(ctx, q.key(redisIDsSuffix), jobID).Result()
+	if err == redis.Nil {
+		return ErrJobNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reschedule job %s: %w", jobID, err)
+	}
+
+	payload, err := q.client.HGet(ctx, q.key(redisJobsSuffix), queueID).Result()
+	if err == redis.Nil {
+		return ErrJobNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reschedule job %s: %w", jobID, err)
+	}
+
+	job, err := decodeJob(payload)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule job %s: %w", jobID, err)
+	}
+	job.SetScheduledAt(scheduledAt)
+	rescheduled, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	target, score := q.key(redisScheduledSuffix), float64(scheduledAt.UnixMilli())
+	if !scheduledAt.After(time.Now()) {
+		target, score = q.key(redisPendingSuffix), pendingScore(job.Priority, time.Now())
+	}
+
+	keys := []string{
+		q.key(redisIDsSuffix), q.key(redisJobsSuffix), q.key(redisPendingSuffix),
+		q.key(redisScheduledSuffix), q.key(redisProcessingSuffix), target,
+	}
+	ok, err := rescheduleScript.Run(ctx, q.client, keys, jobID, queueID, rescheduled, score).Int()
+	if err != nil {
+		return fmt.Errorf("failed to reschedule job %s: %w", jobID, err)
+	}
+	switch ok {
+	case 0:
+		return ErrJobNotFound
+	case 2:
+		return ErrJobLeased
+	}
+	if target == q.key(redisPendingSuffix) {
+		q.signal(ctx, 1)
+	}
+	return nil
+}
+
+// ProcessScheduledJobs moves ready scheduled jobs to the main queue and
+// reclaims jobs whose lease has expired
+func (q *RedisQueue) ProcessScheduledJobs(ctx context.Context) error {
+	moved, err := q.moveDue(ctx, redisScheduledSuffix)
+	if err != nil {
+		return fmt.Errorf("failed to move scheduled jobs: %w", err)
+	}
+	if moved > 0 {
+		q.logger.Info("Moved scheduled jobs to queue", zap.Int("count", moved))
+	}
+
+	if _, err := q.ReclaimExpired(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReclaimExpired puts jobs whose lease has expired back on the pending set,
+// so that a job popped by a crashed worker is eventually processed again
+func (q *RedisQueue) ReclaimExpired(ctx context.Context) (int, error) {
+	reclaimed, err := q.moveDue(ctx, redisProcessingSuffix)
+	if err != nil {
+		return reclaimed, fmt.Errorf("failed to reclaim expired jobs: %w", err)
+	}
+	if reclaimed > 0 {
+		q.logger.Warn("Reclaimed jobs with expired lease", zap.Int("count", reclaimed))
+	}
+	return reclaimed, nil
+}
+
+// moveDue moves every member of the given sorted set whose score is in the past back to the pending set
+func (q *RedisQueue) moveDue(ctx context.Context, sourceSuffix string) (int, error) {
+	source := q.key(sourceSuffix)
+	ids, err := q.client.ZRangeByScore(ctx, source, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(time.Now().UnixMilli(), 10),
+		Count: maxMovesPerRun,
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, id := range ids {
+		payload, err := q.client.HGet(ctx, q.key(redisJobsSuffix), id).Result()
+		if err == redis.Nil {
+			// Payload is gone (acked or cleared), drop the dangling entry
+			q.client.ZRem(ctx, source, id)
+			continue
+		}
+		if err != nil {
+			return moved, err
+		}
+
+		job, err := decodeJob(payload)
+		if err != nil {
+			q.logger.Error("Dropping undecodable job payload", zap.String("queue_id", id), zap.Error(err))
+			q.client.ZRem(ctx, source, id)
+			q.client.HDel(ctx, q.key(redisJobsSuffix), id)
+			continue
+		}
+
+		keys := []string{source, q.key(redisPendingSuffix), q.key(redisLeasesSuffix)}
+		ok, err := moveScript.Run(ctx, q.client, keys, id, pendingScore(job.Priority, time.Now())).Int()
+		if err != nil {
+			return moved, err
+		}
+		moved += ok
+	}
+
+	q.signal(ctx, moved)
+	return moved, nil
+}
+
+// signal pushes count wake-up tokens for subscribers
+func (q *RedisQueue) signal(ctx context.Context, count int) {
+	if count <= 0 {
+		return
+	}
+	pipe := q.client.Pipeline()
+	q.pushSignals(ctx, pipe, count)
+	if _, err := pipe.Exec(ctx); err != nil {
+		q.logger.Warn("Failed to signal queue subscribers", zap.Error(err))
+	}
+}
+
+// pushSignals queues count wake-up tokens on pipe, keeping at most maxSignals
+func (q *RedisQueue) pushSignals(ctx context.Context, pipe redis.Pipeliner, count int) {
+	tokens := make([]any, count)
+	for i := range tokens {
+		tokens[i] = 1
+	}
+	pipe.LPush(ctx, q.key(redisSignalSuffix), tokens...)
+	pipe.LTrim(ctx, q.key(redisSignalSuffix), 0, maxSignals-1)
+}
+
+// key builds a namespaced Redis key for this queue
+func (q *RedisQueue) key(suffix string) string {
+	return q.queueName + suffix
+}
+
+// pendingScore orders jobs by priority first, then by enqueue time
+func pendingScore(priority models.JobPriority, enqueuedAt time.Time) float64 {
+	if priority < models.JobPriorityUrgent || priority > models.JobPriorityLow {
+		priority = models.JobPriorityNormal
+	}
+	return float64(priority)*priorityScoreWeight + float64(enqueuedAt.UnixMilli())
+}
+
+// decodeJob decodes a job payload stored in the queue
+func decodeJob(payload string) (*models.EmailJob, error) {
+	var job models.EmailJob
+	if err := json.Unmarshal([]byte(payload), &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job: %w", err)
+	}
+	return &job, nil
+}
@@ -0,0 +1,250 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/queue"
+)
+
+func newTestRedisQueue(t *testing.T, visibilityTimeout time.Duration) (*queue.RedisQueue, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	q := queue.NewRedisQueue(mr.Addr(), "", 0, "email-jobs", visibilityTimeout, zap.NewNop())
+	t.Cleanup(func() { q.Close() })
+
+	return q, mr
+}
+
+func newTestJob(priority models.JobPriority) *models.EmailJob {
+	return models.NewEmailJob([]string{"user@example.com"}, nil, nil, "email_verification", map[string]any{"Name": "Test"}, priority)
+}
+
+func TestRedisQueue_PublishConsume(t *testing.T) {
+	q, _ := newTestRedisQueue(t, time.Minute)
+	ctx := context.Background()
+
+	job := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, q.Publish(ctx, job))
+	assert.NotEmpty(t, job.QueueID)
+
+	size, err := q.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), size)
+
+	consumed, err := q.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, consumed.ID)
+	assert.Equal(t, job.QueueID, consumed.QueueID)
+	assert.Equal(t, job.TemplateName, consumed.TemplateName)
+
+	_, err = q.Consume(ctx)
+	assert.Equal(t, queue.ErrQueueEmpty, err)
+}
+
+func TestRedisQueue_ConsumeBatchPriorityOrder(t *testing.T) {
+	q, _ := newTestRedisQueue(t, time.Minute)
+	ctx := context.Background()
+
+	low := newTestJob(models.JobPriorityLow)
+	normal := newTestJob(models.JobPriorityNormal)
+	high := newTestJob(models.JobPriorityHigh)
+	for _, job := range []*models.EmailJob{low, normal, high} {
+		require.NoError(t, q.Publish(ctx, job))
+	}
+
+	jobs, err := q.ConsumeBatch(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, high.ID, jobs[0].ID)
+	assert.Equal(t, normal.ID, jobs[1].ID)
+
+	jobs, err = q.ConsumeBatch(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, low.ID, jobs[0].ID)
+}
+
+func TestRedisQueue_ReclaimExpiredLease(t *testing.T) {
+	q, _ := newTestRedisQueue(t, 50*time.Millisecond)
+	ctx := context.Background()
+
+	job := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, q.Publish(ctx, job))
+
+	_, err := q.Consume(ctx)
+	require.NoError(t, err)
+
+	// Lease is still valid, nothing to reclaim
+	reclaimed, err := q.ReclaimExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, reclaimed)
+
+	time.Sleep(100 * time.Millisecond)
+
+	reclaimed, err = q.ReclaimExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reclaimed)
+
+	redelivered, err := q.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, redelivered.ID)
+}
+
+func TestRedisQueue_AckPreventsReclaim(t *testing.T) {
+	q, _ := newTestRedisQueue(t, 50*time.Millisecond)
+	ctx := context.Background()
+
+	job := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, q.Publish(ctx, job))
+
+	consumed, err := q.Consume(ctx)
+	require.NoError(t, err)
+	require.NoError(t, q.Ack(ctx, consumed.Receipt))
+
+	time.Sleep(100 * time.Millisecond)
+
+	reclaimed, err := q.ReclaimExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, reclaimed)
+
+	_, err = q.Consume(ctx)
+	assert.Equal(t, queue.ErrQueueEmpty, err)
+}
+
+func TestRedisQueue_ScheduledJobs(t *testing.T) {
+	q, _ := newTestRedisQueue(t, time.Minute)
+	ctx := context.Background()
+
+	job := newTestJob(models.JobPriorityNormal)
+	require.NoError(t, q.PublishScheduled(ctx, job, time.Now().Add(50*time.Millisecond)))
+
+	// Not due yet
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+	size, err := q.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+
+	time.Sleep(100 * time.Millisecond)
+
+	require.NoError(t, q.ProcessScheduledJobs(ctx))
+	size, err = q.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), size)
+
+	consumed, err := q.Consume(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, consumed.ID)
+	require.NotNil(t, consumed.ProcessedAt)
+}
+
+func TestRedisQueue_ClearAndHealth(t *testing.T) {
+	q, mr := newTestRedisQueue(t, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, q.Publish(ctx, newTestJob(models.JobPriorityNormal)))
+	require.NoError(t, q.PublishScheduled(ctx, newTestJob(models.JobPriorityNormal), time.Now().Add(time.Hour)))
+	require.NoError(t, q.Health(ctx))
+
+	require.NoError(t, q.Clear(ctx))
+	size, err := q.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+	assert.Empty(t, mr.Keys())
+
+	mr.Close()
+	assert.Error(t, q.Health(ctx))
+}
+
+func TestRedisQueue_ConsumeLane(t *testing.T) {
+	q, _ := newTestRedisQueue(t, time.Minute)
+	ctx := context.Background()
+
+	urgent := newTestJob(models.JobPriorityUrgent)
+	low1 := newTestJob(models.JobPriorityLow)
+	low2 := newTestJob(models.JobPriorityLow)
+	for _, job := range []*models.EmailJob{low1, urgent, low2} {
+		require.NoError(t, q.Publish(ctx, job))
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	jobs, err := q.ConsumeLane(ctx, models.JobPriorityLow, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, low1.ID, jobs[0].ID)
+	assert.Equal(t, low2.ID, jobs[1].ID)
+	require.NoError(t, q.Ack(ctx, jobs[0].Receipt))
+
+	jobs, err = q.ConsumeLane(ctx, models.JobPriorityHigh, 10)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	jobs, err = q.ConsumeBatch(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, urgent.ID, jobs[0].ID)
+}
+
+func TestRedisQueue_PromoteAged(t *testing.T) {
+	q, _ := newTestRedisQueue(t, time.Minute)
+	ctx := context.Background()
+
+	normal := newTestJob(models.JobPriorityNormal)
+	low := newTestJob(models.JobPriorityLow)
+	for _, job := range []*models.EmailJob{normal, low} {
+		require.NoError(t, q.Publish(ctx, job))
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	promoted, err := q.PromoteAged(ctx, time.Hour, models.JobPriorityHigh)
+	require.NoError(t, err)
+	assert.Equal(t, 0, promoted)
+
+	// A high priority job published after the promotion queues behind it
+	time.Sleep(2 * time.Millisecond)
+	promoted, err = q.PromoteAged(ctx, 0, models.JobPriorityHigh)
+	require.NoError(t, err)
+	assert.Equal(t, 2, promoted)
+	time.Sleep(2 * time.Millisecond)
+	high := newTestJob(models.JobPriorityHigh)
+	require.NoError(t, q.Publish(ctx, high))
+
+	jobs, err := q.ConsumeLane(ctx, models.JobPriorityHigh, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, normal.ID, jobs[0].ID)
+	assert.Equal(t, models.JobPriorityHigh, jobs[0].Priority)
+	assert.Equal(t, high.ID, jobs[1].ID)
+
+	jobs, err = q.ConsumeLane(ctx, models.JobPriorityNormal, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, low.ID, jobs[0].ID)
+	assert.Equal(t, models.JobPriorityNormal, jobs[0].Priority)
+}
+
+func TestRedisQueue_OldestAge(t *testing.T) {
+	q, _ := newTestRedisQueue(t, time.Minute)
+	ctx := context.Background()
+
+	age, err := q.OldestAge(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, age)
+
+	require.NoError(t, q.Publish(ctx, newTestJob(models.JobPriorityLow)))
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, q.Publish(ctx, newTestJob(models.JobPriorityUrgent)))
+
+	age, err = q.OldestAge(ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, age, 20*time.Millisecond)
+	assert.Less(t, age, time.Minute)
+}
@@ -0,0 +1,110 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// Redis key layout of the rate limiter (prefixed with the queue name):
+//
+//	<name>:ratelimit:<key>  hash  tokens, updated_at (ms), expiring once full
+const redisRateLimitSuffix = ":ratelimit:"
+
+// takeTokenScript takes a token from the bucket KEYS[1], which refills at
+// ARGV[1] tokens per second up to ARGV[2] tokens, at time ARGV[3] in
+// milliseconds. It returns the tokens left and the milliseconds until a
+// token is available when there was none.
+var takeTokenScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated_at')
+local tokens = tonumber(bucket[1]) or burst
+local updated = tonumber(bucket[2]) or now
+if now > updated then
+	tokens = math.min(burst, tokens + (now - updated) / 1000 * rate)
+end
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.ceil((1 - tokens) / rate * 1000)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated_at', math.max(now, updated))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {tostring(tokens), wait}
+`)
+
+// RedisRateLimiter implements RateLimiter next to a RedisQueue. While Redis
+// cannot be reached it falls back to buckets in process memory, limiting
+// each replica on its own rather than stopping sends.
+type RedisRateLimiter struct {
+	client    *redis.Client
+	queueName string
+	logger    *zap.Logger
+	fallback  *MemoryRateLimiter
+	degraded  atomic.Bool
+}
+
+// NewRedisRateLimiter creates a new RedisRateLimiter instance
+func NewRedisRateLimiter(addr, password string, database int, queueName string, logger *zap.Logger) *RedisRateLimiter {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       database,
+	})
+
+	return &RedisRateLimiter{
+		client:    client,
+		queueName: queueName,
+		logger:    logger,
+		fallback:  NewMemoryRateLimiter(),
+	}
+}
+
+// Take takes a token from the bucket of key
+func (l *RedisRateLimiter) Take(ctx context.Context, key string, rate float64, burst int) (float64, time.Duration, error) {
+	keys := []string{l.queueName + redisRateLimitSuffix + key}
+	result, err := takeTokenScript.Run(ctx, l.client, keys, rate, burst, time.Now().UnixMilli()).Slice()
+	if err == nil {
+		var tokens float64
+		var wait int64
+		tokens, wait, err = parseTakeResult(result)
+		if err == nil {
+			if l.degraded.Swap(false) {
+				l.logger.Info("Redis rate limiter recovered, limiting across replicas again")
+			}
+			return tokens, time.Duration(wait) * time.Millisecond, nil
+		}
+	}
+
+	if !l.degraded.Swap(true) {
+		l.logger.Warn("Redis rate limiter unavailable, limiting each replica on its own", zap.Error(err))
+	}
+	return l.fallback.Take(ctx, key, rate, burst)
+}
+
+// parseTakeResult parses the reply of takeTokenScript
+func parseTakeResult(result []any) (float64, int64, error) {
+	if len(result) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rate limit reply %v", result)
+	}
+	text, _ := result[0].(string)
+	tokens, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse rate limit tokens: %w", err)
+	}
+	wait, _ := result[1].(int64)
+	return tokens, wait, nil
+}
+
+// Close closes the limiter connection
+func (l *RedisRateLimiter) Close() error {
+	return l.client.Close()
+}
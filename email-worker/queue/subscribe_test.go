@@ -0,0 +1,98 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"booking-system/email-worker/models"
+	"booking-system/email-worker/queue"
+)
+
+// testSubscribe runs the subscription behaviour every queue with
+// subscription support must have
+func testSubscribe(t *testing.T, q queue.Queue) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals, err := q.Subscribe(ctx)
+	require.NoError(t, err)
+
+	// Nothing is ready, so nothing is signalled
+	select {
+	case <-signals:
+		t.Fatal("unexpected signal without a published job")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, q.Publish(context.Background(), newTestJob(models.JobPriorityNormal)))
+	assertSignalled(t, signals)
+
+	// A job handed back without delay is ready again
+	job, err := q.Consume(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, q.Nack(context.Background(), job.Receipt, 0))
+	assertSignalled(t, signals)
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		select {
+		case _, ok := <-signals:
+			return !ok
+		default:
+			return false
+		}
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func assertSignalled(t *testing.T, signals <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-signals:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a signal")
+	}
+}
+
+func TestQueue_Subscribe(t *testing.T) {
+	testEachQueue(t, time.Minute, testSubscribe)
+}
+
+func TestMemoryQueue_SubscribeSignalsDueScheduledJobs(t *testing.T) {
+	q := queue.NewMemoryQueue(time.Minute, zap.NewNop())
+	ctx := context.Background()
+
+	signals, err := q.Subscribe(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, q.PublishScheduled(ctx, newTestJob(models.JobPriorityNormal), time.Now().Add(50*time.Millisecond)))
+	assertSignalled(t, signals)
+
+	job, err := q.Consume(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, job)
+
+	// Closing the queue ends the subscription
+	require.NoError(t, q.Close())
+	for range signals {
+	}
+}
+
+func TestRedisQueue_SubscribeWakesOneSubscriberPerJob(t *testing.T) {
+	q, _ := newTestRedisQueue(t, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first, err := q.Subscribe(ctx)
+	require.NoError(t, err)
+	second, err := q.Subscribe(ctx)
+	require.NoError(t, err)
+
+	// Each job token wakes a different waiting subscriber
+	require.NoError(t, q.Publish(ctx, newTestJob(models.JobPriorityNormal)))
+	require.NoError(t, q.Publish(ctx, newTestJob(models.JobPriorityNormal)))
+	assertSignalled(t, first)
+	assertSignalled(t, second)
+}
@@ -7,11 +7,27 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 
 	"booking-system/email-worker/models"
 )
 
+// EmailJobStore stores the state of email jobs. EmailJobRepository keeps them
+// in PostgreSQL, MemoryEmailJobRepository in process memory.
+type EmailJobStore interface {
+	Create(ctx context.Context, job *models.EmailJob) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.EmailJob, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
+	UpdateProcessingTime(ctx context.Context, id uuid.UUID, processingAt, completedAt *time.Time) error
+	UpdateRetry(ctx context.Context, job *models.EmailJob) error
+	UpdateOutcome(ctx context.Context, job *models.EmailJob) error
+	ResetForReplay(ctx context.Context, job *models.EmailJob) error
+	GetPendingJobs(ctx context.Context, limit int) ([]*models.EmailJob, error)
+	GetStats(ctx context.Context, timeRange time.Duration) (*JobStats, error)
+	CleanupOldJobs(ctx context.Context, olderThan time.Duration) error
+}
+
 // EmailJobRepository handles database operations for email jobs
 type EmailJobRepository struct {
 	db     *sql.DB
@@ -32,14 +48,14 @@ func (r *EmailJobRepository) Create(ctx context.Context, job *models.EmailJob) e
 		INSERT INTO email_jobs (
 			id, to_emails, cc_emails, bcc_emails, template_name, variables,
 			status, priority, retry_count, max_retries, error_message, 
-			processed_at, sent_at, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			processed_at, sent_at, next_attempt_at, idempotency_key, tenant, recipients, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NULLIF($15, ''), NULLIF($16, ''), $17, $18, $19)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
-		job.ID, job.To, job.CC, job.BCC, job.TemplateName, job.Variables,
+		job.ID, pq.Array([]string(job.To)), pq.Array([]string(job.CC)), pq.Array([]string(job.BCC)), job.TemplateName, job.Variables,
 		job.Status, job.Priority, job.RetryCount, job.MaxRetries, job.ErrorMessage,
-		job.ProcessedAt, job.SentAt, job.CreatedAt, job.UpdatedAt,
+		job.ProcessedAt, job.SentAt, job.NextAttemptAt, job.IdempotencyKey, job.Tenant, job.Recipients, job.CreatedAt, job.UpdatedAt,
 	)
 
 	if err != nil {
@@ -59,16 +75,17 @@ func (r *EmailJobRepository) Create(ctx context.Context, job *models.EmailJob) e
 func (r *EmailJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.EmailJob, error) {
 	query := `
 		SELECT id, to_emails, cc_emails, bcc_emails, template_name, variables,
-			   status, priority, retry_count, max_retries, error_message,
-			   processed_at, sent_at, created_at, updated_at
+			   status, priority, retry_count, max_retries, COALESCE(error_message, ''),
+			   processed_at, sent_at, next_attempt_at, attempts, COALESCE(idempotency_key, ''), COALESCE(tenant, ''), recipients, created_at, updated_at
 		FROM email_jobs WHERE id = $1
 	`
 
+	// Recipient addresses are TEXT[] columns, bound like the postgres queue does
 	var job models.EmailJob
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&job.ID, &job.To, &job.CC, &job.BCC, &job.TemplateName, &job.Variables,
+		&job.ID, pq.Array((*[]string)(&job.To)), pq.Array((*[]string)(&job.CC)), pq.Array((*[]string)(&job.BCC)), &job.TemplateName, &job.Variables,
 		&job.Status, &job.Priority, &job.RetryCount, &job.MaxRetries, &job.ErrorMessage,
-		&job.ProcessedAt, &job.SentAt, &job.CreatedAt, &job.UpdatedAt,
+		&job.ProcessedAt, &job.SentAt, &job.NextAttemptAt, &job.Attempts, &job.IdempotencyKey, &job.Tenant, &job.Recipients, &job.CreatedAt, &job.UpdatedAt,
 	)
 
 	if err != nil {
@@ -136,6 +153,101 @@ func (r *EmailJobRepository) UpdateProcessingTime(ctx context.Context, id uuid.U
 	return nil
 }
 
+// UpdateRetry records a failed attempt of an email job: its status, retry
+// count, last error, attempt history and the time of the next attempt
+func (r *EmailJobRepository) UpdateRetry(ctx context.Context, job *models.EmailJob) error {
+	query := `
+		UPDATE email_jobs 
+		SET status = $1, retry_count = $2, error_message = $3, next_attempt_at = $4, attempts = $5, recipients = $6, updated_at = $7
+		WHERE id = $8
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		job.Status, job.RetryCount, job.ErrorMessage, job.NextAttemptAt, job.Attempts, job.Recipients, time.Now(), job.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update email job retry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("email job not found: %s", job.ID)
+	}
+
+	r.logger.Info("Email job retry scheduled",
+		zap.String("job_id", job.ID.String()),
+		zap.Int("retry_count", job.RetryCount),
+	)
+
+	return nil
+}
+
+// UpdateOutcome records the status of an email job with what its last
+// attempt left behind: its retry count, last error, attempt history, the
+// recipients still to be sent to and the time it was sent
+func (r *EmailJobRepository) UpdateOutcome(ctx context.Context, job *models.EmailJob) error {
+	query := `
+		UPDATE email_jobs
+		SET status = $1, retry_count = $2, error_message = $3, attempts = $4, recipients = $5, sent_at = $6, updated_at = $7
+		WHERE id = $8
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		job.Status, job.RetryCount, job.ErrorMessage, job.Attempts, job.Recipients, job.SentAt, time.Now(), job.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update email job outcome: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("email job not found: %s", job.ID)
+	}
+
+	r.logger.Info("Email job outcome updated",
+		zap.String("job_id", job.ID.String()),
+		zap.String("status", string(job.Status)),
+	)
+
+	return nil
+}
+
+// ResetForReplay records that a dead-lettered email job is pending again
+// with a fresh retry budget
+func (r *EmailJobRepository) ResetForReplay(ctx context.Context, job *models.EmailJob) error {
+	query := `
+		UPDATE email_jobs
+		SET status = $1, retry_count = $2, error_message = $3, next_attempt_at = NULL, processed_at = NULL, sent_at = NULL, updated_at = $4
+		WHERE id = $5
+	`
+
+	result, err := r.db.ExecContext(ctx, query, job.Status, job.RetryCount, job.ErrorMessage, time.Now(), job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to reset email job for replay: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("email job not found: %s", job.ID)
+	}
+
+	r.logger.Info("Email job reset for replay", zap.String("job_id", job.ID.String()))
+
+	return nil
+}
+
 // IncrementRetryCount increments the retry count for an email job
 func (r *EmailJobRepository) IncrementRetryCount(ctx context.Context, id uuid.UUID) error {
 	query := `
@@ -165,7 +277,7 @@ func (r *EmailJobRepository) IncrementRetryCount(ctx context.Context, id uuid.UU
 func (r *EmailJobRepository) GetPendingJobs(ctx context.Context, limit int) ([]*models.EmailJob, error) {
 	query := `
 		SELECT id, to_emails, cc_emails, bcc_emails, template_name, variables,
-			   status, priority, retry_count, max_retries, error_message,
+			   status, priority, retry_count, max_retries, COALESCE(error_message, ''),
 			   processed_at, sent_at, created_at, updated_at
 		FROM email_jobs 
 		WHERE status = 'pending' 
@@ -184,7 +296,7 @@ func (r *EmailJobRepository) GetPendingJobs(ctx context.Context, limit int) ([]*
 	for rows.Next() {
 		var job models.EmailJob
 		err := rows.Scan(
-			&job.ID, &job.To, &job.CC, &job.BCC, &job.TemplateName, &job.Variables,
+			&job.ID, pq.Array((*[]string)(&job.To)), pq.Array((*[]string)(&job.CC)), pq.Array((*[]string)(&job.BCC)), &job.TemplateName, &job.Variables,
 			&job.Status, &job.Priority, &job.RetryCount, &job.MaxRetries, &job.ErrorMessage,
 			&job.ProcessedAt, &job.SentAt, &job.CreatedAt, &job.UpdatedAt,
 		)
@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"booking-system/email-worker/models"
+)
+
+// MemoryEmailJobRepository implements EmailJobStore in process memory, for
+// tests and development. Jobs are lost when the process exits.
+type MemoryEmailJobRepository struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]*models.EmailJob
+}
+
+// NewMemoryEmailJobRepository creates a new MemoryEmailJobRepository
+func NewMemoryEmailJobRepository() *MemoryEmailJobRepository {
+	return &MemoryEmailJobRepository{
+		jobs: make(map[uuid.UUID]*models.EmailJob),
+	}
+}
+
+// Create creates a new email job
+func (r *MemoryEmailJobRepository) Create(ctx context.Context, job *models.EmailJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[job.ID]; ok {
+		return fmt.Errorf("failed to create email job: job %s already exists", job.ID)
+	}
+	r.jobs[job.ID] = copyJob(job)
+	return nil
+}
+
+// GetByID retrieves an email job by ID
+func (r *MemoryEmailJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.EmailJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("email job not found: %s", id)
+	}
+	return copyJob(job), nil
+}
+
+// UpdateStatus updates the status of an email job
+func (r *MemoryEmailJobRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+	return r.update(id, func(job *models.EmailJob) {
+		job.Status = models.JobStatus(status)
+	})
+}
+
+// UpdateProcessingTime updates the processing time fields
+func (r *MemoryEmailJobRepository) UpdateProcessingTime(ctx context.Context, id uuid.UUID, processingAt, completedAt *time.Time) error {
+	return r.update(id, func(job *models.EmailJob) {
+		job.ProcessedAt = copyTime(processingAt)
+		job.SentAt = copyTime(completedAt)
+	})
+}
+
+// UpdateRetry records a failed attempt of an email job
+func (r *MemoryEmailJobRepository) UpdateRetry(ctx context.Context, job *models.EmailJob) error {
+	return r.update(job.ID, func(stored *models.EmailJob) {
+		stored.Status = job.Status
+		stored.RetryCount = job.RetryCount
+		stored.ErrorMessage = job.ErrorMessage
+		stored.NextAttemptAt = copyTime(job.NextAttemptAt)
+		stored.Attempts = append(models.JobAttempts(nil), job.Attempts...)
+		stored.Recipients = append(models.BatchRecipients(nil), job.Recipients...)
+	})
+}
+
+// UpdateOutcome records the status of an email job with what its last attempt left behind
+func (r *MemoryEmailJobRepository) UpdateOutcome(ctx context.Context, job *models.EmailJob) error {
+	return r.update(job.ID, func(stored *models.EmailJob) {
+		stored.Status = job.Status
+		stored.RetryCount = job.RetryCount
+		stored.ErrorMessage = job.ErrorMessage
+		stored.Attempts = append(models.JobAttempts(nil), job.Attempts...)
+		stored.Recipients = append(models.BatchRecipients(nil), job.Recipients...)
+		stored.SentAt = copyTime(job.SentAt)
+	})
+}
+
+// ResetForReplay records that a dead-lettered email job is pending again
+func (r *MemoryEmailJobRepository) ResetForReplay(ctx context.Context, job *models.EmailJob) error {
+	return r.update(job.ID, func(stored *models.EmailJob) {
+		stored.Status = job.Status
+		stored.RetryCount = job.RetryCount
+		stored.ErrorMessage = job.ErrorMessage
+		stored.NextAttemptAt = nil
+		stored.ProcessedAt = nil
+		stored.SentAt = nil
+	})
+}
+
+// GetPendingJobs retrieves pending jobs that are ready to be processed
+func (r *MemoryEmailJobRepository) GetPendingJobs(ctx context.Context, limit int) ([]*models.EmailJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var jobs []*models.EmailJob
+	for _, job := range r.jobs {
+		if job.Status == models.JobStatusPending && (job.ProcessedAt == nil || !job.ProcessedAt.After(now)) {
+			jobs = append(jobs, copyJob(job))
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].Priority != jobs[j].Priority {
+			return jobs[i].Priority < jobs[j].Priority
+		}
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+// GetStats retrieves email job statistics
+func (r *MemoryEmailJobRepository) GetStats(ctx context.Context, timeRange time.Duration) (*JobStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	since := time.Now().Add(-timeRange)
+	var stats JobStats
+	var processingTime float64
+	var processed int
+	for _, job := range r.jobs {
+		if job.CreatedAt.Before(since) {
+			continue
+		}
+		stats.TotalJobs++
+		switch job.Status {
+		case models.JobStatusCompleted:
+			stats.CompletedJobs++
+		case models.JobStatusFailed:
+			stats.FailedJobs++
+		case models.JobStatusPending:
+			stats.PendingJobs++
+		}
+		if job.RetryCount > 0 {
+			stats.RetriedJobs++
+		}
+		if job.ProcessedAt != nil && job.SentAt != nil {
+			processingTime += job.SentAt.Sub(*job.ProcessedAt).Seconds()
+			processed++
+		}
+	}
+
+	if processed > 0 {
+		stats.AverageProcessingTime = processingTime / float64(processed)
+	}
+	if stats.TotalJobs > 0 {
+		stats.SuccessRate = float64(stats.CompletedJobs) / float64(stats.TotalJobs) * 100
+	}
+	return &stats, nil
+}
+
+// CleanupOldJobs removes old completed jobs
+func (r *MemoryEmailJobRepository) CleanupOldJobs(ctx context.Context, olderThan time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan)
+	for id, job := range r.jobs {
+		if job.Status == models.JobStatusCompleted && job.UpdatedAt.Before(cutoff) {
+			delete(r.jobs, id)
+		}
+	}
+	return nil
+}
+
+// update changes a stored job with change
+func (r *MemoryEmailJobRepository) update(id uuid.UUID, change func(job *models.EmailJob)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return fmt.Errorf("email job not found: %s", id)
+	}
+	change(job)
+	job.UpdatedAt = time.Now()
+	return nil
+}
+
+// copyJob copies the stored fields of a job, so the caller cannot change a
+// stored job in place
+func copyJob(job *models.EmailJob) *models.EmailJob {
+	stored := *job
+	stored.Receipt = ""
+	stored.ProcessedAt = copyTime(job.ProcessedAt)
+	stored.SentAt = copyTime(job.SentAt)
+	stored.NextAttemptAt = copyTime(job.NextAttemptAt)
+	stored.Attempts = append(models.JobAttempts(nil), job.Attempts...)
+	stored.Recipients = append(models.BatchRecipients(nil), job.Recipients...)
+	return &stored
+}
+
+// copyTime copies an optional time
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -15,15 +16,27 @@ import (
 
 // EmailService handles email operations
 type EmailService struct {
-	jobRepo       *repositories.EmailJobRepository
+	jobRepo       repositories.EmailJobStore
 	templateRepo  *repositories.EmailTemplateRepository
 	emailProvider providers.Provider
 	templateEngine *templates.Engine
+	// trackingRepo records the deliveries of tracked jobs, it may be nil
+	trackingRepo  *repositories.EmailTrackingRepository
 }
 
+// ErrNoProvider is returned when a job is processed without an email provider
+var ErrNoProvider = errors.New("no email provider configured")
+
+// ErrJobsNotStored is returned when a job is looked up without a job repository
+var ErrJobsNotStored = errors.New("email jobs are not stored")
+
+// ErrJobFinished is returned when a job that was already sent, failed or
+// cancelled is cancelled
+var ErrJobFinished = errors.New("email job is already finished")
+
 // NewEmailService creates a new email service
 func NewEmailService(
-	jobRepo *repositories.EmailJobRepository,
+	jobRepo repositories.EmailJobStore,
 	templateRepo *repositories.EmailTemplateRepository,
 	emailProvider providers.Provider,
 	templateEngine *templates.Engine,
@@ -36,6 +49,11 @@ func NewEmailService(
 	}
 }
 
+// SetTrackingRepository makes the service record the deliveries of tracked jobs
+func (s *EmailService) SetTrackingRepository(trackingRepo *repositories.EmailTrackingRepository) {
+	s.trackingRepo = trackingRepo
+}
+
 // SendEmail sends an email using the provided template and data
 func (s *EmailService) SendEmail(ctx context.Context, request *SendEmailRequest) (*models.EmailJob, error) {
 	// Validate request
@@ -62,6 +80,7 @@ func (s *EmailService) SendEmail(ctx context.Context, request *SendEmailRequest)
 		request.Variables,
 		models.JobPriority(request.Priority),
 	)
+	job.Recipients = request.Recipients
 
 	// Save job to database
 	if err := s.jobRepo.Create(ctx, job); err != nil {
@@ -147,9 +166,11 @@ func (s *EmailService) ProcessJob(ctx context.Context, job *models.EmailJob) err
 			return fmt.Errorf("failed to send email: %w", err)
 		}
 	} else {
-		// Email provider not available, mark as completed but log warning
-		// This allows the service to continue running even without email capability
-		job.ErrorMessage = "Email provider not configured - email not sent"
+		// Never report a job completed that was not sent
+		job.Status = models.JobStatusFailed
+		job.ErrorMessage = ErrNoProvider.Error()
+		s.jobRepo.UpdateStatus(ctx, job.ID, string(job.Status))
+		return ErrNoProvider
 	}
 
 	// Update job status to completed
@@ -293,20 +314,293 @@ type ServiceStats struct {
 	PendingJobs   int `json:"pending_jobs"`
 }
 
-// UpdateJobStatus updates the status of a job
+// UpdateJobStatus updates the status of a job. Without a job repository jobs
+// are not tracked and there is nothing to update.
 func (s *EmailService) UpdateJobStatus(ctx context.Context, jobID, status string) error {
-	// TODO: Implement job status update
-	// For now, return nil to avoid compilation errors
+	if s.jobRepo == nil {
+		return nil
+	}
+	id, err := uuid.Parse(jobID)
+	if err != nil {
+		return fmt.Errorf("invalid job ID: %w", err)
+	}
+	if err := s.jobRepo.UpdateStatus(ctx, id, status); err != nil {
+		return fmt.Errorf("failed to update job status: %w", err)
+	}
+	return nil
+}
+
+// RescheduleJob records the time a pending job is scheduled for. Without a
+// job repository jobs are not tracked and there is nothing to update.
+func (s *EmailService) RescheduleJob(ctx context.Context, jobID string, scheduledAt time.Time) error {
+	if s.jobRepo == nil {
+		return nil
+	}
+	id, err := uuid.Parse(jobID)
+	if err != nil {
+		return fmt.Errorf("invalid job ID: %w", err)
+	}
+	if err := s.jobRepo.UpdateProcessingTime(ctx, id, &scheduledAt, nil); err != nil {
+		return fmt.Errorf("failed to reschedule job: %w", err)
+	}
+	return nil
+}
+
+// CheckCancellable returns ErrJobFinished unless the stored job is pending or
+// being processed, for queues that cannot cancel jobs themselves
+func (s *EmailService) CheckCancellable(ctx context.Context, jobID string) error {
+	if s.jobRepo == nil {
+		return ErrJobsNotStored
+	}
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if job.Status != models.JobStatusPending && job.Status != models.JobStatusProcessing {
+		return ErrJobFinished
+	}
 	return nil
 }
 
-// ProcessEmailJob processes an email job
+// JobCancelled reports whether the stored job was cancelled, for queues that
+// cannot track cancellations themselves. Without a job repository no job is.
+func (s *EmailService) JobCancelled(ctx context.Context, jobID string) (bool, error) {
+	if s.jobRepo == nil {
+		return false, nil
+	}
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return false, err
+	}
+	return job.Status == models.JobStatusCancelled, nil
+}
+
+// ScheduleJobRetry persists the retry state of a job, so that a pending retry
+// and its next attempt time are visible while the job waits on the queue.
+// Without a job repository jobs are not tracked and there is nothing to update.
+func (s *EmailService) ScheduleJobRetry(ctx context.Context, job *models.EmailJob) error {
+	if s.jobRepo == nil {
+		return nil
+	}
+	if err := s.jobRepo.UpdateRetry(ctx, job); err != nil {
+		return fmt.Errorf("failed to schedule job retry: %w", err)
+	}
+	return nil
+}
+
+// CreateJob stores a job that is about to be queued. Without a job repository
+// jobs are not tracked and there is nothing to store.
+func (s *EmailService) CreateJob(ctx context.Context, job *models.EmailJob) error {
+	if s.jobRepo == nil {
+		return nil
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return fmt.Errorf("failed to store job: %w", err)
+	}
+	return nil
+}
+
+// RecordJobOutcome persists the status of a job with the error, attempt
+// history and send time its last attempt left behind. Without a job
+// repository jobs are not tracked and there is nothing to update.
+func (s *EmailService) RecordJobOutcome(ctx context.Context, job *models.EmailJob) error {
+	if s.jobRepo == nil {
+		return nil
+	}
+	if err := s.jobRepo.UpdateOutcome(ctx, job); err != nil {
+		return fmt.Errorf("failed to record job outcome: %w", err)
+	}
+	return nil
+}
+
+// ReplayJob persists a dead-lettered job made pending again for replay.
+// Without a job repository jobs are not tracked and there is nothing to update.
+func (s *EmailService) ReplayJob(ctx context.Context, job *models.EmailJob) error {
+	if s.jobRepo == nil {
+		return nil
+	}
+	if err := s.jobRepo.ResetForReplay(ctx, job); err != nil {
+		return fmt.Errorf("failed to replay job: %w", err)
+	}
+	return nil
+}
+
+// ProcessEmailJob renders the template of a queued job and sends it through the
+// email provider, recording on the job which provider sent it. Without a
+// provider the job fails rather than being reported sent.
 func (s *EmailService) ProcessEmailJob(ctx context.Context, job *models.EmailJob) error {
-	// TODO: Implement email job processing
-	// For now, return nil to avoid compilation errors
+	if s.emailProvider == nil {
+		job.ErrorMessage = ErrNoProvider.Error()
+		return ErrNoProvider
+	}
+	if len(job.Recipients) > 0 {
+		return s.processBatchJob(ctx, job)
+	}
+
+	request, err := s.buildEmailRequest(ctx, job, job.Variables)
+	if err != nil {
+		return err
+	}
+
+	response, err := s.emailProvider.Send(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	sentAt := time.Now()
+	job.SentAt = &sentAt
+	if response != nil {
+		job.Provider = response.Provider
+		job.MessageID = response.MessageID
+	}
 	return nil
 }
 
+// processBatchJob sends a batch job with a message per recipient, in which
+// the data of the recipient replace the template variables of the same name.
+// When some recipients fail, the job is left with the recipients it can be
+// retried for, or else with the ones that failed permanently.
+func (s *EmailService) processBatchJob(ctx context.Context, job *models.EmailJob) error {
+	// Variables set by any recipient render as placeholders, which the
+	// provider fills in for each recipient
+	variables := make(map[string]any, len(job.Variables))
+	for key, value := range job.Variables {
+		variables[key] = value
+	}
+	var keys []string
+	for _, recipient := range job.Recipients {
+		for key := range recipient.Data {
+			if variables[key] != providers.Placeholder(key) {
+				variables[key] = providers.Placeholder(key)
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	request, err := s.buildEmailRequest(ctx, job, variables)
+	if err != nil {
+		return err
+	}
+
+	batch := &providers.BatchRequest{Email: request, Recipients: make([]providers.BatchRecipient, len(job.Recipients))}
+	for i, recipient := range job.Recipients {
+		// Recipients without data of their own keep the job's variable
+		data := make(map[string]string, len(keys))
+		for _, key := range keys {
+			data[key] = ""
+			if value, ok := job.Variables[key]; ok {
+				data[key] = fmt.Sprint(value)
+			}
+		}
+		for key, value := range recipient.Data {
+			data[key] = value
+		}
+		batch.Recipients[i] = providers.BatchRecipient{To: recipient.To, CC: recipient.CC, BCC: recipient.BCC, Data: data}
+	}
+
+	results, err := s.emailProvider.SendBatch(ctx, batch)
+	if err != nil {
+		return fmt.Errorf("failed to send batch: %w", err)
+	}
+
+	var retryable, permanent models.BatchRecipients
+	var retryErr, permanentErr error
+	for i, result := range results {
+		if result.Err == nil {
+			if job.Provider == "" && result.Response != nil {
+				job.Provider = result.Response.Provider
+				job.MessageID = result.Response.MessageID
+			}
+			continue
+		}
+		if class, _ := providers.Classify(result.Err); class == providers.ErrorClassPermanent {
+			permanent = append(permanent, job.Recipients[i])
+			if permanentErr == nil {
+				permanentErr = result.Err
+			}
+		} else {
+			retryable = append(retryable, job.Recipients[i])
+			if retryErr == nil {
+				retryErr = result.Err
+			}
+		}
+	}
+
+	failed := len(retryable) + len(permanent)
+	if failed == 0 {
+		sentAt := time.Now()
+		job.SentAt = &sentAt
+		return nil
+	}
+
+	total := len(job.Recipients)
+	if len(retryable) > 0 {
+		job.Recipients = retryable
+		return fmt.Errorf("failed to send batch to %d of %d recipients: %w", failed, total, retryErr)
+	}
+	job.Recipients = permanent
+	return fmt.Errorf("failed to send batch to %d of %d recipients: %w", failed, total, permanentErr)
+}
+
+// RecordDelivery records which provider sent a tracked job
+func (s *EmailService) RecordDelivery(ctx context.Context, job *models.EmailJob) error {
+	if s.trackingRepo == nil || !job.IsTracked {
+		return nil
+	}
+
+	tracking := models.NewEmailTracking(job.ID, job.Provider)
+	if job.MessageID != "" {
+		tracking.SetMessageID(job.MessageID)
+	}
+	tracking.MarkAsSent()
+	if job.SentAt != nil {
+		tracking.SentAt = job.SentAt
+	}
+
+	if err := s.trackingRepo.Create(ctx, tracking); err != nil {
+		return fmt.Errorf("failed to record delivery: %w", err)
+	}
+	return nil
+}
+
+// buildEmailRequest renders the template of a job with variables into a
+// provider request. Without a template repository the placeholder content of
+// ProcessJob is sent.
+func (s *EmailService) buildEmailRequest(ctx context.Context, job *models.EmailJob, variables map[string]any) (*providers.EmailRequest, error) {
+	request := &providers.EmailRequest{
+		To:          job.To,
+		CC:          job.CC,
+		BCC:         job.BCC,
+		Subject:     "Test Email",
+		HTMLContent: "<h1>Test Email</h1>",
+		TextContent: "Test Email",
+		Template:    job.TemplateName,
+		Priority:    job.Priority.String(),
+		Tenant:      job.Tenant,
+		Metadata:    map[string]string{"job_id": job.ID.String()},
+	}
+	if job.TemplateName != "" {
+		request.Tags = []string{job.TemplateName}
+	}
+	if job.Tenant != "" {
+		request.Metadata["tenant"] = job.Tenant
+	}
+	if s.templateRepo == nil {
+		return request, nil
+	}
+
+	template, err := s.templateRepo.GetByID(ctx, job.TemplateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template %s: %w", job.TemplateName, err)
+	}
+
+	request.Subject, request.HTMLContent, request.TextContent, err = s.templateEngine.Render(template, variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", job.TemplateName, err)
+	}
+	return request, nil
+}
+
 // SendEmailRequest represents a request to send an email
 type SendEmailRequest struct {
 	To           []string               `json:"to"`
@@ -315,13 +609,21 @@ type SendEmailRequest struct {
 	TemplateName string                 `json:"template_name"`
 	Variables    map[string]any `json:"variables"`
 	Priority     models.JobPriority     `json:"priority"`
+	// Recipients make the email a batch, sent to each of them with their data
+	// in place of the variables of the same name
+	Recipients   []models.BatchRecipient `json:"recipients,omitempty"`
 }
 
 // Validate validates the send email request
 func (r *SendEmailRequest) Validate() error {
-	if len(r.To) == 0 {
+	if len(r.To) == 0 && len(r.Recipients) == 0 {
 		return fmt.Errorf("at least one recipient is required")
 	}
+	for _, recipient := range r.Recipients {
+		if len(recipient.To) == 0 {
+			return fmt.Errorf("every batch recipient needs a To address")
+		}
+	}
 	if r.TemplateName == "" {
 		return fmt.Errorf("template name is required")
 	}
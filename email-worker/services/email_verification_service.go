@@ -87,7 +87,7 @@ func (s *EmailVerificationService) SendVerificationEmail(ctx context.Context, da
 		nil,                      // BCC
 		"email_verification",     // Template name
 		variables,                // Variables
-		models.JobPriorityHigh,   // High priority for verification emails
+		models.JobPriorityUrgent, // PIN codes must not wait behind bulk mail
 	)
 
 	// Set user ID for tracking
@@ -105,7 +105,7 @@ func (s *EmailVerificationService) SendVerificationEmail(ctx context.Context, da
 		BCC:          nil,
 		TemplateName: "email_verification",
 		Variables:    variables,
-		Priority:     models.JobPriorityHigh,
+		Priority:     models.JobPriorityUrgent,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to send verification email: %w", err)
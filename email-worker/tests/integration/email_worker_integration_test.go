@@ -102,6 +102,22 @@ func TestEmailWorkerIntegration(t *testing.T) {
 	require.NoError(t, err)
 	defer queueInstance.Close()
 
+	deadLetters, err := queueFactory.CreateDeadLetterStore(queueConfig)
+	require.NoError(t, err)
+	defer deadLetters.Close()
+
+	idempotency, err := queueFactory.CreateIdempotencyStore(queueConfig)
+	require.NoError(t, err)
+	defer idempotency.Close()
+
+	leaders, err := queueFactory.CreateLeaderElector(queueConfig)
+	require.NoError(t, err)
+	defer leaders.Close()
+
+	heartbeats, err := queueFactory.CreateHeartbeatStore(queueConfig)
+	require.NoError(t, err)
+	defer heartbeats.Close()
+
 	// Initialize processor
 	processorConfig := &processor.ProcessorConfig{
 		WorkerCount:     cfg.Worker.WorkerCount,
@@ -113,7 +129,7 @@ func TestEmailWorkerIntegration(t *testing.T) {
 		CleanupInterval: cfg.Worker.CleanupInterval,
 	}
 
-	emailProcessor := processor.NewProcessor(queueInstance, emailService, processorConfig, logger)
+	emailProcessor := processor.NewProcessor(queueInstance, deadLetters, idempotency, leaders, heartbeats, emailService, processorConfig, logger)
 
 	// Start processor
 	err = emailProcessor.Start()